@@ -0,0 +1,82 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// accountHeaders returns the headers identifying the account on whose behalf
+// requests are made, and the token authorizing them. Headers are omitted for a
+// zero account ID or an empty token.
+func accountHeaders(accountID int64, token string) http.Header {
+	h := http.Header{}
+	if accountID != 0 {
+		h.Set(snowthapi.HeaderAccount, strconv.FormatInt(accountID, 10))
+	}
+
+	if token != "" {
+		h.Set(snowthapi.HeaderAuthToken, token)
+	}
+
+	return h
+}
+
+// setAccountHeaders replaces the account headers of a header set.
+func setAccountHeaders(h http.Header, accountID int64,
+	token string) http.Header {
+	if h == nil {
+		h = http.Header{}
+	}
+
+	h.Del(snowthapi.HeaderAccount)
+	h.Del(snowthapi.HeaderAuthToken)
+	for k, v := range accountHeaders(accountID, token) {
+		h[k] = v
+	}
+
+	return h
+}
+
+// WithAccount returns a copy of a context which will cause all requests made
+// using it to be sent on behalf of an account, with the X-Circonus-Account and
+// X-Circonus-Auth-Token headers expected by multi-tenant proxies in front of
+// IRONdb. These headers replace any account set on the client. A zero account
+// ID or an empty token is not sent, and the corresponding header of the
+// client, if any, is not sent either.
+func WithAccount(ctx context.Context, accountID int64,
+	token string) context.Context {
+	h := http.Header{
+		snowthapi.HeaderAccount:   nil,
+		snowthapi.HeaderAuthToken: nil,
+	}
+
+	for k, v := range accountHeaders(accountID, token) {
+		h[k] = v
+	}
+
+	return WithHeaders(ctx, h)
+}
+
+// SetAccount sets the account on whose behalf every request is made, and the
+// token authorizing the requests. A zero account ID and an empty token clear
+// the account.
+func (c *Config) SetAccount(accountID int64, token string) {
+	c.Lock()
+	defer c.Unlock()
+	c.headers = setAccountHeaders(c.headers, accountID, token)
+}
+
+// SetAccount sets the account on whose behalf every request is made, and the
+// token authorizing the requests. A zero account ID and an empty token clear
+// the account. Use WithAccount to make individual requests on behalf of other
+// accounts.
+func (sc *SnowthClient) SetAccount(accountID int64, token string) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.headers = setAccountHeaders(cloneHeaders(sc.headers), accountID,
+		token)
+}
@@ -0,0 +1,98 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestAccount(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var last http.Header
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			mu.Lock()
+			last = r.Header.Clone()
+			mu.Unlock()
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetAccount(1, "token1")
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	check := func(account, token string) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		if v := last.Get("X-Circonus-Account"); v != account {
+			t.Errorf("Expected account: %v, got: %v", account, v)
+		}
+
+		if v := last.Get("X-Circonus-Auth-Token"); v != token {
+			t.Errorf("Expected token: %v, got: %v", token, v)
+		}
+	}
+
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	check("1", "token1")
+	ctx := WithAccount(context.Background(), 2, "token2")
+	if _, _, err := sc.DoRequestContext(ctx, sc.GetActiveNode(), "GET",
+		"/stats.json", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	check("2", "token2")
+	ctx = WithAccount(context.Background(), 2, "")
+	if _, _, err := sc.DoRequestContext(ctx, sc.GetActiveNode(), "GET",
+		"/stats.json", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	check("2", "")
+	ctx = WithAccount(context.Background(), 0, "")
+	if _, _, err := sc.DoRequestContext(ctx, sc.GetActiveNode(), "GET",
+		"/stats.json", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	check("", "")
+	sc.SetAccount(3, "")
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	check("3", "")
+	sc.SetAccount(0, "")
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	check("", "")
+}
@@ -0,0 +1,162 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// maxAsyncWrites is the maximum number of writes sent with the AckNone
+// acknowledgment level which may be outstanding at once.
+const maxAsyncWrites = 64
+
+// AckLevel values control when a write operation is acknowledged to the
+// caller, trading latency for durability.
+type AckLevel int
+
+// Write acknowledgment levels.
+const (
+	// AckNodeAccepted writes return once the receiving node has successfully
+	// responded to the write request. This is the default level.
+	AckNodeAccepted AckLevel = iota
+
+	// AckNone writes are sent asynchronously and return without waiting for
+	// any response. Write errors are only logged. At most 64 writes are
+	// outstanding at once, and further writes wait for one of them to
+	// complete. Use WaitAsyncWrites() to wait for outstanding writes to
+	// complete.
+	AckNone
+
+	// AckJournaled raw writes return an error if the response of the
+	// receiving node reports any record as failed or misdirected. This is
+	// based only on the record counts reported by the receiving node; the
+	// client cannot verify that records were journaled by, or replicated to,
+	// their owning nodes. Numeric, text and histogram write endpoints do not
+	// report per record results, and are acknowledged as with
+	// AckNodeAccepted.
+	AckJournaled
+)
+
+// String returns a string representation of the acknowledgment level.
+func (al AckLevel) String() string {
+	switch al {
+	case AckNodeAccepted:
+		return "node_accepted"
+	case AckNone:
+		return "none"
+	case AckJournaled:
+		return "journaled"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(al))
+	}
+}
+
+// ackLevelKey is the context key used to store write acknowledgment levels.
+type ackLevelKey struct{}
+
+// WithAckLevel returns a copy of the context which specifies the write
+// acknowledgment level used by write operations performed with it, overriding
+// the default level of the client.
+func WithAckLevel(ctx context.Context, level AckLevel) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, ackLevelKey{}, level)
+}
+
+// SetAckLevel sets the default write acknowledgment level used by the client.
+func (sc *SnowthClient) SetAckLevel(level AckLevel) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.ackLevel = level
+}
+
+// getAckLevel returns the write acknowledgment level for an operation
+// performed with the specified context.
+func (sc *SnowthClient) getAckLevel(ctx context.Context) AckLevel {
+	if ctx != nil {
+		if level, ok := ctx.Value(ackLevelKey{}).(AckLevel); ok {
+			return level
+		}
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.ackLevel
+}
+
+// detachedContext values are contexts which keep the values of a parent
+// context, but are never cancelled and have no deadline.
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline returns no deadline.
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done returns a nil channel, since the context is never cancelled.
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err always returns nil.
+func (detachedContext) Err() error {
+	return nil
+}
+
+// writeAsync performs a write operation in the background, logging any
+// error, for writes using the AckNone acknowledgment level. The write is
+// performed with the values of the caller context, such as headers and
+// credentials, but is not cancelled with it. If too many writes are
+// outstanding, writeAsync waits for one of them to complete, or for the
+// context to be cancelled.
+func (sc *SnowthClient) writeAsync(ctx context.Context,
+	f func(ctx context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if sc.asyncSlots != nil {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context terminated: %w", ctx.Err())
+		case sc.asyncSlots <- struct{}{}:
+		}
+	}
+
+	sc.asyncWrites.Add(1)
+	go func() {
+		defer sc.asyncWrites.Done()
+		if sc.asyncSlots != nil {
+			defer func() { <-sc.asyncSlots }()
+		}
+
+		ctx := WithAckLevel(detachedContext{ctx}, AckNodeAccepted)
+		if err := f(ctx); err != nil {
+			sc.LogWarnf("asynchronous write failed: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// WaitAsyncWrites waits for all outstanding writes sent with the AckNone
+// acknowledgment level to complete, or for the context to be cancelled.
+func (sc *SnowthClient) WaitAsyncWrites(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		sc.asyncWrites.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context terminated: %w", ctx.Err())
+	case <-done:
+		return nil
+	}
+}
@@ -0,0 +1,177 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestAckLevel(t *testing.T) {
+	if AckJournaled.String() != "journaled" {
+		t.Errorf("Expected string: journaled, got: %v", AckJournaled.String())
+	}
+
+	sc := &SnowthClient{}
+	if sc.getAckLevel(context.Background()) != AckNodeAccepted {
+		t.Errorf("Expected default ack level: %v, got: %v", AckNodeAccepted,
+			sc.getAckLevel(context.Background()))
+	}
+
+	sc.SetAckLevel(AckJournaled)
+	if sc.getAckLevel(context.Background()) != AckJournaled {
+		t.Errorf("Expected ack level: %v, got: %v", AckJournaled,
+			sc.getAckLevel(context.Background()))
+	}
+
+	ctx := WithAckLevel(context.Background(), AckNone)
+	if sc.getAckLevel(ctx) != AckNone {
+		t.Errorf("Expected ack level: %v, got: %v", AckNone,
+			sc.getAckLevel(ctx))
+	}
+}
+
+func TestWriteAckLevels(t *testing.T) {
+	release := make(chan struct{})
+	var writes int32
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/numeric" {
+			<-release
+			atomic.AddInt32(&writes, 1)
+			return
+		}
+
+		if r.RequestURI == "/raw" {
+			_, _ = w.Write([]byte(`{"records":2,"updated":0,` +
+				`"misdirected":1,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	ctx := WithAckLevel(context.Background(), AckNone)
+	if err := sc.WriteNumericContext(ctx, []NumericWrite{{}}, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&writes) != 0 {
+		t.Error("Expected write to be outstanding")
+	}
+
+	wctx, cancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer cancel()
+	if err := sc.WaitAsyncWrites(wctx); err == nil {
+		t.Error("Expected wait timeout error")
+	}
+
+	close(release)
+	if err := sc.WaitAsyncWrites(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&writes) != 1 {
+		t.Errorf("Expected writes: 1, got: %v", atomic.LoadInt32(&writes))
+	}
+
+	if _, err := sc.WriteRaw(bytes.NewBufferString("test"), true, 2,
+		node); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx = WithAckLevel(context.Background(), AckJournaled)
+	res, err := sc.WriteRawContext(ctx, bytes.NewBufferString("test"), true,
+		2, node)
+	if err == nil {
+		t.Error("Expected write not journaled error")
+	}
+
+	if res == nil || res.Misdirected != 1 {
+		t.Errorf("Expected misdirected: 1, got: %v", res)
+	}
+}
+
+func TestWriteAsync(t *testing.T) {
+	type testKey struct{}
+	sc := &SnowthClient{asyncSlots: make(chan struct{}, 1)}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(),
+		testKey{}, "value"))
+	release := make(chan struct{})
+	res := make(chan error, 1)
+	if err := sc.writeAsync(ctx, func(ctx context.Context) error {
+		<-release
+		if ctx.Value(testKey{}) != "value" {
+			t.Error("Expected context value in asynchronous write")
+		}
+
+		if sc.getAckLevel(ctx) != AckNodeAccepted {
+			t.Errorf("Expected ack level: %v, got: %v", AckNodeAccepted,
+				sc.getAckLevel(ctx))
+		}
+
+		res <- ctx.Err()
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+	wctx, wcancel := context.WithTimeout(context.Background(),
+		10*time.Millisecond)
+	defer wcancel()
+	if err := sc.writeAsync(wctx, func(ctx context.Context) error {
+		return nil
+	}); err == nil {
+		t.Error("Expected error waiting for asynchronous write slot")
+	}
+
+	close(release)
+	if err := <-res; err != nil {
+		t.Errorf("Expected detached context, got error: %v", err)
+	}
+
+	if err := sc.WaitAsyncWrites(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.writeAsync(context.Background(),
+		func(ctx context.Context) error {
+			return nil
+		}); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+
+	if err := sc.WaitAsyncWrites(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -4,6 +4,10 @@ package gosnowth
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // RebuildActivityRequest values represent a request to rebuild activity tracking data.
@@ -27,15 +31,166 @@ func (sc *SnowthClient) RebuildActivityContext(ctx context.Context,
 		return nil, err
 	}
 
-	body, _, err := sc.DoRequestContext(ctx, node, "POST", "/surrogate/activity_rebuild", data, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "POST",
+		snowthapi.PathSurrogateActivityRebuild, data, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	r := &IRONdbPutResponse{}
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
 	return r, nil
 }
+
+// ActivityCoverage values summarize the activity ranges of a metric.
+type ActivityCoverage struct {
+	// First and Last are the start of the earliest, and end of the latest,
+	// activity ranges.
+	First time.Time
+	Last  time.Time
+
+	// Active is the total duration during which the metric was active, with
+	// overlapping ranges counted once.
+	Active time.Duration
+
+	// Coverage is the percentage, from 0 to 100, of the summarized window
+	// during which the metric was active.
+	Coverage float64
+}
+
+// MergeActivity returns a sorted copy of a list of activity ranges, as returned
+// in FindTagsItem values, with overlapping and adjacent ranges merged. Invalid
+// ranges are discarded.
+func MergeActivity(activity [][]int64) [][]int64 {
+	r := make([][]int64, 0, len(activity))
+	for _, a := range activity {
+		if len(a) < 2 || a[1] < a[0] {
+			continue
+		}
+
+		r = append(r, []int64{a[0], a[1]})
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i][0] < r[j][0]
+	})
+
+	merged := make([][]int64, 0, len(r))
+	for _, a := range r {
+		if n := len(merged); n > 0 && a[0] <= merged[n-1][1] {
+			if a[1] > merged[n-1][1] {
+				merged[n-1][1] = a[1]
+			}
+
+			continue
+		}
+
+		merged = append(merged, a)
+	}
+
+	return merged
+}
+
+// SummarizeActivity merges a list of activity ranges, as returned in
+// FindTagsItem values, and summarizes them over the window from start to end.
+// Activity outside the window is ignored. If the window is empty, the activity
+// is summarized over the period from the first to the last active timestamp.
+func SummarizeActivity(activity [][]int64,
+	start, end time.Time) *ActivityCoverage {
+	merged := MergeActivity(activity)
+	ws, we := start.Unix(), end.Unix()
+	if !end.After(start) {
+		if len(merged) == 0 {
+			return &ActivityCoverage{}
+		}
+
+		ws, we = merged[0][0], merged[len(merged)-1][1]
+	}
+
+	r := &ActivityCoverage{}
+	var active int64
+	for _, a := range merged {
+		s, e := a[0], a[1]
+		if s < ws {
+			s = ws
+		}
+
+		if e > we {
+			e = we
+		}
+
+		// Skip ranges entirely outside the window.
+		if e < s || (e == s && a[0] != a[1]) {
+			continue
+		}
+
+		if r.First.IsZero() {
+			r.First = time.Unix(s, 0)
+		}
+
+		r.Last = time.Unix(e, 0)
+		active += e - s
+	}
+
+	r.Active = time.Duration(active) * time.Second
+	if we > ws {
+		r.Coverage = float64(active) / float64(we-ws) * 100
+	}
+
+	return r
+}
+
+// ActivityCoverage summarizes the activity ranges of a metric over the window
+// from start to end.
+func (fi *FindTagsItem) ActivityCoverage(start,
+	end time.Time) *ActivityCoverage {
+	return SummarizeActivity(fi.Activity, start, end)
+}
+
+// ActivityWindow values represent a period during which a metric was active.
+type ActivityWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps returns true if the activity window overlaps the window from start
+// to end, inclusive.
+func (aw ActivityWindow) Overlaps(start, end time.Time) bool {
+	return !aw.Start.After(end) && !aw.End.Before(start)
+}
+
+// ActivityWindows converts a list of activity ranges, as returned in
+// FindTagsItem values, into a sorted list of activity windows, with
+// overlapping and adjacent ranges merged.
+func ActivityWindows(activity [][]int64) []ActivityWindow {
+	merged := MergeActivity(activity)
+	r := make([]ActivityWindow, len(merged))
+	for i, a := range merged {
+		r[i] = ActivityWindow{
+			Start: time.Unix(a[0], 0),
+			End:   time.Unix(a[1], 0),
+		}
+	}
+
+	return r
+}
+
+// ActivityWindows returns the merged activity windows of a metric.
+func (fi *FindTagsItem) ActivityWindows() []ActivityWindow {
+	return ActivityWindows(fi.Activity)
+}
+
+// ActiveDuring returns true if the metric was active at any time during the
+// window from start to end, inclusive.
+func (fi *FindTagsItem) ActiveDuring(start, end time.Time) bool {
+	for _, aw := range fi.ActivityWindows() {
+		if aw.Overlaps(start, end) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,8 +7,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRebuildActivity(t *testing.T) {
@@ -73,3 +75,82 @@ func TestRebuildActivity(t *testing.T) {
 		t.Errorf("Expected context error, got: %v", err.Error())
 	}
 }
+
+func TestMergeActivity(t *testing.T) {
+	t.Parallel()
+
+	res := MergeActivity([][]int64{
+		{300, 600}, {0, 100}, {50, 200}, {600, 700}, {900, 800}, {1000},
+	})
+
+	exp := [][]int64{{0, 200}, {300, 700}}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("Expected activity: %v, got: %v", exp, res)
+	}
+}
+
+func TestSummarizeActivity(t *testing.T) {
+	t.Parallel()
+
+	act := [][]int64{{100, 200}, {150, 300}, {500, 600}}
+	res := SummarizeActivity(act, time.Unix(0, 0), time.Unix(1000, 0))
+	if res.Active != 300*time.Second {
+		t.Errorf("Expected active: 5m, got: %v", res.Active)
+	}
+
+	if res.First.Unix() != 100 || res.Last.Unix() != 600 {
+		t.Errorf("Expected first and last: 100 600, got: %v %v",
+			res.First.Unix(), res.Last.Unix())
+	}
+
+	if res.Coverage != 30 {
+		t.Errorf("Expected coverage: 30, got: %v", res.Coverage)
+	}
+
+	res = SummarizeActivity(act, time.Unix(250, 0), time.Unix(550, 0))
+	if res.Active != 100*time.Second {
+		t.Errorf("Expected active: 100s, got: %v", res.Active)
+	}
+
+	if res.First.Unix() != 250 || res.Last.Unix() != 550 {
+		t.Errorf("Expected first and last: 250 550, got: %v %v",
+			res.First.Unix(), res.Last.Unix())
+	}
+
+	res = SummarizeActivity(act, time.Unix(300, 0), time.Unix(500, 0))
+	if res.Active != 0 || !res.First.IsZero() || res.Coverage != 0 {
+		t.Errorf("Expected no activity, got: %+v", res)
+	}
+
+	fi := &FindTagsItem{Activity: act}
+	res = fi.ActivityCoverage(time.Time{}, time.Time{})
+	if res.Coverage != 60 {
+		t.Errorf("Expected coverage: 60, got: %v", res.Coverage)
+	}
+}
+
+func TestActivityWindows(t *testing.T) {
+	t.Parallel()
+	fi := &FindTagsItem{Activity: [][]int64{{400, 600}, {100, 200}, {150, 300}}}
+	res := fi.ActivityWindows()
+	if len(res) != 2 {
+		t.Fatalf("Expected windows: 2, got: %v", len(res))
+	}
+
+	if res[0].Start.Unix() != 100 || res[0].End.Unix() != 300 {
+		t.Errorf("Expected window: 100 300, got: %v %v",
+			res[0].Start.Unix(), res[0].End.Unix())
+	}
+
+	if !fi.ActiveDuring(time.Unix(250, 0), time.Unix(350, 0)) {
+		t.Errorf("Expected active during: 250 350")
+	}
+
+	if !fi.ActiveDuring(time.Unix(600, 0), time.Unix(700, 0)) {
+		t.Errorf("Expected active during: 600 700")
+	}
+
+	if fi.ActiveDuring(time.Unix(301, 0), time.Unix(399, 0)) {
+		t.Errorf("Expected inactive during: 301 399")
+	}
+}
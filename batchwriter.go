@@ -0,0 +1,352 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default batch writer settings.
+const (
+	defaultBatchSize          = 1000
+	defaultBatchFlushInterval = time.Second
+	defaultBatchRetries       = 3
+	defaultBatchRetryDelay    = 500 * time.Millisecond
+)
+
+// BatchWriterOptions values contain optional parameters used to control the
+// behavior of a batch writer.
+type BatchWriterOptions struct {
+	// BatchSize is the number of buffered records of any kind which causes
+	// the buffer to be flushed, and the maximum number of records sent in a
+	// single write request. The default is 1000.
+	BatchSize int
+
+	// FlushInterval is the maximum duration records are buffered before
+	// they are flushed. The default is one second.
+	FlushInterval time.Duration
+
+	// MaxBuffered is the maximum number of records held by the writer,
+	// including records which are being written. Enqueue operations wait
+	// for records to be written when the limit would be exceeded. The
+	// default is ten times the batch size.
+	MaxBuffered int
+
+	// Retries is the number of times a failed write request is retried
+	// before its records are dropped. The default is 3. A negative value
+	// disables retries.
+	Retries int
+
+	// RetryDelay is the delay before the first retry of a failed write
+	// request, which doubles for each subsequent retry. The default is 500
+	// milliseconds.
+	RetryDelay time.Duration
+
+	// OnError, if set, is called with the error and the number of records
+	// dropped when a write request fails after all retries.
+	OnError func(err error, records int)
+}
+
+// BatchWriterStats values contain the counts of records handled by a batch
+// writer.
+type BatchWriterStats struct {
+	Enqueued int64
+	Written  int64
+	Dropped  int64
+	Buffered int
+}
+
+// BatchWriter values buffer NNT, text and histogram data enqueued by callers,
+// and write it to IRONdb in batches, when the number of buffered records
+// reaches the batch size or the flush interval elapses. Failed writes are
+// retried, and memory use is bounded by the maximum number of buffered
+// records. All buffered data is written when the writer is closed. Batch
+// writers are safe for concurrent use.
+type BatchWriter struct {
+	sc      *SnowthClient
+	opts    BatchWriterOptions
+	flushMu sync.Mutex
+	mu      sync.Mutex
+	nnt     []NNTData
+	text    []TextData
+	hist    []HistogramData
+	space   chan struct{}
+	stats   BatchWriterStats
+	closed  bool
+	flush   chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// NewBatchWriter creates a new batch writer which writes data using the
+// client, and starts its background flush process.
+func (sc *SnowthClient) NewBatchWriter(
+	options *BatchWriterOptions) *BatchWriter {
+	opts := BatchWriterOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultBatchFlushInterval
+	}
+
+	if opts.MaxBuffered <= 0 {
+		opts.MaxBuffered = 10 * opts.BatchSize
+	}
+
+	if opts.Retries == 0 {
+		opts.Retries = defaultBatchRetries
+	} else if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = defaultBatchRetryDelay
+	}
+
+	bw := &BatchWriter{
+		sc:      sc,
+		opts:    opts,
+		space:   make(chan struct{}),
+		flush:   make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go bw.run()
+	return bw
+}
+
+// run flushes the buffered data when the batch size is reached or the flush
+// interval elapses, until the writer is closed.
+func (bw *BatchWriter) run() {
+	defer close(bw.stopped)
+	tick := bw.sc.getClock().NewTicker(bw.opts.FlushInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-bw.stop:
+			return
+		case <-tick.C():
+		case <-bw.flush:
+		}
+
+		if err := bw.Flush(context.Background()); err != nil {
+			bw.sc.LogWarnw("gosnowth batch writer flush failed", "error", err)
+		}
+	}
+}
+
+// enqueue adds n records to the buffer using the add function, waiting for
+// buffer space if needed.
+func (bw *BatchWriter) enqueue(ctx context.Context, n int, add func()) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	for {
+		bw.mu.Lock()
+		if bw.closed {
+			bw.mu.Unlock()
+			return ErrClosed
+		}
+
+		if bw.stats.Buffered == 0 ||
+			bw.stats.Buffered+n <= bw.opts.MaxBuffered {
+			add()
+			bw.stats.Buffered += n
+			bw.stats.Enqueued += int64(n)
+			full := len(bw.nnt)+len(bw.text)+len(bw.hist) >= bw.opts.BatchSize
+			bw.mu.Unlock()
+			if full {
+				select {
+				case bw.flush <- struct{}{}:
+				default:
+				}
+			}
+
+			return nil
+		}
+
+		space := bw.space
+		bw.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context terminated: %w", ctx.Err())
+		case <-space:
+		}
+	}
+}
+
+// EnqueueNNT adds NNT data to the buffer, waiting for buffer space if the
+// maximum number of buffered records would be exceeded. ErrClosed is returned
+// if the writer has been closed.
+func (bw *BatchWriter) EnqueueNNT(ctx context.Context, data ...NNTData) error {
+	return bw.enqueue(ctx, len(data), func() {
+		bw.nnt = append(bw.nnt, data...)
+	})
+}
+
+// EnqueueText adds text data to the buffer, waiting for buffer space if the
+// maximum number of buffered records would be exceeded. ErrClosed is returned
+// if the writer has been closed.
+func (bw *BatchWriter) EnqueueText(ctx context.Context,
+	data ...TextData) error {
+	return bw.enqueue(ctx, len(data), func() {
+		bw.text = append(bw.text, data...)
+	})
+}
+
+// EnqueueHistogram adds histogram data to the buffer, waiting for buffer space
+// if the maximum number of buffered records would be exceeded. ErrClosed is
+// returned if the writer has been closed.
+func (bw *BatchWriter) EnqueueHistogram(ctx context.Context,
+	data ...HistogramData) error {
+	return bw.enqueue(ctx, len(data), func() {
+		bw.hist = append(bw.hist, data...)
+	})
+}
+
+// release frees buffer space used by n records which have been written or
+// dropped, waking any waiting enqueue operations.
+func (bw *BatchWriter) release(n int, written bool) {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	bw.stats.Buffered -= n
+	if written {
+		bw.stats.Written += int64(n)
+	} else {
+		bw.stats.Dropped += int64(n)
+	}
+
+	close(bw.space)
+	bw.space = make(chan struct{})
+}
+
+// send performs a write request for n records, retrying it on failure, and
+// releases the buffer space of the records once they are written or dropped.
+func (bw *BatchWriter) send(ctx context.Context, n int,
+	write func(ctx context.Context) error) error {
+	delay := bw.opts.RetryDelay
+	err := write(ctx)
+	for r := 0; err != nil && r < bw.opts.Retries && ctx.Err() == nil; r++ {
+		select {
+		case <-ctx.Done():
+		case <-bw.sc.getClock().After(delay):
+			err = write(ctx)
+		}
+
+		delay *= 2
+	}
+
+	bw.release(n, err == nil)
+	if err != nil && bw.opts.OnError != nil {
+		bw.opts.OnError(err, n)
+	}
+
+	return err
+}
+
+// Flush writes all buffered data, returning the errors of any write requests
+// which failed after all retries. The records of failed requests are
+// dropped.
+func (bw *BatchWriter) Flush(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	bw.flushMu.Lock()
+	defer bw.flushMu.Unlock()
+	bw.mu.Lock()
+	nnt, text, hist := bw.nnt, bw.text, bw.hist
+	bw.nnt, bw.text, bw.hist = nil, nil, nil
+	bw.mu.Unlock()
+	mErr := newMultiError()
+	size := bw.opts.BatchSize
+	for i := 0; i < len(nnt); i += size {
+		b := nnt[i:minInt(i+size, len(nnt))]
+		mErr.Add(bw.send(ctx, len(b), func(ctx context.Context) error {
+			return bw.sc.WriteNNTContext(ctx, b)
+		}))
+	}
+
+	for i := 0; i < len(text); i += size {
+		b := text[i:minInt(i+size, len(text))]
+		mErr.Add(bw.send(ctx, len(b), func(ctx context.Context) error {
+			return bw.sc.WriteTextContext(ctx, b)
+		}))
+	}
+
+	for i := 0; i < len(hist); i += size {
+		b := hist[i:minInt(i+size, len(hist))]
+		mErr.Add(bw.send(ctx, len(b), func(ctx context.Context) error {
+			return bw.sc.WriteHistogramContext(ctx, b)
+		}))
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// Stats returns the counts of records handled by the writer.
+func (bw *BatchWriter) Stats() BatchWriterStats {
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+	return bw.stats
+}
+
+// Close closes the writer, waiting without limit for all buffered data to be
+// written. See Shutdown.
+func (bw *BatchWriter) Close() error {
+	return bw.Shutdown(context.Background())
+}
+
+// Shutdown closes the writer, stopping its background flush process and
+// writing all buffered data, or until the context is cancelled. Enqueue
+// operations made after Shutdown is called fail with ErrClosed. If the context
+// is cancelled before the background flush process stops, an error is
+// returned and the buffered data is kept, to be written by a later call to
+// Close or Shutdown. Records whose write requests are cancelled are dropped.
+// Calling Shutdown once all data has been written does nothing.
+func (bw *BatchWriter) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	bw.mu.Lock()
+	if !bw.closed {
+		bw.closed = true
+		close(bw.space)
+		bw.space = make(chan struct{})
+		close(bw.stop)
+	}
+
+	bw.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("context terminated with %d records unwritten: %w",
+			bw.Stats().Buffered, ctx.Err())
+	case <-bw.stopped:
+	}
+
+	return bw.Flush(ctx)
+}
+
+// minInt returns the smaller of two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,333 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBatchWriterTestServer creates a test server which serves write requests
+// using the write handler.
+func newBatchWriterTestServer(t *testing.T,
+	write func(w http.ResponseWriter, r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml") {
+			_, _ = w.Write([]byte(topologyXMLTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/write/") ||
+			r.RequestURI == "/histogram/write" {
+			write(w, r)
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+}
+
+func TestBatchWriter(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	writes := map[string]int{}
+	ms := newBatchWriterTestServer(t, func(w http.ResponseWriter,
+		r *http.Request) {
+		mu.Lock()
+		writes[r.URL.Path]++
+		mu.Unlock()
+		w.WriteHeader(200)
+	})
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	bw := sc.NewBatchWriter(&BatchWriterOptions{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+
+	id := "1f846f26-0cfd-4df5-b4f1-e0930604e577"
+	for i := 0; i < 5; i++ {
+		if err := bw.EnqueueNNT(context.Background(), NNTData{
+			Count: 1, Value: 1, Metric: "test", ID: id, Offset: 1,
+			Parts: Parts{Period: 60},
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := bw.EnqueueText(context.Background(), TextData{
+		Metric: "test", ID: id, Offset: "1", Value: "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bw.EnqueueHistogram(context.Background(), HistogramData{
+		Metric: "test", ID: id, Offset: 1, Period: 60,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st := bw.Stats()
+	if st.Enqueued != 7 || st.Written != 7 || st.Dropped != 0 ||
+		st.Buffered != 0 {
+		t.Errorf("Expected stats: 7 enqueued and written, got: %+v", st)
+	}
+
+	mu.Lock()
+	if n := writes["/write/nnt"]; n < 3 {
+		t.Errorf("Expected NNT writes: at least 3, got: %v", n)
+	}
+
+	if n := writes["/write/text"]; n != 1 {
+		t.Errorf("Expected text writes: 1, got: %v", n)
+	}
+
+	if n := writes["/histogram/write"]; n != 1 {
+		t.Errorf("Expected histogram writes: 1, got: %v", n)
+	}
+
+	mu.Unlock()
+	err = bw.EnqueueText(context.Background(), TextData{Metric: "test"})
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+
+	if err := bw.Close(); err != nil {
+		t.Errorf("Expected no error closing again, got: %v", err)
+	}
+}
+
+func TestBatchWriterInterval(t *testing.T) {
+	t.Parallel()
+	written := make(chan struct{}, 1)
+	ms := newBatchWriterTestServer(t, func(w http.ResponseWriter,
+		r *http.Request) {
+		w.WriteHeader(200)
+		select {
+		case written <- struct{}{}:
+		default:
+		}
+	})
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	mc := NewManualClock(time.Unix(1000, 0))
+	sc.SetClock(mc)
+	bw := sc.NewBatchWriter(&BatchWriterOptions{FlushInterval: time.Second})
+	defer func() {
+		if err := bw.Close(); err != nil {
+			t.Error(err)
+		}
+	}()
+
+	if err := bw.EnqueueText(context.Background(), TextData{
+		Metric: "test", ID: "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		Offset: "1", Value: "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	timeout := time.After(5 * time.Second)
+	for {
+		mc.Advance(time.Second)
+		select {
+		case <-written:
+			return
+		case <-timeout:
+			t.Fatal("Expected buffered data to be flushed at the interval")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestBatchWriterBounded(t *testing.T) {
+	t.Parallel()
+	block := make(chan struct{})
+	ms := newBatchWriterTestServer(t, func(w http.ResponseWriter,
+		r *http.Request) {
+		<-block
+		w.WriteHeader(200)
+	})
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	bw := sc.NewBatchWriter(&BatchWriterOptions{
+		BatchSize:     1,
+		MaxBuffered:   1,
+		FlushInterval: time.Hour,
+	})
+
+	td := TextData{
+		Metric: "test", ID: "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		Offset: "1", Value: "test",
+	}
+
+	if err := bw.EnqueueText(context.Background(), td); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+	err = bw.EnqueueText(ctx, td)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error: %v, got: %v", context.DeadlineExceeded, err)
+	}
+
+	close(block)
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := bw.Stats(); st.Enqueued != 1 || st.Written != 1 {
+		t.Errorf("Expected stats: 1 enqueued and written, got: %+v", st)
+	}
+}
+
+func TestBatchWriterShutdownRetry(t *testing.T) {
+	t.Parallel()
+	block := make(chan struct{})
+	var mu sync.Mutex
+	writes := 0
+	ms := newBatchWriterTestServer(t, func(w http.ResponseWriter,
+		r *http.Request) {
+		<-block
+		mu.Lock()
+		writes++
+		mu.Unlock()
+		w.WriteHeader(200)
+	})
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	bw := sc.NewBatchWriter(&BatchWriterOptions{
+		BatchSize:     1,
+		FlushInterval: time.Hour,
+	})
+
+	id := "1f846f26-0cfd-4df5-b4f1-e0930604e577"
+	for i := 0; i < 2; i++ {
+		if err := bw.EnqueueText(context.Background(), TextData{
+			Metric: "test", ID: id, Offset: "1", Value: "test",
+		}); err != nil {
+			t.Fatal(err)
+		}
+
+		// Wait for the background flush of the first record to block.
+		for i == 0 && len(bw.flush) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(),
+		50*time.Millisecond)
+	defer cancel()
+	if err := bw.Shutdown(ctx); err == nil {
+		t.Error("Expected error, got: nil")
+	}
+
+	close(block)
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if st := bw.Stats(); st.Written != 2 || st.Dropped != 0 ||
+		st.Buffered != 0 {
+		t.Errorf("Expected written: 2, dropped: 0, buffered: 0, got: %+v", st)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if writes != 2 {
+		t.Errorf("Expected writes: 2, got: %v", writes)
+	}
+}
+
+func TestBatchWriterDropped(t *testing.T) {
+	t.Parallel()
+	ms := newBatchWriterTestServer(t, func(w http.ResponseWriter,
+		r *http.Request) {
+		w.WriteHeader(400)
+	})
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	var mu sync.Mutex
+	dropped := 0
+	bw := sc.NewBatchWriter(&BatchWriterOptions{
+		FlushInterval: time.Hour,
+		Retries:       1,
+		RetryDelay:    time.Millisecond,
+		OnError: func(err error, records int) {
+			mu.Lock()
+			dropped += records
+			mu.Unlock()
+		},
+	})
+
+	if err := bw.EnqueueText(context.Background(), TextData{
+		Metric: "test", ID: "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		Offset: "1", Value: "test",
+	}, TextData{
+		Metric: "test", ID: "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		Offset: "2", Value: "test",
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bw.Close(); err == nil {
+		t.Error("Expected error for failed write")
+	}
+
+	mu.Lock()
+	if dropped != 2 {
+		t.Errorf("Expected dropped records: 2, got: %v", dropped)
+	}
+
+	mu.Unlock()
+	if st := bw.Stats(); st.Dropped != 2 || st.Buffered != 0 {
+		t.Errorf("Expected stats: 2 dropped, got: %+v", st)
+	}
+}
@@ -0,0 +1,80 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// NumericReadRequest values describe one numeric data read performed by a
+// bulk read. The Type value is the kind of numeric data read, such as
+// "average" or "count", as used by ReadNumericValues. If Node is set, the
+// request is sent to that node, otherwise it is sent to a node which owns the
+// metric.
+type NumericReadRequest struct {
+	Start  time.Time
+	End    time.Time
+	Period int64
+	Type   string
+	UUID   string
+	Metric string
+	Node   *SnowthNode
+}
+
+// NumericReadResult values contain the result of one request of a bulk read.
+type NumericReadResult struct {
+	Request NumericReadRequest
+	Values  []NumericValue
+	Err     error
+}
+
+// ReadNumericValuesBulk reads numeric data for many metrics, sending at most
+// concurrency requests at the same time, spread across the nodes which own the
+// metrics. The results are returned in the same order as the requests, each
+// with the values read or the error which occurred. The returned error
+// combines the errors of all requests which failed. A concurrency value of
+// zero or less uses the default concurrency of a work group.
+func (sc *SnowthClient) ReadNumericValuesBulk(requests []NumericReadRequest,
+	concurrency int) ([]NumericReadResult, error) {
+	return sc.ReadNumericValuesBulkContext(context.Background(), requests,
+		concurrency)
+}
+
+// ReadNumericValuesBulkContext is the context aware version of
+// ReadNumericValuesBulk.
+func (sc *SnowthClient) ReadNumericValuesBulkContext(ctx context.Context,
+	requests []NumericReadRequest,
+	concurrency int) ([]NumericReadResult, error) {
+	res := make([]NumericReadResult, len(requests))
+	g := sc.NewWorkGroup(ctx, concurrency)
+	for i := range requests {
+		r := &res[i]
+		r.Request = requests[i]
+		if err := g.Go(func(ctx context.Context) error {
+			r.Values, r.Err = sc.ReadNumericValuesContext(ctx,
+				r.Request.Start, r.Request.End, r.Request.Period,
+				r.Request.Type, r.Request.UUID, r.Request.Metric,
+				r.Request.Node)
+			return nil
+		}); err != nil {
+			r.Err = err
+		}
+	}
+
+	// Errors are recorded in each result, and combined below.
+	_ = g.Wait()
+	mErr := newMultiError()
+	for _, r := range res {
+		if r.Err != nil {
+			mErr.Add(fmt.Errorf("%s %s: %w", r.Request.UUID, r.Request.Metric,
+				r.Err))
+		}
+	}
+
+	if mErr.HasError() {
+		return res, mErr
+	}
+
+	return res, nil
+}
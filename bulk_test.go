@@ -0,0 +1,123 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadNumericValuesBulk(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	active, peak := 0, 0
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		u := "/read/1529509020/1529509200/1/" +
+			"fc85e0ab-f568-45e6-86ee-d7443be8277d/count/"
+		if strings.HasPrefix(r.RequestURI, u) {
+			mu.Lock()
+			active++
+			if active > peak {
+				peak = active
+			}
+
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			active--
+			mu.Unlock()
+			if strings.HasSuffix(r.RequestURI, "/missing") {
+				w.WriteHeader(404)
+				return
+			}
+
+			_, _ = w.Write([]byte(numericTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	reqs := []NumericReadRequest{}
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, NumericReadRequest{
+			Start:  time.Unix(1529509020, 0),
+			End:    time.Unix(1529509200, 0),
+			Period: 1,
+			Type:   "count",
+			UUID:   "fc85e0ab-f568-45e6-86ee-d7443be8277d",
+			Metric: "test" + strconv.Itoa(i),
+			Node:   node,
+		})
+	}
+
+	reqs[3].Metric = "missing"
+	res, err := sc.ReadNumericValuesBulk(reqs, 3)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected error: %v, got: %v", ErrNotFound, err)
+	}
+
+	if len(res) != len(reqs) {
+		t.Fatalf("Expected results: %v, got: %v", len(reqs), len(res))
+	}
+
+	for i, r := range res {
+		if r.Request.Metric != reqs[i].Metric {
+			t.Errorf("Expected metric: %v, got: %v", reqs[i].Metric,
+				r.Request.Metric)
+		}
+
+		if i == 3 {
+			if !IsNotFound(r.Err) {
+				t.Errorf("Expected error: %v, got: %v", ErrNotFound, r.Err)
+			}
+
+			continue
+		}
+
+		if r.Err != nil {
+			t.Errorf("Expected no error, got: %v", r.Err)
+		}
+
+		if len(r.Values) != 2 || r.Values[0].Value != 50 {
+			t.Errorf("Expected values: %v, got: %v", numericTestData,
+				r.Values)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if peak > 3 {
+		t.Errorf("Expected concurrent requests: <= 3, got: %v", peak)
+	}
+}
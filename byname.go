@@ -0,0 +1,181 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// encodeTagQueryPart encodes a tag category or value for use in a tag search
+// query, using the base64 literal syntax unless it is already encoded.
+func encodeTagQueryPart(s string) string {
+	if strings.HasPrefix(s, `b"`) && strings.HasSuffix(s, `"`) {
+		return s
+	}
+
+	return encodeTagLiteral(s)
+}
+
+// metricNameQuery returns a tag search query which matches the metric with the
+// specified canonical name, including its stream tags.
+func metricNameQuery(name string) string {
+	base, tags := splitStreamTags(name)
+	parts := []string{"__name:" + encodeTagQueryPart(base)}
+	if tags != "" {
+		for _, tag := range strings.Split(tags, ",") {
+			kv := strings.SplitN(tag, ":", 2)
+			if len(kv) == 1 {
+				kv = append(kv, "")
+			}
+
+			parts = append(parts, encodeTagQueryPart(kv[0])+":"+
+				encodeTagQueryPart(kv[1]))
+		}
+	}
+
+	return "and(" + strings.Join(parts, ",") + ")"
+}
+
+// ResolveMetricUUID finds the check UUID of the metric with the specified
+// account ID and canonical metric name, including any stream tags. An error is
+// returned if no metric, or metrics in more than one check, match the name.
+// Resolutions are cached in the resolution cache set with SetResolveCache, or
+// in memory for five minutes if none is set.
+func (sc *SnowthClient) ResolveMetricUUID(accountID int64, name string,
+	nodes ...*SnowthNode) (string, error) {
+	return sc.ResolveMetricUUIDContext(context.Background(), accountID, name,
+		nodes...)
+}
+
+// ResolveMetricUUIDContext is the context aware version of
+// ResolveMetricUUID.
+func (sc *SnowthClient) ResolveMetricUUIDContext(ctx context.Context,
+	accountID int64, name string, nodes ...*SnowthNode) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("metric name required")
+	}
+
+	key := metricUUIDKey(accountID, name)
+	rc := sc.metricUUIDCache()
+	uuid := ""
+	if sc.cacheGet(rc, key, &uuid) {
+		return uuid, nil
+	}
+
+	res, err := sc.FindTagsContext(ctx, accountID, metricNameQuery(name),
+		&FindTagsOptions{}, nodes...)
+	if err != nil {
+		return "", fmt.Errorf("unable to find metric: %w", err)
+	}
+
+	base, tags := splitStreamTags(name)
+	for _, item := range res.Items {
+		if b, t := splitStreamTags(item.MetricName); b != base || t != tags {
+			continue
+		}
+
+		if uuid != "" && uuid != item.UUID {
+			return "", fmt.Errorf("ambiguous metric name: %s: found in "+
+				"multiple checks", name)
+		}
+
+		uuid = item.UUID
+	}
+
+	if uuid == "" {
+		return "", fmt.Errorf("metric %w: account %d: %s", ErrNotFound,
+			accountID, name)
+	}
+
+	sc.cachePut(rc, key, uuid)
+	return uuid, nil
+}
+
+// ReadNumericValuesByName reads numeric data for the metric with the specified
+// account ID and canonical metric name, without requiring the check UUID.
+// The IRONdb read endpoints identify metrics by check UUID and metric name,
+// so the check UUID is resolved by an exact tag search, as ResolveMetricUUID
+// does, before the data is read.
+func (sc *SnowthClient) ReadNumericValuesByName(accountID int64, name string,
+	start, end time.Time, period int64, t string,
+	nodes ...*SnowthNode) ([]NumericValue, error) {
+	return sc.ReadNumericValuesByNameContext(context.Background(), accountID,
+		name, start, end, period, t, nodes...)
+}
+
+// ReadNumericValuesByNameContext is the context aware version of
+// ReadNumericValuesByName.
+func (sc *SnowthClient) ReadNumericValuesByNameContext(ctx context.Context,
+	accountID int64, name string, start, end time.Time, period int64,
+	t string, nodes ...*SnowthNode) ([]NumericValue, error) {
+	uuid, err := sc.ResolveMetricUUIDContext(ctx, accountID, name, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.ReadNumericValuesContext(ctx, start, end, period, t, uuid,
+		name, nodes...)
+}
+
+// ReadRollupValuesByName reads rollup data for the metric with the specified
+// account ID and canonical metric name, without requiring the check UUID.
+func (sc *SnowthClient) ReadRollupValuesByName(accountID int64, name string,
+	period time.Duration, start, end time.Time, dataType string,
+	nodes ...*SnowthNode) ([]RollupValue, error) {
+	return sc.ReadRollupValuesByNameContext(context.Background(), accountID,
+		name, period, start, end, dataType, nodes...)
+}
+
+// ReadRollupValuesByNameContext is the context aware version of
+// ReadRollupValuesByName.
+func (sc *SnowthClient) ReadRollupValuesByNameContext(ctx context.Context,
+	accountID int64, name string, period time.Duration, start, end time.Time,
+	dataType string, nodes ...*SnowthNode) ([]RollupValue, error) {
+	uuid, err := sc.ResolveMetricUUIDContext(ctx, accountID, name, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.ReadRollupValuesContext(ctx, uuid, name, period, start, end,
+		dataType, nodes...)
+}
+
+// FetchValuesByName fetches data for the streams of a fetch query, resolving
+// the check UUID of any stream which does not specify one from the account ID
+// and the canonical metric name of the stream.
+func (sc *SnowthClient) FetchValuesByName(accountID int64, q *FetchQuery,
+	nodes ...*SnowthNode) (*DF4Response, error) {
+	return sc.FetchValuesByNameContext(context.Background(), accountID, q,
+		nodes...)
+}
+
+// FetchValuesByNameContext is the context aware version of
+// FetchValuesByName.
+func (sc *SnowthClient) FetchValuesByNameContext(ctx context.Context,
+	accountID int64, q *FetchQuery,
+	nodes ...*SnowthNode) (*DF4Response, error) {
+	if q == nil {
+		return nil, fmt.Errorf("fetch query required")
+	}
+
+	fq := *q
+	fq.Streams = make([]FetchStream, len(q.Streams))
+	copy(fq.Streams, q.Streams)
+	for i, s := range fq.Streams {
+		if s.UUID != "" {
+			continue
+		}
+
+		uuid, err := sc.ResolveMetricUUIDContext(ctx, accountID, s.Name,
+			nodes...)
+		if err != nil {
+			return nil, err
+		}
+
+		fq.Streams[i].UUID = uuid
+	}
+
+	return sc.FetchValuesContext(ctx, &fq, nodes...)
+}
@@ -0,0 +1,129 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const byNameTestData = `[
+	{
+		"uuid": "11223344-5566-7788-9900-aabbccddeeff",
+		"check_tags": ["test:test"],
+		"metric_name": "test|ST[a:1,b:2]",
+		"type": "numeric",
+		"account_id": 1
+	},
+	{
+		"uuid": "99999999-5566-7788-9900-aabbccddeeff",
+		"metric_name": "test|ST[a:1,b:2,c:3]",
+		"type": "numeric",
+		"account_id": 1
+	}
+]`
+
+func TestMetricNameQuery(t *testing.T) {
+	q := metricNameQuery(`test|ST[b:2,a:b"MQ=="]`)
+	exp := `and(__name:b"dGVzdA==",b"YQ==":b"MQ==",b"Yg==":b"Mg==")`
+	if q != exp {
+		t.Errorf("Expected query: %v, got: %v", exp, q)
+	}
+}
+
+func TestReadNumericValuesByName(t *testing.T) {
+	var finds int32
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=") {
+			atomic.AddInt32(&finds, 1)
+			if r.URL.Query().Get("query") != metricNameQuery("test|ST[a:1,b:2]") {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+
+			_, _ = w.Write([]byte(byNameTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.URL.Path, "/read/1529509020/1529509200/1/"+
+			"11223344-5566-7788-9900-aabbccddeeff/average/") {
+			_, _ = w.Write([]byte(numericTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	mc := NewManualClock(time.Unix(1000, 0))
+	sc.SetClock(mc)
+	uuid, err := sc.ResolveMetricUUID(1, "test|ST[b:2,a:1]", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if uuid != "11223344-5566-7788-9900-aabbccddeeff" {
+		t.Errorf("Expected UUID: 11223344-5566-7788-9900-aabbccddeeff, "+
+			"got: %v", uuid)
+	}
+
+	res, err := sc.ReadNumericValuesByName(1, "test|ST[a:1,b:2]",
+		time.Unix(1529509020, 0), time.Unix(1529509200, 0), 1, "average",
+		node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Errorf("Expected values: 2, got: %v", len(res))
+	}
+
+	n := atomic.LoadInt32(&finds)
+	if _, err := sc.ResolveMetricUUID(1, "test|ST[a:1,b:2]", node); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&finds) != n {
+		t.Error("Expected cached metric UUID resolution")
+	}
+
+	mc.Advance(defaultMetricUUIDTTL)
+	if _, err := sc.ResolveMetricUUID(1, "test|ST[a:1,b:2]", node); err != nil {
+		t.Fatal(err)
+	}
+
+	if atomic.LoadInt32(&finds) != n+1 {
+		t.Error("Expected expired metric UUID resolution")
+	}
+
+	if _, err := sc.ResolveMetricUUID(1, "missing", node); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// tagSpecialChars contains the non-alphanumeric characters which IRONdb allows
+// in tag categories and values without encoding.
+const tagSpecialChars = "`+!@#$%^&\"'/?._-"
+
+// isTagChar returns whether a character is allowed in a tag category, or a
+// tag value if value is true, without encoding.
+func isTagChar(r rune, value bool) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case value && (r == ':' || r == '='):
+		return true
+	default:
+		return strings.ContainsRune(tagSpecialChars, r)
+	}
+}
+
+// canonicalTagPart returns the canonical form of a tag category or value.
+// Parts are base64 encoded using the b"..." syntax only when they contain
+// characters which are not allowed without encoding.
+func canonicalTagPart(s string, value bool) string {
+	if d := decodeTagPart(s); d != s {
+		s = d
+	} else if len(s) >= 3 && strings.HasPrefix(s, `b"`) &&
+		strings.HasSuffix(s, `"`) {
+		// Parts which are not validly encoded are left as they are.
+		return s
+	}
+
+	plain := !(strings.HasPrefix(s, `b"`) && strings.HasSuffix(s, `"`))
+	for _, r := range s {
+		if !plain {
+			break
+		}
+
+		plain = isTagChar(r, value)
+	}
+
+	if plain {
+		return s
+	}
+
+	return encodeTagLiteral(s)
+}
+
+// decodeTagPart decodes a tag category or value which may be encoded using
+// the b"..." base64 syntax. Parts which are not validly encoded are returned
+// unchanged.
+func decodeTagPart(s string) string {
+	if len(s) >= 3 && strings.HasPrefix(s, `b"`) && strings.HasSuffix(s, `"`) {
+		if b, err := base64.StdEncoding.DecodeString(s[2 : len(s)-1]); err == nil {
+			return string(b)
+		}
+	}
+
+	return s
+}
+
+// encodeTagLiteral encodes a tag category or value using the b"..." base64
+// literal syntax.
+func encodeTagLiteral(s string) string {
+	return `b"` + base64.StdEncoding.EncodeToString([]byte(s)) + `"`
+}
+
+// encodeTagRegex encodes a regular expression used to match tag categories or
+// values in a tag query, using the b/.../ base64 syntax.
+func encodeTagRegex(re string) string {
+	return "b/" + base64.StdEncoding.EncodeToString([]byte(re)) + "/"
+}
+
+// canonicalTag returns the canonical form of a category:value tag.
+func canonicalTag(tag string) string {
+	i := strings.Index(tag, ":")
+	if i < 0 {
+		return canonicalTagPart(tag, false)
+	}
+
+	return canonicalTagPart(tag[:i], false) + ":" +
+		canonicalTagPart(tag[i+1:], true)
+}
+
+// CanonicalStreamTags returns the canonical form of a list of stream tags,
+// with each tag properly encoded, and the tags sorted with duplicates and
+// empty tags removed.
+func CanonicalStreamTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	res := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t = canonicalTag(strings.TrimSpace(t)); t == "" || seen[t] {
+			continue
+		}
+
+		seen[t] = true
+		res = append(res, t)
+	}
+
+	sort.Strings(res)
+	return res
+}
+
+// splitMetricName separates a metric name into the base name and the list of
+// its stream tags, in the order they appear in the name.
+func splitMetricName(name string) (string, []string) {
+	i := strings.Index(name, "|ST[")
+	if i < 0 {
+		return name, nil
+	}
+
+	st := name[i+4:]
+	if j := strings.LastIndex(st, "]"); j >= 0 {
+		st = st[:j]
+	}
+
+	if st == "" {
+		return name[:i], nil
+	}
+
+	return name[:i], strings.Split(st, ",")
+}
+
+// CanonicalMetricName returns the canonical form of a metric name, combining
+// the stream tags in the name with any additional stream tags, so that the
+// same metric is always written using the same name. The stream tags are
+// properly encoded, sorted and deduplicated. All write operations write metric
+// names in this form.
+func CanonicalMetricName(name string, tags ...string) string {
+	base, st := splitMetricName(name)
+	st = CanonicalStreamTags(append(st, tags...))
+	if len(st) == 0 {
+		return base
+	}
+
+	return base + "|ST[" + strings.Join(st, ",") + "]"
+}
+
+// canonicalMetricList returns a metric list with the names of all metrics
+// in canonical form, with the stream tags in the names moved to the stream
+// tags of the metrics. The provided metric list is not modified.
+func canonicalMetricList(ml *noit.MetricListT) *noit.MetricListT {
+	res := &noit.MetricListT{Metrics: make([]*noit.MetricT, 0,
+		len(ml.Metrics))}
+	for _, m := range ml.Metrics {
+		if m == nil || m.Value == nil {
+			res.Metrics = append(res.Metrics, m)
+			continue
+		}
+
+		base, st := splitMetricName(m.Value.Name)
+		mv := *m.Value
+		mv.Name = base
+		mv.StreamTags = CanonicalStreamTags(append(st, m.Value.StreamTags...))
+		if len(mv.StreamTags) == 0 {
+			mv.StreamTags = nil
+		}
+
+		nm := *m
+		nm.Value = &mv
+		res.Metrics = append(res.Metrics, &nm)
+	}
+
+	return res
+}
@@ -0,0 +1,153 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+func TestCanonicalMetricName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		tags []string
+		exp  string
+	}{
+		{"test", nil, "test"},
+		{"test|ST[]", nil, "test"},
+		{"test|ST[b:2,a:1]", nil, "test|ST[a:1,b:2]"},
+		{"test|ST[a:1,a:1]", []string{"a:1", ""}, "test|ST[a:1]"},
+		{`test|ST[b"YQ==":b"MQ=="]`, nil, "test|ST[a:1]"},
+		{"test|ST[a:x y]", nil, `test|ST[a:b"eCB5"]`},
+		{"test|ST[a:b:c=d]", nil, "test|ST[a:b:c=d]"},
+		{"test", []string{"c:3", "b:2"}, "test|ST[b:2,c:3]"},
+		{`test|ST[a:b"YiJ4Ig=="]`, nil, `test|ST[a:b"YiJ4Ig=="]`},
+	}
+
+	for _, tt := range tests {
+		if n := CanonicalMetricName(tt.name, tt.tags...); n != tt.exp {
+			t.Errorf("Expected canonical name of %q: %q, got: %q", tt.name,
+				tt.exp, n)
+		}
+	}
+}
+
+func TestCanonicalWrites(t *testing.T) {
+	t.Parallel()
+	const (
+		name = `cpu|ST[b:2,a:1,a:1,b"Yw==":3,d:x y]`
+		exp  = `cpu|ST[a:1,b:2,c:3,d:b"eCB5"]`
+	)
+
+	var mu sync.Mutex
+	names := map[string]string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/raw") {
+			ml := noit.GetRootAsMetricList(b, 0).UnPack()
+			mv := ml.Metrics[0].Value
+			names[r.URL.Path] = CanonicalMetricName(mv.Name) + "/" +
+				strings.Join(mv.StreamTags, ",")
+			_, _ = w.Write([]byte(`{"records":1,"updated":1}`))
+			return
+		}
+
+		v := []struct {
+			Metric string `json:"metric"`
+		}{}
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Error(err)
+		}
+
+		names[r.URL.Path] = v[0].Metric
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	nw := []NumericWrite{{Metric: name}}
+	if err := sc.WriteNumeric(nw, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if nw[0].Metric != name {
+		t.Errorf("Expected write data not to be modified, got: %v",
+			nw[0].Metric)
+	}
+
+	if err := sc.WriteNNT([]NNTData{{Metric: name}}, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.WriteText([]TextData{{Metric: name}}, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.WriteHistogram([]HistogramData{{Metric: name}},
+		node); err != nil {
+		t.Fatal(err)
+	}
+
+	ml := &noit.MetricListT{Metrics: []*noit.MetricT{{
+		Value: &noit.MetricValueT{
+			Name:       "cpu|ST[b:2,a:1]",
+			StreamTags: []string{"d:x y", `b"Yw==":3`, "a:1"},
+			Value: &noit.MetricValueUnionT{
+				Type:  noit.MetricValueUnionIntValue,
+				Value: &noit.IntValueT{Value: 1},
+			},
+		},
+	}}}
+
+	if _, err := sc.WriteRawMetricList(ml, nil, node); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, p := range []string{"/write/numeric", "/write/nnt", "/write/text",
+		"/histogram/write"} {
+		if names[p] != exp {
+			t.Errorf("Expected %s metric name: %v, got: %v", p, exp, names[p])
+		}
+	}
+
+	if raw := names["/raw"]; raw != `cpu/a:1,b:2,c:3,d:b"eCB5"` {
+		t.Errorf("Expected raw metric name: %v, got: %v",
+			`cpu/a:1,b:2,c:3,d:b"eCB5"`, raw)
+	}
+}
@@ -0,0 +1,362 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// defaultCapacityImbalance is the default relative difference between the
+// weight share and disk share of a node above which its weight is flagged as
+// imbalanced.
+const defaultCapacityImbalance = 0.2
+
+// CapacityOptions values contain the settings used to generate a capacity
+// report.
+type CapacityOptions struct {
+	// Retention contains the retention of the data stored in each rollup
+	// period, keyed by the period in seconds. When the retention of every
+	// rollup period of a node is known, the disks of the node are projected
+	// to stop growing once the longest retention has elapsed.
+	Retention map[uint64]time.Duration
+
+	// SampleInterval is the duration between the two samples of node state
+	// used to measure ingest rates and disk growth. If this is zero, a single
+	// sample is taken, and no time-to-full projections are made.
+	SampleInterval time.Duration
+
+	// ImbalanceThreshold is the relative difference between the share of the
+	// topology weight assigned to a node and its share of the cluster disk
+	// capacity above which the node weight is flagged as imbalanced. If this
+	// is zero, a threshold of 0.2 is used.
+	ImbalanceThreshold float64
+}
+
+// NodeCapacity values describe the disk capacity and usage of an IRONdb node.
+type NodeCapacity struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+
+	// Weight is the topology weight of the node, and WeightShare is its
+	// share of the total weight of the topology.
+	Weight      uint16  `json:"weight"`
+	WeightShare float64 `json:"weight_share"`
+
+	// DiskTotalMB and DiskFreeMB are the total and available sizes of the
+	// file systems used by the node to store data, and DiskShare is the share
+	// of the total disk size of the cluster.
+	DiskTotalMB float64 `json:"disk_total_mb"`
+	DiskFreeMB  float64 `json:"disk_free_mb"`
+	DiskShare   float64 `json:"disk_share"`
+
+	// IngestRate is the number of data put calls per second, and GrowthRate
+	// the number of megabytes of disk used per second, measured between the
+	// state samples.
+	IngestRate float64 `json:"ingest_rate"`
+	GrowthRate float64 `json:"growth_mb_per_sec"`
+
+	// Retention is the longest retention of the rollup periods of the node,
+	// if the retention of every rollup period is known.
+	Retention time.Duration `json:"retention,omitempty"`
+
+	// WillFill is true if the disks of the node are projected to fill,
+	// after TimeToFull has elapsed.
+	WillFill   bool          `json:"will_fill"`
+	TimeToFull time.Duration `json:"time_to_full,omitempty"`
+
+	// Imbalanced is true if the weight share of the node differs from its
+	// disk share by more than the imbalance threshold.
+	Imbalanced bool `json:"imbalanced"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// CapacityReport values describe the disk capacity and usage of all of the
+// nodes in a cluster topology.
+type CapacityReport struct {
+	Time        time.Time      `json:"time"`
+	Topology    string         `json:"topology"`
+	Nodes       []NodeCapacity `json:"nodes"`
+	TotalWeight uint64         `json:"total_weight"`
+	DiskTotalMB float64        `json:"disk_total_mb"`
+	DiskFreeMB  float64        `json:"disk_free_mb"`
+
+	// Imbalanced is the number of nodes with imbalanced weights, and Errors
+	// the number of nodes whose state could not be retrieved.
+	Imbalanced int `json:"imbalanced"`
+	Errors     int `json:"errors"`
+}
+
+// capacitySample values contain the disk and ingest counters of a node at a
+// point in time.
+type capacitySample struct {
+	time    time.Time
+	totalMB float64
+	freeMB  float64
+	puts    uint64
+	rollups []uint64
+	err     string
+}
+
+// GetCapacityReport generates a capacity planning report for the cluster,
+// combining the topology weights, disk usage and ingest rates of the nodes,
+// and the retention of each rollup period, to project when the disks of each
+// node will fill and to flag nodes with imbalanced weights.
+func (sc *SnowthClient) GetCapacityReport(
+	opts *CapacityOptions) (*CapacityReport, error) {
+	return sc.GetCapacityReportContext(context.Background(), opts)
+}
+
+// GetCapacityReportContext is the context aware version of
+// GetCapacityReport.
+func (sc *SnowthClient) GetCapacityReportContext(ctx context.Context,
+	opts *CapacityOptions) (*CapacityReport, error) {
+	if opts == nil {
+		opts = &CapacityOptions{}
+	}
+
+	if opts.SampleInterval < 0 || opts.ImbalanceThreshold < 0 {
+		return nil, fmt.Errorf("invalid capacity options")
+	}
+
+	threshold := opts.ImbalanceThreshold
+	if threshold == 0 {
+		threshold = defaultCapacityImbalance
+	}
+
+	topo, err := sc.GetTopologyInfoContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get topology: %w", err)
+	}
+
+	nodes := make([]*SnowthNode, len(topo.Nodes))
+	for i, tn := range topo.Nodes {
+		nodes[i] = sc.topologyNode(tn)
+	}
+
+	clock := sc.getClock()
+	first := sc.capacitySamples(ctx, nodes)
+	var second []capacitySample
+	if opts.SampleInterval > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("context terminated: %w", ctx.Err())
+		case <-clock.After(opts.SampleInterval):
+		}
+
+		second = sc.capacitySamples(ctx, nodes)
+	}
+
+	cr := &CapacityReport{
+		Time:     clock.Now(),
+		Topology: topo.Hash,
+		Nodes:    make([]NodeCapacity, len(topo.Nodes)),
+	}
+
+	for i, tn := range topo.Nodes {
+		nc := &cr.Nodes[i]
+		nc.ID = tn.ID
+		nc.URL = nodes[i].GetURL().String()
+		nc.Weight = tn.Weight
+		cr.TotalWeight += uint64(tn.Weight)
+		s := first[i]
+		if second != nil {
+			s = second[i]
+		}
+
+		if s.err != "" {
+			nc.Error = s.err
+			cr.Errors++
+			continue
+		}
+
+		nc.DiskTotalMB, nc.DiskFreeMB = s.totalMB, s.freeMB
+		cr.DiskTotalMB += s.totalMB
+		cr.DiskFreeMB += s.freeMB
+		nc.Retention = capacityRetention(s.rollups, opts.Retention)
+		if second == nil || first[i].err != "" {
+			continue
+		}
+
+		secs := s.time.Sub(first[i].time).Seconds()
+		if secs <= 0 {
+			continue
+		}
+
+		if s.puts >= first[i].puts {
+			nc.IngestRate = float64(s.puts-first[i].puts) / secs
+		}
+
+		nc.GrowthRate = (first[i].freeMB - s.freeMB) / secs
+		if nc.GrowthRate > 0 {
+			nc.TimeToFull = time.Duration(s.freeMB / nc.GrowthRate *
+				float64(time.Second))
+			nc.WillFill = nc.Retention == 0 || nc.TimeToFull <= nc.Retention
+		}
+	}
+
+	// Weights are compared with disk sizes only among the nodes whose state
+	// was retrieved.
+	var sampled uint64
+	for _, nc := range cr.Nodes {
+		if nc.Error == "" {
+			sampled += uint64(nc.Weight)
+		}
+	}
+
+	for i := range cr.Nodes {
+		nc := &cr.Nodes[i]
+		if cr.TotalWeight > 0 {
+			nc.WeightShare = float64(nc.Weight) / float64(cr.TotalWeight)
+		}
+
+		if nc.Error != "" || cr.DiskTotalMB <= 0 || sampled == 0 {
+			continue
+		}
+
+		nc.DiskShare = nc.DiskTotalMB / cr.DiskTotalMB
+		ws := float64(nc.Weight) / float64(sampled)
+		if nc.DiskShare > 0 && math.Abs(ws/nc.DiskShare-1) > threshold {
+			nc.Imbalanced = true
+			cr.Imbalanced++
+		}
+	}
+
+	return cr, nil
+}
+
+// topologyNode returns the known node with the identifier of a topology node,
+// or a new node using the address of the topology node if there is none.
+func (sc *SnowthClient) topologyNode(tn TopologyNode) *SnowthNode {
+	for _, n := range append(sc.ListActiveNodes(), sc.ListInactiveNodes()...) {
+		if strings.EqualFold(n.identifier, tn.ID) {
+			return n
+		}
+	}
+
+	return &SnowthNode{identifier: tn.ID, url: sc.topologyNodeURL(tn)}
+}
+
+// capacitySamples retrieves the state of each node, without retrying on
+// other nodes, and returns its disk and ingest counters.
+func (sc *SnowthClient) capacitySamples(ctx context.Context,
+	nodes []*SnowthNode) []capacitySample {
+	res := make([]capacitySample, len(nodes))
+	for i, node := range nodes {
+		body, _, err := sc.do(ctx, node, "GET", snowthapi.PathState, nil, nil)
+		res[i].time = sc.getClock().Now()
+		if err != nil {
+			res[i].err = err.Error()
+			continue
+		}
+
+		ns := &NodeState{}
+		if err := decodeJSON(body, &ns); err != nil {
+			res[i].err = fmt.Sprintf("unable to decode IRONdb response: %v",
+				err)
+			continue
+		}
+
+		res[i].rollups = ns.Rollups
+		fs := map[uint64]FileSystemDetails{}
+		for _, r := range []*Rollup{&ns.NNT, ns.NNTBS, &ns.Text,
+			&ns.Histogram} {
+			if r == nil {
+				continue
+			}
+
+			for _, rd := range r.RollupEntries {
+				fs[rd.FilesSystem.ID] = rd.FilesSystem
+				res[i].puts += rd.PutCalls
+			}
+		}
+
+		for _, f := range fs {
+			res[i].totalMB += f.TotalMB
+			res[i].freeMB += f.FreeMB
+		}
+	}
+
+	return res
+}
+
+// capacityRetention returns the longest retention of a list of rollup periods,
+// or zero if the retention of any of the periods is not known.
+func capacityRetention(rollups []uint64,
+	retention map[uint64]time.Duration) time.Duration {
+	var longest time.Duration
+	if len(rollups) == 0 {
+		return 0
+	}
+
+	for _, p := range rollups {
+		r, ok := retention[p]
+		if !ok || r <= 0 {
+			return 0
+		}
+
+		if r > longest {
+			longest = r
+		}
+	}
+
+	return longest
+}
+
+// WriteJSON writes the capacity report to a writer in JSON format.
+func (cr *CapacityReport) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(cr); err != nil {
+		return fmt.Errorf("unable to encode capacity report: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes the node capacities of the capacity report to a writer in
+// CSV format, with a header row. Durations are written in seconds.
+func (cr *CapacityReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	rows := [][]string{{"id", "url", "weight", "weight_share",
+		"disk_total_mb", "disk_free_mb", "disk_share", "ingest_rate",
+		"growth_mb_per_sec", "retention", "will_fill", "time_to_full",
+		"imbalanced", "error"}}
+	ff := func(f float64) string {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	for _, nc := range cr.Nodes {
+		rows = append(rows, []string{
+			nc.ID,
+			nc.URL,
+			strconv.FormatUint(uint64(nc.Weight), 10),
+			ff(nc.WeightShare),
+			ff(nc.DiskTotalMB),
+			ff(nc.DiskFreeMB),
+			ff(nc.DiskShare),
+			ff(nc.IngestRate),
+			ff(nc.GrowthRate),
+			ff(nc.Retention.Seconds()),
+			strconv.FormatBool(nc.WillFill),
+			ff(nc.TimeToFull.Seconds()),
+			strconv.FormatBool(nc.Imbalanced),
+			nc.Error,
+		})
+	}
+
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("unable to write capacity report: %w", err)
+	}
+
+	return nil
+}
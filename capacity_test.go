@@ -0,0 +1,253 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+)
+
+const capacityStateTestData = `{
+	"identity": "%s",
+	"rollups": [60],
+	"nnt": {
+		"rollups": [60],
+		"rollup_60": {
+			"fs": {"id": 1, "totalMb": %v, "availMb": %v},
+			"put.calls": %d
+		}
+	},
+	"text": {
+		"rollups": [60],
+		"rollup_60": {
+			"fs": {"id": %d, "totalMb": %v, "availMb": %v},
+			"put.calls": 0
+		}
+	}
+}`
+
+func TestGetCapacityReport(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(1600000000, 0)
+	mc := NewManualClock(start)
+	later := func() bool { return mc.Now().After(start) }
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/state" {
+			free, puts := 1000, 100
+			if later() {
+				free, puts = 900, 600
+			}
+
+			_, _ = w.Write([]byte(fmt.Sprintf(capacityStateTestData,
+				"bb6f7162-4828-11df-bab8-6bac200dcc2a", 2000, free, puts,
+				1, 2000, free)))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	ms2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(fmt.Sprintf(capacityStateTestData,
+				"1533fc6b-de08-6eac-eb46-d3920a1a18a3", 500, 250, 0,
+				2, 500, 250)))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms2.Close()
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	u2, err := url.Parse(ms2.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	port, _ := strconv.Atoi(u.Port())
+	port2, _ := strconv.Atoi(u2.Port())
+	topo, err := TopologyLoadXML(fmt.Sprintf(`<nodes n="2">
+<node id="bb6f7162-4828-11df-bab8-6bac200dcc2a" address="%s" port="%d" apiport="%d" weight="10" side="a"/>
+<node id="1533fc6b-de08-6eac-eb46-d3920a1a18a3" address="%s" port="%d" apiport="%d" weight="10" side="a"/>
+<node id="18111a24-5832-42c8-e780-bcbf88f47215" address="127.0.0.1" port="1" apiport="1" weight="10" side="a"/>
+</nodes>`, u.Hostname(), port, port, u2.Hostname(), port2, port2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	sc.Lock()
+	sc.currentTopologyCompiled = topo
+	sc.Unlock()
+	sc.SetClock(mc)
+	if _, err := sc.GetCapacityReport(&CapacityOptions{
+		SampleInterval: -time.Second,
+	}); err == nil {
+		t.Error("Expected error for invalid options")
+	}
+
+	cr, err := sc.GetCapacityReport(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cr.Nodes) != 3 {
+		t.Fatalf("Expected nodes: 3, got: %v", len(cr.Nodes))
+	}
+
+	if cr.Nodes[0].GrowthRate != 0 || cr.Nodes[0].WillFill {
+		t.Errorf("Expected no projection, got: %+v", cr.Nodes[0])
+	}
+
+	res := make(chan *CapacityReport, 1)
+	go func() {
+		cr, err := sc.GetCapacityReportContext(context.Background(),
+			&CapacityOptions{
+				SampleInterval: 100 * time.Second,
+				Retention:      map[uint64]time.Duration{60: time.Hour},
+			})
+		if err != nil {
+			t.Error(err)
+		}
+
+		res <- cr
+	}()
+
+	for {
+		mc.Lock()
+		waiting := len(mc.waiters) > 0
+		mc.Unlock()
+		if waiting {
+			break
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	mc.Advance(100 * time.Second)
+	cr = <-res
+	if cr == nil {
+		t.Fatal("Expected capacity report")
+	}
+
+	if cr.TotalWeight != 30 {
+		t.Errorf("Expected total weight: 30, got: %v", cr.TotalWeight)
+	}
+
+	if cr.DiskTotalMB != 3000 {
+		t.Errorf("Expected disk total: 3000, got: %v", cr.DiskTotalMB)
+	}
+
+	if cr.Errors != 1 || cr.Nodes[2].Error == "" {
+		t.Errorf("Expected errors: 1, got: %v", cr.Errors)
+	}
+
+	n := cr.Nodes[0]
+	if n.URL != ms.URL {
+		t.Errorf("Expected URL: %v, got: %v", ms.URL, n.URL)
+	}
+
+	if n.DiskTotalMB != 2000 || n.DiskFreeMB != 900 {
+		t.Errorf("Expected disk: 2000/900, got: %v/%v", n.DiskTotalMB,
+			n.DiskFreeMB)
+	}
+
+	if n.IngestRate != 5 {
+		t.Errorf("Expected ingest rate: 5, got: %v", n.IngestRate)
+	}
+
+	if n.GrowthRate != 1 {
+		t.Errorf("Expected growth rate: 1, got: %v", n.GrowthRate)
+	}
+
+	if !n.WillFill || n.TimeToFull != 900*time.Second {
+		t.Errorf("Expected time to full: 15m, got: %v", n.TimeToFull)
+	}
+
+	if n.Retention != time.Hour {
+		t.Errorf("Expected retention: 1h, got: %v", n.Retention)
+	}
+
+	n = cr.Nodes[1]
+	if n.DiskTotalMB != 1000 || n.GrowthRate != 0 || n.WillFill {
+		t.Errorf("Expected no growth, got: %+v", n)
+	}
+
+	if !cr.Nodes[0].Imbalanced || !cr.Nodes[1].Imbalanced ||
+		cr.Nodes[2].Imbalanced || cr.Imbalanced != 2 {
+		t.Errorf("Expected imbalanced nodes: 2, got: %v", cr.Imbalanced)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := cr.WriteJSON(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := &CapacityReport{}
+	if err := json.Unmarshal(buf.Bytes(), dec); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dec.Nodes) != 3 || dec.Nodes[0].TimeToFull != 900*time.Second {
+		t.Errorf("Expected decoded report, got: %+v", dec)
+	}
+
+	buf.Reset()
+	if err := cr.WriteCSV(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rows) != 4 || rows[0][0] != "id" || rows[1][11] != "900" {
+		t.Errorf("Expected CSV rows, got: %v", rows)
+	}
+}
+
+func TestCapacityRetention(t *testing.T) {
+	t.Parallel()
+	ret := map[uint64]time.Duration{60: time.Hour, 600: 24 * time.Hour}
+	if r := capacityRetention([]uint64{60, 600}, ret); r != 24*time.Hour {
+		t.Errorf("Expected retention: 24h, got: %v", r)
+	}
+
+	if r := capacityRetention([]uint64{60, 7200}, ret); r != 0 {
+		t.Errorf("Expected retention: 0, got: %v", r)
+	}
+
+	if r := capacityRetention(nil, ret); r != 0 {
+		t.Errorf("Expected retention: 0, got: %v", r)
+	}
+}
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // CAQLQuery values represent CAQL queries and associated parameters.
@@ -102,7 +105,7 @@ func (sc *SnowthClient) GetCAQLQueryContext(ctx context.Context, q *CAQLQuery,
 		q = &CAQLQuery{}
 	}
 
-	u := sc.getURL(node, "/extension/lua/public/caql_v1")
+	u := sc.getURL(node, snowthapi.PathCAQL)
 	q.Format = "DF4"
 	qBuf, err := encodeJSON(q)
 	if err != nil {
@@ -139,9 +142,57 @@ func (sc *SnowthClient) GetCAQLQueryContext(ctx context.Context, q *CAQLQuery,
 
 	rb = ReplaceInf(rb)
 
-	if err := decodeJSON(bytes.NewBuffer(rb), &r); err != nil {
+	if err := sc.decodeResponse(bytes.NewBuffer(rb), &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
 	return r, err
 }
+
+// CAQLResult values contain the typed results of a CAQL query, with one series
+// for each output stream of the query.
+type CAQLResult = DF4Result
+
+// QueryCAQL runs a CAQL query for an account over the time range from start
+// to end, with a data period in seconds, and returns the typed results.
+func (sc *SnowthClient) QueryCAQL(accountID int64, q string, start,
+	end time.Time, period int64, nodes ...*SnowthNode) (*CAQLResult, error) {
+	return sc.QueryCAQLContext(context.Background(), accountID, q, start, end,
+		period, nodes...)
+}
+
+// QueryCAQLContext is the context aware version of QueryCAQL.
+func (sc *SnowthClient) QueryCAQLContext(ctx context.Context, accountID int64,
+	q string, start, end time.Time, period int64,
+	nodes ...*SnowthNode) (*CAQLResult, error) {
+	if q == "" {
+		return nil, fmt.Errorf("CAQL query cannot be empty")
+	}
+
+	if period <= 0 {
+		return nil, fmt.Errorf("invalid CAQL query period: %d", period)
+	}
+
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid CAQL query time range: %v - %v",
+			start, end)
+	}
+
+	df4, err := sc.GetCAQLQueryContext(ctx, &CAQLQuery{
+		AccountID: accountID,
+		Query:     q,
+		Start:     start.Unix(),
+		End:       end.Unix(),
+		Period:    period,
+	}, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := df4.Result()
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode CAQL results: %w", err)
+	}
+
+	return r, nil
+}
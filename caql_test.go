@@ -2,12 +2,14 @@
 package gosnowth
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 const testCAQLError = `{
@@ -156,3 +158,79 @@ func TestGetCAQLQuery(t *testing.T) {
 		t.Errorf("Expected error JSON: %v, got: %v", exp, val)
 	}
 }
+
+func TestQueryCAQL(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.Method == "POST" && r.RequestURI == "/extension/lua/public/caql_v1" {
+			q := CAQLQuery{}
+			if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+				t.Error("Unable to decode JSON data")
+			}
+
+			if q.AccountID != 1 || q.Query != "find('test')" ||
+				q.Start != 0 || q.End != 900 || q.Period != 300 {
+				t.Errorf("Unexpected CAQL query: %+v", q)
+			}
+
+			_, _ = w.Write([]byte(testFetchDF4Response))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.QueryCAQL(1, "find('test')", time.Unix(0, 0),
+		time.Unix(900, 0), 300, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Period != 5*time.Minute || len(res.Timestamps) != 3 ||
+		res.Timestamps[2].Unix() != 600 {
+		t.Errorf("Unexpected result times: %v, %v", res.Period,
+			res.Timestamps)
+	}
+
+	if len(res.Series) != 1 || res.Series[0].Name != "test" {
+		t.Fatalf("Unexpected result series: %+v", res.Series)
+	}
+
+	if v := res.Series[0].Values; len(v) != 5 || *v[2] != 3 {
+		t.Errorf("Unexpected series values: %v", v)
+	}
+
+	if _, err := sc.QueryCAQL(1, "", time.Unix(0, 0), time.Unix(900, 0), 300,
+		node); err == nil {
+		t.Error("Expected error for empty query")
+	}
+
+	if _, err := sc.QueryCAQL(1, "find('test')", time.Unix(900, 0),
+		time.Unix(0, 0), 300, node); err == nil {
+		t.Error("Expected error for invalid time range")
+	}
+}
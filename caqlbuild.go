@@ -0,0 +1,199 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CAQL aggregation functions used by the window, rolling, and rollup stages of
+// a CAQL query builder.
+const (
+	CAQLMean   = "mean"
+	CAQLSum    = "sum"
+	CAQLMin    = "min"
+	CAQLMax    = "max"
+	CAQLCount  = "count"
+	CAQLStddev = "stddev"
+	CAQLMerge  = "merge"
+)
+
+// CAQLBuilder values compose CAQL queries from a source, such as a find() or
+// metric() selector, followed by a pipeline of processing stages. All string
+// arguments are quoted and escaped when the query is rendered. Builder methods
+// return the builder, so stages can be chained.
+type CAQLBuilder struct {
+	stages []string
+	err    error
+}
+
+// CAQLFind returns a CAQL query builder with a find() source, selecting
+// metrics by name pattern and, optionally, a tag query.
+func CAQLFind(name string, tagQuery ...string) *CAQLBuilder {
+	return CAQLFindType("", name, tagQuery...)
+}
+
+// CAQLFindType returns a CAQL query builder with a find() source of the
+// specified type, such as "histogram" or "counter", selecting metrics by name
+// pattern and, optionally, a tag query. An empty type selects the default
+// find() source.
+func CAQLFindType(typ, name string, tagQuery ...string) *CAQLBuilder {
+	b := &CAQLBuilder{}
+	if name == "" {
+		b.err = fmt.Errorf("CAQL find name cannot be empty")
+	}
+
+	args := []interface{}{name}
+	for _, q := range tagQuery {
+		if q != "" {
+			args = append(args, q)
+		}
+	}
+
+	fn := "find"
+	if typ != "" {
+		fn += ":" + typ
+	}
+
+	return b.Then(fn, args...)
+}
+
+// CAQLMetric returns a CAQL query builder with a metric() source, selecting a
+// single metric by check UUID and canonical metric name. The kind selects the
+// data of the metric to use, such as "average" or "counter", or the default
+// if empty.
+func CAQLMetric(kind, uuid, name string) *CAQLBuilder {
+	b := &CAQLBuilder{}
+	if uuid == "" || name == "" {
+		b.err = fmt.Errorf("CAQL metric check uuid and name required")
+	}
+
+	fn := "metric"
+	if kind != "" {
+		fn += ":" + kind
+	}
+
+	return b.Then(fn, uuid, name)
+}
+
+// Then adds a processing stage to the query pipeline, calling a CAQL function
+// with the specified arguments. Arguments can be strings, which are quoted,
+// time.Duration values, which are rendered as CAQL durations, numbers, or
+// booleans.
+func (b *CAQLBuilder) Then(fn string, args ...interface{}) *CAQLBuilder {
+	if fn == "" {
+		b.setErr(fmt.Errorf("CAQL function name cannot be empty"))
+		return b
+	}
+
+	parts := make([]string, len(args))
+	for i, a := range args {
+		s, err := caqlArg(a)
+		if err != nil {
+			b.setErr(fmt.Errorf("invalid argument to CAQL function %s: %w",
+				fn, err))
+			return b
+		}
+
+		parts[i] = s
+	}
+
+	b.stages = append(b.stages, fn+"("+strings.Join(parts, ", ")+")")
+	return b
+}
+
+// Rollup adds a rollup stage to the query pipeline, aggregating the data into
+// periods of the specified duration with an aggregation function.
+func (b *CAQLBuilder) Rollup(fn string, period time.Duration) *CAQLBuilder {
+	return b.Then("rollup:"+fn, period)
+}
+
+// Window adds a window stage to the query pipeline, aggregating the data over
+// consecutive, non-overlapping windows of the specified duration.
+func (b *CAQLBuilder) Window(fn string, d time.Duration) *CAQLBuilder {
+	return b.Then("window:"+fn, d)
+}
+
+// Rolling adds a rolling stage to the query pipeline, aggregating the data
+// over a sliding window of the specified duration.
+func (b *CAQLBuilder) Rolling(fn string, d time.Duration) *CAQLBuilder {
+	return b.Then("rolling:"+fn, d)
+}
+
+// Label adds a label stage to the query pipeline, naming the output streams
+// with a label format, such as "%n" or "%tv{host}".
+func (b *CAQLBuilder) Label(format string) *CAQLBuilder {
+	return b.Then("label", format)
+}
+
+// Build returns the rendered CAQL query, or an error if any part of the query
+// was invalid.
+func (b *CAQLBuilder) Build() (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+
+	if len(b.stages) == 0 {
+		return "", fmt.Errorf("CAQL query has no stages")
+	}
+
+	return strings.Join(b.stages, " | "), nil
+}
+
+// String returns the rendered CAQL query, ignoring any errors.
+func (b *CAQLBuilder) String() string {
+	return strings.Join(b.stages, " | ")
+}
+
+// setErr records the first error encountered while building a query.
+func (b *CAQLBuilder) setErr(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// caqlArg renders a value as a CAQL function argument.
+func caqlArg(v interface{}) (string, error) {
+	switch tv := v.(type) {
+	case string:
+		return strconv.Quote(tv), nil
+	case time.Duration:
+		return caqlDuration(tv)
+	case int:
+		return strconv.Itoa(tv), nil
+	case int64:
+		return strconv.FormatInt(tv, 10), nil
+	case float64:
+		return strconv.FormatFloat(tv, 'g', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(tv), nil
+	}
+
+	return "", fmt.Errorf("unsupported argument type: %T", v)
+}
+
+// caqlDuration renders a duration in CAQL duration syntax, using the largest
+// unit which represents it exactly.
+func caqlDuration(d time.Duration) (string, error) {
+	if d <= 0 || d%time.Second != 0 {
+		return "", fmt.Errorf("invalid duration: %v", d)
+	}
+
+	for _, u := range []struct {
+		d time.Duration
+		s string
+	}{
+		{7 * 24 * time.Hour, "w"},
+		{24 * time.Hour, "d"},
+		{time.Hour, "h"},
+		{time.Minute, "m"},
+	} {
+		if d%u.d == 0 {
+			return strconv.FormatInt(int64(d/u.d), 10) + u.s, nil
+		}
+	}
+
+	return strconv.FormatInt(int64(d/time.Second), 10) + "s", nil
+}
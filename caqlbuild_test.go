@@ -0,0 +1,58 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCAQLBuilder(t *testing.T) {
+	t.Parallel()
+	q, err := CAQLFind("cpu.*", `and(service:"api")`).
+		Rollup(CAQLMean, time.Minute).
+		Window(CAQLMax, 2*time.Hour).
+		Rolling(CAQLSum, 90*time.Second).
+		Then("top", 5).
+		Label("%n{host}").
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `find("cpu.*", "and(service:\"api\")") | rollup:mean(1m) | ` +
+		`window:max(2h) | rolling:sum(90s) | top(5) | label("%n{host}")`
+	if q != exp {
+		t.Errorf("Expected query: %v, got: %v", exp, q)
+	}
+
+	q, err = CAQLFindType("histogram", "latency").
+		Window(CAQLMerge, 7*24*time.Hour).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp = `find:histogram("latency") | window:merge(1w)`
+	if q != exp {
+		t.Errorf("Expected query: %v, got: %v", exp, q)
+	}
+
+	if s := CAQLMetric("average", "11223344-5566-7788-9900-aabbccddeeff",
+		"test").String(); s != `metric:average("11223344-5566-7788-9900-`+
+		`aabbccddeeff", "test")` {
+		t.Errorf("Unexpected metric query: %v", s)
+	}
+
+	for _, b := range []*CAQLBuilder{
+		CAQLFind(""),
+		CAQLMetric("", "", "test"),
+		CAQLFind("cpu").Window(CAQLMax, 0),
+		CAQLFind("cpu").Rollup(CAQLMean, 1500*time.Millisecond),
+		CAQLFind("cpu").Then("top", []int{1}),
+		CAQLFind("cpu").Then(""),
+		{},
+	} {
+		if _, err := b.Build(); err == nil {
+			t.Errorf("Expected error for query: %v", b)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// CardinalityAction values control what happens when writes exceed the stream
+// tag cardinality budget of a metric.
+type CardinalityAction int
+
+// Actions taken when the cardinality budget is exceeded.
+const (
+	// CardinalityWarn logs a warning, once per metric name, and continues
+	// with the write. This is the default action.
+	CardinalityWarn CardinalityAction = iota
+
+	// CardinalityReject fails writes which contain new stream tag
+	// combinations beyond the budget, without sending any data. Combinations
+	// which were previously written can still be written.
+	CardinalityReject
+)
+
+// hllPrecision is the number of hash bits used to select a HyperLogLog
+// register. 2^10 registers give a standard error of about 3%.
+const hllPrecision = 10
+
+// hyperLogLog values estimate the number of distinct values added to them.
+type hyperLogLog struct {
+	registers [1 << hllPrecision]uint8
+}
+
+// add adds a value to the estimator, and returns true if it changed the
+// estimate. Values which do not change the estimate have, most likely, been
+// added before.
+func (h *hyperLogLog) add(v string) bool {
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(v))
+	x := f.Sum64()
+
+	// Mix the FNV hash to improve the distribution of the high bits.
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	idx := x >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank <= h.registers[idx] {
+		return false
+	}
+
+	h.registers[idx] = rank
+	return true
+}
+
+// estimate returns the estimated number of distinct values added.
+func (h *hyperLogLog) estimate() uint64 {
+	m := float64(len(h.registers))
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += 1 / float64(uint64(1)<<r)
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(e + 0.5)
+}
+
+// cardinalityGuard values track the approximate number of distinct stream tag
+// combinations written for each metric name.
+type cardinalityGuard struct {
+	sync.Mutex
+	limit    uint64
+	action   CardinalityAction
+	sketches map[string]*hyperLogLog
+	warned   map[string]bool
+}
+
+// newCardinalityGuard creates a new guard with the specified budget of
+// distinct stream tag combinations per metric name.
+func newCardinalityGuard(limit int64,
+	action CardinalityAction) *cardinalityGuard {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &cardinalityGuard{
+		limit:    uint64(limit),
+		action:   action,
+		sketches: map[string]*hyperLogLog{},
+		warned:   map[string]bool{},
+	}
+}
+
+// splitStreamTags separates a metric name into the base name and a sorted,
+// comma separated, list of its stream tags and any extra stream tags, which
+// identifies the stream tag combination.
+func splitStreamTags(name string, extra ...string) (string, string) {
+	base, tags := splitMetricName(name)
+	tags = append(tags, extra...)
+	sort.Strings(tags)
+	return base, strings.Join(tags, ",")
+}
+
+// check records the stream tag combinations of metric names and returns an
+// error if any new combination exceeds the budget of its metric and the
+// action is to reject. Rejected writes are not recorded.
+func (cg *cardinalityGuard) check(sc *SnowthClient, names []string,
+	tags [][]string) error {
+	if cg == nil {
+		return nil
+	}
+
+	cg.Lock()
+	defer cg.Unlock()
+	pending := map[string]*hyperLogLog{}
+	for i, name := range names {
+		var extra []string
+		if i < len(tags) {
+			extra = tags[i]
+		}
+
+		base, st := splitStreamTags(name, extra...)
+		h, ok := pending[base]
+		if !ok {
+			h = &hyperLogLog{}
+			if cur, ok := cg.sketches[base]; ok {
+				*h = *cur
+			}
+
+			pending[base] = h
+		}
+
+		if !h.add(st) {
+			continue
+		}
+
+		est := h.estimate()
+		if est <= cg.limit {
+			continue
+		}
+
+		if cg.action == CardinalityReject {
+			return fmt.Errorf("stream tag cardinality budget exceeded for "+
+				"metric %s: approximately %d of %d", base, est, cg.limit)
+		}
+
+		if !cg.warned[base] {
+			cg.warned[base] = true
+			sc.LogWarnf("stream tag cardinality budget exceeded for "+
+				"metric %s: approximately %d of %d", base, est, cg.limit)
+		}
+	}
+
+	for base, h := range pending {
+		cg.sketches[base] = h
+	}
+
+	return nil
+}
+
+// SetCardinalityLimit sets the budget of distinct stream tag combinations
+// which can be written for each metric name, and the action taken when writes
+// exceed it. A limit of zero disables the cardinality guard. Setting a limit
+// discards previously tracked cardinality estimates.
+func (sc *SnowthClient) SetCardinalityLimit(limit int64,
+	action CardinalityAction) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.cardinality = newCardinalityGuard(limit, action)
+}
+
+// MetricCardinality returns the approximate number of distinct stream tag
+// combinations written by the client for a metric name, if the cardinality
+// guard is enabled.
+func (sc *SnowthClient) MetricCardinality(name string) int64 {
+	sc.RLock()
+	cg := sc.cardinality
+	sc.RUnlock()
+	if cg == nil {
+		return 0
+	}
+
+	base, _ := splitStreamTags(name)
+	cg.Lock()
+	defer cg.Unlock()
+	if h, ok := cg.sketches[base]; ok {
+		return int64(h.estimate())
+	}
+
+	return 0
+}
+
+// checkCardinality applies the cardinality guard of the client to a list of
+// metric names, with optional separate stream tags for each name.
+func (sc *SnowthClient) checkCardinality(names []string,
+	tags [][]string) error {
+	sc.RLock()
+	cg := sc.cardinality
+	sc.RUnlock()
+	return cg.check(sc, names, tags)
+}
+
+// checkMetricListCardinality applies the cardinality guard of the client to
+// the metrics in a raw metric list.
+func (sc *SnowthClient) checkMetricListCardinality(
+	ml *noit.MetricListT) error {
+	sc.RLock()
+	cg := sc.cardinality
+	sc.RUnlock()
+	if cg == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(ml.Metrics))
+	tags := make([][]string, 0, len(ml.Metrics))
+	for _, m := range ml.Metrics {
+		if m == nil || m.Value == nil {
+			continue
+		}
+
+		names = append(names, m.Value.Name)
+		tags = append(tags, m.Value.StreamTags)
+	}
+
+	return cg.check(sc, names, tags)
+}
@@ -0,0 +1,134 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestHyperLogLog(t *testing.T) {
+	h := &hyperLogLog{}
+	if h.estimate() != 0 {
+		t.Errorf("Expected estimate: 0, got: %v", h.estimate())
+	}
+
+	for i := 0; i < 10000; i++ {
+		h.add(fmt.Sprintf("host:%d", i))
+		h.add(fmt.Sprintf("host:%d", i))
+	}
+
+	est := h.estimate()
+	if est < 9000 || est > 11000 {
+		t.Errorf("Expected estimate near 10000, got: %v", est)
+	}
+}
+
+func TestSplitStreamTags(t *testing.T) {
+	base, tags := splitStreamTags("test|ST[b:2,a:1]", "c:3")
+	if base != "test" {
+		t.Errorf("Expected base: test, got: %v", base)
+	}
+
+	if tags != "a:1,b:2,c:3" {
+		t.Errorf("Expected tags: a:1,b:2,c:3, got: %v", tags)
+	}
+
+	base, tags = splitStreamTags("test")
+	if base != "test" || tags != "" {
+		t.Errorf("Expected base: test, tags: empty, got: %v, %v", base, tags)
+	}
+}
+
+func TestCardinalityGuard(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/numeric" {
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	sc.SetCardinalityLimit(10, CardinalityReject)
+	data := []NumericWrite{}
+	for i := 0; i < 5; i++ {
+		data = append(data, NumericWrite{
+			Metric: fmt.Sprintf("test|ST[host:%d]", i),
+		})
+	}
+
+	if err := sc.WriteNumeric(data, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if c := sc.MetricCardinality("test"); c != 5 {
+		t.Errorf("Expected cardinality: 5, got: %v", c)
+	}
+
+	for i := range data {
+		data[i].Metric = fmt.Sprintf("test|ST[host:%d]", i+100)
+	}
+
+	data = append(data, NumericWrite{Metric: "test|ST[host:200]"})
+	if err := sc.WriteNumeric(data, node); err == nil {
+		t.Error("Expected cardinality budget error")
+	}
+
+	if c := sc.MetricCardinality("test"); c != 5 {
+		t.Errorf("Expected cardinality: 5, got: %v", c)
+	}
+
+	if err := sc.WriteNumeric(data[:5], node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.WriteNumeric(data[5:], node); err == nil {
+		t.Error("Expected cardinality budget error")
+	}
+
+	if err := sc.WriteNumeric([]NumericWrite{{Metric: "test|ST[host:0]"}},
+		node); err != nil {
+		t.Errorf("Expected existing series write to succeed, got: %v", err)
+	}
+
+	sc.SetCardinalityLimit(10, CardinalityWarn)
+	for i := 0; i < 3; i++ {
+		if err := sc.WriteNumeric(data, node); err != nil {
+			t.Fatal(err)
+		}
+
+		for j := range data {
+			data[j].Metric = fmt.Sprintf("test|ST[host:%d]", j+i*10)
+		}
+	}
+
+	if c := sc.MetricCardinality("test"); c < 11 {
+		t.Errorf("Expected cardinality > 10, got: %v", c)
+	}
+}
@@ -0,0 +1,60 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// checkTagsUpdate values are the request bodies of check tags updates.
+type checkTagsUpdate struct {
+	CheckTags []string `json:"check_tags"`
+}
+
+// UpdateCheckTags replaces the check tags of a check, which are returned in
+// the CheckTags of FindTagsItem values for all metrics of the check, and can
+// be used in tag queries. The tags are category:value strings, and are
+// encoded, sorted, and deduplicated in the same way as stream tags. An empty
+// list of tags removes all check tags from the check.
+func (sc *SnowthClient) UpdateCheckTags(uuid string, tags []string,
+	nodes ...*SnowthNode) error {
+	return sc.UpdateCheckTagsContext(context.Background(), uuid, tags,
+		nodes...)
+}
+
+// UpdateCheckTagsContext is the context aware version of UpdateCheckTags.
+func (sc *SnowthClient) UpdateCheckTagsContext(ctx context.Context,
+	uuid string, tags []string, nodes ...*SnowthNode) error {
+	if _, err := ParseCheckUUID(uuid); err != nil {
+		return err
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	data, err := encodeJSON(&checkTagsUpdate{
+		CheckTags: CanonicalStreamTags(tags),
+	})
+	if err != nil {
+		return err
+	}
+
+	hdrs := http.Header{
+		snowthapi.HeaderContentType: {snowthapi.ContentTypeJSON},
+	}
+	_, _, err = sc.doRequest(ctx, node, sc.activeNodeIDs(), "PUT",
+		path.Join(snowthapi.PathCheckTags, uuid), data, hdrs)
+	if err != nil {
+		return fmt.Errorf("unable to update check tags: %w", err)
+	}
+
+	return nil
+}
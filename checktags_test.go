@@ -0,0 +1,68 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestUpdateCheckTags(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.Method == "PUT" && r.RequestURI ==
+			"/check_tags/fc85e0ab-f568-45e6-86ee-d7443be8277d" {
+			ct := checkTagsUpdate{}
+			if err := json.NewDecoder(r.Body).Decode(&ct); err != nil {
+				t.Error("Unable to decode JSON data")
+			}
+
+			exp := []string{`env:b"cHJvZCAx"`, "service:api"}
+			if !reflect.DeepEqual(ct.CheckTags, exp) {
+				t.Errorf("Expected check tags: %v, got: %v", exp,
+					ct.CheckTags)
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	if err := sc.UpdateCheckTags("fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		[]string{"service:api", "env:prod 1", "service:api"},
+		node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.UpdateCheckTags("invalid", nil, node); err == nil {
+		t.Error("Expected error for invalid check uuid")
+	}
+}
@@ -0,0 +1,95 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// checkUUIDNamespace is the default namespace used to generate deterministic
+// check UUIDs. It is the version 5 UUID of the URL
+// https://github.com/circonus-labs/gosnowth/check in the standard URL
+// namespace.
+var checkUUIDNamespace = uuid.NewSHA1(uuid.NameSpaceURL,
+	[]byte("https://github.com/circonus-labs/gosnowth/check"))
+
+// CheckUUIDNamespace returns the default namespace used to generate
+// deterministic check UUIDs.
+func CheckUUIDNamespace() uuid.UUID {
+	return checkUUIDNamespace
+}
+
+// CheckKey values identify a check by its target and module, from which a
+// deterministic check UUID is generated. Check UUIDs generated from check keys
+// are local to this library: IRONdb does not derive check UUIDs, and these are
+// not the UUIDs assigned to checks by Circonus or reconnoiter, so they do not
+// identify checks created by those systems.
+type CheckKey struct {
+	Target string
+	Module string
+}
+
+// name returns the name from which the check UUID is generated. This is the
+// target and module separated by a backtick, a format chosen by this library.
+func (ck CheckKey) name() string {
+	return ck.Target + "`" + ck.Module
+}
+
+// CheckUUID returns the deterministic, version 5, check UUID of the check with
+// the specified target and module, in the default check UUID namespace. The
+// same target and module always produce the same UUID, so that data written
+// for a check by different collectors using this library is stored under the
+// same check. The UUID is local to this library, as described by CheckKey.
+func CheckUUID(target, module string) string {
+	return CheckUUIDNS(checkUUIDNamespace, target, module)
+}
+
+// CheckUUIDNS returns the deterministic, version 5, check UUID of the check
+// with the specified target and module, in the specified namespace.
+func CheckUUIDNS(ns uuid.UUID, target, module string) string {
+	return uuid.NewSHA1(ns, []byte(CheckKey{target, module}.name())).String()
+}
+
+// CheckUUIDs returns the deterministic check UUIDs of a list of checks, in the
+// default check UUID namespace, in the same order as the checks.
+func CheckUUIDs(keys ...CheckKey) []string {
+	r := make([]string, len(keys))
+	for i, k := range keys {
+		r[i] = CheckUUID(k.Target, k.Module)
+	}
+
+	return r
+}
+
+// ValidateCheckUUID returns an error if a value is not a check UUID in the
+// canonical form used by IRONdb: 36 lower case hexadecimal characters and
+// hyphens. The nil UUID is not a valid check UUID.
+func ValidateCheckUUID(id string) error {
+	if len(id) != 36 {
+		return fmt.Errorf("invalid check UUID: %s: invalid length", id)
+	}
+
+	u, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid check UUID: %s: %w", id, err)
+	}
+
+	if u == uuid.Nil {
+		return fmt.Errorf("invalid check UUID: %s: nil UUID", id)
+	}
+
+	if id != strings.ToLower(id) {
+		return fmt.Errorf("invalid check UUID: %s: not lower case", id)
+	}
+
+	return nil
+}
+
+// VerifyCheckUUID returns whether a check UUID is the deterministic check UUID
+// of the check with the specified target and module, in the default check
+// UUID namespace.
+func VerifyCheckUUID(id, target, module string) bool {
+	return strings.EqualFold(id, CheckUUID(target, module))
+}
@@ -0,0 +1,74 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCheckUUID(t *testing.T) {
+	t.Parallel()
+
+	id := CheckUUID("10.0.0.1", "http")
+	if err := ValidateCheckUUID(id); err != nil {
+		t.Fatal(err)
+	}
+
+	u := uuid.MustParse(id)
+	if u.Version() != 5 {
+		t.Errorf("Expected UUID version: 5, got: %v", u.Version())
+	}
+
+	if id != CheckUUID("10.0.0.1", "http") {
+		t.Error("Expected deterministic check UUID")
+	}
+
+	if id == CheckUUID("10.0.0.1", "ping_icmp") {
+		t.Error("Expected different check UUIDs for different modules")
+	}
+
+	if id != CheckUUIDNS(CheckUUIDNamespace(), "10.0.0.1", "http") {
+		t.Error("Expected check UUID in the default namespace")
+	}
+
+	if id == CheckUUIDNS(uuid.NameSpaceDNS, "10.0.0.1", "http") {
+		t.Error("Expected different check UUIDs for different namespaces")
+	}
+
+	if !VerifyCheckUUID(id, "10.0.0.1", "http") {
+		t.Error("Expected check UUID to verify")
+	}
+
+	if VerifyCheckUUID(id, "10.0.0.2", "http") {
+		t.Error("Expected check UUID not to verify")
+	}
+
+	ids := CheckUUIDs(CheckKey{"10.0.0.1", "http"},
+		CheckKey{"10.0.0.2", "http"})
+	if len(ids) != 2 || ids[0] != id || ids[1] == id {
+		t.Errorf("Expected check UUIDs: [%v ...], got: %v", id, ids)
+	}
+}
+
+func TestValidateCheckUUID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"fc85e0ab-f568-45e6-86ee-d7443be8277d", true},
+		{"FC85E0AB-F568-45E6-86EE-D7443BE8277D", false},
+		{"fc85e0abf56845e686eed7443be8277d", false},
+		{"00000000-0000-0000-0000-000000000000", false},
+		{"fc85e0ab-f568-45e6-86ee-d7443be8277z", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateCheckUUID(tt.id); (err == nil) != tt.valid {
+			t.Errorf("Expected %v valid: %v, got: %v", tt.id, tt.valid, err)
+		}
+	}
+}
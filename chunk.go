@@ -0,0 +1,254 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// WriteChunk values contain the result of sending one chunk of a write
+// operation which was split to stay under the maximum write payload size.
+type WriteChunk struct {
+	First   int
+	Records int
+	Bytes   int
+	Err     error
+}
+
+// WriteSummary values contain the combined results of a write operation which
+// was split into multiple chunks. When any chunk of a write fails, the write
+// returns its WriteSummary as the error value, which can be retrieved with
+// errors.As().
+type WriteSummary struct {
+	Chunks   []WriteChunk
+	Records  int
+	Failed   int
+	Response IRONdbPutResponse
+}
+
+// Error implements the error interface for WriteSummary values.
+func (ws *WriteSummary) Error() string {
+	mErr := newMultiError()
+	for _, c := range ws.Chunks {
+		if c.Err != nil {
+			mErr.Add(fmt.Errorf("records %d-%d: %w", c.First,
+				c.First+c.Records-1, c.Err))
+		}
+	}
+
+	return fmt.Sprintf("%d of %d write chunks failed: %v", ws.Failed,
+		len(ws.Chunks), mErr)
+}
+
+// add records the result of sending a chunk.
+func (ws *WriteSummary) add(c WriteChunk, r *IRONdbPutResponse) {
+	ws.Chunks = append(ws.Chunks, c)
+	if c.Err != nil {
+		ws.Failed++
+		return
+	}
+
+	ws.Records += c.Records
+	if r != nil {
+		ws.Response.Errors += r.Errors
+		ws.Response.Misdirected += r.Misdirected
+		ws.Response.Records += r.Records
+		ws.Response.Updated += r.Updated
+	}
+}
+
+// err returns the summary as an error value if any chunk failed.
+func (ws *WriteSummary) err() error {
+	if ws.Failed > 0 {
+		return ws
+	}
+
+	return nil
+}
+
+// SetMaxWritePayload sets the maximum size, in bytes, of the body of a single
+// write request. Writes with larger payloads are split into multiple requests.
+// A value of zero means no limit is enforced.
+func (sc *SnowthClient) SetMaxWritePayload(n int64) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.maxWritePayload = n
+}
+
+// getMaxWritePayload returns the maximum write request payload size.
+func (sc *SnowthClient) getMaxWritePayload() int64 {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.maxWritePayload
+}
+
+// payloadChunk values contain the encoded body of one chunk of a write.
+type payloadChunk struct {
+	first   int
+	records int
+	buf     *bytes.Buffer
+}
+
+// splitJSONRecords splits a list of JSON encoded records into JSON array
+// payloads of no more than max bytes each, preserving the record order.
+func splitJSONRecords(recs [][]byte, max int64) ([]payloadChunk, error) {
+	chunks := []payloadChunk{}
+	cur := payloadChunk{buf: &bytes.Buffer{}}
+	for i, r := range recs {
+		if int64(len(r)+3) > max {
+			return nil, fmt.Errorf("record %d exceeds maximum write payload "+
+				"size: %d bytes", i, len(r))
+		}
+
+		if cur.records > 0 && int64(cur.buf.Len()+len(r)+3) > max {
+			cur.buf.WriteString("]\n")
+			chunks = append(chunks, cur)
+			cur = payloadChunk{buf: &bytes.Buffer{}}
+		}
+
+		if cur.records == 0 {
+			cur.first = i
+			cur.buf.WriteByte('[')
+		} else {
+			cur.buf.WriteByte(',')
+		}
+
+		cur.buf.Write(r)
+		cur.records++
+	}
+
+	if cur.records > 0 {
+		cur.buf.WriteString("]\n")
+		chunks = append(chunks, cur)
+	}
+
+	return chunks, nil
+}
+
+// writeJSON sends a JSON encoded write request body to a node. If the body
+// exceeds the maximum write payload size, the n records returned by the rec
+// function are encoded individually and sent in order in multiple requests.
+func (sc *SnowthClient) writeJSON(ctx context.Context, node *SnowthNode,
+	url string, body *bytes.Buffer, n int, rec func(i int) interface{}) error {
+	sc.getMetrics().batch(nodeHost(node), endpointName(url), n)
+	chunks := []payloadChunk{{records: n, buf: body}}
+	if max := sc.getMaxWritePayload(); max > 0 && int64(body.Len()) > max {
+		recs := make([][]byte, n)
+		for i := range recs {
+			b, err := json.Marshal(rec(i))
+			if err != nil {
+				return fmt.Errorf("failed to encode record %d for write: %w",
+					i, err)
+			}
+
+			recs[i] = b
+		}
+
+		var err error
+		if chunks, err = splitJSONRecords(recs, max); err != nil {
+			return err
+		}
+	}
+
+	send := func(ctx context.Context) error {
+		if len(chunks) == 1 {
+			_, _, err := sc.postWrite(ctx, node, url, chunks[0].buf, nil)
+			return err
+		}
+
+		ws := &WriteSummary{}
+		for _, c := range chunks {
+			wc := WriteChunk{First: c.first, Records: c.records,
+				Bytes: c.buf.Len()}
+			if ctx.Err() != nil {
+				wc.Err = fmt.Errorf("context terminated: %w", ctx.Err())
+			} else {
+				_, _, wc.Err = sc.postWrite(ctx, node, url, c.buf, nil)
+			}
+
+			ws.add(wc, nil)
+		}
+
+		return ws.err()
+	}
+
+	if sc.getAckLevel(ctx) == AckNone {
+		return sc.writeAsync(ctx, send)
+	}
+
+	return send(ctx)
+}
+
+// packMetricList encodes a list of metrics as a FlatBuffers metric list.
+func packMetricList(builder *flatbuffers.Builder,
+	metrics []*noit.MetricT) []byte {
+	builder.Reset()
+	offset := noit.MetricListPack(builder, &noit.MetricListT{Metrics: metrics})
+	builder.FinishWithFileIdentifier(offset, []byte("CIML"))
+	b := builder.FinishedBytes()
+	out := make([]byte, len(b))
+	copy(out, b)
+	return out
+}
+
+// splitMetricList recursively halves a list of metrics until each part
+// encodes to a FlatBuffers payload of no more than max bytes.
+func splitMetricList(builder *flatbuffers.Builder, metrics []*noit.MetricT,
+	first int, max int64, chunks []payloadChunk) ([]payloadChunk, error) {
+	b := packMetricList(builder, metrics)
+	if int64(len(b)) <= max {
+		return append(chunks, payloadChunk{
+			first:   first,
+			records: len(metrics),
+			buf:     bytes.NewBuffer(b),
+		}), nil
+	}
+
+	if len(metrics) == 1 {
+		return nil, fmt.Errorf("record %d exceeds maximum write payload "+
+			"size: %d bytes", first, len(b))
+	}
+
+	mid := len(metrics) / 2
+	chunks, err := splitMetricList(builder, metrics[:mid], first, max, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitMetricList(builder, metrics[mid:], first+mid, max, chunks)
+}
+
+// writeRawMetricListChunks sends a list of metrics to a node in multiple
+// FlatBuffers requests, each no larger than the maximum write payload size,
+// and combines the responses.
+func (sc *SnowthClient) writeRawMetricListChunks(ctx context.Context,
+	metricList *noit.MetricListT, builder *flatbuffers.Builder, max int64,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	chunks, err := splitMetricList(builder, metricList.Metrics, 0, max,
+		[]payloadChunk{})
+	if err != nil {
+		return nil, err
+	}
+
+	ws := &WriteSummary{}
+	for _, c := range chunks {
+		wc := WriteChunk{First: c.first, Records: c.records, Bytes: c.buf.Len()}
+		var r *IRONdbPutResponse
+		if ctx.Err() != nil {
+			wc.Err = fmt.Errorf("context terminated: %w", ctx.Err())
+		} else {
+			r, wc.Err = sc.WriteRawContext(ctx, c.buf, true,
+				uint64(c.records), nodes...)
+		}
+
+		ws.add(wc, r)
+	}
+
+	return &ws.Response, ws.err()
+}
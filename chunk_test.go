@@ -0,0 +1,336 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+func TestSplitJSONRecords(t *testing.T) {
+	recs := [][]byte{[]byte(`"aaaa"`), []byte(`"bbbb"`), []byte(`"cccc"`)}
+	chunks, err := splitJSONRecords(recs, 18)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) != 2 {
+		t.Fatalf("Expected chunks: 2, got: %v", len(chunks))
+	}
+
+	if chunks[0].buf.String() != "[\"aaaa\",\"bbbb\"]\n" {
+		t.Errorf("Expected chunk: %q, got: %q", "[\"aaaa\",\"bbbb\"]\n",
+			chunks[0].buf.String())
+	}
+
+	if chunks[1].first != 2 || chunks[1].records != 1 {
+		t.Errorf("Expected first: 2, records: 1, got: %v, %v",
+			chunks[1].first, chunks[1].records)
+	}
+
+	if _, err := splitJSONRecords(recs, 5); err == nil {
+		t.Error("Expected oversized record error")
+	}
+}
+
+func TestWriteNumericChunked(t *testing.T) {
+	mu := sync.Mutex{}
+	received := []string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/numeric" {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Error("Unable to read request body")
+			}
+
+			if len(b) > 300 {
+				t.Errorf("Expected payload size <= 300, got: %v", len(b))
+			}
+
+			data := []NumericWrite{}
+			if err := json.Unmarshal(b, &data); err != nil {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, d := range data {
+				if d.Metric == "fail" {
+					w.WriteHeader(500)
+					return
+				}
+
+				received = append(received, d.Metric)
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	sc.SetMaxWritePayload(300)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	data := []NumericWrite{}
+	for i := 0; i < 10; i++ {
+		data = append(data, NumericWrite{Metric: strconv.Itoa(i)})
+	}
+
+	if err := sc.WriteNumeric(data, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(received) != 10 {
+		t.Fatalf("Expected records: 10, got: %v", len(received))
+	}
+
+	for i, m := range received {
+		if m != strconv.Itoa(i) {
+			t.Errorf("Expected metric: %v, got: %v", i, m)
+		}
+	}
+
+	data[9].Metric = "fail"
+	err = sc.WriteNumeric(data, node)
+	ws := &WriteSummary{}
+	if !errors.As(err, &ws) {
+		t.Fatalf("Expected write summary error, got: %v", err)
+	}
+
+	if ws.Failed != 1 {
+		t.Errorf("Expected failed chunks: 1, got: %v", ws.Failed)
+	}
+
+	if ws.Records != 9 {
+		t.Errorf("Expected records: 9, got: %v", ws.Records)
+	}
+}
+
+func TestWriteRawMetricListChunked(t *testing.T) {
+	requests := 0
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/raw" {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Error("Unable to read request body")
+			}
+
+			if len(b) > 1024 {
+				t.Errorf("Expected payload size <= 1024, got: %v", len(b))
+			}
+
+			requests++
+			_, _ = w.Write([]byte(`{"records":` +
+				r.Header.Get("X-Snowth-Datapoints") +
+				`,"updated":0,"misdirected":0,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetMaxWritePayload(1024)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	list := &noit.MetricListT{}
+	for i := 0; i < 50; i++ {
+		list.Metrics = append(list.Metrics, &noit.MetricT{
+			Timestamp: 1,
+			CheckName: "test",
+			CheckUuid: "11223344-5566-7788-9900-aabbccddeeff",
+			AccountId: 1,
+			Value: &noit.MetricValueT{
+				Name:      fmt.Sprintf("test%d", i),
+				Timestamp: 1,
+				Value: &noit.MetricValueUnionT{
+					Type:  noit.MetricValueUnionIntValue,
+					Value: &noit.IntValueT{Value: 1},
+				},
+			},
+		})
+	}
+
+	res, err := sc.WriteRawMetricList(list, flatbuffers.NewBuilder(0), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if requests < 2 {
+		t.Errorf("Expected multiple requests, got: %v", requests)
+	}
+
+	if res.Records != 50 {
+		t.Errorf("Expected records: 50, got: %v", res.Records)
+	}
+}
+
+func TestWriteNNTTextChunked(t *testing.T) {
+	mu := sync.Mutex{}
+	received := map[string][]string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/nnt" || r.RequestURI == "/write/text" {
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Error("Unable to read request body")
+			}
+
+			if len(b) > 400 {
+				t.Errorf("Expected payload size <= 400, got: %v", len(b))
+			}
+
+			data := []struct {
+				Metric string          `json:"metric"`
+				Parts  json.RawMessage `json:"parts"`
+			}{}
+			if err := json.Unmarshal(b, &data); err != nil {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			for _, d := range data {
+				if d.Metric == "fail" {
+					w.WriteHeader(500)
+					return
+				}
+
+				if r.RequestURI == "/write/nnt" &&
+					string(d.Parts) != `[60,null]` {
+					t.Errorf("Expected parts: [60,null], got: %s", d.Parts)
+				}
+
+				received[r.RequestURI] = append(received[r.RequestURI],
+					d.Metric)
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	sc.SetMaxWritePayload(400)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	nnt := []NNTData{}
+	text := []TextData{}
+	for i := 0; i < 10; i++ {
+		nnt = append(nnt, NNTData{Metric: strconv.Itoa(i),
+			Parts: Parts{Period: 60}})
+		text = append(text, TextData{Metric: strconv.Itoa(i)})
+	}
+
+	if err := sc.WriteNNT(nnt, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.WriteText(text, node); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"/write/nnt", "/write/text"} {
+		if len(received[p]) != 10 {
+			t.Fatalf("Expected %s records: 10, got: %v", p, len(received[p]))
+		}
+
+		for i, m := range received[p] {
+			if m != strconv.Itoa(i) {
+				t.Errorf("Expected %s metric: %v, got: %v", p, i, m)
+			}
+		}
+	}
+
+	nnt[0].Metric = "fail"
+	err = sc.WriteNNT(nnt, node)
+	ws := &WriteSummary{}
+	if !errors.As(err, &ws) {
+		t.Fatalf("Expected write summary error, got: %v", err)
+	}
+
+	if ws.Failed != 1 || len(ws.Chunks) < 2 {
+		t.Errorf("Expected failed chunks: 1 of at least 2, got: %v of %v",
+			ws.Failed, len(ws.Chunks))
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // Logger values implement the behavior used by SnowthClient for logging,
@@ -95,6 +97,10 @@ type SnowthClient struct {
 	sync.RWMutex
 	c httpClient
 
+	// parent is the client which owns the nodes and topology used by a
+	// client created using WithConfig.
+	parent *SnowthClient
+
 	// retries is used to determine weather or not to retry requests which
 	// fail due to timeouts or other non-connection problems
 	retries int64
@@ -137,6 +143,183 @@ type SnowthClient struct {
 	// current topology
 	currentTopology         string
 	currentTopologyCompiled *Topology
+
+	// watchdogInterval and watchdogFailures control how often the node
+	// health watchdog checks nodes, and how many consecutive failures will
+	// cause a node to be deactivated.
+	watchdogInterval time.Duration
+	watchdogFailures int64
+
+	// watchdogStop and watchdogDone are used to stop a running watchdog and
+	// wait for it to exit.
+	watchdogStop chan struct{}
+	watchdogDone chan struct{}
+
+	// healthFuncs are user registered callbacks invoked by the watchdog when
+	// a node changes between active and inactive states.
+	healthFuncs []func(e *NodeHealthEvent)
+
+	// discoverInterval is the duration between automatic topology refreshes
+	// performed when node discovery is enabled.
+	discoverInterval time.Duration
+
+	// discoverStop and discoverDone are used to stop the running discovery
+	// process and wait for it to exit.
+	discoverStop chan struct{}
+	discoverDone chan struct{}
+
+	// schemaMode controls the handling of unknown response fields.
+	schemaMode SchemaMode
+
+	// clock provides the current time and timers for all time dependent
+	// behavior of the client.
+	clock Clock
+
+	// readTimeout, writeTimeout and adminTimeout are applied to the context
+	// of requests according to the kind of operation being performed.
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+	adminTimeout time.Duration
+
+	// maxWritePayload is the maximum size in bytes of a write request body.
+	maxWritePayload int64
+
+	// rateLimit and rateBurst define the default token bucket used to limit
+	// the rate of requests sent to each node. limiters holds the token bucket
+	// for each node, keyed by node URL, including any per node overrides.
+	// Clients created using WithConfig use the rate limits of the original
+	// client.
+	rateLimit float64
+	rateBurst int64
+	limiters  map[string]*rateLimiter
+
+	// hedgeDelay is the duration after which an incomplete read request is
+	// also sent to a second node.
+	hedgeDelay time.Duration
+
+	// ackLevel is the default write acknowledgment level. asyncWrites tracks
+	// the writes sent in the background with the AckNone level, and
+	// asyncSlots limits the number of them outstanding at once.
+	ackLevel    AckLevel
+	asyncWrites sync.WaitGroup
+	asyncSlots  chan struct{}
+
+	// writeConsistency is the default number of owning nodes which must
+	// acknowledge writes.
+	writeConsistency WriteConsistency
+
+	// spool holds writes which could not be sent to any node, to be replayed
+	// when connectivity is restored.
+	spool *Spool
+
+	// nonFinitePolicy and writePrecision control the handling of NaN,
+	// infinite and high precision floating point values in writes.
+	nonFinitePolicy NonFinitePolicy
+	writePrecision  int
+
+	// compression enables gzip compression of large write request bodies
+	// and of responses.
+	compression bool
+
+	// cardinality tracks the stream tag cardinality of written metrics, if a
+	// cardinality budget is configured.
+	cardinality *cardinalityGuard
+
+	// nodeAddress overrides the addresses of nodes discovered from the
+	// cluster topology.
+	nodeAddress NodeAddressFunc
+
+	// resolveCache caches metric name and tag query resolutions, if enabled.
+	// uuidCache caches metric name resolutions in memory when it is not.
+	resolveCache *resolveCache
+	uuidCache    *resolveCache
+
+	// nodeSelection is the mode used to select the node owning a metric from
+	// which its data is read.
+	nodeSelection NodeSelection
+
+	// maxRedirects is the maximum number of redirects followed by a request.
+	maxRedirects int
+
+	// disableRedirects causes redirect responses to be returned as errors.
+	disableRedirects bool
+
+	// quarantine contains the nodes which have been manually deactivated,
+	// keyed by URL, with the time their quarantine ends, or a zero time if
+	// they remain inactive until reactivated.
+	quarantine map[string]time.Time
+
+	// healthFailures contains the number of consecutive failed health checks
+	// of each node, keyed by URL. The watchdog trips the circuit breaker of a
+	// node, deactivating it, when this reaches watchdogFailures.
+	healthFailures map[string]int64
+
+	// requestID generates the request IDs of requests.
+	requestID func(ctx context.Context) string
+
+	// debugDump causes every request and response to be logged, with bodies
+	// truncated to debugDumpLimit bytes.
+	debugDump      bool
+	debugDumpLimit int
+
+	// stateStore persists the client state across restarts, if set.
+	stateStore StateStore
+
+	// topologyNodes contains the nodes of the most recently retrieved
+	// topology, keyed by ID, used to detect changes in the topology.
+	topologyNodes map[string]TopologyNode
+
+	// topologyWatchers receive topology change events.
+	topologyWatchers []*topologyWatcher
+
+	// closing and closed are set when the client is being shut down, and when
+	// it no longer accepts requests. done is closed to stop background
+	// processes, and inflight tracks the requests in progress.
+	closing  bool
+	closed   bool
+	done     chan struct{}
+	inflight sync.WaitGroup
+
+	// tlsAuditFuncs are notified the first time each node whose TLS
+	// certificate is not verified is used, and tlsAudited contains the hosts
+	// of those nodes which have been used.
+	tlsAuditFuncs []func(e *TLSAuditEvent)
+	tlsAudited    map[string]bool
+
+	// reqStats records the latency and errors of the requests sent to each
+	// node.
+	reqStats requestStats
+
+	// headers and userAgent are sent with every request which does not set
+	// them itself.
+	headers   http.Header
+	userAgent string
+
+	// retryBudget limits the retries of all requests, if set, and
+	// retryBudgetFuncs are notified when it is exhausted.
+	retryBudget      *retryBudget
+	retryBudgetFuncs []func(e *RetryBudgetEvent)
+
+	// keepAlives allows connections to be reused for multiple requests.
+	keepAlives bool
+
+	// tracerProvider creates the tracer used to trace requests, if set.
+	tracerProvider TracerProvider
+
+	// requestQueue limits the number of requests in progress, if set, and
+	// priorityHeader is the header used to send request priorities.
+	requestQueue   *requestQueue
+	priorityHeader string
+
+	// metrics records client metrics, if set.
+	metrics *Metrics
+
+	// slowRequestThreshold is the request latency at or above which requests
+	// are logged as slow requests, if set.
+	slowRequestThreshold time.Duration
+
+	// interceptors wrap the function used to send requests.
+	interceptors []Interceptor
 }
 
 // NewSnowthClient initializes a new SnowthClient value, constructing all the
@@ -159,24 +342,37 @@ func NewSnowthClient(discover bool, addrs ...string) (*SnowthClient, error) {
 
 // NewClient creates and performs initial setup of a new SnowthClient.
 func NewClient(cfg *Config) (*SnowthClient, error) {
+	proxy, err := cfg.proxyFunc()
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           cfg.dialer(),
+		ForceAttemptHTTP2:     true,
+		DisableKeepAlives:     !cfg.KeepAlives(),
+		MaxConnsPerHost:       cfg.MaxConnsPerHost(),
+		MaxIdleConns:          cfg.MaxIdleConns(),
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost(),
+		IdleConnTimeout:       cfg.IdleConnTimeout(),
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 10 * time.Second,
+	}
+
+	if tc := cfg.TLSConfig(); tc != nil {
+		transport.TLSClientConfig = tc.Clone()
+	}
+
 	client := &http.Client{
-		Timeout: cfg.Timeout(),
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			DialContext: (&net.Dialer{
-				Timeout:   cfg.DialTimeout(),
-				KeepAlive: 30 * time.Second,
-				DualStack: true,
-			}).DialContext,
-			ForceAttemptHTTP2:     true,
-			DisableKeepAlives:     true,
-			MaxConnsPerHost:       0,
-			MaxIdleConns:          10,
-			MaxIdleConnsPerHost:   1,
-			IdleConnTimeout:       5 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 10 * time.Second,
-		},
+		Timeout:   cfg.Timeout(),
+		Transport: transport,
+	}
+
+	var nodeTLS *nodeTLSTransport
+	if hosts := cfg.InsecureNodes(); len(hosts) > 0 {
+		nodeTLS = newNodeTLSTransport(transport, hosts)
+		client.Transport = nodeTLS
 	}
 
 	sc := &SnowthClient{
@@ -188,6 +384,61 @@ func NewClient(cfg *Config) (*SnowthClient, error) {
 		connRetries:   cfg.ConnectRetries(),
 		dumpRequests:  os.Getenv("GOSNOWTH_DUMP_REQUESTS"),
 		traceRequests: os.Getenv("GOSNOWTH_TRACE_REQUESTS"),
+		schemaMode:    cfg.SchemaMode(),
+
+		watchdogInterval: cfg.WatchdogInterval(),
+		watchdogFailures: cfg.WatchdogFailures(),
+		discoverInterval: cfg.DiscoverInterval(),
+		clock:            realClock{},
+		readTimeout:      cfg.ReadTimeout(),
+		writeTimeout:     cfg.WriteTimeout(),
+		adminTimeout:     cfg.AdminTimeout(),
+		maxWritePayload:  cfg.MaxWritePayload(),
+		rateLimit:        cfg.RateLimit(),
+		rateBurst:        cfg.RateBurst(),
+		limiters:         map[string]*rateLimiter{},
+		hedgeDelay:       cfg.HedgeDelay(),
+		asyncSlots:       make(chan struct{}, maxAsyncWrites),
+		nonFinitePolicy:  cfg.NonFinitePolicy(),
+		writePrecision:   cfg.WritePrecision(),
+		compression:      cfg.Compression(),
+		cardinality: newCardinalityGuard(cfg.CardinalityLimit(),
+			cfg.CardinalityAction()),
+		nodeAddress:   cfg.NodeAddress(),
+		nodeSelection: cfg.NodeSelection(),
+		uuidCache: &resolveCache{store: NewMemoryCacheStore(),
+			ttl: defaultMetricUUIDTTL},
+
+		maxRedirects:     cfg.MaxRedirects(),
+		disableRedirects: cfg.DisableRedirects(),
+		quarantine:       map[string]time.Time{},
+		healthFailures:   map[string]int64{},
+		debugDump:        cfg.DebugDump(),
+		debugDumpLimit:   cfg.DebugDumpLimit(),
+		stateStore:       cfg.StateStore(),
+		spool:            cfg.Spool(),
+		done:             make(chan struct{}),
+		headers:          cfg.Headers(),
+		userAgent:        cfg.UserAgent(),
+		retryBudget: newRetryBudget(cfg.RetryBudgetRatio(),
+			cfg.RetryBudgetMax()),
+		keepAlives:     cfg.KeepAlives(),
+		tracerProvider: cfg.TracerProvider(),
+		requestQueue:   newRequestQueue(cfg.MaxConcurrentRequests()),
+		priorityHeader: cfg.PriorityHeader(),
+		metrics:        cfg.Metrics(),
+
+		slowRequestThreshold: cfg.SlowRequestThreshold(),
+	}
+
+	client.CheckRedirect = sc.checkRedirect
+	for _, d := range []time.Duration{sc.readTimeout, sc.writeTimeout,
+		sc.adminTimeout} {
+		sc.raiseHTTPTimeout(d)
+	}
+
+	if nodeTLS != nil {
+		nodeTLS.audit = sc.auditInsecure
 	}
 
 	// For each of the addrs we need to parse the connection string,
@@ -231,7 +482,18 @@ func NewClient(cfg *Config) (*SnowthClient, error) {
 		return nil, fmt.Errorf("no snowth nodes could be activated")
 	}
 
-	if cfg.Discover() {
+	// A persisted state from a previous client can be used to warm start the
+	// client, if the topology has not changed since it was saved.
+	var saved *ClientState
+	if sc.stateStore != nil {
+		if saved, err = sc.stateStore.LoadState(); err != nil {
+			sc.LogWarnf("unable to load client state: %v", err)
+		}
+	}
+
+	warm := saved != nil && saved.Topology != "" &&
+		saved.Topology == sc.currentTopology
+	if cfg.Discover() && !warm {
 		// For robustness, we will perform a discovery of associated nodes
 		// this works by pulling the topology information for given nodes
 		// and adding nodes discovered within the topology into the client.
@@ -240,6 +502,14 @@ func NewClient(cfg *Config) (*SnowthClient, error) {
 		}
 	}
 
+	sc.restoreState(saved, cfg.Discover() && warm)
+	sc.persistState()
+	if cfg.Discover() {
+		// Nodes joining or leaving the cluster after the client is created
+		// are handled by periodically refreshing the topology.
+		sc.StartDiscovery()
+	}
+
 	return sc, nil
 }
 
@@ -350,23 +620,48 @@ func (sc *SnowthClient) LogDebugf(format string, args ...interface{}) {
 
 // Topology returns the currently active topology
 func (sc *SnowthClient) Topology() (*Topology, error) {
-	if sc.currentTopologyCompiled != nil {
-		return sc.currentTopologyCompiled, nil
+	return sc.TopologyContext(context.Background())
+}
+
+// TopologyContext is the context aware version of Topology.
+func (sc *SnowthClient) TopologyContext(ctx context.Context) (*Topology,
+	error) {
+	if sc.parent != nil {
+		return sc.parent.TopologyContext(ctx)
+	}
+
+	sc.RLock()
+	topo := sc.currentTopologyCompiled
+	sc.RUnlock()
+	if topo != nil {
+		return topo, nil
 	}
 
-	var lasterr error = nil
+	lasterr := fmt.Errorf("no active nodes")
 	for _, node := range sc.ListActiveNodes() {
-		if topology, lasterr := sc.GetTopologyInfo(node); lasterr == nil {
+		topology, err := sc.GetTopologyInfoContext(ctx, node)
+		if err == nil {
+			sc.Lock()
 			sc.currentTopologyCompiled = topology
+			sc.Unlock()
 			return topology, nil
 		}
+
+		lasterr = err
 	}
+
 	return nil, lasterr
 }
 
 // FindMetricNodeIDs returns (possibly) as list of uuid node identifiers that own the metric
 func (sc *SnowthClient) FindMetricNodeIDs(uuid, metric string) []string {
-	topo, err := sc.Topology()
+	return sc.FindMetricNodeIDsContext(context.Background(), uuid, metric)
+}
+
+// FindMetricNodeIDsContext is the context aware version of FindMetricNodeIDs.
+func (sc *SnowthClient) FindMetricNodeIDsContext(ctx context.Context,
+	uuid, metric string) []string {
+	topo, err := sc.TopologyContext(ctx)
 	if topo == nil || err != nil {
 		return make([]string, 0)
 	}
@@ -381,10 +676,11 @@ func (sc *SnowthClient) FindMetricNodeIDs(uuid, metric string) []string {
 // account the ability to get the node state, gossip information and the gossip
 // age of the node. If the age is larger than 10 the node is considered
 // inactive.
-func (sc *SnowthClient) isNodeActive(node *SnowthNode) bool {
+func (sc *SnowthClient) isNodeActive(ctx context.Context,
+	node *SnowthNode) bool {
 	if node.identifier == "" || node.semVer == "" {
 		// go get state to figure out identity
-		stats, err := sc.GetStats(node)
+		stats, err := sc.GetStatsContext(ctx, node)
 		if err != nil {
 			// error means we failed, node is not active
 			sc.LogWarnf("unable to get the state of the node: %s",
@@ -398,7 +694,7 @@ func (sc *SnowthClient) isNodeActive(node *SnowthNode) bool {
 			node.GetURL().Host, node.identifier)
 	}
 
-	gossip, err := sc.GetGossipInfo(node)
+	gossip, err := sc.GetGossipInfoContext(ctx, node)
 	if err != nil {
 		sc.LogWarnf("unable to get the gossip info of the node: %s",
 			err.Error())
@@ -430,18 +726,20 @@ func (sc *SnowthClient) WatchAndUpdate(ctx context.Context) {
 	sc.RLock()
 	wi := sc.watchInterval
 	sc.RUnlock()
-	if wi <= time.Duration(0) {
+	if wi <= time.Duration(0) || sc.parent != nil {
 		return
 	}
 
 	go func() {
-		tick := time.NewTicker(wi)
+		tick := sc.getClock().NewTicker(wi)
 		defer tick.Stop()
 		for {
 			select {
 			case <-ctx.Done():
 				return
-			case <-tick.C:
+			case <-sc.done:
+				return
+			case <-tick.C():
 				sc.LogDebugf("firing watch and update")
 				if err := sc.discoverNodes(); err != nil {
 					sc.LogErrorf("failed to perform watch discovery: %v", err)
@@ -453,7 +751,7 @@ func (sc *SnowthClient) WatchAndUpdate(ctx context.Context) {
 				for _, node := range sc.ListInactiveNodes() {
 					sc.LogDebugf("checking node for inactive -> active: %s",
 						node.GetURL().Host)
-					if sc.isNodeActive(node) {
+					if !sc.isQuarantined(node) && sc.isNodeActive(ctx, node) {
 						// Move to active.
 						sc.LogDebugf("active, moving to active list: %s",
 							node.GetURL().Host)
@@ -468,7 +766,9 @@ func (sc *SnowthClient) WatchAndUpdate(ctx context.Context) {
 				for _, node := range sc.ListActiveNodes() {
 					sc.LogDebugf("checking node for active -> inactive: %s",
 						node.GetURL().Host)
-					if !sc.isNodeActive(node) {
+					// A node is not deactivated because the watch was stopped
+					// while it was being checked.
+					if !sc.isNodeActive(ctx, node) && ctx.Err() == nil {
 						// Move to inactive.
 						sc.LogWarnf("inactive, moving to inactive list: %s",
 							node.GetURL().Host)
@@ -479,6 +779,8 @@ func (sc *SnowthClient) WatchAndUpdate(ctx context.Context) {
 						wf(node)
 					}
 				}
+
+				sc.replaySpoolWatch(ctx)
 			}
 		}
 	}()
@@ -500,10 +802,12 @@ func (sc *SnowthClient) discoverNodes() error {
 		}
 
 		// populate all the nodes with the appropriate topology information
+		events := sc.topologyEvents(node.GetCurrentTopology(), topology)
 		for _, topoNode := range topology.Nodes {
 			sc.populateNodeInfo(node.GetCurrentTopology(), topoNode)
 		}
 
+		sc.notifyTopology(events)
 		success = true
 	}
 
@@ -519,33 +823,26 @@ func (sc *SnowthClient) discoverNodes() error {
 // populateNodeInfo populates an existing node with details from the topology.
 // If a node doesn't exist, it will be added to the list of active nodes.
 func (sc *SnowthClient) populateNodeInfo(hash string, topology TopologyNode) {
+	if sc.parent != nil {
+		sc.parent.populateNodeInfo(hash, topology)
+		return
+	}
+
 	sc.Lock()
 	found := false
 	for i := 0; i < len(sc.activeNodes); i++ {
 		if sc.activeNodes[i].identifier == topology.ID {
 			found = true
-			url := url.URL{
-				Scheme: "http",
-				Host: fmt.Sprintf("%s:%d", topology.Address,
-					topology.APIPort),
-			}
-
-			sc.activeNodes[i].url = &url
+			sc.activeNodes[i].url = sc.topologyNodeURL(topology)
 			sc.activeNodes[i].currentTopology = hash
 			continue
 		}
 	}
 
 	for i := 0; i < len(sc.inactiveNodes); i++ {
-		found = true
 		if sc.inactiveNodes[i].identifier == topology.ID {
-			url := url.URL{
-				Scheme: "http",
-				Host: fmt.Sprintf("%s:%d", topology.Address,
-					topology.APIPort),
-			}
-
-			sc.inactiveNodes[i].url = &url
+			found = true
+			sc.inactiveNodes[i].url = sc.topologyNodeURL(topology)
 			sc.inactiveNodes[i].currentTopology = hash
 			continue
 		}
@@ -559,12 +856,8 @@ func (sc *SnowthClient) populateNodeInfo(hash string, topology TopologyNode) {
 	sc.Unlock()
 	if !found {
 		newNode := &SnowthNode{
-			identifier: topology.ID,
-			url: &url.URL{
-				Scheme: "http",
-				Host: fmt.Sprintf("%s:%d", topology.Address,
-					topology.APIPort),
-			},
+			identifier:      topology.ID,
+			url:             sc.topologyNodeURL(topology),
 			currentTopology: hash,
 		}
 
@@ -575,6 +868,11 @@ func (sc *SnowthClient) populateNodeInfo(hash string, topology TopologyNode) {
 
 // ActivateNodes makes provided nodes active.
 func (sc *SnowthClient) ActivateNodes(nodes ...*SnowthNode) {
+	if sc.parent != nil {
+		sc.parent.ActivateNodes(nodes...)
+		return
+	}
+
 	sc.Lock()
 	defer sc.Unlock()
 	in := []*SnowthNode{}
@@ -606,6 +904,7 @@ func (sc *SnowthClient) ActivateNodes(nodes ...*SnowthNode) {
 
 		if !match {
 			an = append(an, v)
+			sc.LogInfow("gosnowth node activated", "node", v.GetURL().Host)
 		}
 	}
 
@@ -614,6 +913,11 @@ func (sc *SnowthClient) ActivateNodes(nodes ...*SnowthNode) {
 
 // DeactivateNodes makes provided nodes inactive.
 func (sc *SnowthClient) DeactivateNodes(nodes ...*SnowthNode) {
+	if sc.parent != nil {
+		sc.parent.DeactivateNodes(nodes...)
+		return
+	}
+
 	sc.Lock()
 	defer sc.Unlock()
 	an := []*SnowthNode{}
@@ -629,6 +933,10 @@ func (sc *SnowthClient) DeactivateNodes(nodes ...*SnowthNode) {
 
 		if !match {
 			an = append(an, av)
+		} else {
+			sc.metrics.trip(av.GetURL().Host)
+			sc.LogWarnw("gosnowth node deactivated", "node",
+				av.GetURL().Host)
 		}
 	}
 
@@ -653,6 +961,11 @@ func (sc *SnowthClient) DeactivateNodes(nodes ...*SnowthNode) {
 
 // AddNodes adds node values to the inactive node list.
 func (sc *SnowthClient) AddNodes(nodes ...*SnowthNode) {
+	if sc.parent != nil {
+		sc.parent.AddNodes(nodes...)
+		return
+	}
+
 	sc.Lock()
 	defer sc.Unlock()
 	in := []*SnowthNode{}
@@ -676,6 +989,10 @@ func (sc *SnowthClient) AddNodes(nodes ...*SnowthNode) {
 
 // ListInactiveNodes lists all of the currently inactive nodes.
 func (sc *SnowthClient) ListInactiveNodes() []*SnowthNode {
+	if sc.parent != nil {
+		return sc.parent.ListInactiveNodes()
+	}
+
 	sc.RLock()
 	defer sc.RUnlock()
 	result := []*SnowthNode{}
@@ -685,6 +1002,10 @@ func (sc *SnowthClient) ListInactiveNodes() []*SnowthNode {
 
 // ListActiveNodes lists all of the currently active nodes.
 func (sc *SnowthClient) ListActiveNodes() []*SnowthNode {
+	if sc.parent != nil {
+		return sc.parent.ListActiveNodes()
+	}
+
 	sc.RLock()
 	defer sc.RUnlock()
 	result := []*SnowthNode{}
@@ -694,6 +1015,10 @@ func (sc *SnowthClient) ListActiveNodes() []*SnowthNode {
 
 // GetActiveNode returns a random active node in the cluster
 func (sc *SnowthClient) GetActiveNode(idsets ...[]string) *SnowthNode {
+	if sc.parent != nil {
+		return sc.parent.GetActiveNode(idsets...)
+	}
+
 	sc.RLock()
 	defer sc.RUnlock()
 	if len(sc.activeNodes) == 0 {
@@ -727,6 +1052,39 @@ func (sc *SnowthClient) DoRequest(node *SnowthNode,
 func (sc *SnowthClient) DoRequestContext(ctx context.Context, node *SnowthNode,
 	method string, url string, body io.Reader,
 	headers http.Header) (io.Reader, http.Header, error) {
+	return sc.doRequest(ctx, node, nil, method, url, body, headers)
+}
+
+// doRequest sends a request to IRONdb, retrying using other nodes on failures.
+// Active nodes with identifiers in the owners list are tried before any other
+// nodes, and are always tried when a request fails with a connection error or
+// a server error, regardless of the connect retries setting. If requests are
+// traced, the request and all of its retries are recorded in a single span.
+func (sc *SnowthClient) doRequest(ctx context.Context, node *SnowthNode,
+	owners []string, method string, url string, body io.Reader,
+	headers http.Header) (io.Reader, http.Header, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, span := sc.startSpan(ctx, method, url)
+	bdy, hdr, err := sc.retryRequest(ctx, node, owners, method, url, body,
+		headers)
+	endSpan(span, err)
+	return bdy, hdr, err
+}
+
+// retryRequest sends a request to IRONdb, retrying using other nodes on
+// failures, as described by doRequest.
+func (sc *SnowthClient) retryRequest(ctx context.Context, node *SnowthNode,
+	owners []string, method string, url string, body io.Reader,
+	headers http.Header) (io.Reader, http.Header, error) {
+	if d := sc.operationTimeout(method, url); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+
 	retries := sc.Retries()
 	if retries < 0 {
 		retries = 0
@@ -741,10 +1099,22 @@ func (sc *SnowthClient) DoRequestContext(ctx context.Context, node *SnowthNode,
 		}
 	}
 
+	if bBody, headers, err = sc.compressBody(method, url, bBody,
+		headers); err != nil {
+		return nil, nil, err
+	}
+
 	cr := sc.ConnectRetries()
-	nodes := append([]*SnowthNode{node}, sc.ListActiveNodes()...)
+	nodes := append([]*SnowthNode{node}, sc.ownerNodes(node, owners)...)
+	failover := len(nodes)
+	if isNoFailover(ctx) {
+		nodes, failover = nodes[:1], 1
+	} else {
+		nodes = append(nodes, sc.ListActiveNodes()...)
+	}
 	var bdy io.Reader
 	var hdr http.Header
+	var last *SnowthNode
 	for r := int64(0); r < retries+1; r++ {
 		connRetries := cr
 		surl := url
@@ -764,11 +1134,24 @@ func (sc *SnowthClient) DoRequestContext(ctx context.Context, node *SnowthNode,
 				surl = sc.getURL(sn, u)
 			}
 
+			if last != nil {
+				if !sc.allowRetry(method, url, last, err) {
+					return bdy, hdr, &retryBudgetError{err: err}
+				}
+
+				sc.getMetrics().retry(nodeHost(last), endpointName(url))
+				sc.LogInfow("gosnowth retrying request", "method", method,
+					"endpoint", endpointName(url), "node", nodeHost(last),
+					"retry_node", nodeHost(sn), "error", err)
+			}
+
+			last = sn
 			sc.LogDebugf("gosnowth attempting request: %s %s %v",
 				method, surl, sn)
-			bdy, hdr, err = sc.do(ctx, sn, method, surl,
-				bytes.NewBuffer(bBody), headers)
+			bdy, hdr, err = sc.doHedged(ctx, sn, method, surl, bBody,
+				headers)
 			if err == nil {
+				sc.depositRetry()
 				return bdy, hdr, nil
 			}
 
@@ -778,10 +1161,16 @@ func (sc *SnowthClient) DoRequestContext(ctx context.Context, node *SnowthNode,
 			// There are likely more types of IRONdb errors that need to be
 			// checked for and included in this section for errors which
 			// indicate that retries would not be helpful.
-			if strings.Contains(err.Error(), "cannot parse") {
+			if !IsRetryable(err) {
 				return bdy, hdr, err
 			}
 
+			// Always try the remaining owners of the requested data when the
+			// request fails due to a connection or server error.
+			if n+1 < failover && ctx.Err() == nil && isFailoverError(err) {
+				continue
+			}
+
 			// Stop retrying other nodes if this is not a network connection
 			// error.
 			if nerr, ok := err.(net.Error); ok && !nerr.Temporary() {
@@ -801,7 +1190,11 @@ func (sc *SnowthClient) DoRequestContext(ctx context.Context, node *SnowthNode,
 			}
 		}
 
-		time.Sleep(time.Millisecond * time.Duration(100*2^r))
+		select {
+		case <-ctx.Done():
+		case <-sc.getClock().After(time.Millisecond *
+			time.Duration(100*2^r)):
+		}
 	}
 
 	return bdy, hdr, err
@@ -814,37 +1207,32 @@ func (sc *SnowthClient) do(ctx context.Context, node *SnowthNode,
 		ctx = context.Background()
 	}
 
-	r, err := http.NewRequest(method, sc.getURL(node, url), body)
+	finish, err := sc.startRequest()
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, nil, err
 	}
 
-	sc.RLock()
-	traceReq := sc.traceRequests != "" && (sc.traceRequests == "*" || strings.HasPrefix(r.URL.Path, sc.traceRequests))
-	traceID := time.Now().UTC().Nanosecond()
-	dumpReq := sc.dumpRequests != "" && (sc.dumpRequests == "*" || strings.HasPrefix(r.URL.Path, sc.dumpRequests))
-	sc.RUnlock()
+	defer finish()
+	done, err := sc.waitRequestQueue(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	r.Close = true
-	for key, values := range headers {
-		for _, value := range values {
-			r.Header.Add(key, value)
-		}
+	defer done()
+	if err := sc.waitRateLimit(ctx, node); err != nil {
+		return nil, nil, err
+	}
+
+	r, reqID, err := sc.newRequest(ctx, node, method, url, body, headers)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	r = r.WithContext(ctx)
 	sc.RLock()
-	rf := sc.request
+	traceReq := sc.traceRequests != "" && (sc.traceRequests == "*" || strings.HasPrefix(r.URL.Path, sc.traceRequests))
+	traceID := time.Now().UTC().Nanosecond()
+	dumpReq := sc.dumpRequests != "" && (sc.dumpRequests == "*" || strings.HasPrefix(r.URL.Path, sc.dumpRequests))
 	sc.RUnlock()
-	if rf != nil {
-		if err := rf(r); err != nil {
-			return nil, nil, fmt.Errorf("unable to process request: %w", err)
-		}
-
-		if r == nil {
-			return nil, nil, fmt.Errorf("invalid request after processing")
-		}
-	}
 
 	if traceReq {
 		ctrace := &httptrace.ClientTrace{
@@ -903,34 +1291,51 @@ func (sc *SnowthClient) do(ctx context.Context, node *SnowthNode,
 		fmt.Println(string(dump))
 	}
 
-	sc.LogDebugf("gosnowth request: %+v", r)
-	var start = time.Now()
+	sc.LogDebugf("gosnowth request %s: %+v", reqID, r)
+	debugDump, dumpLimit := sc.debugDumpEnabled(ctx)
+	if debugDump {
+		sc.dumpRequest(r, reqID, dumpLimit)
+	}
+
+	clock := sc.getClock()
+	var start = clock.Now()
 	sc.RLock()
 	cli := sc.c
 	sc.RUnlock()
-	resp, err := cli.Do(r)
+	resp, err := sc.roundTrip(cli)(r)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to perform request: %w", err)
+		if debugDump {
+			sc.LogInfof("gosnowth dump response %s: error: %v", reqID, err)
+		}
+
+		se := &SnowthError{
+			Node:      r.URL.Host,
+			Method:    r.Method,
+			Endpoint:  r.URL.Path,
+			RequestID: r.Header.Get(RequestIDHeader),
+			Err:       err,
+		}
+
+		sc.recordRequest(node, r.URL.Path, start, 0, se)
+		return nil, nil, se
 	}
 
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	res, err := ioutil.ReadAll(resp.Body)
+	res, err := readResponseBody(resp)
+	sc.recordRequest(node, r.URL.Path, start, resp.StatusCode,
+		err)
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to read response body: %w", err)
 	}
 
-	newTopo := resp.Header.Get("X-Topo-0")
-	sc.Lock()
-	if newTopo != "" && (newTopo != sc.currentTopology || newTopo != node.currentTopology) {
-		sc.currentTopology = newTopo
-		node.currentTopology = newTopo
-		sc.currentTopologyCompiled = nil
+	if debugDump {
+		sc.dumpResponse(resp, res, reqID, dumpLimit, clock.Now().Sub(start))
 	}
-	sc.Unlock()
 
+	sc.updateTopology(node, resp.Header)
 	if traceReq {
 		msg := string(res[0:64]) + "..."
 		if resp.StatusCode != http.StatusOK {
@@ -939,9 +1344,9 @@ func (sc *SnowthClient) do(ctx context.Context, node *SnowthNode,
 		fmt.Printf("TRACE-%d: complete %s - %s\n", traceID, resp.Status, msg)
 	}
 
-	sc.LogDebugf("gosnowth response: %+v", resp)
+	sc.LogDebugf("gosnowth response %s: %+v", reqID, resp)
 	// sc.LogDebugf("gosnowth response body: %v", string(res))
-	sc.LogDebugf("gosnowth latency: %+v", time.Since(start))
+	sc.LogDebugf("gosnowth latency: %+v", clock.Now().Sub(start))
 	select {
 	case <-ctx.Done():
 		return nil, nil, fmt.Errorf("context terminated: %w", ctx.Err())
@@ -949,16 +1354,78 @@ func (sc *SnowthClient) do(ctx context.Context, node *SnowthNode,
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		sc.LogWarnf("error returned from IRONdb: [%d] %s",
-			resp.StatusCode, string(res))
+		sc.LogWarnf("error returned from IRONdb: [%d] %s (request ID: %s)",
+			resp.StatusCode, string(res), reqID)
 		return bytes.NewBuffer(res), resp.Header,
-			fmt.Errorf("error returned from IRONdb (%s): [%d] %s",
-				r.URL.Host, resp.StatusCode, string(res))
+			newSnowthError(r, resp.StatusCode, res)
 	}
 
 	return bytes.NewBuffer(res), resp.Header, nil
 }
 
+// newRequest creates a request to send to a node, with the request headers,
+// default headers and request ID set, and processed by the request function
+// of the client, if any. It returns the request and its request ID.
+func (sc *SnowthClient) newRequest(ctx context.Context, node *SnowthNode,
+	method, url string, body io.Reader,
+	headers http.Header) (*http.Request, string, error) {
+	r, err := http.NewRequest(method, sc.getURL(node, url), body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	sc.RLock()
+	keepAlives := sc.keepAlives
+	sc.RUnlock()
+
+	r.Close = !keepAlives
+	for key, values := range headers {
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+
+	r = r.WithContext(ctx)
+	sc.applyHeaders(r)
+	sc.setPriorityHeader(r)
+
+	if sc.compressionEnabled() &&
+		r.Header.Get(snowthapi.HeaderAcceptEncoding) == "" {
+		r.Header.Set(snowthapi.HeaderAcceptEncoding, snowthapi.EncodingGzip)
+	}
+
+	reqID := sc.setRequestID(r)
+	sc.traceRequest(r, reqID)
+	sc.RLock()
+	rf := sc.request
+	sc.RUnlock()
+	if rf != nil {
+		if err := rf(r); err != nil {
+			return nil, "", fmt.Errorf("unable to process request: %w", err)
+		}
+
+		if r == nil {
+			return nil, "", fmt.Errorf("invalid request after processing")
+		}
+	}
+
+	return r, reqID, nil
+}
+
+// updateTopology records the current topology of the cluster, if it is
+// reported in the headers of a response from a node.
+func (sc *SnowthClient) updateTopology(node *SnowthNode, hdr http.Header) {
+	newTopo := hdr.Get(snowthapi.HeaderTopology)
+	root := sc.root()
+	root.Lock()
+	if newTopo != "" && (newTopo != root.currentTopology || newTopo != node.currentTopology) {
+		root.currentTopology = newTopo
+		node.currentTopology = newTopo
+		root.currentTopologyCompiled = nil
+	}
+	root.Unlock()
+}
+
 // getURL resolves the URL with a reference for a particular node.
 func (sc *SnowthClient) getURL(node *SnowthNode, ref string) string {
 	return resolveURL(node.url, ref)
@@ -201,7 +201,7 @@ func TestSnowthClientDiscoverNodesWatch(t *testing.T) {
 	sc.WatchAndUpdate(ctx)
 	sc.AddNodes(node)
 	sc.ActivateNodes(node)
-	if !sc.isNodeActive(node) {
+	if !sc.isNodeActive(context.Background(), node) {
 		t.Errorf("Expected node to be active")
 	}
 
@@ -211,13 +211,13 @@ func TestSnowthClientDiscoverNodesWatch(t *testing.T) {
 	})
 
 	time.Sleep(150 * time.Millisecond)
-	if sc.isNodeActive(node) {
+	if sc.isNodeActive(context.Background(), node) {
 		t.Errorf("Expected node to be inactive")
 	}
 
 	sc.SetRequestFunc(nil)
 	time.Sleep(150 * time.Millisecond)
-	if !sc.isNodeActive(node) {
+	if !sc.isNodeActive(context.Background(), node) {
 		t.Errorf("Expected node to be active")
 	}
 
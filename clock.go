@@ -0,0 +1,201 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock values provide the current time and timers used by SnowthClient
+// values for all time dependent behavior. A Clock can be assigned to a client
+// with SetClock() so that tests can control the passage of time.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker values deliver ticks on a channel at intervals, as time.Ticker
+// values do.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock values implement the Clock interface using the system clock.
+type realClock struct{}
+
+// Now returns the current system time.
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// After waits for the duration to elapse and then sends the current time on
+// the returned channel.
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTicker returns a new ticker using the system clock.
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+// realTicker values implement the Ticker interface using a time.Ticker.
+type realTicker struct {
+	t *time.Ticker
+}
+
+// C returns the channel on which ticks are delivered.
+func (rt realTicker) C() <-chan time.Time {
+	return rt.t.C
+}
+
+// Stop turns off the ticker.
+func (rt realTicker) Stop() {
+	rt.t.Stop()
+}
+
+// SystemClock returns a Clock value which uses the system clock. This is the
+// default clock used by SnowthClient values.
+func SystemClock() Clock {
+	return realClock{}
+}
+
+// SetClock assigns the clock used by the client for all time dependent
+// behavior. If the clock is nil, the system clock is used.
+func (sc *SnowthClient) SetClock(c Clock) {
+	if c == nil {
+		c = realClock{}
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+	sc.clock = c
+}
+
+// getClock returns the clock used by the client.
+func (sc *SnowthClient) getClock() Clock {
+	sc.RLock()
+	defer sc.RUnlock()
+	if sc.clock == nil {
+		return realClock{}
+	}
+
+	return sc.clock
+}
+
+// ManualClock values implement a Clock which only changes time when it is
+// explicitly advanced. This is intended for use in tests.
+type ManualClock struct {
+	sync.Mutex
+	now     time.Time
+	waiters []*manualWaiter
+}
+
+// manualWaiter values represent pending timers and tickers of a ManualClock.
+type manualWaiter struct {
+	when    time.Time
+	period  time.Duration
+	ch      chan time.Time
+	stopped bool
+}
+
+// NewManualClock creates a new ManualClock set to the specified time.
+func NewManualClock(t time.Time) *ManualClock {
+	return &ManualClock{now: t}
+}
+
+// Now returns the current time of the clock.
+func (mc *ManualClock) Now() time.Time {
+	mc.Lock()
+	defer mc.Unlock()
+	return mc.now
+}
+
+// After returns a channel which receives the clock time once the clock has
+// been advanced by at least the specified duration.
+func (mc *ManualClock) After(d time.Duration) <-chan time.Time {
+	mc.Lock()
+	defer mc.Unlock()
+	w := &manualWaiter{when: mc.now.Add(d), ch: make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- mc.now
+		return w.ch
+	}
+
+	mc.waiters = append(mc.waiters, w)
+	return w.ch
+}
+
+// NewTicker returns a ticker which ticks each time the clock is advanced past
+// a multiple of the specified duration.
+func (mc *ManualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for ManualClock.NewTicker")
+	}
+
+	mc.Lock()
+	defer mc.Unlock()
+	w := &manualWaiter{
+		when:   mc.now.Add(d),
+		period: d,
+		ch:     make(chan time.Time, 1),
+	}
+
+	mc.waiters = append(mc.waiters, w)
+	return &manualTicker{mc: mc, w: w}
+}
+
+// Advance moves the clock forward by the specified duration, firing any
+// timers and tickers which become due.
+func (mc *ManualClock) Advance(d time.Duration) {
+	mc.Lock()
+	defer mc.Unlock()
+	mc.now = mc.now.Add(d)
+	pending := []*manualWaiter{}
+	for _, w := range mc.waiters {
+		if w.stopped {
+			continue
+		}
+
+		if w.when.After(mc.now) {
+			pending = append(pending, w)
+			continue
+		}
+
+		// As with time.Ticker, ticks are dropped for slow receivers.
+		select {
+		case w.ch <- mc.now:
+		default:
+		}
+
+		if w.period > 0 {
+			for !w.when.After(mc.now) {
+				w.when = w.when.Add(w.period)
+			}
+
+			pending = append(pending, w)
+		}
+	}
+
+	mc.waiters = pending
+}
+
+// manualTicker values implement the Ticker interface for a ManualClock.
+type manualTicker struct {
+	mc *ManualClock
+	w  *manualWaiter
+}
+
+// C returns the channel on which ticks are delivered.
+func (mt *manualTicker) C() <-chan time.Time {
+	return mt.w.ch
+}
+
+// Stop turns off the ticker.
+func (mt *manualTicker) Stop() {
+	mt.mc.Lock()
+	defer mt.mc.Unlock()
+	mt.w.stopped = true
+}
@@ -0,0 +1,121 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestManualClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	mc := NewManualClock(start)
+	if !mc.Now().Equal(start) {
+		t.Errorf("Expected time: %v, got: %v", start, mc.Now())
+	}
+
+	after := mc.After(time.Second)
+	tick := mc.NewTicker(time.Second)
+	mc.Advance(500 * time.Millisecond)
+	select {
+	case <-after:
+		t.Fatal("Unexpected timer before duration elapsed")
+	case <-tick.C():
+		t.Fatal("Unexpected tick before interval elapsed")
+	default:
+	}
+
+	mc.Advance(500 * time.Millisecond)
+	select {
+	case v := <-after:
+		if !v.Equal(start.Add(time.Second)) {
+			t.Errorf("Expected time: %v, got: %v", start.Add(time.Second), v)
+		}
+	default:
+		t.Fatal("Expected timer to fire")
+	}
+
+	select {
+	case <-tick.C():
+	default:
+		t.Fatal("Expected tick")
+	}
+
+	mc.Advance(3 * time.Second)
+	select {
+	case <-tick.C():
+	default:
+		t.Fatal("Expected tick")
+	}
+
+	tick.Stop()
+	mc.Advance(time.Second)
+	select {
+	case <-tick.C():
+		t.Fatal("Unexpected tick after stop")
+	default:
+	}
+
+	select {
+	case <-mc.After(0):
+	default:
+		t.Fatal("Expected immediate timer")
+	}
+}
+
+func TestSnowthClientClock(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			w.WriteHeader(500)
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	start := time.Unix(1000, 0)
+	mc := NewManualClock(start)
+	sc.SetClock(mc)
+	sc.SetWatchdogInterval(time.Minute)
+	sc.SetWatchdogFailures(1)
+	events := make(chan *NodeHealthEvent, 1)
+	sc.OnNodeHealthChange(func(e *NodeHealthEvent) {
+		events <- e
+	})
+
+	sc.StartWatchdog(context.Background())
+	defer sc.StopWatchdog()
+	for i := 0; i < 100; i++ {
+		mc.Advance(time.Minute)
+		select {
+		case e := <-events:
+			if e.Active {
+				t.Error("Expected node deactivation event")
+			}
+
+			if e.Time.Before(start.Add(time.Minute)) {
+				t.Errorf("Expected event time from clock, got: %v", e.Time)
+			}
+
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	t.Fatal("Expected node deactivation event")
+}
@@ -0,0 +1,170 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ClientOverrides values contain the settings which are changed in a client
+// created using WithConfig. Settings with a zero or nil value are copied from
+// the original client unchanged.
+type ClientOverrides struct {
+	// Timeout is the timeout of each HTTP request.
+	Timeout time.Duration
+
+	// ReadTimeout, WriteTimeout and AdminTimeout are applied to the context
+	// of requests according to the kind of operation being performed.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	AdminTimeout time.Duration
+
+	// Retries and ConnectRetries are the numbers of times failed requests
+	// are retried.
+	Retries        *int64
+	ConnectRetries *int64
+
+	// Log receives the log output of the client.
+	Log Logger
+}
+
+// WithConfig returns a clone of the client using the specified overridden
+// settings. The clone shares the HTTP transport, nodes, topology, node
+// quarantines and node rate limits of the original client, so that nodes
+// activated, deactivated or discovered by either client are used by both, but
+// has its own timeout, retry and log settings. This allows strict deadlines to
+// be used for interactive queries, and lax ones for batch jobs, without
+// creating another connection pool or node watchdog. The clone does not run
+// any background processes, and shutting down the clone does not shut down
+// the original client. Requests made using the clone fail with ErrClosed once
+// the original client is shut down.
+func (sc *SnowthClient) WithConfig(o *ClientOverrides) (*SnowthClient, error) {
+	if o == nil {
+		o = &ClientOverrides{}
+	}
+
+	if o.Timeout < 0 || o.Timeout > (5*time.Minute) {
+		return nil, fmt.Errorf("invalid timeout value")
+	}
+
+	if o.ReadTimeout < 0 || o.WriteTimeout < 0 || o.AdminTimeout < 0 {
+		return nil, fmt.Errorf("invalid operation timeout value")
+	}
+
+	if (o.Retries != nil && *o.Retries < 0) ||
+		(o.ConnectRetries != nil && *o.ConnectRetries < 0) {
+		return nil, fmt.Errorf("invalid retries value")
+	}
+
+	sc.RLock()
+	clone := &SnowthClient{
+		parent:           sc.root(),
+		c:                sc.c,
+		retries:          sc.retries,
+		connRetries:      sc.connRetries,
+		watchInterval:    sc.watchInterval,
+		log:              sc.log,
+		request:          sc.request,
+		watch:            sc.watch,
+		dumpRequests:     sc.dumpRequests,
+		traceRequests:    sc.traceRequests,
+		watchdogInterval: sc.watchdogInterval,
+		watchdogFailures: sc.watchdogFailures,
+		discoverInterval: sc.discoverInterval,
+		clock:            sc.clock,
+		readTimeout:      sc.readTimeout,
+		writeTimeout:     sc.writeTimeout,
+		adminTimeout:     sc.adminTimeout,
+		maxWritePayload:  sc.maxWritePayload,
+		hedgeDelay:       sc.hedgeDelay,
+		ackLevel:         sc.ackLevel,
+		asyncSlots:       sc.asyncSlots,
+		writeConsistency: sc.writeConsistency,
+		spool:            sc.spool,
+		nonFinitePolicy:  sc.nonFinitePolicy,
+		schemaMode:       sc.schemaMode,
+		writePrecision:   sc.writePrecision,
+		compression:      sc.compression,
+		cardinality:      sc.cardinality,
+		nodeAddress:      sc.nodeAddress,
+		resolveCache:     sc.resolveCache,
+		uuidCache:        sc.uuidCache,
+		nodeSelection:    sc.nodeSelection,
+		maxRedirects:     sc.maxRedirects,
+		disableRedirects: sc.disableRedirects,
+		requestID:        sc.requestID,
+		debugDump:        sc.debugDump,
+		debugDumpLimit:   sc.debugDumpLimit,
+		stateStore:       sc.stateStore,
+		done:             make(chan struct{}),
+		headers:          sc.headers,
+		userAgent:        sc.userAgent,
+		retryBudget:      sc.retryBudget,
+		keepAlives:       sc.keepAlives,
+		tracerProvider:   sc.tracerProvider,
+		requestQueue:     sc.requestQueue,
+		priorityHeader:   sc.priorityHeader,
+		metrics:          sc.metrics,
+		interceptors:     append([]Interceptor(nil), sc.interceptors...),
+
+		slowRequestThreshold: sc.slowRequestThreshold,
+	}
+
+	sc.RUnlock()
+	if hc, ok := clone.c.(*http.Client); ok {
+		c := &http.Client{
+			Transport:     hc.Transport,
+			Jar:           hc.Jar,
+			Timeout:       hc.Timeout,
+			CheckRedirect: clone.checkRedirect,
+		}
+
+		if o.Timeout > 0 {
+			c.Timeout = o.Timeout
+		}
+
+		clone.c = c
+	}
+
+	if o.ReadTimeout > 0 {
+		clone.readTimeout = o.ReadTimeout
+	}
+
+	if o.WriteTimeout > 0 {
+		clone.writeTimeout = o.WriteTimeout
+	}
+
+	if o.AdminTimeout > 0 {
+		clone.adminTimeout = o.AdminTimeout
+	}
+
+	for _, d := range []time.Duration{clone.readTimeout, clone.writeTimeout,
+		clone.adminTimeout} {
+		clone.raiseHTTPTimeout(d)
+	}
+
+	if o.Retries != nil {
+		clone.retries = *o.Retries
+	}
+
+	if o.ConnectRetries != nil {
+		clone.connRetries = *o.ConnectRetries
+	}
+
+	if o.Log != nil {
+		clone.log = o.Log
+	}
+
+	return clone, nil
+}
+
+// root returns the client which owns the nodes and topology used by a client.
+// This is the client itself, unless it was created using WithConfig.
+func (sc *SnowthClient) root() *SnowthClient {
+	if sc.parent != nil {
+		return sc.parent
+	}
+
+	return sc
+}
@@ -0,0 +1,229 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithConfig(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	if _, err := sc.WithConfig(&ClientOverrides{
+		Timeout: -time.Second,
+	}); err == nil {
+		t.Error("Expected error for invalid timeout")
+	}
+
+	retries := int64(-1)
+	if _, err := sc.WithConfig(&ClientOverrides{
+		Retries: &retries,
+	}); err == nil {
+		t.Error("Expected error for invalid retries")
+	}
+
+	retries = 5
+	sc.SetSchemaMode(SchemaStrict)
+	ml := &mockLog{}
+	clone, err := sc.WithConfig(&ClientOverrides{
+		Timeout:     time.Minute,
+		ReadTimeout: 2 * time.Second,
+		Retries:     &retries,
+		Log:         ml,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if clone.Retries() != 5 {
+		t.Errorf("Expected retries: 5, got: %v", clone.Retries())
+	}
+
+	if sc.Retries() != 0 {
+		t.Errorf("Expected original retries: 0, got: %v", sc.Retries())
+	}
+
+	if clone.ConnectRetries() != sc.ConnectRetries() {
+		t.Errorf("Expected connect retries: %v, got: %v",
+			sc.ConnectRetries(), clone.ConnectRetries())
+	}
+
+	if clone.readTimeout != 2*time.Second {
+		t.Errorf("Expected read timeout: 2s, got: %v", clone.readTimeout)
+	}
+
+	if clone.writeTimeout != sc.writeTimeout {
+		t.Errorf("Expected write timeout: %v, got: %v", sc.writeTimeout,
+			clone.writeTimeout)
+	}
+
+	if clone.schemaMode != SchemaStrict {
+		t.Errorf("Expected schema mode: strict, got: %v", clone.schemaMode)
+	}
+
+	hc, ok := clone.c.(*http.Client)
+	if !ok {
+		t.Fatalf("Expected HTTP client, got: %T", clone.c)
+	}
+
+	if hc.Timeout != time.Minute {
+		t.Errorf("Expected timeout: 1m, got: %v", hc.Timeout)
+	}
+
+	if hc.Transport != sc.c.(*http.Client).Transport {
+		t.Error("Expected shared transport")
+	}
+
+	if sc.c.(*http.Client).Timeout != 10*time.Second {
+		t.Errorf("Expected original timeout: 10s, got: %v",
+			sc.c.(*http.Client).Timeout)
+	}
+
+	clone.LogInfof("test")
+	if ml.last != "INFO test" {
+		t.Errorf("Expected log entry: INFO test, got: %v", ml.last)
+	}
+
+	if _, err := clone.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	nodes := clone.ListActiveNodes()
+	if len(nodes) != 1 || nodes[0] != sc.ListActiveNodes()[0] {
+		t.Fatalf("Expected shared active nodes, got: %v", nodes)
+	}
+
+	clone.DeactivateNode(nodes[0])
+	if len(sc.ListActiveNodes()) != 0 {
+		t.Error("Expected node to be deactivated in original client")
+	}
+
+	if !sc.isQuarantined(nodes[0]) {
+		t.Error("Expected node to be quarantined in original client")
+	}
+
+	sc.ActivateNode(nodes[0])
+	if len(clone.ListActiveNodes()) != 1 {
+		t.Error("Expected node to be activated in clone")
+	}
+
+	nested, err := clone.WithConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if nested.parent != sc {
+		t.Error("Expected nested clone to share original client nodes")
+	}
+
+	if nested.Retries() != 5 {
+		t.Errorf("Expected retries: 5, got: %v", nested.Retries())
+	}
+	sc.SetRateLimit(1, 1)
+	rl := sc.nodeRateLimiter(nodes[0])
+	if rl == nil || clone.nodeRateLimiter(nodes[0]) != rl {
+		t.Error("Expected clone to share original client rate limiter")
+	}
+
+	clone.SetNodeRateLimit(nodes[0], 5, 5)
+	if rl := sc.nodeRateLimiter(nodes[0]); rl == nil || !rl.override {
+		t.Error("Expected node rate limit override in original client")
+	}
+}
+
+func TestWithConfigShutdown(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetWatchdogInterval(time.Hour)
+	sc.StartWatchdog(context.Background())
+	clone, err := sc.WithConfig(&ClientOverrides{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clone.StartWatchdog(context.Background())
+	clone.RLock()
+	running := clone.watchdogStop != nil
+	clone.RUnlock()
+	if running {
+		t.Error("Expected no watchdog to be started by clone")
+	}
+
+	if err := clone.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	sc.RLock()
+	running = sc.watchdogStop != nil
+	sc.RUnlock()
+	if !running {
+		t.Error("Expected original watchdog to be running")
+	}
+
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := clone.GetStats(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+
+	other, err := sc.WithConfig(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := other.GetStats(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+}
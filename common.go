@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
@@ -66,6 +67,18 @@ func (me multiError) Error() string {
 	return me.String()
 }
 
+// Is returns whether any of the errors matches the target, so that
+// errors.Is() can be used with multiError values.
+func (me multiError) Is(target error) bool {
+	for _, err := range me.errs {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // encodeJSON create a reader of JSON data representing an interface.
 func encodeJSON(v interface{}) (io.Reader, error) {
 	buf := &bytes.Buffer{}
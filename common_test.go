@@ -4,6 +4,7 @@ package gosnowth
 import (
 	"bytes"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -58,6 +59,15 @@ func TestMultiError(t *testing.T) {
 	if res != exp {
 		t.Errorf("Expected result: %v, got: %v", exp, res)
 	}
+
+	me.Add(fmt.Errorf("error 3: %w", ErrNotFound))
+	if !errors.Is(me, ErrNotFound) {
+		t.Errorf("Expected error: %v, got: %v", ErrNotFound, me)
+	}
+
+	if errors.Is(me, ErrClosed) {
+		t.Errorf("Unexpected error: %v", ErrClosed)
+	}
 }
 
 func TestDecodeJSON(t *testing.T) {
@@ -0,0 +1,103 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// compressMinSize is the minimum size in bytes of a write request body which
+// will be compressed when compression is enabled. Smaller bodies are sent
+// uncompressed, since compression would not reduce the transfer time.
+const compressMinSize = 1024
+
+// SetCompression sets whether the client will gzip compress large NNT,
+// numeric, text and histogram write request bodies, and request gzip
+// compressed responses.
+func (sc *SnowthClient) SetCompression(enabled bool) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.compression = enabled
+}
+
+// compressionEnabled returns whether compression is enabled for the client.
+func (sc *SnowthClient) compressionEnabled() bool {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.compression
+}
+
+// isCompressibleWrite returns whether the body of a request with the
+// specified method and URL is a JSON write which can be compressed.
+func isCompressibleWrite(method, ref string) bool {
+	if method != "POST" && method != "PUT" {
+		return false
+	}
+
+	p := ref
+	if u, err := url.Parse(ref); err == nil {
+		p = u.Path
+	}
+
+	return strings.HasPrefix(p, snowthapi.PathWrite) ||
+		strings.HasPrefix(p, snowthapi.PathHistogramWrite)
+}
+
+// compressBody gzip compresses a write request body, if compression is
+// enabled and the body is large enough, and returns the body with a copy of
+// the headers including the required Content-Encoding header.
+func (sc *SnowthClient) compressBody(method, ref string, body []byte,
+	headers http.Header) ([]byte, http.Header, error) {
+	if !sc.compressionEnabled() || len(body) < compressMinSize ||
+		!isCompressibleWrite(method, ref) ||
+		headers.Get(snowthapi.HeaderContentEncoding) != "" {
+		return body, headers, nil
+	}
+
+	buf := &bytes.Buffer{}
+	gw := gzip.NewWriter(buf)
+	if _, err := gw.Write(body); err != nil {
+		return nil, nil, fmt.Errorf("unable to compress request body: %w",
+			err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return nil, nil, fmt.Errorf("unable to compress request body: %w",
+			err)
+	}
+
+	hdrs := http.Header{}
+	for k, v := range headers {
+		hdrs[k] = append([]string{}, v...)
+	}
+
+	hdrs.Set(snowthapi.HeaderContentEncoding, snowthapi.EncodingGzip)
+	return buf.Bytes(), hdrs, nil
+}
+
+// readResponseBody reads the body of a response, decompressing it if it was
+// gzip compressed and not already decompressed by the HTTP transport.
+func readResponseBody(resp *http.Response) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get(snowthapi.HeaderContentEncoding),
+		snowthapi.EncodingGzip) {
+		return ioutil.ReadAll(resp.Body)
+	}
+
+	gr, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		_ = gr.Close()
+	}()
+
+	return ioutil.ReadAll(gr)
+}
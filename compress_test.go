@@ -0,0 +1,127 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompression(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/text" {
+			if r.Header.Get("Content-Encoding") != "gzip" {
+				t.Errorf("Expected content encoding: gzip, got: %v",
+					r.Header.Get("Content-Encoding"))
+				w.WriteHeader(500)
+				return
+			}
+
+			gr, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			data := []TextData{}
+			if err := json.NewDecoder(gr).Decode(&data); err != nil {
+				t.Error(err)
+			}
+
+			if len(data) != 100 {
+				t.Errorf("Expected records: 100, got: %v", len(data))
+			}
+
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/read/1/2/"+
+			"3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d/test") {
+			if r.Header.Get("Accept-Encoding") != "gzip" {
+				t.Errorf("Expected accept encoding: gzip, got: %v",
+					r.Header.Get("Accept-Encoding"))
+			}
+
+			w.Header().Set("Content-Encoding", "gzip")
+			gw := gzip.NewWriter(w)
+			_, _ = gw.Write([]byte(textTestData))
+			_ = gw.Close()
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetCompression(true)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	data := []TextData{}
+	for i := 0; i < 100; i++ {
+		data = append(data, TextData{
+			Metric: "test",
+			ID:     "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+			Offset: "1",
+			Value:  "test",
+		})
+	}
+
+	if err := sc.WriteText(data, node); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := sc.ReadTextValues("3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+		"test", time.Unix(1, 0), time.Unix(2, 0), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Errorf("Expected values: 2, got: %v", len(res))
+	}
+}
+
+func TestIsCompressibleWrite(t *testing.T) {
+	tests := []struct {
+		method string
+		url    string
+		exp    bool
+	}{
+		{"POST", "/write/nnt", true},
+		{"POST", "/histogram/write", true},
+		{"POST", "/raw", false},
+		{"GET", "/write/text", false},
+	}
+
+	for _, test := range tests {
+		if res := isCompressibleWrite(test.method, test.url); res != test.exp {
+			t.Errorf("Expected compressible for %s %s: %v, got: %v",
+				test.method, test.url, test.exp, res)
+		}
+	}
+}
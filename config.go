@@ -2,13 +2,31 @@
 package gosnowth
 
 import (
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
 
+// Environment variables which can be used to configure SnowthClient values
+// through ConfigFromEnv().
+const (
+	EnvServers        = "GOSNOWTH_SERVERS"
+	EnvDialTimeout    = "GOSNOWTH_DIAL_TIMEOUT"
+	EnvDiscover       = "GOSNOWTH_DISCOVER"
+	EnvTimeout        = "GOSNOWTH_TIMEOUT"
+	EnvWatchInterval  = "GOSNOWTH_WATCH_INTERVAL"
+	EnvRetries        = "GOSNOWTH_RETRIES"
+	EnvConnectRetries = "GOSNOWTH_CONNECT_RETRIES"
+)
+
 // Config values represent configuration information SnowthClient values.
 type Config struct {
 	sync.RWMutex
@@ -19,6 +37,56 @@ type Config struct {
 	watchInterval  time.Duration
 	retries        int64
 	connectRetries int64
+	schemaMode     SchemaMode
+
+	watchdogInterval time.Duration
+	watchdogFailures int64
+	discoverInterval time.Duration
+	readTimeout      time.Duration
+	writeTimeout     time.Duration
+	adminTimeout     time.Duration
+	maxWritePayload  int64
+	rateLimit        float64
+	rateBurst        int64
+	hedgeDelay       time.Duration
+	nonFinitePolicy  NonFinitePolicy
+	writePrecision   int
+	compression      bool
+	cardinalityLimit int64
+	cardinalityAct   CardinalityAction
+	proxy            string
+	proxyUser        string
+	proxyPass        string
+	noProxy          []string
+	resolver         *net.Resolver
+	dialContext      DialFunc
+	nodeAddress      NodeAddressFunc
+	nodeSelection    NodeSelection
+	maxRedirects     int
+	disableRedirects bool
+	debugDump        bool
+	debugDumpLimit   int
+	stateStore       StateStore
+	spool            *Spool
+	tlsConfig        *tls.Config
+	insecureNodes    []string
+	headers          http.Header
+	userAgent        string
+	retryBudgetRatio float64
+	retryBudgetMax   int64
+	tracerProvider   TracerProvider
+	priorityHeader   string
+	metrics          *Metrics
+
+	maxConcurrentRequests int
+	slowRequestThreshold  time.Duration
+
+	keepAlives          bool
+	keepAlivePeriod     time.Duration
+	maxIdleConns        int
+	maxIdleConnsPerHost int
+	maxConnsPerHost     int
+	idleConnTimeout     time.Duration
 }
 
 // NewConfig creates and initializes a new SnowthClient configuration value.
@@ -31,6 +99,20 @@ func NewConfig(servers ...string) (*Config, error) {
 		watchInterval:  30 * time.Second,
 		retries:        0,
 		connectRetries: -1,
+
+		watchdogInterval: 10 * time.Second,
+		watchdogFailures: 3,
+		discoverInterval: 5 * time.Minute,
+		rateBurst:        1,
+		writePrecision:   -1,
+		maxRedirects:     defaultMaxRedirects,
+		debugDumpLimit:   defaultDebugDumpLimit,
+		retryBudgetRatio: defaultRetryBudgetRatio,
+
+		keepAlivePeriod:     defaultKeepAlivePeriod,
+		maxIdleConns:        defaultMaxIdleConns,
+		maxIdleConnsPerHost: defaultMaxIdleConnsPerHost,
+		idleConnTimeout:     defaultIdleConnTimeout,
 	}
 
 	if err := c.SetServers(servers...); err != nil {
@@ -40,6 +122,99 @@ func NewConfig(servers ...string) (*Config, error) {
 	return c, nil
 }
 
+// ConfigFromEnv creates and initializes a new SnowthClient configuration value
+// using the default configuration values overridden by any GOSNOWTH_*
+// environment variables which are set.
+func ConfigFromEnv() (*Config, error) {
+	c, err := NewConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.LoadEnv(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// LoadEnv updates the configuration with the values of any GOSNOWTH_*
+// environment variables which are set. The GOSNOWTH_SERVERS variable can
+// contain a list of server addresses separated by commas or spaces.
+func (c *Config) LoadEnv() error {
+	if v := os.Getenv(EnvServers); v != "" {
+		servers := strings.FieldsFunc(v, func(r rune) bool {
+			return r == ',' || r == ' '
+		})
+
+		if err := c.SetServers(servers...); err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvServers, err)
+		}
+	}
+
+	if v := os.Getenv(EnvDialTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvDialTimeout, err)
+		}
+
+		if err := c.SetDialTimeout(d); err != nil {
+			return err
+		}
+	}
+
+	if v := os.Getenv(EnvDiscover); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvDiscover, err)
+		}
+
+		c.SetDiscover(b)
+	}
+
+	if v := os.Getenv(EnvTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvTimeout, err)
+		}
+
+		if err := c.SetTimeout(d); err != nil {
+			return err
+		}
+	}
+
+	if v := os.Getenv(EnvWatchInterval); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvWatchInterval, err)
+		}
+
+		if err := c.SetWatchInterval(d); err != nil {
+			return err
+		}
+	}
+
+	if v := os.Getenv(EnvRetries); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvRetries, err)
+		}
+
+		c.SetRetries(n)
+	}
+
+	if v := os.Getenv(EnvConnectRetries); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("unable to parse %s: %w", EnvConnectRetries, err)
+		}
+
+		c.SetConnectRetries(n)
+	}
+
+	return nil
+}
+
 // MarshalJSON encodes a Config value into a JSON format byte slice.
 func (c *Config) MarshalJSON() ([]byte, error) {
 	c.RLock()
@@ -186,8 +361,31 @@ func (c *Config) SetDiscover(d bool) {
 	c.Unlock()
 }
 
+// DiscoverInterval gets the frequency at which a SnowthClient with discovery
+// enabled will refresh the cluster topology to find nodes which have joined
+// or left the cluster. The default value is 5 minutes.
+func (c *Config) DiscoverInterval() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.discoverInterval
+}
+
+// SetDiscoverInterval sets a new interval for automatic topology refreshes. A
+// value of zero disables automatic refreshes.
+func (c *Config) SetDiscoverInterval(i time.Duration) error {
+	if i < 0 || i > (time.Hour*24) {
+		return fmt.Errorf("invalid discover interval value")
+	}
+
+	c.Lock()
+	c.discoverInterval = i
+	c.Unlock()
+	return nil
+}
+
 // Timeout gets the timeout duration for HTTP requests to IRONdb. The default
-// value is 10 seconds.
+// value is 10 seconds. If any of the read, write or admin timeouts is longer,
+// the HTTP request timeout is raised to the longest of them.
 func (c *Config) Timeout() time.Duration {
 	c.RLock()
 	defer c.RUnlock()
@@ -206,6 +404,257 @@ func (c *Config) SetTimeout(t time.Duration) error {
 	return nil
 }
 
+// ReadTimeout gets the timeout duration applied to data read and search
+// operations, in addition to any deadline of the caller's context. A value of
+// zero means no additional timeout is applied.
+func (c *Config) ReadTimeout() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.readTimeout
+}
+
+// SetReadTimeout sets a new timeout duration for read operations.
+func (c *Config) SetReadTimeout(t time.Duration) error {
+	if t < 0 || t > (5*time.Minute) {
+		return fmt.Errorf("invalid read timeout value")
+	}
+
+	c.Lock()
+	c.readTimeout = t
+	c.Unlock()
+	return nil
+}
+
+// WriteTimeout gets the timeout duration applied to data write and delete
+// operations, in addition to any deadline of the caller's context. A value of
+// zero means no additional timeout is applied.
+func (c *Config) WriteTimeout() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.writeTimeout
+}
+
+// SetWriteTimeout sets a new timeout duration for write operations.
+func (c *Config) SetWriteTimeout(t time.Duration) error {
+	if t < 0 || t > (5*time.Minute) {
+		return fmt.Errorf("invalid write timeout value")
+	}
+
+	c.Lock()
+	c.writeTimeout = t
+	c.Unlock()
+	return nil
+}
+
+// AdminTimeout gets the timeout duration applied to administrative
+// operations, such as state, stats, gossip and topology requests, in addition
+// to any deadline of the caller's context. A value of zero means no
+// additional timeout is applied.
+func (c *Config) AdminTimeout() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.adminTimeout
+}
+
+// SetAdminTimeout sets a new timeout duration for administrative operations.
+func (c *Config) SetAdminTimeout(t time.Duration) error {
+	if t < 0 || t > (5*time.Minute) {
+		return fmt.Errorf("invalid admin timeout value")
+	}
+
+	c.Lock()
+	c.adminTimeout = t
+	c.Unlock()
+	return nil
+}
+
+// MaxWritePayload gets the maximum size, in bytes, of the body of a single
+// write request. Writes with larger payloads are split into multiple requests.
+// A value of zero, the default, means no limit is enforced.
+func (c *Config) MaxWritePayload() int64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.maxWritePayload
+}
+
+// SetMaxWritePayload sets a new maximum write request payload size in bytes.
+func (c *Config) SetMaxWritePayload(n int64) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max write payload value")
+	}
+
+	c.Lock()
+	c.maxWritePayload = n
+	c.Unlock()
+	return nil
+}
+
+// RateLimit gets the maximum sustained rate, in requests per second, at which
+// the client will send requests to each individual node. A value of zero, the
+// default, means requests are not rate limited.
+func (c *Config) RateLimit() float64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.rateLimit
+}
+
+// SetRateLimit sets a new per node request rate limit in requests per second.
+func (c *Config) SetRateLimit(rps float64) error {
+	if rps < 0 {
+		return fmt.Errorf("invalid rate limit value")
+	}
+
+	c.Lock()
+	c.rateLimit = rps
+	c.Unlock()
+	return nil
+}
+
+// RateBurst gets the maximum number of requests which may be sent to a node in
+// a burst, above the sustained rate limit. The default value is 1.
+func (c *Config) RateBurst() int64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.rateBurst
+}
+
+// SetRateBurst sets a new per node request burst size.
+func (c *Config) SetRateBurst(n int64) error {
+	if n < 1 {
+		return fmt.Errorf("invalid rate burst value")
+	}
+
+	c.Lock()
+	c.rateBurst = n
+	c.Unlock()
+	return nil
+}
+
+// HedgeDelay gets the duration after which a read request which has not yet
+// completed is also sent to a second node, with the first successful response
+// being used. A value of zero, the default, disables hedged reads.
+func (c *Config) HedgeDelay() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.hedgeDelay
+}
+
+// SetHedgeDelay sets a new delay before hedged read requests are sent.
+func (c *Config) SetHedgeDelay(d time.Duration) error {
+	if d < 0 || d > (5*time.Minute) {
+		return fmt.Errorf("invalid hedge delay value")
+	}
+
+	c.Lock()
+	c.hedgeDelay = d
+	c.Unlock()
+	return nil
+}
+
+// NonFinitePolicy gets the policy used to handle NaN and infinite floating
+// point values in raw writes of FlatBuffers metric lists and RawMetric values.
+// The default value is NonFiniteAllow, which sends the values unchanged.
+func (c *Config) NonFinitePolicy() NonFinitePolicy {
+	c.RLock()
+	defer c.RUnlock()
+	return c.nonFinitePolicy
+}
+
+// SetNonFinitePolicy sets a new policy for writing NaN and infinite values.
+func (c *Config) SetNonFinitePolicy(p NonFinitePolicy) error {
+	if p < NonFiniteAllow || p > NonFiniteNull {
+		return fmt.Errorf("invalid non-finite policy value")
+	}
+
+	c.Lock()
+	c.nonFinitePolicy = p
+	c.Unlock()
+	return nil
+}
+
+// WritePrecision gets the number of decimal places to which floating point
+// values are rounded in raw writes of FlatBuffers metric lists and RawMetric
+// values. The default value is -1, which disables rounding.
+func (c *Config) WritePrecision() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.writePrecision
+}
+
+// SetWritePrecision sets a new write precision in decimal places. A negative
+// value disables rounding.
+func (c *Config) SetWritePrecision(digits int) error {
+	if digits > 15 {
+		return fmt.Errorf("invalid write precision value")
+	}
+
+	if digits < 0 {
+		digits = -1
+	}
+
+	c.Lock()
+	c.writePrecision = digits
+	c.Unlock()
+	return nil
+}
+
+// Compression gets whether the client will gzip compress large NNT, numeric,
+// text and histogram write request bodies, and request gzip compressed
+// responses. The default value is false.
+func (c *Config) Compression() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.compression
+}
+
+// SetCompression sets whether the client will use gzip compression.
+func (c *Config) SetCompression(enabled bool) {
+	c.Lock()
+	c.compression = enabled
+	c.Unlock()
+}
+
+// CardinalityLimit gets the budget of distinct stream tag combinations which
+// the client will write for each metric name. A value of zero, the default,
+// disables the cardinality guard.
+func (c *Config) CardinalityLimit() int64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cardinalityLimit
+}
+
+// SetCardinalityLimit sets a new per metric stream tag cardinality budget.
+func (c *Config) SetCardinalityLimit(n int64) error {
+	if n < 0 {
+		return fmt.Errorf("invalid cardinality limit value")
+	}
+
+	c.Lock()
+	c.cardinalityLimit = n
+	c.Unlock()
+	return nil
+}
+
+// CardinalityAction gets the action taken when a write exceeds the stream tag
+// cardinality budget of a metric. The default value is CardinalityWarn.
+func (c *Config) CardinalityAction() CardinalityAction {
+	c.RLock()
+	defer c.RUnlock()
+	return c.cardinalityAct
+}
+
+// SetCardinalityAction sets a new action for exceeded cardinality budgets.
+func (c *Config) SetCardinalityAction(a CardinalityAction) error {
+	if a != CardinalityWarn && a != CardinalityReject {
+		return fmt.Errorf("invalid cardinality action value")
+	}
+
+	c.Lock()
+	c.cardinalityAct = a
+	c.Unlock()
+	return nil
+}
+
 // Retries gets the number of times requests will be retried.
 func (c *Config) Retries() int64 {
 	c.RLock()
@@ -282,3 +731,40 @@ func (c *Config) SetWatchInterval(i time.Duration) error {
 	c.Unlock()
 	return nil
 }
+
+// WatchdogInterval gets the frequency at which the node health watchdog checks
+// the state of every node, if StartWatchdog() is called. The default value is
+// 10 seconds.
+func (c *Config) WatchdogInterval() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.watchdogInterval
+}
+
+// SetWatchdogInterval sets a new interval for the node health watchdog.
+func (c *Config) SetWatchdogInterval(i time.Duration) error {
+	if i < 0 || i > (time.Hour*24) {
+		return fmt.Errorf("invalid watchdog interval value")
+	}
+
+	c.Lock()
+	c.watchdogInterval = i
+	c.Unlock()
+	return nil
+}
+
+// WatchdogFailures gets the number of consecutive failed health checks after
+// which the watchdog will deactivate a node. The default value is 3.
+func (c *Config) WatchdogFailures() int64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.watchdogFailures
+}
+
+// SetWatchdogFailures sets the number of consecutive failed health checks
+// after which the watchdog will deactivate a node.
+func (c *Config) SetWatchdogFailures(num int64) {
+	c.Lock()
+	c.watchdogFailures = num
+	c.Unlock()
+}
@@ -3,6 +3,7 @@ package gosnowth
 
 import (
 	"encoding/json"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -186,3 +187,308 @@ func TestConfigMarshalJSON(t *testing.T) {
 		t.Error("Expected error not returned.")
 	}
 }
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		EnvServers:        "http://test1:8112, http://test2:8112",
+		EnvDialTimeout:    "1s",
+		EnvDiscover:       "true",
+		EnvTimeout:        "20s",
+		EnvWatchInterval:  "1m",
+		EnvRetries:        "2",
+		EnvConnectRetries: "3",
+	}
+
+	for k, v := range env {
+		if err := os.Setenv(k, v); err != nil {
+			t.Fatal(err)
+		}
+
+		defer os.Unsetenv(k)
+	}
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.Servers()) != 2 {
+		t.Fatalf("Expected servers length: 2, got: %v", len(cfg.Servers()))
+	}
+
+	if cfg.Servers()[1] != "http://test2:8112" {
+		t.Errorf("Expected server value: http://test2:8112, got: %v",
+			cfg.Servers()[1])
+	}
+
+	if cfg.DialTimeout() != time.Second {
+		t.Errorf("Expected dial timeout: %v, got: %v",
+			time.Second, cfg.DialTimeout())
+	}
+
+	if !cfg.Discover() {
+		t.Error("Expected discover value: true")
+	}
+
+	if cfg.Timeout() != 20*time.Second {
+		t.Errorf("Expected timeout: %v, got: %v",
+			20*time.Second, cfg.Timeout())
+	}
+
+	if cfg.WatchInterval() != time.Minute {
+		t.Errorf("Expected watch interval: %v, got: %v",
+			time.Minute, cfg.WatchInterval())
+	}
+
+	if cfg.Retries() != 2 {
+		t.Errorf("Expected retries: 2, got: %v", cfg.Retries())
+	}
+
+	if cfg.ConnectRetries() != 3 {
+		t.Errorf("Expected connect retries: 3, got: %v",
+			cfg.ConnectRetries())
+	}
+
+	if err := os.Setenv(EnvTimeout, "invalid"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = ConfigFromEnv()
+	if err == nil || !strings.Contains(err.Error(), EnvTimeout) {
+		t.Errorf("Expected timeout parse error, got: %v", err)
+	}
+}
+
+func TestConfigWatchdog(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetWatchdogInterval(-1); err == nil {
+		t.Error("Expected invalid watchdog interval error")
+	}
+
+	if err := cfg.SetWatchdogInterval(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetWatchdogFailures(5)
+	if cfg.WatchdogInterval() != time.Second {
+		t.Errorf("Expected watchdog interval: %v, got: %v",
+			time.Second, cfg.WatchdogInterval())
+	}
+
+	if cfg.WatchdogFailures() != 5 {
+		t.Errorf("Expected watchdog failures: 5, got: %v",
+			cfg.WatchdogFailures())
+	}
+}
+
+func TestConfigOperationTimeouts(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetReadTimeout(-1); err == nil {
+		t.Error("Expected invalid read timeout error")
+	}
+
+	if err := cfg.SetWriteTimeout(time.Hour); err == nil {
+		t.Error("Expected invalid write timeout error")
+	}
+
+	if err := cfg.SetAdminTimeout(-1); err == nil {
+		t.Error("Expected invalid admin timeout error")
+	}
+
+	if err := cfg.SetReadTimeout(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetWriteTimeout(time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetAdminTimeout(2 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.ReadTimeout() != time.Minute {
+		t.Errorf("Expected read timeout: %v, got: %v", time.Minute,
+			cfg.ReadTimeout())
+	}
+
+	if cfg.WriteTimeout() != time.Second {
+		t.Errorf("Expected write timeout: %v, got: %v", time.Second,
+			cfg.WriteTimeout())
+	}
+
+	if cfg.AdminTimeout() != 2*time.Second {
+		t.Errorf("Expected admin timeout: %v, got: %v", 2*time.Second,
+			cfg.AdminTimeout())
+	}
+}
+
+func TestConfigMaxWritePayload(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetMaxWritePayload(-1); err == nil {
+		t.Error("Expected invalid max write payload error")
+	}
+
+	if err := cfg.SetMaxWritePayload(1024); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MaxWritePayload() != 1024 {
+		t.Errorf("Expected max write payload: 1024, got: %v",
+			cfg.MaxWritePayload())
+	}
+}
+
+func TestConfigRateLimit(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.RateBurst() != 1 {
+		t.Errorf("Expected rate burst: 1, got: %v", cfg.RateBurst())
+	}
+
+	if err := cfg.SetRateLimit(-1); err == nil {
+		t.Error("Expected invalid rate limit error")
+	}
+
+	if err := cfg.SetRateBurst(0); err == nil {
+		t.Error("Expected invalid rate burst error")
+	}
+
+	if err := cfg.SetRateLimit(100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetRateBurst(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.RateLimit() != 100 {
+		t.Errorf("Expected rate limit: 100, got: %v", cfg.RateLimit())
+	}
+
+	if cfg.RateBurst() != 10 {
+		t.Errorf("Expected rate burst: 10, got: %v", cfg.RateBurst())
+	}
+}
+
+func TestConfigHedgeDelay(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetHedgeDelay(-1); err == nil {
+		t.Error("Expected invalid hedge delay error")
+	}
+
+	if err := cfg.SetHedgeDelay(50 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.HedgeDelay() != 50*time.Millisecond {
+		t.Errorf("Expected hedge delay: %v, got: %v", 50*time.Millisecond,
+			cfg.HedgeDelay())
+	}
+}
+
+func TestConfigWritePolicy(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.WritePrecision() != -1 {
+		t.Errorf("Expected write precision: -1, got: %v",
+			cfg.WritePrecision())
+	}
+
+	if err := cfg.SetNonFinitePolicy(NonFinitePolicy(10)); err == nil {
+		t.Error("Expected invalid non-finite policy error")
+	}
+
+	if err := cfg.SetWritePrecision(16); err == nil {
+		t.Error("Expected invalid write precision error")
+	}
+
+	if err := cfg.SetNonFinitePolicy(NonFiniteDrop); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetWritePrecision(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.NonFinitePolicy() != NonFiniteDrop {
+		t.Errorf("Expected non-finite policy: %v, got: %v", NonFiniteDrop,
+			cfg.NonFinitePolicy())
+	}
+
+	if cfg.WritePrecision() != 3 {
+		t.Errorf("Expected write precision: 3, got: %v",
+			cfg.WritePrecision())
+	}
+}
+
+func TestConfigCompression(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Compression() {
+		t.Error("Expected compression to be disabled by default")
+	}
+
+	cfg.SetCompression(true)
+	if !cfg.Compression() {
+		t.Error("Expected compression to be enabled")
+	}
+}
+
+func TestConfigCardinality(t *testing.T) {
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetCardinalityLimit(-1); err == nil {
+		t.Error("Expected invalid cardinality limit error")
+	}
+
+	if err := cfg.SetCardinalityAction(CardinalityAction(5)); err == nil {
+		t.Error("Expected invalid cardinality action error")
+	}
+
+	if err := cfg.SetCardinalityLimit(1000); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetCardinalityAction(CardinalityReject); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.CardinalityLimit() != 1000 {
+		t.Errorf("Expected cardinality limit: 1000, got: %v",
+			cfg.CardinalityLimit())
+	}
+
+	if cfg.CardinalityAction() != CardinalityReject {
+		t.Errorf("Expected cardinality action: %v, got: %v",
+			CardinalityReject, cfg.CardinalityAction())
+	}
+}
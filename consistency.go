@@ -0,0 +1,232 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrWriteQuorum is returned when fewer owning nodes than required by the
+// write consistency level acknowledged a write.
+var ErrWriteQuorum = errors.New("write quorum not reached")
+
+// WriteConsistency values control how many of the nodes which own written
+// metrics must acknowledge a write before it succeeds.
+type WriteConsistency int
+
+// Write consistency levels.
+const (
+	// WriteConsistencyOne writes are sent to a single node, which replicates
+	// the data to the other owning nodes through its journal. This is the
+	// default level.
+	WriteConsistencyOne WriteConsistency = iota
+
+	// WriteConsistencyQuorum writes are sent to every node which owns the
+	// written metrics, according to the cluster topology, and succeed once a
+	// majority of the owning nodes of every metric acknowledge them.
+	WriteConsistencyQuorum
+
+	// WriteConsistencyAll writes are sent to every node which owns the
+	// written metrics, and succeed only once every owning node acknowledges
+	// them.
+	WriteConsistencyAll
+)
+
+// String returns a string representation of the write consistency level.
+func (wc WriteConsistency) String() string {
+	switch wc {
+	case WriteConsistencyOne:
+		return "one"
+	case WriteConsistencyQuorum:
+		return "quorum"
+	case WriteConsistencyAll:
+		return "all"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(wc))
+	}
+}
+
+// required returns the number of acknowledgments required from n owners.
+func (wc WriteConsistency) required(n int) int {
+	switch wc {
+	case WriteConsistencyQuorum:
+		return n/2 + 1
+	case WriteConsistencyAll:
+		return n
+	default:
+		return 1
+	}
+}
+
+// writeConsistencyKey is the context key used to store write consistency
+// levels.
+type writeConsistencyKey struct{}
+
+// WithWriteConsistency returns a copy of the context which specifies the
+// write consistency level used by numeric, NNT, text and histogram writes
+// performed with it, overriding the default level of the client. The level
+// is not used by writes sent to an explicitly specified node.
+func WithWriteConsistency(ctx context.Context,
+	level WriteConsistency) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, writeConsistencyKey{}, level)
+}
+
+// SetWriteConsistency sets the default write consistency level used by the
+// client.
+func (sc *SnowthClient) SetWriteConsistency(level WriteConsistency) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.writeConsistency = level
+}
+
+// getWriteConsistency returns the write consistency level for an operation
+// performed with the specified context.
+func (sc *SnowthClient) getWriteConsistency(
+	ctx context.Context) WriteConsistency {
+	if ctx != nil {
+		if level, ok := ctx.Value(writeConsistencyKey{}).(WriteConsistency); ok {
+			return level
+		}
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.writeConsistency
+}
+
+// noFailoverKey is the context key used to prevent requests from being sent
+// to any node other than the one specified.
+type noFailoverKey struct{}
+
+// withoutFailover returns a copy of the context which prevents requests from
+// failing over to other nodes.
+func withoutFailover(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noFailoverKey{}, true)
+}
+
+// isNoFailover returns whether requests made with the context may only be
+// sent to the specified node.
+func isNoFailover(ctx context.Context) bool {
+	v, _ := ctx.Value(noFailoverKey{}).(bool)
+	return v
+}
+
+// ownerGroup values contain the indexes of written records which are owned
+// by the same nodes.
+type ownerGroup struct {
+	ids  []string
+	recs []int
+}
+
+// writeConsistent sends n JSON encoded records, returned by the rec function,
+// to every node which owns them, returning an error unless enough owners of
+// every record acknowledge the write to satisfy the consistency level. The
+// key function returns the check UUID and metric name of a record.
+func (sc *SnowthClient) writeConsistent(ctx context.Context,
+	level WriteConsistency, url string, n int, rec func(i int) interface{},
+	key func(i int) (string, string)) error {
+	if sc.getAckLevel(ctx) == AckNone {
+		return sc.writeAsync(ctx, func(ctx context.Context) error {
+			return sc.writeConsistent(ctx, level, url, n, rec, key)
+		})
+	}
+
+	topo, err := sc.TopologyContext(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to find metric owners for %s write: %w",
+			level, err)
+	}
+
+	groups := map[string]*ownerGroup{}
+	order := []string{}
+	for i := 0; i < n; i++ {
+		uuid, metric := key(i)
+		ids, err := topo.FindMetricNodeIDs(uuid, metric)
+		if err != nil {
+			return fmt.Errorf("unable to find owners of metric %s %s: %w",
+				uuid, metric, err)
+		}
+
+		k := strings.Join(ids, ",")
+		g, ok := groups[k]
+		if !ok {
+			g = &ownerGroup{ids: ids}
+			groups[k] = g
+			order = append(order, k)
+		}
+
+		g.recs = append(g.recs, i)
+	}
+
+	mErr := newMultiError()
+	for _, k := range order {
+		mErr.Add(sc.writeOwners(ctx, level, url, groups[k], rec))
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// writeOwners sends the records of an owner group to each of the owning
+// nodes, returning an error unless enough of them acknowledge the write.
+func (sc *SnowthClient) writeOwners(ctx context.Context,
+	level WriteConsistency, url string, g *ownerGroup,
+	rec func(i int) interface{}) error {
+	recs := make([]interface{}, len(g.recs))
+	for i, r := range g.recs {
+		recs[i] = rec(r)
+	}
+
+	body, err := json.Marshal(recs)
+	if err != nil {
+		return fmt.Errorf("failed to encode records for write: %w", err)
+	}
+
+	ctx = withoutFailover(ctx)
+	var mu sync.Mutex
+	acks := 0
+	mErr := newMultiError()
+	wg := sync.WaitGroup{}
+	for _, id := range g.ids {
+		node := sc.ownerNodes(nil, []string{id})
+		if len(node) == 0 {
+			mErr.Add(fmt.Errorf("owner node %s is not active", id))
+			continue
+		}
+
+		wg.Add(1)
+		go func(id string, node *SnowthNode) {
+			defer wg.Done()
+			err := sc.writeJSON(ctx, node, url, bytes.NewBuffer(body),
+				len(recs), func(i int) interface{} { return recs[i] })
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				mErr.Add(fmt.Errorf("owner node %s: %w", id, err))
+				return
+			}
+
+			acks++
+		}(id, node[0])
+	}
+
+	wg.Wait()
+	if req := level.required(len(g.ids)); acks < req {
+		return fmt.Errorf("%w: %d of %d owners acknowledged, %d required: %v",
+			ErrWriteQuorum, acks, len(g.ids), req, mErr)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteConsistencyString(t *testing.T) {
+	t.Parallel()
+	if s := WriteConsistencyQuorum.String(); s != "quorum" {
+		t.Errorf("Expected string: quorum, got: %v", s)
+	}
+
+	for n, exp := range map[int]int{1: 1, 2: 2, 3: 2, 5: 3} {
+		if r := WriteConsistencyQuorum.required(n); r != exp {
+			t.Errorf("Expected required acknowledgments for %d: %v, got: %v",
+				n, exp, r)
+		}
+	}
+}
+
+func TestWriteConsistency(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml") {
+			_, _ = w.Write([]byte(topologyXMLTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	var mu sync.Mutex
+	writes := map[string]int{}
+	owners := []string{
+		"9d1a34cd-b150-4c19-a894-e20280b42b62",
+		"3d8ae36d-3d4d-4eda-ab53-c58538985062",
+		"1533fc6b-de08-6eac-eb46-d3920a1a18a3",
+	}
+
+	for i, id := range owners {
+		id, fail := id, i == 2
+		ns := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			if r.RequestURI != "/write/numeric" {
+				t.Errorf("Unexpected request: %v", r)
+				w.WriteHeader(500)
+				return
+			}
+
+			if fail {
+				w.WriteHeader(500)
+				return
+			}
+
+			mu.Lock()
+			writes[id]++
+			mu.Unlock()
+		}))
+
+		defer ns.Close()
+		u, err := url.Parse(ns.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		node := &SnowthNode{url: u, identifier: id}
+		sc.AddNodes(node)
+		sc.ActivateNodes(node)
+	}
+
+	data := []NumericWrite{{
+		Metric: "test", ID: "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		Offset: 1, Count: 1, Value: 1,
+	}}
+
+	ctx := WithWriteConsistency(context.Background(), WriteConsistencyQuorum)
+	if err := sc.WriteNumericContext(ctx, data); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	if writes[owners[0]] != 1 || writes[owners[1]] != 1 {
+		t.Errorf("Expected writes to owners, got: %v", writes)
+	}
+
+	mu.Unlock()
+	sc.SetWriteConsistency(WriteConsistencyAll)
+	err = sc.WriteNumeric(data)
+	if !errors.Is(err, ErrWriteQuorum) {
+		t.Errorf("Expected error: %v, got: %v", ErrWriteQuorum, err)
+	}
+
+	mu.Lock()
+	if writes[owners[0]] != 2 || writes[owners[1]] != 2 {
+		t.Errorf("Expected writes to owners, got: %v", writes)
+	}
+
+	mu.Unlock()
+}
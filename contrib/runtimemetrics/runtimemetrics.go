@@ -0,0 +1,245 @@
+// Package runtimemetrics contains an adapter which periodically samples Go
+// runtime metrics and expvar values of the host application and writes them
+// to IRONdb using a gosnowth client.
+package runtimemetrics
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/circonus-labs/gosnowth"
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// Writer values implement the behavior needed to submit sampled metrics to
+// IRONdb. This is satisfied by *gosnowth.SnowthClient values.
+type Writer interface {
+	WriteRawMetricListContext(ctx context.Context,
+		metricList *noit.MetricListT, builder *flatbuffers.Builder,
+		nodes ...*gosnowth.SnowthNode) (*gosnowth.IRONdbPutResponse, error)
+}
+
+// Reporter values sample runtime metrics and expvar values and write them to
+// IRONdb.
+type Reporter struct {
+	sync.Mutex
+	w         Writer
+	accountID int32
+	checkUUID string
+	checkName string
+	interval  time.Duration
+	tags      []string
+	expvars   bool
+	builder   *flatbuffers.Builder
+	stop      chan struct{}
+	done      chan struct{}
+	errFunc   func(err error)
+}
+
+// NewReporter creates a new reporter which will write metrics to IRONdb using
+// the provided account ID, check UUID and check name. By default, metrics are
+// sampled every 60 seconds, expvar values are included, and every metric is
+// tagged with the host name and Go version.
+func NewReporter(w Writer, accountID int32,
+	checkUUID, checkName string) *Reporter {
+	host, _ := os.Hostname()
+	tags := []string{"go_version:" + runtime.Version()}
+	if host != "" {
+		tags = append(tags, "host:"+host)
+	}
+
+	return &Reporter{
+		w:         w,
+		accountID: accountID,
+		checkUUID: checkUUID,
+		checkName: checkName,
+		interval:  time.Minute,
+		tags:      tags,
+		expvars:   true,
+		builder:   flatbuffers.NewBuilder(1024),
+	}
+}
+
+// SetInterval sets the interval at which metrics are sampled and written.
+func (r *Reporter) SetInterval(d time.Duration) {
+	r.Lock()
+	defer r.Unlock()
+	r.interval = d
+}
+
+// SetTags adds stream tags, in category:value format, to the standard tags
+// applied to every metric.
+func (r *Reporter) SetTags(tags ...string) {
+	r.Lock()
+	defer r.Unlock()
+	r.tags = append(r.tags, tags...)
+}
+
+// SetExpvar sets whether published expvar values are sampled.
+func (r *Reporter) SetExpvar(enabled bool) {
+	r.Lock()
+	defer r.Unlock()
+	r.expvars = enabled
+}
+
+// SetErrorFunc sets a function which is called with any error returned while
+// writing metrics in the background.
+func (r *Reporter) SetErrorFunc(f func(err error)) {
+	r.Lock()
+	defer r.Unlock()
+	r.errFunc = f
+}
+
+// Sample returns a metric list containing the current runtime metrics and,
+// if enabled, expvar values.
+func (r *Reporter) Sample() *noit.MetricListT {
+	r.Lock()
+	tags := make([]string, len(r.tags))
+	copy(tags, r.tags)
+	expvars := r.expvars
+	r.Unlock()
+	ts := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	values := map[string]float64{}
+	ms := &runtime.MemStats{}
+	runtime.ReadMemStats(ms)
+	values["runtime`goroutines"] = float64(runtime.NumGoroutine())
+	values["runtime`gomaxprocs"] = float64(runtime.GOMAXPROCS(0))
+	values["runtime`cgo_calls"] = float64(runtime.NumCgoCall())
+	values["runtime`heap_alloc"] = float64(ms.HeapAlloc)
+	values["runtime`heap_inuse"] = float64(ms.HeapInuse)
+	values["runtime`heap_idle"] = float64(ms.HeapIdle)
+	values["runtime`heap_objects"] = float64(ms.HeapObjects)
+	values["runtime`sys"] = float64(ms.Sys)
+	values["runtime`mallocs"] = float64(ms.Mallocs)
+	values["runtime`frees"] = float64(ms.Frees)
+	values["runtime`gc_count"] = float64(ms.NumGC)
+	values["runtime`gc_pause_total_ns"] = float64(ms.PauseTotalNs)
+	if ms.NumGC > 0 {
+		values["runtime`gc_pause_last_ns"] =
+			float64(ms.PauseNs[(ms.NumGC+255)%256])
+	}
+
+	if expvars {
+		expvar.Do(func(kv expvar.KeyValue) {
+			sampleExpvar(values, "expvar`"+kv.Key, kv.Value)
+		})
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	list := &noit.MetricListT{Metrics: make([]*noit.MetricT, 0, len(names))}
+	for _, name := range names {
+		list.Metrics = append(list.Metrics, &noit.MetricT{
+			Timestamp: ts,
+			CheckName: r.checkName,
+			CheckUuid: r.checkUUID,
+			AccountId: r.accountID,
+			Value: &noit.MetricValueT{
+				Name:      name,
+				Timestamp: ts,
+				Value: &noit.MetricValueUnionT{
+					Type:  noit.MetricValueUnionDoubleValue,
+					Value: &noit.DoubleValueT{Value: values[name]},
+				},
+				StreamTags: tags,
+			},
+		})
+	}
+
+	return list
+}
+
+// sampleExpvar adds the numeric values contained in an expvar value to a map
+// of metric values. Map values are sampled recursively, and non-numeric
+// values are ignored.
+func sampleExpvar(values map[string]float64, name string, v expvar.Var) {
+	switch ev := v.(type) {
+	case *expvar.Map:
+		ev.Do(func(kv expvar.KeyValue) {
+			sampleExpvar(values, name+"`"+kv.Key, kv.Value)
+		})
+	default:
+		if fv, err := strconv.ParseFloat(v.String(), 64); err == nil {
+			values[name] = fv
+		}
+	}
+}
+
+// Report samples the current metrics and writes them to IRONdb.
+func (r *Reporter) Report(ctx context.Context) error {
+	list := r.Sample()
+	r.Lock()
+	defer r.Unlock()
+	if _, err := r.w.WriteRawMetricListContext(ctx, list,
+		r.builder); err != nil {
+		return fmt.Errorf("unable to write runtime metrics: %w", err)
+	}
+
+	return nil
+}
+
+// Start begins sampling and writing metrics in the background until the
+// context is cancelled or Stop() is called. If the reporter is already
+// running, this function does nothing.
+func (r *Reporter) Start(ctx context.Context) {
+	r.Lock()
+	if r.stop != nil || r.interval <= 0 {
+		r.Unlock()
+		return
+	}
+
+	interval := r.interval
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	r.stop, r.done = stop, done
+	r.Unlock()
+	go func() {
+		defer close(done)
+		tick := time.NewTicker(interval)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-tick.C:
+				if err := r.Report(ctx); err != nil {
+					r.Lock()
+					ef := r.errFunc
+					r.Unlock()
+					if ef != nil {
+						ef(err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops a running reporter and waits for it to exit.
+func (r *Reporter) Stop() {
+	r.Lock()
+	stop, done := r.stop, r.done
+	r.stop, r.done = nil, nil
+	r.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
@@ -0,0 +1,125 @@
+// Package runtimemetrics contains an adapter which periodically samples Go
+// runtime metrics and expvar values of the host application and writes them
+// to IRONdb using a gosnowth client.
+package runtimemetrics
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/circonus-labs/gosnowth"
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+type mockWriter struct {
+	sync.Mutex
+	lists []*noit.MetricListT
+	err   error
+}
+
+func (m *mockWriter) WriteRawMetricListContext(ctx context.Context,
+	metricList *noit.MetricListT, builder *flatbuffers.Builder,
+	nodes ...*gosnowth.SnowthNode) (*gosnowth.IRONdbPutResponse, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.lists = append(m.lists, metricList)
+	return &gosnowth.IRONdbPutResponse{}, m.err
+}
+
+func TestReporterSample(t *testing.T) {
+	expvar.NewInt("runtimemetrics_test_int").Set(42)
+	expvar.NewMap("runtimemetrics_test_map").Add("key", 7)
+	expvar.NewString("runtimemetrics_test_string").Set("text")
+	r := NewReporter(&mockWriter{}, 1, "11223344-5566-7788-9900-aabbccddeeff",
+		"test")
+	r.SetTags("service:test")
+	list := r.Sample()
+	values := map[string]float64{}
+	for _, m := range list.Metrics {
+		if m.AccountId != 1 || m.CheckName != "test" {
+			t.Errorf("Unexpected metric check: %v %v", m.AccountId,
+				m.CheckName)
+		}
+
+		dv, ok := m.Value.Value.Value.(*noit.DoubleValueT)
+		if !ok {
+			t.Fatalf("Expected double value, got: %T", m.Value.Value.Value)
+		}
+
+		values[m.Value.Name] = dv.Value
+		found := false
+		for _, tag := range m.Value.StreamTags {
+			if tag == "service:test" {
+				found = true
+			}
+		}
+
+		if !found {
+			t.Errorf("Expected service:test tag, got: %v",
+				m.Value.StreamTags)
+		}
+	}
+
+	if values["runtime`goroutines"] < 1 {
+		t.Errorf("Expected goroutines metric, got: %v",
+			values["runtime`goroutines"])
+	}
+
+	if values["expvar`runtimemetrics_test_int"] != 42 {
+		t.Errorf("Expected expvar int: 42, got: %v",
+			values["expvar`runtimemetrics_test_int"])
+	}
+
+	if values["expvar`runtimemetrics_test_map`key"] != 7 {
+		t.Errorf("Expected expvar map value: 7, got: %v",
+			values["expvar`runtimemetrics_test_map`key"])
+	}
+
+	if _, ok := values["expvar`runtimemetrics_test_string"]; ok {
+		t.Error("Expected non-numeric expvar to be ignored")
+	}
+
+	r.SetExpvar(false)
+	for _, m := range r.Sample().Metrics {
+		if m.Value.Name == "expvar`runtimemetrics_test_int" {
+			t.Error("Expected expvar values to be disabled")
+		}
+	}
+}
+
+func TestReporterStart(t *testing.T) {
+	mw := &mockWriter{err: fmt.Errorf("write failed")}
+	r := NewReporter(mw, 1, "11223344-5566-7788-9900-aabbccddeeff", "test")
+	r.SetInterval(10 * time.Millisecond)
+	errs := make(chan error, 10)
+	r.SetErrorFunc(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	r.Start(context.Background())
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("Expected write error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected background report")
+	}
+
+	r.Stop()
+	r.Stop()
+	mw.Lock()
+	defer mw.Unlock()
+	if len(mw.lists) == 0 {
+		t.Error("Expected metric lists to be written")
+	}
+}
@@ -0,0 +1,544 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// DeleteRawNumericRange deletes the raw numeric data of a metric, which is
+// stored at full resolution before it is rolled up, within a time range. The
+// rolled up NNT data is not deleted; use DeleteNumericRange to delete it. The
+// delete is sent to the specified node, or to an owning node of the metric,
+// or every owning node if the context was created by WithDeleteFanout, and an
+// error is returned unless every node acknowledges it.
+func (sc *SnowthClient) DeleteRawNumericRange(uuid, metric string,
+	start, end time.Time, nodes ...*SnowthNode) error {
+	return sc.DeleteRawNumericRangeContext(context.Background(), uuid, metric,
+		start, end, nodes...)
+}
+
+// DeleteRawNumericRangeContext is the context aware version of
+// DeleteRawNumericRange.
+func (sc *SnowthClient) DeleteRawNumericRangeContext(ctx context.Context,
+	uuid, metric string, start, end time.Time,
+	nodes ...*SnowthNode) error {
+	qp, err := deleteRangeQuery(uuid, metric, start, end)
+	if err != nil {
+		return err
+	}
+
+	if len(nodes) > 1 {
+		nodes = nodes[:1]
+	}
+
+	_, err = sc.deleteNodes(ctx, uuid, metric,
+		path.Join(snowthapi.PathRaw, uuid, url.PathEscape(metric))+"?"+
+			qp.Encode(), nil, nodes...)
+	return err
+}
+
+// deleteRangeQuery validates the metric and time range of a range delete, and
+// returns the query parameters specifying the range.
+func deleteRangeQuery(uuid, metric string, start,
+	end time.Time) (url.Values, error) {
+	if uuid == "" || metric == "" {
+		return nil, fmt.Errorf("uuid and metric name are required for delete")
+	}
+
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid delete range: end must be after start")
+	}
+
+	qp := url.Values{}
+	qp.Add("start_ts", formatTimestamp(start))
+	qp.Add("end_ts", formatTimestamp(end))
+	return qp, nil
+}
+
+// PartitionedDeleteOptions values contain optional parameters used to control
+// a partitioned delete operation.
+type PartitionedDeleteOptions struct {
+	// SliceDuration is the width of each time slice. The default is one day.
+	SliceDuration time.Duration
+
+	// Concurrency is the maximum number of slices deleted in parallel. The
+	// default is 4.
+	Concurrency int
+
+	// Interval is the minimum delay between starting successive slice
+	// deletes, used to limit the request rate. Zero means no delay.
+	Interval time.Duration
+
+	// Verify enables counting the data points in each slice before and after
+	// the delete, to estimate the number of points removed and detect
+	// slices that still contain data.
+	Verify bool
+}
+
+// PartitionedDeleteSlice values contain the result of deleting one time
+// slice of a partitioned delete operation.
+type PartitionedDeleteSlice struct {
+	Start     time.Time
+	End       time.Time
+	Before    int64
+	Remaining int64
+	Err       error
+}
+
+// PartitionedDeleteReport values contain the results of a partitioned delete
+// operation.
+type PartitionedDeleteReport struct {
+	Slices  []PartitionedDeleteSlice
+	Removed int64
+	Failed  int
+}
+
+// Err returns an error combining the errors of all failed slices, or nil if
+// every slice succeeded.
+func (r *PartitionedDeleteReport) Err() error {
+	mErr := newMultiError()
+	for _, s := range r.Slices {
+		if s.Err != nil {
+			mErr.Add(fmt.Errorf("slice %s-%s: %w", formatTimestamp(s.Start),
+				formatTimestamp(s.End), s.Err))
+		}
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// DeleteRawNumericPartitioned deletes raw numeric data for a metric within a
+// large time range by splitting the range into slices which are deleted in
+// parallel.
+func (sc *SnowthClient) DeleteRawNumericPartitioned(uuid, metric string,
+	start, end time.Time, options *PartitionedDeleteOptions,
+	nodes ...*SnowthNode) (*PartitionedDeleteReport, error) {
+	return sc.DeleteRawNumericPartitionedContext(context.Background(), uuid,
+		metric, start, end, options, nodes...)
+}
+
+// DeleteRawNumericPartitionedContext is the context aware version of
+// DeleteRawNumericPartitioned.
+func (sc *SnowthClient) DeleteRawNumericPartitionedContext(
+	ctx context.Context, uuid, metric string, start, end time.Time,
+	options *PartitionedDeleteOptions,
+	nodes ...*SnowthNode) (*PartitionedDeleteReport, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid delete range: end must be after start")
+	}
+
+	opts := PartitionedDeleteOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.SliceDuration <= 0 {
+		opts.SliceDuration = 24 * time.Hour
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	r := &PartitionedDeleteReport{}
+	for s := start; s.Before(end); s = s.Add(opts.SliceDuration) {
+		e := s.Add(opts.SliceDuration)
+		if e.After(end) {
+			e = end
+		}
+
+		r.Slices = append(r.Slices, PartitionedDeleteSlice{Start: s, End: e})
+	}
+
+	var tick <-chan time.Time
+	if opts.Interval > 0 {
+		t := sc.getClock().NewTicker(opts.Interval)
+		defer t.Stop()
+		tick = t.C()
+	}
+
+	g := sc.NewWorkGroup(ctx, opts.Concurrency)
+	for i := range r.Slices {
+		if i > 0 && tick != nil {
+			select {
+			case <-ctx.Done():
+			case <-tick:
+			}
+		}
+
+		if ctx.Err() != nil {
+			r.Slices[i].Err = fmt.Errorf("context terminated: %w", ctx.Err())
+			continue
+		}
+
+		sl := &r.Slices[i]
+		if err := g.Go(func(ctx context.Context) error {
+			sc.deleteSlice(ctx, uuid, metric, sl, opts.Verify, nodes...)
+			return nil
+		}); err != nil {
+			sl.Err = err
+		}
+	}
+
+	// Errors are recorded in each slice, and returned by the report.
+	_ = g.Wait()
+	for _, s := range r.Slices {
+		if s.Err != nil {
+			r.Failed++
+			continue
+		}
+
+		r.Removed += s.Before - s.Remaining
+	}
+
+	return r, r.Err()
+}
+
+// deleteSlice deletes the data in a single slice of a partitioned delete,
+// optionally counting the data points before and after the delete.
+func (sc *SnowthClient) deleteSlice(ctx context.Context, uuid, metric string,
+	sl *PartitionedDeleteSlice, verify bool, nodes ...*SnowthNode) {
+	if verify {
+		v, err := sc.ReadRawNumericValuesContext(ctx, sl.Start, sl.End,
+			uuid, metric, nodes...)
+		if err != nil {
+			sl.Err = fmt.Errorf("unable to count data before delete: %w", err)
+			return
+		}
+
+		sl.Before = int64(len(v))
+	}
+
+	if err := sc.DeleteRawNumericRangeContext(ctx, uuid, metric,
+		sl.Start, sl.End, nodes...); err != nil {
+		sl.Err = err
+		return
+	}
+
+	if verify {
+		v, err := sc.ReadRawNumericValuesContext(ctx, sl.Start, sl.End,
+			uuid, metric, nodes...)
+		if err != nil {
+			sl.Err = fmt.Errorf("unable to count data after delete: %w", err)
+			return
+		}
+
+		sl.Remaining = int64(len(v))
+		if sl.Remaining > 0 {
+			sl.Err = fmt.Errorf("%d data points remain after delete",
+				sl.Remaining)
+		}
+	}
+}
+
+// deleteFanoutKey is the context key used to request that deletes are sent
+// to every node which owns the deleted metric.
+type deleteFanoutKey struct{}
+
+// WithDeleteFanout returns a copy of the context which causes metric deletes
+// performed with it to be sent to every active node which owns the metric,
+// according to the cluster topology, rather than to a single node. The
+// option is not used by deletes sent to explicitly specified nodes.
+func WithDeleteFanout(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, deleteFanoutKey{}, true)
+}
+
+// isDeleteFanout returns whether deletes performed with the context are sent
+// to every owning node.
+func isDeleteFanout(ctx context.Context) bool {
+	v, _ := ctx.Value(deleteFanoutKey{}).(bool)
+	return v
+}
+
+// DeleteResult values contain the result of a delete request sent to one
+// node.
+type DeleteResult struct {
+	Node *SnowthNode
+	Err  error
+}
+
+// DeleteReport values contain the results of a delete operation, with one
+// result for each node the delete was sent to.
+type DeleteReport struct {
+	Results []DeleteResult
+}
+
+// Acknowledged returns the nodes which acknowledged the delete.
+func (r *DeleteReport) Acknowledged() []*SnowthNode {
+	res := []*SnowthNode{}
+	for _, dr := range r.Results {
+		if dr.Err == nil {
+			res = append(res, dr.Node)
+		}
+	}
+
+	return res
+}
+
+// Err returns an error combining the errors of all nodes which did not
+// acknowledge the delete, or nil if every node acknowledged it.
+func (r *DeleteReport) Err() error {
+	mErr := newMultiError()
+	for _, dr := range r.Results {
+		if dr.Err != nil {
+			mErr.Add(fmt.Errorf("node %s: %w", dr.Node.GetURL().Host, dr.Err))
+		}
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// deleteNodes sends a delete request for a metric to the specified nodes, or
+// if none are specified, to one owning node of the metric, or every active
+// owning node if the context requests a delete fanout.
+func (sc *SnowthClient) deleteNodes(ctx context.Context, uuid, metric,
+	url string, hdrs http.Header,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	targets := []*SnowthNode{}
+	for _, n := range nodes {
+		if n != nil {
+			targets = append(targets, n)
+		}
+	}
+
+	if len(targets) == 0 {
+		ids := sc.FindMetricNodeIDsContext(ctx, uuid, metric)
+		if isDeleteFanout(ctx) {
+			targets = sc.ownerNodes(nil, ids)
+		}
+
+		if len(targets) == 0 {
+			if n := sc.GetActiveNode(ids); n != nil {
+				targets = append(targets, n)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("unable to find a node for metric delete")
+	}
+
+	r := &DeleteReport{Results: make([]DeleteResult, len(targets))}
+	if len(targets) > 1 {
+		ctx = withoutFailover(ctx)
+	}
+
+	wg := sync.WaitGroup{}
+	for i, n := range targets {
+		r.Results[i].Node = n
+		wg.Add(1)
+		go func(dr *DeleteResult) {
+			defer wg.Done()
+			_, _, dr.Err = sc.DoRequestContext(ctx, dr.Node, "DELETE", url,
+				nil, hdrs)
+		}(&r.Results[i])
+	}
+
+	wg.Wait()
+	return r, r.Err()
+}
+
+// DeleteMetricFull deletes all data of every type, and the metadata, of a
+// metric of an account, using the IRONdb full delete endpoint. The delete is
+// sent to the specified nodes, or to an owning node of the metric, or every
+// owning node if the context was created by WithDeleteFanout. The returned
+// report contains the result from each node.
+func (sc *SnowthClient) DeleteMetricFull(accountID int64, uuid, metric string,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	return sc.DeleteMetricFullContext(context.Background(), accountID, uuid,
+		metric, nodes...)
+}
+
+// DeleteMetricFullContext is the context aware version of DeleteMetricFull.
+func (sc *SnowthClient) DeleteMetricFullContext(ctx context.Context,
+	accountID int64, uuid, metric string,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	if uuid == "" || metric == "" {
+		return nil, fmt.Errorf("uuid and metric name are required for delete")
+	}
+
+	qp := url.Values{}
+	qp.Add("account_id", strconv.FormatInt(accountID, 10))
+	return sc.deleteNodes(ctx, uuid, metric,
+		path.Join(snowthapi.PathFullCanonical, uuid, url.PathEscape(metric))+
+			"?"+qp.Encode(), nil, nodes...)
+}
+
+// DeleteNumericBefore deletes the numeric NNT and rollup data of a metric
+// which is older than the cutoff time. The raw numeric data is not deleted;
+// use DeleteRawNumericRange to delete it. The delete is sent to the specified
+// nodes, or to an owning node of the metric, or every owning node if the
+// context was created by WithDeleteFanout. The returned report contains the
+// result from each node.
+func (sc *SnowthClient) DeleteNumericBefore(uuid, metric string,
+	cutoff time.Time, nodes ...*SnowthNode) (*DeleteReport, error) {
+	return sc.DeleteNumericBeforeContext(context.Background(), uuid, metric,
+		cutoff, nodes...)
+}
+
+// DeleteNumericBeforeContext is the context aware version of
+// DeleteNumericBefore.
+func (sc *SnowthClient) DeleteNumericBeforeContext(ctx context.Context,
+	uuid, metric string, cutoff time.Time,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	if uuid == "" || metric == "" {
+		return nil, fmt.Errorf("uuid and metric name are required for delete")
+	}
+
+	hdrs := http.Header{}
+	hdrs.Set(snowthapi.HeaderDeleteTime, formatTimestamp(cutoff))
+	return sc.deleteNodes(ctx, uuid, metric,
+		path.Join(snowthapi.PathNNT, uuid, url.PathEscape(metric)), hdrs,
+		nodes...)
+}
+
+// DeleteNumericRange deletes the numeric NNT and rollup data of a metric
+// within a time range. The raw numeric data is not deleted; use
+// DeleteRawNumericRange to delete it. The delete is sent to the specified
+// nodes, or to an owning node of the metric, or every owning node if the
+// context was created by WithDeleteFanout. The returned report contains the
+// result from each node.
+func (sc *SnowthClient) DeleteNumericRange(uuid, metric string,
+	start, end time.Time, nodes ...*SnowthNode) (*DeleteReport, error) {
+	return sc.DeleteNumericRangeContext(context.Background(), uuid, metric,
+		start, end, nodes...)
+}
+
+// DeleteNumericRangeContext is the context aware version of
+// DeleteNumericRange.
+func (sc *SnowthClient) DeleteNumericRangeContext(ctx context.Context,
+	uuid, metric string, start, end time.Time,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	qp, err := deleteRangeQuery(uuid, metric, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.deleteNodes(ctx, uuid, metric,
+		path.Join(snowthapi.PathNNT, uuid, url.PathEscape(metric))+"?"+
+			qp.Encode(), nil, nodes...)
+}
+
+// TagQueryDeleteResult values contain the result of deleting one metric found
+// by a delete by tag query.
+type TagQueryDeleteResult struct {
+	Item   FindTagsItem
+	Report *DeleteReport
+	Err    error
+}
+
+// TagQueryDeleteReport values contain the results of a delete by tag query.
+// If the tag search results were truncated by the search limit, Truncated is
+// set, and the delete can be repeated to remove the remaining metrics.
+type TagQueryDeleteReport struct {
+	DryRun    bool
+	Truncated bool
+	Results   []TagQueryDeleteResult
+	Deleted   int
+	Failed    int
+}
+
+// Err returns an error combining the errors of all metrics which could not be
+// deleted, or nil if every metric was deleted.
+func (r *TagQueryDeleteReport) Err() error {
+	mErr := newMultiError()
+	for _, dr := range r.Results {
+		if dr.Err != nil {
+			mErr.Add(fmt.Errorf("%s %s: %w", dr.Item.UUID,
+				dr.Item.MetricName, dr.Err))
+		}
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// DeleteByTagQuery deletes all data and metadata of every metric of an
+// account which matches a tag query. Each metric is deleted on every node
+// which owns it. If dryRun is true, the matching metrics are found but not
+// deleted, and the returned report lists the metrics which would be deleted.
+// The tag search is sent to the specified node, if any.
+func (sc *SnowthClient) DeleteByTagQuery(accountID int64, query string,
+	dryRun bool, nodes ...*SnowthNode) (*TagQueryDeleteReport, error) {
+	return sc.DeleteByTagQueryContext(context.Background(), accountID, query,
+		dryRun, nodes...)
+}
+
+// DeleteByTagQueryContext is the context aware version of DeleteByTagQuery.
+func (sc *SnowthClient) DeleteByTagQueryContext(ctx context.Context,
+	accountID int64, query string, dryRun bool,
+	nodes ...*SnowthNode) (*TagQueryDeleteReport, error) {
+	if query == "" {
+		return nil, fmt.Errorf("tag query cannot be empty for delete")
+	}
+
+	fr, err := sc.FindTagsContext(ctx, accountID, query, &FindTagsOptions{},
+		nodes...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find metrics to delete: %w", err)
+	}
+
+	r := &TagQueryDeleteReport{
+		DryRun:    dryRun,
+		Truncated: fr.Truncated,
+		Results:   make([]TagQueryDeleteResult, len(fr.Items)),
+	}
+
+	for i, item := range fr.Items {
+		r.Results[i].Item = item
+	}
+
+	if dryRun {
+		return r, nil
+	}
+
+	ctx = WithDeleteFanout(ctx)
+	g := sc.NewWorkGroup(ctx, 0)
+	for i := range r.Results {
+		dr := &r.Results[i]
+		if err := g.Go(func(ctx context.Context) error {
+			dr.Report, dr.Err = sc.DeleteMetricFullContext(ctx, accountID,
+				dr.Item.UUID, dr.Item.MetricName)
+			return nil
+		}); err != nil {
+			dr.Err = err
+		}
+	}
+
+	// Errors are recorded in each result, and returned by the report.
+	_ = g.Wait()
+	for _, dr := range r.Results {
+		if dr.Err != nil {
+			r.Failed++
+			continue
+		}
+
+		r.Deleted++
+	}
+
+	return r, r.Err()
+}
@@ -0,0 +1,452 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDeleteRawNumericRange(t *testing.T) {
+	metric := "test|ST[dir:/var/log]"
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.Method == "DELETE" && strings.HasPrefix(r.RequestURI, "/raw") {
+			exp := "/raw/11223344-5566-7788-9900-aabbccddeeff/" +
+				url.PathEscape(metric)
+			if r.URL.EscapedPath() != exp {
+				t.Errorf("Expected path: %v, got: %v", exp,
+					r.URL.EscapedPath())
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	if err := sc.DeleteRawNumericRange("11223344-5566-7788-9900-aabbccddeeff",
+		metric, time.Unix(0, 0), time.Unix(3600, 0), node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.DeleteRawNumericRange("11223344-5566-7788-9900-aabbccddeeff",
+		metric, time.Unix(3600, 0), time.Unix(0, 0), node); err == nil {
+		t.Error("Expected invalid range error")
+	}
+
+	if err := sc.DeleteRawNumericRange("11223344-5566-7788-9900-aabbccddeeff",
+		"", time.Unix(0, 0), time.Unix(3600, 0), node); err == nil {
+		t.Error("Expected metric name required error")
+	}
+}
+
+func TestDeleteRawNumericPartitioned(t *testing.T) {
+	mu := sync.Mutex{}
+	deleted := map[string]bool{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			start := r.URL.Query().Get("start_ts")
+			mu.Lock()
+			defer mu.Unlock()
+			if r.Method == "DELETE" {
+				if start == "7200" {
+					w.WriteHeader(500)
+					_, _ = w.Write([]byte("delete failed"))
+					return
+				}
+
+				deleted[start] = true
+				return
+			}
+
+			if deleted[start] {
+				_, _ = w.Write([]byte(`[]`))
+				return
+			}
+
+			_, _ = w.Write([]byte(
+				`[[1529509063064,0],[1529509122985,0],[1529509183764,0]]`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.DeleteRawNumericPartitioned(
+		"11223344-5566-7788-9900-aabbccddeeff", "test",
+		time.Unix(0, 0), time.Unix(3*3600+1800, 0),
+		&PartitionedDeleteOptions{
+			SliceDuration: time.Hour,
+			Concurrency:   2,
+			Interval:      time.Millisecond,
+			Verify:        true,
+		}, node)
+	if err == nil || !strings.Contains(err.Error(), "delete failed") {
+		t.Errorf("Expected slice error, got: %v", err)
+	}
+
+	if len(res.Slices) != 4 {
+		t.Fatalf("Expected slices: 4, got: %v", len(res.Slices))
+	}
+
+	if !res.Slices[3].End.Equal(time.Unix(3*3600+1800, 0)) {
+		t.Errorf("Expected last slice end: %v, got: %v",
+			time.Unix(3*3600+1800, 0), res.Slices[3].End)
+	}
+
+	if res.Failed != 1 {
+		t.Errorf("Expected failed slices: 1, got: %v", res.Failed)
+	}
+
+	if res.Removed != 9 {
+		t.Errorf("Expected removed points: 9, got: %v", res.Removed)
+	}
+
+	_, err = sc.DeleteRawNumericPartitioned(
+		"11223344-5566-7788-9900-aabbccddeeff", "test",
+		time.Unix(10, 0), time.Unix(0, 0), nil, node)
+	if err == nil {
+		t.Error("Expected invalid range error")
+	}
+}
+
+func TestDeleteMetricFull(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml") {
+			_, _ = w.Write([]byte(topologyXMLTestData))
+			return
+		}
+
+		if r.Method == "DELETE" && r.RequestURI == "/full/canonical/"+
+			"1f846f26-0cfd-4df5-b4f1-e0930604e577/test%7CST%5Ba:b%5D"+
+			"?account_id=1" {
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.DeleteMetricFull(1, "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		"test|ST[a:b]", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ack := res.Acknowledged(); len(ack) != 1 || ack[0] != node {
+		t.Errorf("Expected acknowledged: [%v], got: %v", node, ack)
+	}
+
+	if _, err := sc.DeleteMetricFull(1, "", "test", node); err == nil {
+		t.Error("Expected error for missing uuid")
+	}
+
+	var mu sync.Mutex
+	deletes := map[string]int{}
+	owners := []string{
+		"9d1a34cd-b150-4c19-a894-e20280b42b62",
+		"3d8ae36d-3d4d-4eda-ab53-c58538985062",
+		"1533fc6b-de08-6eac-eb46-d3920a1a18a3",
+	}
+
+	for i, id := range owners {
+		id, fail := id, i == 2
+		ns := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+			r *http.Request) {
+			if r.Method != "DELETE" ||
+				!strings.HasPrefix(r.RequestURI, "/full/canonical/") {
+				t.Errorf("Unexpected request: %v", r)
+				w.WriteHeader(500)
+				return
+			}
+
+			if fail {
+				w.WriteHeader(500)
+				return
+			}
+
+			mu.Lock()
+			deletes[id]++
+			mu.Unlock()
+		}))
+
+		defer ns.Close()
+		u, err := url.Parse(ns.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		node := &SnowthNode{url: u, identifier: id}
+		sc.AddNodes(node)
+		sc.ActivateNodes(node)
+	}
+
+	ctx := WithDeleteFanout(context.Background())
+	res, err = sc.DeleteMetricFullContext(ctx, 1,
+		"1f846f26-0cfd-4df5-b4f1-e0930604e577", "test")
+	if err == nil {
+		t.Error("Expected error for failed owner node")
+	}
+
+	if len(res.Results) != 3 || len(res.Acknowledged()) != 2 {
+		t.Errorf("Expected results: 3, acknowledged: 2, got: %v, %v",
+			len(res.Results), len(res.Acknowledged()))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if deletes[owners[0]] != 1 || deletes[owners[1]] != 1 {
+		t.Errorf("Expected deletes from owners, got: %v", deletes)
+	}
+}
+
+func TestDeleteNumeric(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		u := "/nnt/1f846f26-0cfd-4df5-b4f1-e0930604e577/test"
+		if r.Method == "DELETE" && r.RequestURI == u {
+			if h := r.Header.Get("X-Snowth-Delete-Time"); h != "1529509020" {
+				t.Errorf("Expected delete time: 1529509020, got: %v", h)
+			}
+
+			return
+		}
+
+		if r.Method == "DELETE" && r.RequestURI == u+
+			"?end_ts=1529509200&start_ts=1529509020" {
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.DeleteNumericBefore("1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		"test", time.Unix(1529509020, 0), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Acknowledged()) != 1 {
+		t.Errorf("Expected acknowledged: 1, got: %v", res.Acknowledged())
+	}
+
+	res, err = sc.DeleteNumericRange("1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		"test", time.Unix(1529509020, 0), time.Unix(1529509200, 0), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Acknowledged()) != 1 {
+		t.Errorf("Expected acknowledged: 1, got: %v", res.Acknowledged())
+	}
+
+	if _, err := sc.DeleteNumericRange("1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		"test", time.Unix(1529509200, 0), time.Unix(1529509020, 0),
+		node); err == nil {
+		t.Error("Expected error for invalid range")
+	}
+}
+
+func TestDeleteByTagQuery(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	deleted := []string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml") {
+			_, _ = w.Write([]byte(topologyXMLTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=") {
+			if q := r.URL.Query().Get("query"); q != "and(stale:true)" {
+				t.Errorf("Expected query: and(stale:true), got: %v", q)
+			}
+
+			_, _ = w.Write([]byte(`[
+				{"uuid":"11223344-5566-7788-9900-aabbccddeeff",
+				 "metric_name":"cpu|ST[stale:true]","account_id":1},
+				{"uuid":"11223344-5566-7788-9900-aabbccddeeff",
+				 "metric_name":"mem|ST[stale:true]","account_id":1}
+			]`))
+			return
+		}
+
+		if r.Method == "DELETE" &&
+			strings.HasPrefix(r.RequestURI, "/full/canonical/") {
+			if strings.Contains(r.RequestURI, "/mem") {
+				w.WriteHeader(500)
+				return
+			}
+
+			mu.Lock()
+			deleted = append(deleted, r.RequestURI)
+			mu.Unlock()
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.DeleteByTagQuery(1, "and(stale:true)", true, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !res.DryRun || len(res.Results) != 2 || res.Deleted != 0 ||
+		res.Results[1].Item.MetricName != "mem|ST[stale:true]" {
+		t.Errorf("Unexpected dry run report: %+v", res)
+	}
+
+	mu.Lock()
+	if len(deleted) != 0 {
+		t.Errorf("Expected no deletes for dry run, got: %v", deleted)
+	}
+
+	mu.Unlock()
+	res, err = sc.DeleteByTagQuery(1, "and(stale:true)", false, node)
+	if err == nil {
+		t.Error("Expected error for failed delete")
+	}
+
+	if res.Deleted != 1 || res.Failed != 1 || res.Results[1].Err == nil {
+		t.Errorf("Expected deleted: 1, failed: 1, got: %v, %v",
+			res.Deleted, res.Failed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	exp := "/full/canonical/11223344-5566-7788-9900-aabbccddeeff/" +
+		"cpu%7CST%5Bstale:true%5D?account_id=1"
+	if len(deleted) != 1 || deleted[0] != exp {
+		t.Errorf("Expected deleted: [%v], got: %v", exp, deleted)
+	}
+
+	if _, err := sc.DeleteByTagQuery(1, "", false, node); err == nil {
+		t.Error("Expected error for empty query")
+	}
+}
@@ -0,0 +1,195 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// NodeInfo values aggregate descriptive information about an IRONdb node, for
+// use in support bundles and debugging.
+type NodeInfo struct {
+	ID              string        `json:"id"`
+	URL             string        `json:"url"`
+	Active          bool          `json:"active"`
+	Application     string        `json:"application,omitempty"`
+	Version         string        `json:"version,omitempty"`
+	SemVer          string        `json:"semver,omitempty"`
+	Features        []string      `json:"features,omitempty"`
+	CurrentTopology string        `json:"current_topology,omitempty"`
+	NextTopology    string        `json:"next_topology,omitempty"`
+	Time            time.Time     `json:"time,omitempty"`
+	ClockSkew       time.Duration `json:"clock_skew"`
+	Uptime          time.Duration `json:"uptime,omitempty"`
+	BaseRollup      uint64        `json:"base_rollup,omitempty"`
+	Rollups         []uint64      `json:"rollups,omitempty"`
+	NNTCacheSize    uint64        `json:"nnt_cache_size,omitempty"`
+	MaxRSS          uint64        `json:"max_rss,omitempty"`
+	MaxPeerLag      float64       `json:"max_peer_lag"`
+	AvgPeerLag      float64       `json:"avg_peer_lag"`
+	Error           string        `json:"error,omitempty"`
+}
+
+// ClusterInfo values aggregate descriptive information about all of the nodes
+// known to a client.
+type ClusterInfo struct {
+	Nodes []NodeInfo `json:"nodes"`
+
+	// Topologies contains the number of nodes using each current topology
+	// hash. More than one entry indicates the nodes disagree.
+	Topologies map[string]int `json:"topologies"`
+
+	// Versions contains the number of nodes running each IRONdb version.
+	Versions map[string]int `json:"versions"`
+
+	// Errors is the number of nodes which could not be described.
+	Errors int `json:"errors"`
+}
+
+// DescribeNode retrieves descriptive information about an IRONdb node,
+// including its version, features, topology, current time, uptime, and key
+// statistics. The information is retrieved only from the specified node, or
+// from an active node if none is specified, without retrying on other nodes.
+func (sc *SnowthClient) DescribeNode(nodes ...*SnowthNode) (*NodeInfo, error) {
+	return sc.DescribeNodeContext(context.Background(), nodes...)
+}
+
+// DescribeNodeContext is the context aware version of DescribeNode.
+func (sc *SnowthClient) DescribeNodeContext(ctx context.Context,
+	nodes ...*SnowthNode) (*NodeInfo, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	if node == nil || node.GetURL() == nil {
+		return nil, fmt.Errorf("unable to get active node")
+	}
+
+	ni := &NodeInfo{
+		ID:     node.identifier,
+		URL:    node.GetURL().String(),
+		SemVer: node.SemVer(),
+	}
+
+	for _, n := range sc.ListActiveNodes() {
+		if n.GetURL().String() == ni.URL {
+			ni.Active = true
+			break
+		}
+	}
+
+	clock := sc.getClock()
+	body, hdr, err := sc.do(ctx, node, "GET", snowthapi.PathState, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read IRONdb response: %w", err)
+	}
+
+	state := &NodeState{}
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	raw := struct {
+		Features map[string]interface{} `json:"features"`
+	}{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	for k, v := range raw.Features {
+		if fmt.Sprint(v) == "1" || v == true {
+			ni.Features = append(ni.Features, k)
+		}
+	}
+
+	sort.Strings(ni.Features)
+	if state.Identity != "" {
+		ni.ID = state.Identity
+	}
+
+	ni.Application = state.Application
+	ni.Version = state.Version
+	ni.CurrentTopology = state.Current
+	ni.NextTopology = state.Next
+	ni.BaseRollup = state.BaseRollup
+	ni.Rollups = state.Rollups
+	ni.NNTCacheSize = state.NNTCacheSize
+	ni.MaxRSS = state.RUsageMaxRSS
+	ni.MaxPeerLag = state.MaxPeerLag
+	ni.AvgPeerLag = state.AvgPeerLag
+	if d, err := http.ParseTime(hdr.Get("Date")); err == nil {
+		ni.Time = d
+		ni.ClockSkew = d.Sub(clock.Now()).Truncate(time.Second)
+	}
+
+	body, _, err = sc.do(ctx, node, "GET", snowthapi.PathStats, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &Stats{}
+	if err := sc.decodeResponse(body, &stats); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	if v := stats.SemVer(); v != "" {
+		ni.SemVer = v
+	}
+
+	ni.Uptime = stats.Uptime()
+	return ni, nil
+}
+
+// DescribeCluster retrieves descriptive information about every node known to
+// the client, active or inactive. Nodes which cannot be described are included
+// in the result with their Error field set.
+func (sc *SnowthClient) DescribeCluster() *ClusterInfo {
+	return sc.DescribeClusterContext(context.Background())
+}
+
+// DescribeClusterContext is the context aware version of DescribeCluster.
+func (sc *SnowthClient) DescribeClusterContext(
+	ctx context.Context) *ClusterInfo {
+	ci := &ClusterInfo{
+		Nodes:      []NodeInfo{},
+		Topologies: map[string]int{},
+		Versions:   map[string]int{},
+	}
+
+	for _, node := range append(sc.ListActiveNodes(),
+		sc.ListInactiveNodes()...) {
+		ni, err := sc.DescribeNodeContext(ctx, node)
+		if err != nil {
+			ni = &NodeInfo{
+				ID:     node.identifier,
+				URL:    node.GetURL().String(),
+				SemVer: node.SemVer(),
+				Error:  err.Error(),
+			}
+
+			ci.Errors++
+		} else {
+			ci.Topologies[ni.CurrentTopology]++
+			ci.Versions[ni.SemVer]++
+		}
+
+		ci.Nodes = append(ci.Nodes, *ni)
+	}
+
+	return ci
+}
@@ -0,0 +1,131 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestDescribeNode(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.DescribeNode(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.ID != "bb6f7162-4828-11df-bab8-6bac200dcc2a" {
+		t.Errorf("Expected ID: bb6f7162-4828-11df-bab8-6bac200dcc2a, got: %v",
+			res.ID)
+	}
+
+	if !res.Active {
+		t.Errorf("Expected active: true, got: %v", res.Active)
+	}
+
+	if res.SemVer != "0.1.1570000000" {
+		t.Errorf("Expected semver: 0.1.1570000000, got: %v", res.SemVer)
+	}
+
+	if res.Application != "snowth" {
+		t.Errorf("Expected application: snowth, got: %v", res.Application)
+	}
+
+	if len(res.Features) != 7 || res.Features[0] != "features" {
+		t.Errorf("Expected sorted features: 7, got: %v", res.Features)
+	}
+
+	exp := "294cbd39999c2270964029691e8bc5e231a867d525ccba62181dc8988ff218dc"
+	if res.CurrentTopology != exp {
+		t.Errorf("Expected current topology: %v, got: %v", exp,
+			res.CurrentTopology)
+	}
+
+	if res.Time.IsZero() {
+		t.Errorf("Expected node time, got: %v", res.Time)
+	}
+
+	if res.Error != "" {
+		t.Errorf("Expected no error, got: %v", res.Error)
+	}
+}
+
+func TestDescribeCluster(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	u, err := url.Parse("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	sc.AddNodes(&SnowthNode{url: u, identifier: "down"})
+	res := sc.DescribeCluster()
+	if len(res.Nodes) != 2 {
+		t.Fatalf("Expected nodes: 2, got: %v", len(res.Nodes))
+	}
+
+	if res.Errors != 1 {
+		t.Errorf("Expected errors: 1, got: %v", res.Errors)
+	}
+
+	if res.Nodes[1].ID != "down" || res.Nodes[1].Error == "" {
+		t.Errorf("Expected failed node: down, got: %+v", res.Nodes[1])
+	}
+
+	exp := "294cbd39999c2270964029691e8bc5e231a867d525ccba62181dc8988ff218dc"
+	if res.Topologies[exp] != 1 {
+		t.Errorf("Expected topology count: 1, got: %v", res.Topologies)
+	}
+}
@@ -4,8 +4,10 @@ package gosnowth
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"math"
 	"strconv"
+	"time"
 )
 
 // DF4Response values represent time series data in the DF4 format.
@@ -58,6 +60,140 @@ func (dr *DF4Response) Copy() *DF4Response {
 	return b
 }
 
+// DF4Series values contain the data of one stream of a DF4 time series data
+// response, with values converted to Go types. Only the values slice matching
+// the type of the stream data is populated, and missing values are nil.
+type DF4Series struct {
+	Name       string
+	Kind       string
+	Tags       []string
+	Values     []*float64
+	Histograms []map[string]int64
+	Text       []*string
+}
+
+// DF4Result values contain the typed data of a DF4 time series data response,
+// with one series for each stream.
+type DF4Result struct {
+	Start      time.Time
+	Period     time.Duration
+	Timestamps []time.Time
+	Series     []DF4Series
+}
+
+// Result returns the data of a DF4 response as a typed result value.
+func (dr *DF4Response) Result() (*DF4Result, error) {
+	series, err := dr.Series()
+	if err != nil {
+		return nil, err
+	}
+
+	return &DF4Result{
+		Start:      time.Unix(dr.Head.Start, 0),
+		Period:     time.Duration(dr.Head.Period) * time.Second,
+		Timestamps: dr.Timestamps(),
+		Series:     series,
+	}, nil
+}
+
+// Timestamps returns the start times of the data periods of a DF4 response.
+func (dr *DF4Response) Timestamps() []time.Time {
+	ts := make([]time.Time, 0, dr.Head.Count)
+	for i := int64(0); i < dr.Head.Count; i++ {
+		ts = append(ts, time.Unix(dr.Head.Start+i*dr.Head.Period, 0))
+	}
+
+	return ts
+}
+
+// Series returns the data of a DF4 response as typed series values, one per
+// stream. The name, kind, and tags of each series are taken from the response
+// metadata, when present.
+func (dr *DF4Response) Series() ([]DF4Series, error) {
+	r := make([]DF4Series, len(dr.Data))
+	for i, data := range dr.Data {
+		s := &r[i]
+		if i < len(dr.Meta) {
+			s.Name = dr.Meta[i].Label
+			s.Kind = dr.Meta[i].Kind
+			s.Tags = dr.Meta[i].Tags
+		}
+
+		if err := s.setValues(data); err != nil {
+			return nil, fmt.Errorf("invalid data in series %d: %w", i, err)
+		}
+	}
+
+	return r, nil
+}
+
+// setValues converts and stores the data values of a DF4 stream.
+func (s *DF4Series) setValues(data []interface{}) error {
+	kind := s.Kind
+	for _, v := range data {
+		if kind != "" {
+			break
+		}
+
+		switch v.(type) {
+		case float64:
+			kind = "numeric"
+		case map[string]interface{}:
+			kind = "histogram"
+		case string:
+			kind = "text"
+		}
+	}
+
+	switch kind {
+	case "histogram":
+		s.Histograms = make([]map[string]int64, len(data))
+	case "text":
+		s.Text = make([]*string, len(data))
+	default:
+		s.Values = make([]*float64, len(data))
+	}
+
+	for i, v := range data {
+		switch tv := v.(type) {
+		case nil:
+		case float64:
+			if s.Values == nil {
+				return fmt.Errorf("unexpected numeric value at %d", i)
+			}
+
+			s.Values[i] = &tv
+		case string:
+			if s.Text == nil {
+				return fmt.Errorf("unexpected text value at %d", i)
+			}
+
+			s.Text[i] = &tv
+		case map[string]interface{}:
+			if s.Histograms == nil {
+				return fmt.Errorf("unexpected histogram value at %d", i)
+			}
+
+			h := make(map[string]int64, len(tv))
+			for k, c := range tv {
+				n, ok := c.(float64)
+				if !ok {
+					return fmt.Errorf("invalid histogram bin count at %d: %v",
+						i, c)
+				}
+
+				h[k] = int64(n)
+			}
+
+			s.Histograms[i] = h
+		default:
+			return fmt.Errorf("unexpected value at %d: %v", i, v)
+		}
+	}
+
+	return nil
+}
+
 // ReplaceInf is used to remove infinity and NaN values from DF4 JSON strings
 // prior to attempting to parse them into DF4Response values.
 func ReplaceInf(b []byte) []byte {
@@ -127,3 +127,62 @@ func TestUnmarshalDF4Timeseries(t *testing.T) {
 		t.Errorf("Expected length explain: 53, got: %v", len(v.Head.Explain))
 	}
 }
+
+func TestDF4ResponseSeries(t *testing.T) {
+	t.Parallel()
+	dr := &DF4Response{}
+	if err := json.Unmarshal([]byte(`{
+		"version": "DF4",
+		"head": {"count": 2, "start": 300, "period": 60},
+		"meta": [
+			{"kind": "numeric", "label": "a"},
+			{"kind": "histogram", "label": "b"},
+			{"kind": "text", "label": "c"}
+		],
+		"data": [
+			[1.5, null],
+			[{"+23e-004": 5}, null],
+			[null, "ok"],
+			[null, 2]
+		]
+	}`), &dr); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := dr.Timestamps()
+	if len(ts) != 2 || ts[0].Unix() != 300 || ts[1].Unix() != 360 {
+		t.Errorf("Unexpected timestamps: %v", ts)
+	}
+
+	s, err := dr.Series()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s) != 4 {
+		t.Fatalf("Expected series: 4, got: %v", len(s))
+	}
+
+	if s[0].Name != "a" || len(s[0].Values) != 2 || *s[0].Values[0] != 1.5 ||
+		s[0].Values[1] != nil {
+		t.Errorf("Unexpected numeric series: %+v", s[0])
+	}
+
+	if s[1].Kind != "histogram" || s[1].Histograms[0]["+23e-004"] != 5 ||
+		s[1].Histograms[1] != nil || s[1].Values != nil {
+		t.Errorf("Unexpected histogram series: %+v", s[1])
+	}
+
+	if s[2].Text[0] != nil || *s[2].Text[1] != "ok" {
+		t.Errorf("Unexpected text series: %+v", s[2])
+	}
+
+	if s[3].Name != "" || *s[3].Values[1] != 2 {
+		t.Errorf("Unexpected series without metadata: %+v", s[3])
+	}
+
+	dr.Data[0][1] = "bad"
+	if _, err := dr.Series(); err == nil {
+		t.Error("Expected error for invalid value")
+	}
+}
@@ -0,0 +1,346 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// df4ChunkSize is the maximum number of data values of a stream delivered in
+// each chunk by DecodeDF4Stream.
+const df4ChunkSize = 1024
+
+// DF4Chunk values contain a contiguous part of the data of one stream of a DF4
+// time series data response.
+type DF4Chunk struct {
+	// Stream is the index of the stream in the response.
+	Stream int
+
+	// Offset is the index of the data period of the first value in the chunk.
+	Offset int
+
+	// Series contains the name, kind, and tags of the stream, and the values
+	// in the chunk.
+	Series *DF4Series
+}
+
+// infReader values wrap a reader of DF4 JSON data, replacing infinity and NaN
+// values outside of strings as they are read, in the same way as ReplaceInf.
+type infReader struct {
+	r       *bufio.Reader
+	pending []byte
+	inStr   bool
+	esc     bool
+}
+
+// newInfReader returns a reader which replaces infinity and NaN values in the
+// DF4 JSON data read from r.
+func newInfReader(r io.Reader) *infReader {
+	return &infReader{r: bufio.NewReader(r)}
+}
+
+// Read reads filtered DF4 JSON data.
+func (ir *infReader) Read(p []byte) (int, error) {
+	for len(ir.pending) == 0 {
+		b, err := ir.r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+
+		ir.pending = append(ir.pending[:0], b)
+		switch {
+		case ir.inStr:
+			if ir.esc {
+				ir.esc = false
+			} else if b == '\\' {
+				ir.esc = true
+			} else if b == '"' {
+				ir.inStr = false
+			}
+		case b == '"':
+			ir.inStr = true
+		case b == '+' || b == '-':
+			if ir.match("inf", 0) {
+				ir.replace(3, b == '-')
+			}
+		case b == 'i' || b == 'n' || b == 'N':
+			if ir.match("inf", b) || ir.match("nan", b) {
+				ir.replace(2, false)
+			}
+		}
+	}
+
+	n := copy(p, ir.pending)
+	ir.pending = ir.pending[n:]
+	return n, nil
+}
+
+// match returns whether the next bytes to be read, preceded by first if it is
+// not zero, case insensitively match a word.
+func (ir *infReader) match(word string, first byte) bool {
+	w := []byte(word)
+	if first != 0 {
+		if bytes.ToLower([]byte{first})[0] != w[0] {
+			return false
+		}
+
+		w = w[1:]
+	}
+
+	b, _ := ir.r.Peek(len(w))
+	return bytes.Equal(bytes.ToLower(b), w)
+}
+
+// replace discards the next n bytes to be read, and replaces the pending byte
+// with the maximum float value.
+func (ir *infReader) replace(n int, neg bool) {
+	_, _ = ir.r.Discard(n)
+	v := math.MaxFloat64
+	if neg {
+		v = -v
+	}
+
+	ir.pending = strconv.AppendFloat(ir.pending[:0], v, 'g', -1, 64)
+}
+
+// DecodeDF4Stream decodes DF4 time series data from a reader incrementally,
+// calling fn with chunks of the typed data of each stream as they are decoded,
+// so only one chunk of at most 1024 values is held in memory at a time. DF4
+// data is ordered by stream, so all of the chunks of a stream are delivered,
+// in period order, before those of the next stream. The head and metadata of
+// the response, which IRONdb sends before the data, are returned without the
+// data. Decoding stops if fn returns an error, and the error is returned.
+func DecodeDF4Stream(r io.Reader,
+	fn func(head *DF4Head, c *DF4Chunk) error) (*DF4Response, error) {
+	dec := json.NewDecoder(newInfReader(r))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	df := &DF4Response{}
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		switch t {
+		case "version":
+			err = dec.Decode(&df.Ver)
+		case "head":
+			err = dec.Decode(&df.Head)
+		case "meta":
+			err = dec.Decode(&df.Meta)
+		case "data":
+			err = decodeDF4StreamData(dec, df, fn)
+		default:
+			err = dec.Decode(&json.RawMessage{})
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+
+	return df, nil
+}
+
+// decodeDF4StreamData decodes the data array of a DF4 response, calling fn for
+// each chunk of each stream.
+func decodeDF4StreamData(dec *json.Decoder, df *DF4Response,
+	fn func(head *DF4Head, c *DF4Chunk) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	for i := 0; dec.More(); i++ {
+		if err := decodeDF4StreamSeries(dec, df, i, fn); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// decodeDF4StreamSeries decodes the data of one stream of a DF4 response,
+// value by value, calling fn for each chunk of values.
+func decodeDF4StreamSeries(dec *json.Decoder, df *DF4Response, i int,
+	fn func(head *DF4Head, c *DF4Chunk) error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+
+	base := DF4Series{}
+	if i < len(df.Meta) {
+		base.Name = df.Meta[i].Label
+		base.Kind = df.Meta[i].Kind
+		base.Tags = df.Meta[i].Tags
+	}
+
+	data := make([]interface{}, 0, df4ChunkSize)
+	offset := 0
+	flush := func() error {
+		s := base
+		if err := s.setValues(data); err != nil {
+			return fmt.Errorf("invalid data in series %d at %d: %w", i,
+				offset, err)
+		}
+
+		// Streams without metadata keep the kind of their first non-null
+		// value for all later chunks.
+		if base.Kind == "" {
+			switch {
+			case hasValue(s.Histograms != nil, data):
+				base.Kind = "histogram"
+			case hasValue(s.Text != nil, data):
+				base.Kind = "text"
+			case hasValue(s.Values != nil, data):
+				base.Kind = "numeric"
+			}
+		}
+
+		if err := fn(&df.Head, &DF4Chunk{
+			Stream: i,
+			Offset: offset,
+			Series: &s,
+		}); err != nil {
+			return err
+		}
+
+		offset += len(data)
+		data = make([]interface{}, 0, df4ChunkSize)
+		return nil
+	}
+
+	for dec.More() {
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+
+		data = append(data, v)
+		if len(data) == df4ChunkSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(data) > 0 || offset == 0 {
+		if err := flush(); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+// hasValue returns whether a chunk of DF4 data, of a kind in use, contains
+// any non-null value.
+func hasValue(use bool, data []interface{}) bool {
+	if !use {
+		return false
+	}
+
+	for _, v := range data {
+		if v != nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// expectDelim reads the next JSON token and returns an error if it is not the
+// specified delimiter.
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	if t != d {
+		return fmt.Errorf("expected %v, got: %v", d, t)
+	}
+
+	return nil
+}
+
+// FetchValuesStream retrieves data values using the IRONdb fetch API, decoding
+// the response as it is received and calling fn with chunks of the typed data
+// of each stream, as described by DecodeDF4Stream. This keeps memory use
+// bounded by the chunk size, rather than the size of the response or of any
+// one stream, for very large fetch requests. The head and metadata of the
+// response are returned.
+func (sc *SnowthClient) FetchValuesStream(q *FetchQuery,
+	fn func(head *DF4Head, c *DF4Chunk) error,
+	nodes ...*SnowthNode) (*DF4Response, error) {
+	return sc.FetchValuesStreamContext(context.Background(), q, fn, nodes...)
+}
+
+// FetchValuesStreamContext is the context aware version of
+// FetchValuesStream.
+func (sc *SnowthClient) FetchValuesStreamContext(ctx context.Context,
+	q *FetchQuery, fn func(head *DF4Head, c *DF4Chunk) error,
+	nodes ...*SnowthNode) (*DF4Response, error) {
+	if q == nil {
+		return nil, fmt.Errorf("fetch query required")
+	}
+
+	if fn == nil {
+		return nil, fmt.Errorf("stream function required")
+	}
+
+	var node *SnowthNode
+	switch {
+	case len(nodes) > 0 && nodes[0] != nil:
+		node = nodes[0]
+	case len(q.Streams) > 0:
+		node = sc.readNode(ctx, q.Streams[0].UUID, q.Streams[0].Name)
+	default:
+		node = sc.GetActiveNode()
+	}
+
+	buf := &bytes.Buffer{}
+	if err := json.NewEncoder(buf).Encode(&q); err != nil {
+		return nil, err
+	}
+
+	hdrs := http.Header{
+		snowthapi.HeaderContentType: {snowthapi.ContentTypeJSON},
+	}
+	body, _, err := sc.DoRequestStreamContext(ctx, node, "POST",
+		snowthapi.PathFetch, buf, hdrs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+	var fnErr error
+	r, err := DecodeDF4Stream(body, func(head *DF4Head, c *DF4Chunk) error {
+		fnErr = fn(head, c)
+		return fnErr
+	})
+	if fnErr != nil {
+		return nil, fnErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	return r, nil
+}
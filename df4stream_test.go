@@ -0,0 +1,198 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInfReader(t *testing.T) {
+	t.Parallel()
+	in := `{"a":"inf NaN","b":[inf,-inf,+inf,NaN,null,-1,nan]}`
+	b, err := ioutil.ReadAll(newInfReader(strings.NewReader(in)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	max := "1.7976931348623157e+308"
+	exp := `{"a":"inf NaN","b":[` + max + `,-` + max + `,` + max + `,` +
+		max + `,null,-1,` + max + `]}`
+	if string(b) != exp {
+		t.Errorf("Expected data: %v, got: %s", exp, b)
+	}
+}
+
+func TestDecodeDF4Stream(t *testing.T) {
+	t.Parallel()
+	series := []*DF4Series{}
+	r, err := DecodeDF4Stream(strings.NewReader(testFetchDF4Response),
+		func(head *DF4Head, c *DF4Chunk) error {
+			if head.Count != 3 || head.Period != 300 {
+				t.Errorf("Unexpected head: %+v", head)
+			}
+
+			if c.Stream != 0 || c.Offset != 0 {
+				t.Errorf("Unexpected chunk: %+v", c)
+			}
+
+			series = append(series, c.Series)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Ver != "DF4" || len(r.Meta) != 1 || r.Data != nil {
+		t.Errorf("Unexpected response: %+v", r)
+	}
+
+	if len(series) != 1 || series[0].Name != "test" {
+		t.Fatalf("Unexpected series: %+v", series)
+	}
+
+	if v := series[0].Values; len(v) != 5 || *v[2] != 3 ||
+		*v[4] != -math.MaxFloat64 {
+		t.Errorf("Unexpected series values: %v", v)
+	}
+
+	for _, d := range []string{`[]`, `{"data":{}}`, `{"data":[[true]]}`,
+		`{"data":[[1]`} {
+		if _, err := DecodeDF4Stream(strings.NewReader(d),
+			func(*DF4Head, *DF4Chunk) error { return nil }); err == nil {
+			t.Errorf("Expected error for data: %v", d)
+		}
+	}
+}
+
+func TestDecodeDF4StreamChunks(t *testing.T) {
+	t.Parallel()
+	n := df4ChunkSize*2 + 10
+	vals := make([]string, n)
+	for i := range vals {
+		vals[i] = "null"
+		if i >= df4ChunkSize+5 {
+			vals[i] = `"` + strconv.Itoa(i) + `"`
+		}
+	}
+
+	d := `{"data":[[` + strings.Join(vals, ",") + `],[1]]}`
+	chunks := []DF4Chunk{}
+	if _, err := DecodeDF4Stream(strings.NewReader(d),
+		func(head *DF4Head, c *DF4Chunk) error {
+			chunks = append(chunks, *c)
+			return nil
+		}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(chunks) != 4 {
+		t.Fatalf("Expected chunks: 4, got: %v", len(chunks))
+	}
+
+	for i, exp := range []struct {
+		stream, offset, size int
+		kind                 string
+	}{
+		{0, 0, df4ChunkSize, ""},
+		{0, df4ChunkSize, df4ChunkSize, ""},
+		{0, df4ChunkSize * 2, 10, "text"},
+		{1, 0, 1, ""},
+	} {
+		c := chunks[i]
+		size := len(c.Series.Values) + len(c.Series.Text)
+		if c.Stream != exp.stream || c.Offset != exp.offset ||
+			size != exp.size || c.Series.Kind != exp.kind {
+			t.Errorf("Expected chunk: %+v, got: %v %v %v %v", exp, c.Stream,
+				c.Offset, size, c.Series.Kind)
+		}
+	}
+
+	if v := chunks[1].Series.Text; len(v) != df4ChunkSize ||
+		v[4] != nil || *v[5] != strconv.Itoa(df4ChunkSize+5) {
+		t.Errorf("Unexpected text values: %v", v)
+	}
+
+	if v := chunks[3].Series.Values; len(v) != 1 || *v[0] != 1 {
+		t.Errorf("Unexpected numeric values: %v", v)
+	}
+}
+
+func TestFetchValuesStream(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.Method == "POST" && r.RequestURI == "/fetch" {
+			_, _ = w.Write([]byte(testFetchDF4Response))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	q := &FetchQuery{
+		Start:  time.Unix(0, 0),
+		Period: 300 * time.Second,
+		Count:  3,
+		Streams: []FetchStream{{
+			UUID:      "11223344-5566-7788-9900-aabbccddeeff",
+			Name:      "test",
+			Kind:      FetchKindNumeric,
+			Transform: FetchTransformAverage,
+		}},
+		Reduce: []FetchReduce{{Label: "test", Method: FetchReducePass}},
+	}
+
+	n := 0
+	res, err := sc.FetchValuesStream(q, func(head *DF4Head,
+		c *DF4Chunk) error {
+		n++
+		return nil
+	}, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 1 || res.Head.Count != 3 {
+		t.Errorf("Expected streams: 1, count: 3, got: %v, %v", n,
+			res.Head.Count)
+	}
+
+	stop := errors.New("stop")
+	if _, err := sc.FetchValuesStream(q, func(head *DF4Head,
+		c *DF4Chunk) error {
+		return stop
+	}, node); err != stop {
+		t.Errorf("Expected error: %v, got: %v", stop, err)
+	}
+}
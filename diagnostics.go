@@ -0,0 +1,248 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// NodeLogLine values represent a single line of an IRONdb node internal log.
+type NodeLogLine struct {
+	Index int64
+	Time  time.Time
+	Line  string
+}
+
+// UnmarshalJSON decodes a JSON format byte slice into a NodeLogLine value.
+func (nl *NodeLogLine) UnmarshalJSON(b []byte) error {
+	v := struct {
+		Index  int64   `json:"idx"`
+		Whence float64 `json:"whence"`
+		Line   string  `json:"line"`
+	}{}
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return fmt.Errorf("failed to decode log line: %w", err)
+	}
+
+	nl.Index = v.Index
+	nl.Time = time.Unix(0, int64(v.Whence*float64(time.Millisecond)))
+	nl.Line = v.Line
+	return nil
+}
+
+// NodeLogOptions values contain optional parameters used when retrieving the
+// internal log of an IRONdb node.
+type NodeLogOptions struct {
+	// Since retrieves only the log lines with an index greater than this
+	// value. This is used to tail a log.
+	Since int64
+
+	// Last limits the result to the most recent number of lines.
+	Last int64
+
+	// Level retrieves only the log lines logged to a facility beginning with
+	// this value, such as "error" or "debug". Filtering is performed by the
+	// client.
+	Level string
+}
+
+// GetNodeLog retrieves the recent lines of a named in-memory log, such as
+// "internal", from an IRONdb node. The log is retrieved only from the
+// specified node, or from an active node if none is specified, without
+// retrying on other nodes.
+func (sc *SnowthClient) GetNodeLog(name string, options *NodeLogOptions,
+	nodes ...*SnowthNode) ([]NodeLogLine, error) {
+	return sc.GetNodeLogContext(context.Background(), name, options, nodes...)
+}
+
+// GetNodeLogContext is the context aware version of GetNodeLog.
+func (sc *SnowthClient) GetNodeLogContext(ctx context.Context, name string,
+	options *NodeLogOptions, nodes ...*SnowthNode) ([]NodeLogLine, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	if node == nil {
+		return nil, fmt.Errorf("unable to get active node")
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("log name required")
+	}
+
+	opts := NodeLogOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	qp := url.Values{}
+	if opts.Since > 0 {
+		qp.Add("since", strconv.FormatInt(opts.Since, 10))
+	}
+
+	if opts.Last > 0 {
+		qp.Add("last", strconv.FormatInt(opts.Last, 10))
+	}
+
+	u := snowthapi.PathEventerLogs + "/" + url.PathEscape(name) + ".json"
+	if len(qp) > 0 {
+		u += "?" + qp.Encode()
+	}
+
+	body, _, err := sc.do(ctx, node, "GET", u, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := []NodeLogLine{}
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	if opts.Level == "" {
+		return r, nil
+	}
+
+	level := "[" + strings.ToLower(opts.Level)
+	res := []NodeLogLine{}
+	for _, l := range r {
+		if strings.Contains(strings.ToLower(l.Line), level) {
+			res = append(res, l)
+		}
+	}
+
+	return res, nil
+}
+
+// TailNodeLog polls a named in-memory log of an IRONdb node at the specified
+// interval, passing each new log line to the function f, until the context is
+// cancelled. Only lines logged after the first poll are passed to f, unless
+// options.Since is set. Log line indexes are specific to a node, so every poll
+// is sent to the same node.
+func (sc *SnowthClient) TailNodeLog(ctx context.Context, name string,
+	interval time.Duration, options *NodeLogOptions, f func(l NodeLogLine),
+	nodes ...*SnowthNode) error {
+	if interval <= 0 {
+		return fmt.Errorf("invalid tail interval: %v", interval)
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	if node == nil {
+		return fmt.Errorf("no active nodes")
+	}
+
+	opts := NodeLogOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.Since <= 0 {
+		r, err := sc.GetNodeLogContext(ctx, name,
+			&NodeLogOptions{Last: 1}, node)
+		if err != nil {
+			return err
+		}
+
+		for _, l := range r {
+			if l.Index > opts.Since {
+				opts.Since = l.Index
+			}
+		}
+	}
+
+	tick := sc.getClock().NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tick.C():
+		}
+
+		r, err := sc.GetNodeLogContext(ctx, name, &opts, node)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return err
+		}
+
+		for _, l := range r {
+			if l.Index > opts.Since {
+				opts.Since = l.Index
+			}
+
+			f(l)
+		}
+	}
+}
+
+// NodeJobQueue values contain diagnostic information about an IRONdb node
+// job queue.
+type NodeJobQueue struct {
+	Concurrency        int64   `json:"concurrency"`
+	DesiredConcurrency int64   `json:"desired_concurrency"`
+	MinConcurrency     int64   `json:"min_concurrency"`
+	MaxConcurrency     int64   `json:"max_concurrency"`
+	TotalJobs          int64   `json:"total_jobs"`
+	Backlog            int64   `json:"backlog"`
+	Inflight           int64   `json:"inflight"`
+	Timeouts           int64   `json:"timeouts"`
+	AvgWaitMS          float64 `json:"avg_wait_ms"`
+	AvgRunMS           float64 `json:"avg_run_ms"`
+}
+
+// GetNodeJobQueues retrieves diagnostic information about the job queues of
+// an IRONdb node, keyed by job queue name. The information is retrieved only
+// from the specified node, or from an active node if none is specified,
+// without retrying on other nodes.
+func (sc *SnowthClient) GetNodeJobQueues(
+	nodes ...*SnowthNode) (map[string]NodeJobQueue, error) {
+	return sc.GetNodeJobQueuesContext(context.Background(), nodes...)
+}
+
+// GetNodeJobQueuesContext is the context aware version of GetNodeJobQueues.
+func (sc *SnowthClient) GetNodeJobQueuesContext(ctx context.Context,
+	nodes ...*SnowthNode) (map[string]NodeJobQueue, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	if node == nil {
+		return nil, fmt.Errorf("unable to get active node")
+	}
+
+	body, _, err := sc.do(ctx, node, "GET", snowthapi.PathEventerJobQueues,
+		nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := map[string]NodeJobQueue{}
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,194 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const nodeLogTestData = `[
+	{"idx":1,"whence":1580000000000,"line":"[2020-01-26 00:53:20] [error/http] failed\n"},
+	{"idx":2,"whence":1580000001000,"line":"[2020-01-26 00:53:21] [notice] started\n"}
+]`
+
+const jobqTestData = `{
+	"default_queue": {
+		"concurrency": 4,
+		"desired_concurrency": 4,
+		"min_concurrency": 1,
+		"max_concurrency": 8,
+		"total_jobs": 100,
+		"backlog": 2,
+		"inflight": 1,
+		"timeouts": 0,
+		"avg_wait_ms": 0.5,
+		"avg_run_ms": 1.25
+	}
+}`
+
+func TestGetNodeLog(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/eventer/logs/internal.json" {
+			switch r.URL.Query().Get("since") {
+			case "":
+				_, _ = w.Write([]byte(nodeLogTestData))
+			case "2":
+				_, _ = w.Write([]byte(`[{"idx":3,"whence":1580000002000,` +
+					`"line":"[error] tailed\n"}]`))
+			default:
+				_, _ = w.Write([]byte(`[]`))
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.GetNodeLog("internal", nil, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("Expected length: 2, got: %v", len(res))
+	}
+
+	if res[1].Index != 2 {
+		t.Errorf("Expected index: 2, got: %v", res[1].Index)
+	}
+
+	if res[1].Time.Unix() != 1580000001 {
+		t.Errorf("Expected time: 1580000001, got: %v", res[1].Time.Unix())
+	}
+
+	res, err = sc.GetNodeLog("internal", &NodeLogOptions{Level: "error"},
+		node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 || res[0].Index != 1 {
+		t.Errorf("Expected error level line, got: %v", res)
+	}
+
+	if _, err := sc.GetNodeLog("", nil, node); err == nil {
+		t.Error("Expected log name error")
+	}
+
+	mc := NewManualClock(time.Unix(1000, 0))
+	sc.SetClock(mc)
+	ctx, cancel := context.WithCancel(context.Background())
+	lines := make(chan NodeLogLine, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- sc.TailNodeLog(ctx, "internal", time.Second, nil,
+			func(l NodeLogLine) {
+				lines <- l
+			}, node)
+	}()
+
+	for i := 0; i < 100; i++ {
+		mc.Advance(time.Second)
+		select {
+		case l := <-lines:
+			if l.Index != 3 {
+				t.Errorf("Expected index: 3, got: %v", l.Index)
+			}
+
+			cancel()
+			if err := <-done; err != nil {
+				t.Fatal(err)
+			}
+
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	t.Fatal("Expected tailed log line")
+}
+
+func TestTailNodeLogNoActiveNodes(t *testing.T) {
+	sc := &SnowthClient{clock: realClock{}}
+	if err := sc.TailNodeLog(context.Background(), "internal", time.Second,
+		nil, func(l NodeLogLine) {}); err == nil {
+		t.Error("Expected no active nodes error")
+	}
+}
+
+func TestGetNodeJobQueues(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/eventer/jobq.json" {
+			_, _ = w.Write([]byte(jobqTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	res, err := sc.GetNodeJobQueues()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, ok := res["default_queue"]
+	if !ok {
+		t.Fatalf("Expected job queue: default_queue, got: %v", res)
+	}
+
+	if q.Backlog != 2 {
+		t.Errorf("Expected backlog: 2, got: %v", q.Backlog)
+	}
+
+	if q.AvgRunMS != 1.25 {
+		t.Errorf("Expected average run time: 1.25, got: %v", q.AvgRunMS)
+	}
+}
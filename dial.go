@@ -0,0 +1,100 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+)
+
+// DialFunc values are functions used to establish network connections to
+// IRONdb nodes. This matches the signature of net.Dialer.DialContext.
+type DialFunc func(ctx context.Context, network,
+	address string) (net.Conn, error)
+
+// NodeAddressFunc values are functions which return the host and port address
+// used to connect to a node discovered from the cluster topology. The function
+// receives the node ID and the address advertised in the topology, in
+// host:port form, and can return a replacement address.
+type NodeAddressFunc func(id, address string) string
+
+// Resolver gets the DNS resolver used when connecting to IRONdb nodes. A nil
+// value, the default, uses the default resolver.
+func (c *Config) Resolver() *net.Resolver {
+	c.RLock()
+	defer c.RUnlock()
+	return c.resolver
+}
+
+// SetResolver sets the DNS resolver used when connecting to IRONdb nodes. This
+// is not used if a custom dial function is set.
+func (c *Config) SetResolver(r *net.Resolver) {
+	c.Lock()
+	c.resolver = r
+	c.Unlock()
+}
+
+// DialContext gets the custom function used to establish network connections
+// to IRONdb nodes.
+func (c *Config) DialContext() DialFunc {
+	c.RLock()
+	defer c.RUnlock()
+	return c.dialContext
+}
+
+// SetDialContext sets a custom function used to establish network connections
+// to IRONdb nodes, replacing the default dialer. A nil value restores the
+// default dialer.
+func (c *Config) SetDialContext(f DialFunc) {
+	c.Lock()
+	c.dialContext = f
+	c.Unlock()
+}
+
+// NodeAddress gets the function used to override the addresses of nodes
+// discovered from the cluster topology.
+func (c *Config) NodeAddress() NodeAddressFunc {
+	c.RLock()
+	defer c.RUnlock()
+	return c.nodeAddress
+}
+
+// SetNodeAddress sets a function used to override the addresses of nodes
+// discovered from the cluster topology, such as to replace internal IP
+// addresses advertised by the nodes with addresses reachable by the client.
+func (c *Config) SetNodeAddress(f NodeAddressFunc) {
+	c.Lock()
+	c.nodeAddress = f
+	c.Unlock()
+}
+
+// dialer returns the function used by the HTTP transport of the client to
+// establish network connections.
+func (c *Config) dialer() DialFunc {
+	c.RLock()
+	defer c.RUnlock()
+	if c.dialContext != nil {
+		return c.dialContext
+	}
+
+	return (&net.Dialer{
+		Timeout:   c.dialTimeout,
+		KeepAlive: c.keepAlivePeriod,
+		DualStack: true,
+		Resolver:  c.resolver,
+	}).DialContext
+}
+
+// topologyNodeURL returns the URL used to connect to a node in the cluster
+// topology, applying the node address function of the client, if one is set.
+func (sc *SnowthClient) topologyNodeURL(tn TopologyNode) *url.URL {
+	addr := fmt.Sprintf("%s:%d", tn.Address, tn.APIPort)
+	if sc.nodeAddress != nil {
+		if a := sc.nodeAddress(tn.ID, addr); a != "" {
+			addr = a
+		}
+	}
+
+	return &url.URL{Scheme: "http", Host: addr}
+}
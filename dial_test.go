@@ -0,0 +1,121 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConfigDial(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Resolver() != nil || cfg.DialContext() != nil ||
+		cfg.NodeAddress() != nil {
+		t.Error("Expected default resolver, dialer and node address")
+	}
+
+	r := &net.Resolver{PreferGo: true}
+	cfg.SetResolver(r)
+	if cfg.Resolver() != r {
+		t.Errorf("Expected resolver: %v, got: %v", r, cfg.Resolver())
+	}
+
+	cfg.SetDialContext((&net.Dialer{}).DialContext)
+	if cfg.DialContext() == nil {
+		t.Error("Expected dial function")
+	}
+
+	cfg.SetNodeAddress(func(id, address string) string { return address })
+	if cfg.NodeAddress() == nil {
+		t.Error("Expected node address function")
+	}
+}
+
+func TestClientDial(t *testing.T) {
+	t.Parallel()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml/") {
+			_, _ = w.Write([]byte(`<nodes n="1"><node ` +
+				`id="bb6f7162-4828-11df-bab8-6bac200dcc2a" ` +
+				`address="10.255.255.1" port="8112" apiport="8112" ` +
+				`weight="32"/></nodes>`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+	}))
+
+	defer ms.Close()
+	addr := ms.Listener.Addr().String()
+	cfg, err := NewConfig("http://irondb.invalid:8112")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dials int64
+	cfg.SetDialContext(func(ctx context.Context, network,
+		address string) (net.Conn, error) {
+		atomic.AddInt64(&dials, 1)
+		if address != "irondb.invalid:8112" &&
+			address != "irondb.override:8112" {
+			t.Errorf("Unexpected dial address: %v", address)
+		}
+
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	})
+
+	cfg.SetNodeAddress(func(id, address string) string {
+		if address != "10.255.255.1:8112" {
+			t.Errorf("Expected address: 10.255.255.1:8112, got: %v", address)
+		}
+
+		return "irondb.override:8112"
+	})
+
+	cfg.SetDiscover(true)
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sc.StopDiscovery()
+	if atomic.LoadInt64(&dials) == 0 {
+		t.Error("Expected custom dial function to be used")
+	}
+
+	nodes := sc.ListActiveNodes()
+	if len(nodes) != 1 {
+		t.Fatalf("Expected active nodes: 1, got: %v", len(nodes))
+	}
+
+	if nodes[0].GetURL().Host != "irondb.override:8112" {
+		t.Errorf("Expected node host: irondb.override:8112, got: %v",
+			nodes[0].GetURL().Host)
+	}
+
+	if _, err := sc.GetNodeState(nodes[0]); err != nil {
+		t.Fatal(err)
+	}
+}
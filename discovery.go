@@ -0,0 +1,186 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// SetDiscoverInterval sets the interval at which the topology is refreshed
+// when node discovery is enabled. This takes effect the next time the
+// discovery process is started.
+func (sc *SnowthClient) SetDiscoverInterval(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.discoverInterval = d
+}
+
+// RefreshTopology retrieves the current topology from the cluster, adding
+// any nodes which have joined the cluster and removing any nodes which are
+// no longer part of it.
+func (sc *SnowthClient) RefreshTopology() error {
+	return sc.RefreshTopologyContext(context.Background())
+}
+
+// RefreshTopologyContext is the context aware version of RefreshTopology.
+func (sc *SnowthClient) RefreshTopologyContext(ctx context.Context) error {
+	mErr := newMultiError()
+	for _, node := range sc.ListActiveNodes() {
+		// The current topology hash is requested from the node directly,
+		// without retrying on other nodes, so that a changed topology is
+		// detected.
+		body, _, err := sc.do(ctx, node, "GET", snowthapi.PathStats, nil, nil)
+		if err != nil {
+			mErr.Add(fmt.Errorf("unable to get node stats: %w", err))
+			continue
+		}
+
+		stats := &Stats{}
+		if err := decodeJSON(body, &stats); err != nil {
+			mErr.Add(fmt.Errorf("unable to decode IRONdb response: %w", err))
+			continue
+		}
+
+		if ct := stats.CurrentTopology(); ct != "" {
+			root := sc.root()
+			root.Lock()
+			node.currentTopology = ct
+			root.Unlock()
+		}
+
+		topology, err := sc.GetTopologyInfoContext(ctx, node)
+		if err != nil {
+			mErr.Add(fmt.Errorf("error getting topology info: %w", err))
+			continue
+		}
+
+		events := sc.topologyEvents(node.GetCurrentTopology(), topology)
+		for _, topoNode := range topology.Nodes {
+			sc.populateNodeInfo(node.GetCurrentTopology(), topoNode)
+		}
+
+		sc.removeMissingNodes(topology)
+		sc.persistState()
+		sc.notifyTopology(events)
+		return nil
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return fmt.Errorf("no active nodes")
+}
+
+// removeMissingNodes removes any identified nodes which are not present in a
+// topology from the client. The last active node is never removed.
+func (sc *SnowthClient) removeMissingNodes(topology *Topology) {
+	if sc.parent != nil {
+		sc.parent.removeMissingNodes(topology)
+		return
+	}
+
+	ids := make(map[string]bool, len(topology.Nodes))
+	for _, tn := range topology.Nodes {
+		ids[strings.ToLower(tn.ID)] = true
+	}
+
+	keep := func(n *SnowthNode) bool {
+		return n.identifier == "" || ids[strings.ToLower(n.identifier)]
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+	an := []*SnowthNode{}
+	for _, n := range sc.activeNodes {
+		if keep(n) {
+			an = append(an, n)
+		} else {
+			sc.LogInfof("removing node no longer in topology: %s",
+				n.GetURL().Host)
+		}
+	}
+
+	if len(an) == 0 {
+		return
+	}
+
+	sc.activeNodes = an
+	in := []*SnowthNode{}
+	for _, n := range sc.inactiveNodes {
+		if keep(n) {
+			in = append(in, n)
+		} else {
+			sc.LogInfof("removing node no longer in topology: %s",
+				n.GetURL().Host)
+		}
+	}
+
+	sc.inactiveNodes = in
+}
+
+// StartDiscovery starts the background process which periodically refreshes
+// the topology to add and remove nodes. This is started automatically when
+// a client is created with discovery enabled, and can be used to restart
+// the process after StopDiscovery() has been called. A topology refresh in
+// progress is cancelled when the process is stopped.
+func (sc *SnowthClient) StartDiscovery() {
+	sc.Lock()
+	di := sc.discoverInterval
+	if di <= time.Duration(0) || sc.discoverStop != nil || sc.closing ||
+		sc.parent != nil {
+		sc.Unlock()
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	sc.discoverStop, sc.discoverDone = stop, done
+	sc.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	go func() {
+		defer close(done)
+		defer cancel()
+		tick := sc.getClock().NewTicker(di)
+		defer tick.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-tick.C():
+				sc.LogDebugf("refreshing topology")
+				if err := sc.RefreshTopologyContext(ctx); err != nil &&
+					ctx.Err() == nil {
+					sc.LogErrorf("failed to refresh topology: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// StopDiscovery stops the background topology refresh process, if running,
+// and waits for it to exit.
+func (sc *SnowthClient) StopDiscovery() {
+	sc.Lock()
+	stop, done := sc.discoverStop, sc.discoverDone
+	sc.discoverStop, sc.discoverDone = nil, nil
+	sc.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
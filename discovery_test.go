@@ -0,0 +1,156 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRefreshTopology(t *testing.T) {
+	var version int32
+	var ms *httptest.Server
+	ms = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		u, _ := url.Parse(ms.URL)
+		host, port := u.Hostname(), u.Port()
+		v := atomic.LoadInt32(&version)
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(strings.Replace(statsTestData,
+				"294cbd39999c2270964029691e8bc5e231a867d525ccba62181dc8988ff218dc",
+				fmt.Sprintf("topo%d", v), 1)))
+			return
+		}
+
+		if r.RequestURI == fmt.Sprintf("/topology/xml/topo%d", v) {
+			s := `<nodes n="2">` +
+				`<node id="bb6f7162-4828-11df-bab8-6bac200dcc2a" address="` +
+				host + `" port="` + port + `" apiport="` + port +
+				`" weight="10"/>`
+			if v == 0 {
+				s += `<node id="5c32c076-ffeb-cfdd-a541-97e25c028dd6" ` +
+					`address="10.128.0.100" port="8112" apiport="8112" ` +
+					`weight="10"/>`
+			}
+
+			_, _ = w.Write([]byte(s + `</nodes>`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetDiscover(true)
+	if err := cfg.SetDiscoverInterval(time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	defer sc.StopDiscovery()
+	if len(sc.ListActiveNodes()) != 2 {
+		t.Fatalf("Expected active nodes: 2, got: %v",
+			len(sc.ListActiveNodes()))
+	}
+
+	atomic.StoreInt32(&version, 1)
+	sc.DeactivateNodes(sc.ListActiveNodes()[1])
+	if err := sc.RefreshTopology(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sc.ListActiveNodes()) != 1 {
+		t.Fatalf("Expected active nodes: 1, got: %v",
+			len(sc.ListActiveNodes()))
+	}
+
+	if len(sc.ListInactiveNodes()) != 0 {
+		t.Fatalf("Expected inactive nodes: 0, got: %v",
+			len(sc.ListInactiveNodes()))
+	}
+
+	if sc.ListActiveNodes()[0].GetCurrentTopology() != "topo1" {
+		t.Errorf("Expected topology: topo1, got: %v",
+			sc.ListActiveNodes()[0].GetCurrentTopology())
+	}
+}
+
+func TestStopDiscoveryCancelsRefresh(t *testing.T) {
+	var block int32
+	blocked := make(chan struct{}, 1)
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			if atomic.LoadInt32(&block) != 0 {
+				blocked <- struct{}{}
+				<-r.Context().Done()
+				return
+			}
+
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	mc := NewManualClock(time.Unix(1000, 0))
+	sc.SetClock(mc)
+	sc.SetDiscoverInterval(time.Minute)
+	atomic.StoreInt32(&block, 1)
+	sc.StartDiscovery()
+	refreshing := false
+	for i := 0; i < 100 && !refreshing; i++ {
+		mc.Advance(time.Minute)
+		select {
+		case <-blocked:
+			refreshing = true
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if !refreshing {
+		t.Fatal("Expected topology refresh")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		sc.StopDiscovery()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected topology refresh to be cancelled")
+	}
+}
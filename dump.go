@@ -0,0 +1,165 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// defaultDebugDumpLimit is the default maximum number of bytes of request and
+// response bodies logged in debug dump mode.
+const defaultDebugDumpLimit = 1024
+
+// debugDumpKey is the context key used to enable debug dumps per request.
+type debugDumpKey struct{}
+
+// WithDebugDump returns a copy of a context which will cause all requests made
+// using it to be logged in debug dump mode, regardless of client settings.
+func WithDebugDump(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugDumpKey{}, true)
+}
+
+// DebugDump gets whether the method, URL, headers, and truncated bodies of
+// every request and response are logged.
+func (c *Config) DebugDump() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.debugDump
+}
+
+// SetDebugDump sets whether the method, URL, headers, and truncated bodies of
+// every request and response are logged, using the client logger.
+func (c *Config) SetDebugDump(enable bool) {
+	c.Lock()
+	c.debugDump = enable
+	c.Unlock()
+}
+
+// DebugDumpLimit gets the maximum number of bytes of request and response
+// bodies logged in debug dump mode. The default value is 1024.
+func (c *Config) DebugDumpLimit() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.debugDumpLimit
+}
+
+// SetDebugDumpLimit sets the maximum number of bytes of request and response
+// bodies logged in debug dump mode. A value of zero logs entire bodies.
+func (c *Config) SetDebugDumpLimit(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid debug dump limit value")
+	}
+
+	c.Lock()
+	c.debugDumpLimit = n
+	c.Unlock()
+	return nil
+}
+
+// SetDebugDump sets whether the method, URL, headers, and truncated bodies of
+// every request and response are logged, using the client logger.
+func (sc *SnowthClient) SetDebugDump(enable bool) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.debugDump = enable
+}
+
+// SetDebugDumpLimit sets the maximum number of bytes of request and response
+// bodies logged in debug dump mode. A value of zero logs entire bodies.
+func (sc *SnowthClient) SetDebugDumpLimit(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid debug dump limit value")
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+	sc.debugDumpLimit = n
+	return nil
+}
+
+// debugDumpEnabled returns whether requests made with a context should be
+// logged in debug dump mode, and the body size limit to use.
+func (sc *SnowthClient) debugDumpEnabled(ctx context.Context) (bool, int) {
+	sc.RLock()
+	defer sc.RUnlock()
+	if sc.debugDump {
+		return true, sc.debugDumpLimit
+	}
+
+	if v, ok := ctx.Value(debugDumpKey{}).(bool); ok && v {
+		return true, sc.debugDumpLimit
+	}
+
+	return false, sc.debugDumpLimit
+}
+
+// dumpRequest logs the method, URL, headers and truncated body of a request.
+// The request body is only logged if it can be read again when the request is
+// sent.
+func (sc *SnowthClient) dumpRequest(r *http.Request, id string, limit int) {
+	body := ""
+	if r.GetBody != nil {
+		if rc, err := r.GetBody(); err == nil {
+			b, err := ioutil.ReadAll(rc)
+			_ = rc.Close()
+			if err == nil {
+				body = truncateBody(b, limit)
+			}
+		}
+	} else if r.Body != nil {
+		body = "(body not available)"
+	}
+
+	sc.LogInfof("gosnowth dump request %s: %s %s headers: %s body: %s", id,
+		r.Method, r.URL.String(), dumpHeaders(r.Header), body)
+}
+
+// dumpResponse logs the status, headers and truncated body of a response.
+func (sc *SnowthClient) dumpResponse(resp *http.Response, body []byte,
+	id string, limit int, latency time.Duration) {
+	sc.LogInfof("gosnowth dump response %s: %s (%v) headers: %s body: %s", id,
+		resp.Status, latency, dumpHeaders(resp.Header),
+		truncateBody(body, limit))
+}
+
+// dumpHeaders formats HTTP headers for logging, sorted by name, with the
+// values of credential headers redacted.
+func dumpHeaders(h http.Header) string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		v := strings.Join(h[k], ", ")
+		switch http.CanonicalHeaderKey(k) {
+		case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+			snowthapi.HeaderAuthToken:
+			v = "[redacted]"
+		}
+
+		parts = append(parts, k+": "+v)
+	}
+
+	return "{" + strings.Join(parts, "; ") + "}"
+}
+
+// truncateBody returns a body as a string, truncated to the limit number of
+// bytes if the limit is greater than zero.
+func truncateBody(b []byte, limit int) string {
+	if limit > 0 && len(b) > limit {
+		return fmt.Sprintf("%s... (%d bytes truncated)", b[:limit],
+			len(b)-limit)
+	}
+
+	return string(b)
+}
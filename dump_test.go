@@ -0,0 +1,178 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type dumpLog struct {
+	sync.Mutex
+	infos []string
+}
+
+func (l *dumpLog) Debugf(format string, args ...interface{}) {}
+func (l *dumpLog) Errorf(format string, args ...interface{}) {}
+func (l *dumpLog) Warnf(format string, args ...interface{})  {}
+func (l *dumpLog) Infof(format string, args ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+	l.infos = append(l.infos, fmt.Sprintf(format, args...))
+}
+
+func (l *dumpLog) dumps() []string {
+	l.Lock()
+	defer l.Unlock()
+	res := []string{}
+	for _, s := range l.infos {
+		if strings.HasPrefix(s, "gosnowth dump ") {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+func TestDebugDump(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/test" {
+			_, _ = w.Write([]byte("0123456789"))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	dl := &dumpLog{}
+	sc.SetLog(dl)
+	_, _, err = sc.DoRequest(node, "POST", "/test?q=1",
+		strings.NewReader("request body"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dl.dumps()) != 0 {
+		t.Errorf("Expected dumps: 0, got: %v", dl.dumps())
+	}
+
+	sc.SetDebugDump(true)
+	if err := sc.SetDebugDumpLimit(-1); err == nil {
+		t.Error("Expected error for negative limit")
+	}
+
+	if err := sc.SetDebugDumpLimit(4); err != nil {
+		t.Fatal(err)
+	}
+	hdrs := http.Header{}
+	hdrs.Set("Authorization", "secret")
+	_, _, err = sc.DoRequest(node, "POST", "/test?q=1",
+		strings.NewReader("request body"), hdrs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dumps := dl.dumps()
+	if len(dumps) != 2 {
+		t.Fatalf("Expected dumps: 2, got: %v", dumps)
+	}
+
+	if !strings.Contains(dumps[0], "POST "+ms.URL+"/test?q=1") ||
+		!strings.Contains(dumps[0], "body: requ... (8 bytes truncated)") ||
+		!strings.Contains(dumps[0], "Authorization: [redacted]") ||
+		strings.Contains(dumps[0], "secret") {
+		t.Errorf("Unexpected request dump: %v", dumps[0])
+	}
+
+	if !strings.Contains(dumps[1], "200 OK") ||
+		!strings.Contains(dumps[1], "body: 0123... (6 bytes truncated)") {
+		t.Errorf("Unexpected response dump: %v", dumps[1])
+	}
+
+	sc.SetDebugDump(false)
+	_, _, err = sc.DoRequestContext(WithDebugDump(context.Background()),
+		node, "GET", "/test", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dl.dumps()) != 4 {
+		t.Errorf("Expected dumps: 4, got: %v", dl.dumps())
+	}
+
+	ctx := WithAccount(WithDebugDump(context.Background()), 1, "token-value")
+	_, _, err = sc.DoRequestContext(ctx, node, "GET", "/test", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dumps = dl.dumps()
+	if len(dumps) != 6 {
+		t.Fatalf("Expected dumps: 6, got: %v", dumps)
+	}
+
+	if !strings.Contains(dumps[4], "X-Circonus-Auth-Token: [redacted]") ||
+		strings.Contains(dumps[4], "token-value") {
+		t.Errorf("Unexpected request dump: %v", dumps[4])
+	}
+}
+
+func TestDebugDumpConfig(t *testing.T) {
+	t.Parallel()
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.DebugDump() || cfg.DebugDumpLimit() != defaultDebugDumpLimit {
+		t.Errorf("Expected debug dump: false/%d, got: %v/%v",
+			defaultDebugDumpLimit, cfg.DebugDump(), cfg.DebugDumpLimit())
+	}
+
+	cfg.SetDebugDump(true)
+	if !cfg.DebugDump() {
+		t.Errorf("Expected debug dump: true, got: %v", cfg.DebugDump())
+	}
+
+	if err := cfg.SetDebugDumpLimit(-1); err == nil {
+		t.Error("Expected invalid debug dump limit error")
+	}
+
+	if err := cfg.SetDebugDumpLimit(0); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.DebugDumpLimit() != 0 {
+		t.Errorf("Expected debug dump limit: 0, got: %v", cfg.DebugDumpLimit())
+	}
+}
@@ -0,0 +1,170 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// SnowthError values describe failed requests to IRONdb nodes. Requests which
+// receive an unsuccessful HTTP response have a Status value, along with the
+// response body and any error details decoded from it. Requests which fail
+// without receiving a response, such as due to connection errors, have a
+// Status value of zero and the underlying error in Err.
+type SnowthError struct {
+	// Status is the HTTP status code of the response.
+	Status int
+
+	// Node is the address of the node to which the request was sent.
+	Node string
+
+	// Method and Endpoint are the HTTP method and URL path of the request.
+	Method   string
+	Endpoint string
+
+	// RequestID is the request ID sent with the request, which can be used
+	// to find the request in the IRONdb node logs.
+	RequestID string
+
+	// Body is the raw response body.
+	Body string
+
+	// Message is the error message decoded from a JSON response body, if the
+	// body contained one.
+	Message string
+
+	// Details contains all fields decoded from a JSON object response body.
+	Details map[string]interface{}
+
+	// Err is the underlying error, for requests which failed without a
+	// response.
+	Err error
+}
+
+// newSnowthError creates a SnowthError value for an unsuccessful response to
+// a request, decoding any JSON error payload in the response body.
+func newSnowthError(r *http.Request, status int, body []byte) *SnowthError {
+	se := &SnowthError{
+		Status: status,
+		Body:   string(body),
+	}
+
+	if r != nil && r.URL != nil {
+		se.Node = r.URL.Host
+		se.Method = r.Method
+		se.Endpoint = r.URL.Path
+		se.RequestID = r.Header.Get(RequestIDHeader)
+	}
+
+	details := map[string]interface{}{}
+	if err := json.Unmarshal(body, &details); err != nil {
+		return se
+	}
+
+	se.Details = details
+	for _, k := range []string{"error", "message", "reason", "status"} {
+		if v, ok := details[k].(string); ok && v != "" {
+			se.Message = v
+			break
+		}
+	}
+
+	return se
+}
+
+// Error implements the error interface for SnowthError values.
+func (se *SnowthError) Error() string {
+	if se.Err != nil {
+		return fmt.Sprintf("failed to perform request: %v", se.Err)
+	}
+
+	return fmt.Sprintf("error returned from IRONdb (%s): [%d] %s",
+		se.Node, se.Status, se.Body)
+}
+
+// Unwrap returns the underlying error of a request which failed without a
+// response.
+func (se *SnowthError) Unwrap() error {
+	return se.Err
+}
+
+// Sentinel errors used to classify failed requests with errors.Is().
+var (
+	// ErrNotFound indicates that the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrThrottled indicates that a node rejected a request because it is
+	// overloaded or rate limiting requests.
+	ErrThrottled = errors.New("request throttled")
+)
+
+// Is reports whether the error matches a sentinel error, allowing requests
+// which failed with a 404 status to match ErrNotFound, and requests which
+// failed with a 429 or 503 status to match ErrThrottled.
+func (se *SnowthError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return se.Status == http.StatusNotFound
+	case ErrThrottled:
+		return se.Status == http.StatusTooManyRequests ||
+			se.Status == http.StatusServiceUnavailable
+	}
+
+	return false
+}
+
+// IsNotFound returns whether an error indicates that the requested resource
+// does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsThrottled returns whether an error indicates that a request was rejected
+// because a node is overloaded or rate limiting requests. Throttled requests
+// should be retried after backing off.
+func IsThrottled(err error) bool {
+	return errors.Is(err, ErrThrottled)
+}
+
+// IsRetryable returns whether a request which failed with an error may
+// succeed if it is retried. Connection errors, timeouts of individual
+// requests, throttling and server errors are retryable. Client errors, such as
+// invalid or not found requests, and errors caused by cancellation or
+// expiration of the caller's context are permanent.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if errors.Is(err, errRedirectLimit) ||
+		strings.Contains(err.Error(), "cannot parse") {
+		return false
+	}
+
+	var se *SnowthError
+	if errors.As(err, &se) {
+		switch {
+		case se.Err != nil:
+			return true
+		case se.Status == http.StatusRequestTimeout,
+			se.Status == http.StatusTooManyRequests,
+			se.Status >= http.StatusInternalServerError:
+			return true
+		default:
+			return false
+		}
+	}
+
+	var nerr net.Error
+	return errors.As(err, &nerr)
+}
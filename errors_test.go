@@ -0,0 +1,184 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestSnowthError(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/missing" {
+			w.WriteHeader(404)
+			_, _ = w.Write([]byte(`{"error":"not found","code":1}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	node := &SnowthNode{url: u}
+	_, _, err = sc.DoRequest(node, "GET", "/missing", nil, nil)
+	var se *SnowthError
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected SnowthError, got: %v", err)
+	}
+
+	if se.Status != 404 {
+		t.Errorf("Expected status: 404, got: %v", se.Status)
+	}
+
+	if se.Node != u.Host {
+		t.Errorf("Expected node: %v, got: %v", u.Host, se.Node)
+	}
+
+	if se.Method != "GET" || se.Endpoint != "/missing" {
+		t.Errorf("Expected endpoint: GET /missing, got: %v %v", se.Method,
+			se.Endpoint)
+	}
+
+	if se.Message != "not found" {
+		t.Errorf("Expected message: not found, got: %v", se.Message)
+	}
+
+	if se.Details["code"] != float64(1) {
+		t.Errorf("Expected code detail: 1, got: %v", se.Details["code"])
+	}
+
+	exp := "error returned from IRONdb (" + u.Host +
+		`): [404] {"error":"not found","code":1}`
+	if se.Error() != exp {
+		t.Errorf("Expected error: %v, got: %v", exp, se.Error())
+	}
+
+	cause := errors.New("connection refused")
+	se = &SnowthError{Err: cause}
+	if !errors.Is(se, cause) {
+		t.Error("Expected wrapped error")
+	}
+
+	if se.Error() != "failed to perform request: connection refused" {
+		t.Errorf("Expected error: failed to perform request: "+
+			"connection refused, got: %v", se.Error())
+	}
+}
+
+func TestErrorClassification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+		notFound  bool
+		throttled bool
+	}{
+		{"nil", nil, false, false, false},
+		{"not found", &SnowthError{Status: 404}, false, true, false},
+		{"bad request", &SnowthError{Status: 400}, false, false, false},
+		{"request timeout", &SnowthError{Status: 408}, true, false, false},
+		{"too many requests", &SnowthError{Status: 429}, true, false, true},
+		{"server error", &SnowthError{Status: 500}, true, false, false},
+		{"unavailable", &SnowthError{Status: 503}, true, false, true},
+		{"cannot parse", &SnowthError{Status: 500,
+			Body: "cannot parse query"}, false, false, false},
+		{"transport", &SnowthError{Err: errors.New("connection refused")},
+			true, false, false},
+		{"cancelled", &SnowthError{Err: context.Canceled},
+			false, false, false},
+		{"wrapped", fmt.Errorf("unable to read: %w",
+			&SnowthError{Status: 502}), true, false, false},
+		{"sentinel", fmt.Errorf("metric %w: x", ErrNotFound),
+			false, true, false},
+		{"other", errors.New("invalid value"), false, false, false},
+	}
+
+	for _, tt := range tests {
+		if v := IsRetryable(tt.err); v != tt.retryable {
+			t.Errorf("%s: Expected retryable: %v, got: %v", tt.name,
+				tt.retryable, v)
+		}
+
+		if v := IsNotFound(tt.err); v != tt.notFound {
+			t.Errorf("%s: Expected not found: %v, got: %v", tt.name,
+				tt.notFound, v)
+		}
+
+		if v := IsThrottled(tt.err); v != tt.throttled {
+			t.Errorf("%s: Expected throttled: %v, got: %v", tt.name,
+				tt.throttled, v)
+		}
+	}
+}
+
+func TestDoRequestPermanentError(t *testing.T) {
+	t.Parallel()
+
+	calls := 0
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	_, _, err = sc.DoRequest(node, "GET", "/missing", nil, nil)
+	if !IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected requests: 1, got: %v", calls)
+	}
+}
@@ -0,0 +1,232 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetricKey values identify a metric by its check UUID and metric name.
+type MetricKey struct {
+	UUID   string
+	Metric string
+}
+
+// MetricExistence values contain the results of checking which of a list of
+// metrics exist in IRONdb, as a bitmap indexed by the position of each metric
+// in the list.
+type MetricExistence struct {
+	Keys   []MetricKey
+	bitmap []uint64
+}
+
+// newMetricExistence creates an empty existence result for a list of metrics.
+func newMetricExistence(keys []MetricKey) *MetricExistence {
+	return &MetricExistence{
+		Keys:   keys,
+		bitmap: make([]uint64, (len(keys)+63)/64),
+	}
+}
+
+// set marks the metric at index i as existing.
+func (me *MetricExistence) set(i int) {
+	me.bitmap[i/64] |= 1 << uint(i%64)
+}
+
+// Exists returns whether the metric at index i of the list of metrics exists.
+func (me *MetricExistence) Exists(i int) bool {
+	if i < 0 || i >= len(me.Keys) {
+		return false
+	}
+
+	return me.bitmap[i/64]&(1<<uint(i%64)) != 0
+}
+
+// Count returns the number of metrics which exist.
+func (me *MetricExistence) Count() int {
+	n := 0
+	for _, w := range me.bitmap {
+		n += bits.OnesCount64(w)
+	}
+
+	return n
+}
+
+// Missing returns the metrics which do not exist, in list order.
+func (me *MetricExistence) Missing() []MetricKey {
+	res := []MetricKey{}
+	for i, k := range me.Keys {
+		if !me.Exists(i) {
+			res = append(res, k)
+		}
+	}
+
+	return res
+}
+
+// Map returns a map of every metric to whether it exists.
+func (me *MetricExistence) Map() map[MetricKey]bool {
+	res := make(map[MetricKey]bool, len(me.Keys))
+	for i, k := range me.Keys {
+		res[k] = res[k] || me.Exists(i)
+	}
+
+	return res
+}
+
+// MetricsExistOptions values contain optional parameters used when checking
+// which metrics exist.
+type MetricsExistOptions struct {
+	// BatchSize is the maximum number of check UUIDs searched by each tag
+	// query. The default is 32.
+	BatchSize int
+
+	// Concurrency is the maximum number of tag queries sent in parallel. The
+	// default is 4.
+	Concurrency int
+}
+
+// MetricsExist determines which of a list of metrics exist in IRONdb, using
+// as few requests as possible. Metrics are grouped by check UUID, and every
+// request searches for all metrics in a batch of checks. Metric names are
+// compared in canonical form. The result reports the existence of each metric
+// by its index in the list.
+func (sc *SnowthClient) MetricsExist(accountID int64, keys []MetricKey,
+	options *MetricsExistOptions,
+	nodes ...*SnowthNode) (*MetricExistence, error) {
+	return sc.MetricsExistContext(context.Background(), accountID, keys,
+		options, nodes...)
+}
+
+// MetricsExistContext is the context aware version of MetricsExist.
+func (sc *SnowthClient) MetricsExistContext(ctx context.Context,
+	accountID int64, keys []MetricKey, options *MetricsExistOptions,
+	nodes ...*SnowthNode) (*MetricExistence, error) {
+	opts := MetricsExistOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 32
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	// Index the metrics by check UUID and canonical metric name.
+	idx := map[string]map[string][]int{}
+	for i, k := range keys {
+		id := strings.ToLower(k.UUID)
+		if err := ValidateCheckUUID(id); err != nil {
+			return nil, err
+		}
+
+		if idx[id] == nil {
+			idx[id] = map[string][]int{}
+		}
+
+		name := CanonicalMetricName(k.Metric)
+		idx[id][name] = append(idx[id][name], i)
+	}
+
+	ids := make([]string, 0, len(idx))
+	for id := range idx {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	batches := [][]string{}
+	for len(ids) > 0 {
+		n := opts.BatchSize
+		if n > len(ids) {
+			n = len(ids)
+		}
+
+		batches = append(batches, ids[:n])
+		ids = ids[n:]
+	}
+
+	r := newMetricExistence(keys)
+	var mu sync.Mutex
+	g := sc.NewWorkGroup(ctx, opts.Concurrency)
+	for _, b := range batches {
+		batch := b
+		_ = g.Go(func(ctx context.Context) error {
+			return sc.metricsExistBatch(ctx, accountID, batch, idx, r, &mu,
+				nodes...)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// metricsExistBatch searches for the metrics of a batch of check UUIDs and
+// records those found. Batches with truncated results are split and searched
+// again, and the metrics of single checks with truncated results are each
+// searched individually.
+func (sc *SnowthClient) metricsExistBatch(ctx context.Context,
+	accountID int64, batch []string, idx map[string]map[string][]int,
+	r *MetricExistence, mu *sync.Mutex, nodes ...*SnowthNode) error {
+	parts := make([]string, len(batch))
+	for i, id := range batch {
+		parts[i] = "__check_uuid:" + id
+	}
+
+	res, err := sc.FindTagsContext(ctx, accountID,
+		"or("+strings.Join(parts, ",")+")", &FindTagsOptions{}, nodes...)
+	if err != nil {
+		return fmt.Errorf("unable to find metrics: %w", err)
+	}
+
+	if !res.Truncated {
+		recordMetricsExist(res.Items, idx, r, mu)
+		return nil
+	}
+
+	if len(batch) > 1 {
+		h := len(batch) / 2
+		if err := sc.metricsExistBatch(ctx, accountID, batch[:h], idx, r, mu,
+			nodes...); err != nil {
+			return err
+		}
+
+		return sc.metricsExistBatch(ctx, accountID, batch[h:], idx, r, mu,
+			nodes...)
+	}
+
+	for name := range idx[batch[0]] {
+		res, err := sc.FindTagsContext(ctx, accountID, "and(__check_uuid:"+
+			batch[0]+","+metricNameQuery(name)+")", &FindTagsOptions{},
+			nodes...)
+		if err != nil {
+			return fmt.Errorf("unable to find metric: %w", err)
+		}
+
+		recordMetricsExist(res.Items, idx, r, mu)
+	}
+
+	return nil
+}
+
+// recordMetricsExist marks the metrics found by a tag query as existing.
+func recordMetricsExist(items []FindTagsItem,
+	idx map[string]map[string][]int, r *MetricExistence, mu *sync.Mutex) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, item := range items {
+		names := idx[strings.ToLower(item.UUID)]
+		for _, i := range names[CanonicalMetricName(item.MetricName)] {
+			r.set(i)
+		}
+	}
+}
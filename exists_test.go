@@ -0,0 +1,121 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMetricExistence(t *testing.T) {
+	t.Parallel()
+	keys := make([]MetricKey, 130)
+	me := newMetricExistence(keys)
+	me.set(0)
+	me.set(64)
+	me.set(129)
+	if !me.Exists(64) || me.Exists(65) || me.Exists(130) || me.Exists(-1) {
+		t.Error("Expected existence of set indices only")
+	}
+
+	if me.Count() != 3 {
+		t.Errorf("Expected count: 3, got: %v", me.Count())
+	}
+
+	if n := len(me.Missing()); n != 127 {
+		t.Errorf("Expected missing: 127, got: %v", n)
+	}
+}
+
+func TestMetricsExist(t *testing.T) {
+	t.Parallel()
+	u1 := "11223344-5566-7788-9900-aabbccddeeff"
+	u2 := "fc85e0ab-f568-45e6-86ee-d7443be8277d"
+	items := map[string][]FindTagsItem{
+		u1: {
+			{UUID: u1, MetricName: "a|ST[x:1,b:2]"},
+			{UUID: u1, MetricName: "b"},
+		},
+		u2: {
+			{UUID: u2, MetricName: "c"},
+		},
+	}
+
+	var requests int64
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?") {
+			atomic.AddInt64(&requests, 1)
+			q := r.URL.Query().Get("query")
+			res := []FindTagsItem{}
+			for id, v := range items {
+				if strings.Contains(q, id) {
+					res = append(res, v...)
+				}
+			}
+
+			if strings.HasPrefix(q, "or(") && len(res) > 2 {
+				w.Header().Set("X-Snowth-Search-Result-Count", "3")
+				res = res[:2]
+			}
+
+			b, _ := json.Marshal(res)
+			_, _ = w.Write(b)
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	keys := []MetricKey{
+		{UUID: u1, Metric: "a|ST[b:2,x:1]"},
+		{UUID: u1, Metric: "missing"},
+		{UUID: u2, Metric: "c"},
+		{UUID: strings.ToUpper(u1), Metric: "b"},
+	}
+
+	res, err := sc.MetricsExist(1, keys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, exp := range []bool{true, false, true, true} {
+		if res.Exists(i) != exp {
+			t.Errorf("Expected exists %v: %v, got: %v", keys[i], exp,
+				res.Exists(i))
+		}
+	}
+
+	if n := atomic.LoadInt64(&requests); n != 3 {
+		t.Errorf("Expected requests: 3, got: %v", n)
+	}
+
+	if m := res.Missing(); len(m) != 1 || m[0] != keys[1] {
+		t.Errorf("Expected missing: %v, got: %v", keys[1], m)
+	}
+
+	if _, err := sc.MetricsExist(1, []MetricKey{{UUID: "invalid"}},
+		nil); err == nil {
+		t.Error("Expected error for invalid check UUID")
+	}
+}
@@ -0,0 +1,88 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"net"
+	"net/http"
+)
+
+// isFailoverError returns whether a request error is a connection error or a
+// server error, which may succeed if the request is sent to another node.
+func isFailoverError(err error) bool {
+	var nerr net.Error
+	if errors.As(err, &nerr) {
+		return true
+	}
+
+	var serr *SnowthError
+	if errors.As(err, &serr) {
+		return serr.Status >= http.StatusInternalServerError
+	}
+
+	return false
+}
+
+// ownerNodes returns the active nodes, other than the specified node, which
+// have identifiers in the owners list.
+func (sc *SnowthClient) ownerNodes(node *SnowthNode,
+	owners []string) []*SnowthNode {
+	if sc.parent != nil {
+		return sc.parent.ownerNodes(node, owners)
+	}
+
+	res := []*SnowthNode{}
+	if len(owners) == 0 {
+		return res
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	for _, id := range owners {
+		for _, n := range sc.activeNodes {
+			if n != node && n.identifier == id {
+				res = append(res, n)
+			}
+		}
+	}
+
+	return res
+}
+
+// activeNodeIDs returns the identifiers of all active nodes, for use as the
+// owners of requests which can be served by any node.
+func (sc *SnowthClient) activeNodeIDs() []string {
+	if sc.parent != nil {
+		return sc.parent.activeNodeIDs()
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	res := make([]string, 0, len(sc.activeNodes))
+	for _, n := range sc.activeNodes {
+		if n.identifier != "" {
+			res = append(res, n.identifier)
+		}
+	}
+
+	return res
+}
+
+// metricOwnerIDs returns the identifiers of the nodes which own a metric,
+// using only a previously loaded topology so that no requests are made.
+func (sc *SnowthClient) metricOwnerIDs(uuid, metric string) []string {
+	root := sc.root()
+	root.RLock()
+	topo := root.currentTopologyCompiled
+	root.RUnlock()
+	if topo == nil {
+		return nil
+	}
+
+	ids, err := topo.FindMetricNodeIDs(uuid, metric)
+	if err != nil {
+		return nil
+	}
+
+	return sc.orderOwners(uuid, metric, ids)
+}
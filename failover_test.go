@@ -0,0 +1,126 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+type testNetError struct{}
+
+func (testNetError) Error() string   { return "test network error" }
+func (testNetError) Timeout() bool   { return false }
+func (testNetError) Temporary() bool { return false }
+
+func TestIsFailoverError(t *testing.T) {
+	tests := []struct {
+		err error
+		exp bool
+	}{
+		{fmt.Errorf("failed to perform request: %w", testNetError{}), true},
+		{&SnowthError{Status: 503}, true},
+		{&SnowthError{Status: 404}, false},
+		{errors.New("other"), false},
+	}
+
+	for _, test := range tests {
+		if res := isFailoverError(test.err); res != test.exp {
+			t.Errorf("Expected failover for %v: %v, got: %v", test.err,
+				test.exp, res)
+		}
+	}
+}
+
+func TestReadFailover(t *testing.T) {
+	var badReads, goodReads int32
+	handler := func(id string, fail bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.RequestURI == "/state" {
+				_, _ = w.Write([]byte(stateTestData))
+				return
+			}
+
+			if r.RequestURI == "/stats.json" {
+				_, _ = w.Write([]byte(strings.Replace(statsTestData,
+					"bb6f7162-4828-11df-bab8-6bac200dcc2a", id, 1)))
+				return
+			}
+
+			if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=test") {
+				if fail {
+					atomic.AddInt32(&badReads, 1)
+					w.WriteHeader(503)
+					_, _ = w.Write([]byte("unavailable"))
+					return
+				}
+
+				atomic.AddInt32(&goodReads, 1)
+				w.Header().Set("X-Snowth-Search-Result-Count", "1")
+				_, _ = w.Write([]byte(tagsTestData))
+				return
+			}
+
+			t.Errorf("Unexpected request: %v", r)
+			w.WriteHeader(500)
+		}
+	}
+
+	bad := httptest.NewServer(handler("bad", true))
+	defer bad.Close()
+	good := httptest.NewServer(handler("good", false))
+	defer good.Close()
+	sc, err := NewSnowthClient(false, bad.URL, good.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	var node *SnowthNode
+	for _, n := range sc.ListActiveNodes() {
+		if n.identifier == "bad" {
+			node = n
+		}
+	}
+
+	if node == nil {
+		t.Fatal("Expected active node: bad")
+	}
+
+	owners := sc.ownerNodes(node, []string{"bad", "good"})
+	if len(owners) != 1 || owners[0].identifier != "good" {
+		t.Fatalf("Expected owner nodes: [good], got: %v", owners)
+	}
+
+	res, err := sc.FindTags(1, "test", &FindTagsOptions{}, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Count != 1 {
+		t.Errorf("Expected count: 1, got: %v", res.Count)
+	}
+
+	if atomic.LoadInt32(&badReads) != 1 || atomic.LoadInt32(&goodReads) != 1 {
+		t.Errorf("Expected one read from each node, got: %v, %v",
+			atomic.LoadInt32(&badReads), atomic.LoadInt32(&goodReads))
+	}
+
+	u, err := url.Parse(bad.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	_, _, err = sc.DoRequest(&SnowthNode{url: u}, "GET",
+		"/find/1/tags?query=test", nil, nil)
+	var serr *SnowthError
+	if !errors.As(err, &serr) || serr.Status != 503 {
+		t.Errorf("Expected status error: 503, got: %v", err)
+	}
+}
@@ -14,6 +14,45 @@ import (
 	flatbuffers "github.com/google/flatbuffers/go"
 
 	"github.com/circonus-labs/gosnowth/fb/fetch"
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// Fetch stream data kinds.
+const (
+	FetchKindNumeric   = "numeric"
+	FetchKindHistogram = "histogram"
+	FetchKindText      = "text"
+)
+
+// Fetch stream transforms, which convert the raw data of a stream into the
+// values for each period of a fetch request. Numeric streams support all
+// transforms except the histogram transforms, which require histogram streams.
+const (
+	FetchTransformNone              = "none"
+	FetchTransformAverage           = "average"
+	FetchTransformSum               = "sum"
+	FetchTransformCount             = "count"
+	FetchTransformStddev            = "stddev"
+	FetchTransformDerivative        = "derivative"
+	FetchTransformDerivativeStddev  = "derivative_stddev"
+	FetchTransformCounter           = "counter"
+	FetchTransformCounterStddev     = "counter_stddev"
+	FetchTransformPercentile        = "percentile"
+	FetchTransformInversePercentile = "inverse_percentile"
+	FetchTransformCountAbove        = "count_above"
+	FetchTransformCountBelow        = "count_below"
+)
+
+// Fetch reduce methods, which combine the transformed values of the streams
+// of a fetch request. The pass method returns each stream unchanged, and the
+// merge method combines histogram streams.
+const (
+	FetchReducePass    = "pass"
+	FetchReduceAverage = "average"
+	FetchReduceSum     = "sum"
+	FetchReduceMin     = "min"
+	FetchReduceMax     = "max"
+	FetchReduceMerge   = "merge"
 )
 
 // FetchStream values represent queries for individual data streams in an
@@ -135,12 +174,16 @@ func (sc *SnowthClient) FetchValues(q *FetchQuery, nodes ...*SnowthNode) (*DF4Re
 // FetchValuesContext is the context aware version of FetchValues.
 func (sc *SnowthClient) FetchValuesContext(ctx context.Context,
 	q *FetchQuery, nodes ...*SnowthNode) (*DF4Response, error) {
+	if q == nil {
+		return nil, fmt.Errorf("fetch query required")
+	}
+
 	var node *SnowthNode
 	switch {
 	case len(nodes) > 0 && nodes[0] != nil:
 		node = nodes[0]
 	case len(q.Streams) > 0:
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(q.Streams[0].UUID, q.Streams[0].Name))
+		node = sc.readNode(ctx, q.Streams[0].UUID, q.Streams[0].Name)
 	default:
 		node = sc.GetActiveNode()
 	}
@@ -150,8 +193,11 @@ func (sc *SnowthClient) FetchValuesContext(ctx context.Context,
 		return nil, err
 	}
 
-	hdrs := http.Header{"Content-Type": {"application/json"}}
-	body, _, err := sc.DoRequestContext(ctx, node, "POST", "/fetch", buf, hdrs)
+	hdrs := http.Header{
+		snowthapi.HeaderContentType: {snowthapi.ContentTypeJSON},
+	}
+	body, _, err := sc.DoRequestContext(ctx, node, "POST",
+		snowthapi.PathFetch, buf, hdrs)
 	if err != nil {
 		return nil, err
 	}
@@ -164,7 +210,64 @@ func (sc *SnowthClient) FetchValuesContext(ctx context.Context,
 	rb = ReplaceInf(rb)
 
 	r := &DF4Response{}
-	if err := decodeJSON(bytes.NewBuffer(rb), &r); err != nil {
+	if err := sc.decodeResponse(bytes.NewBuffer(rb), &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	return r, nil
+}
+
+// Validate returns an error if the fetch query is not a valid IRONdb fetch
+// request, which requires a period, a count, and at least one stream and one
+// reduce operation.
+func (fq *FetchQuery) Validate() error {
+	switch {
+	case fq.Period <= 0:
+		return fmt.Errorf("fetch query requires a period")
+	case fq.Count <= 0:
+		return fmt.Errorf("fetch query requires a count")
+	case len(fq.Streams) < 1:
+		return fmt.Errorf("fetch query requires at least one stream")
+	case len(fq.Reduce) < 1:
+		return fmt.Errorf("fetch query requires at least one reduce")
+	}
+
+	for i, s := range fq.Streams {
+		if s.UUID == "" || s.Name == "" {
+			return fmt.Errorf("fetch query stream %d requires a check uuid "+
+				"and metric name", i)
+		}
+	}
+
+	return nil
+}
+
+// FetchSeries retrieves data using the IRONdb fetch API, and returns it as
+// typed series values, one for each stream of data returned. The query is
+// validated before it is sent.
+func (sc *SnowthClient) FetchSeries(q *FetchQuery,
+	nodes ...*SnowthNode) (*DF4Result, error) {
+	return sc.FetchSeriesContext(context.Background(), q, nodes...)
+}
+
+// FetchSeriesContext is the context aware version of FetchSeries.
+func (sc *SnowthClient) FetchSeriesContext(ctx context.Context,
+	q *FetchQuery, nodes ...*SnowthNode) (*DF4Result, error) {
+	if q == nil {
+		return nil, fmt.Errorf("fetch query required")
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	df4, err := sc.FetchValuesContext(ctx, q, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := df4.Result()
+	if err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -172,10 +275,10 @@ func (sc *SnowthClient) FetchValuesContext(ctx context.Context,
 }
 
 // FetchFlatbufferContentType is the content type header for flatbuffer fetch data.
-const FetchFlatbufferContentType = "x-irondb-fetch-flatbuffer"
+const FetchFlatbufferContentType = snowthapi.ContentTypeFetchFlatbuffer
 
 // Df4FlatbufferAccept is the accept header for flatbuffer df4 data.
-const Df4FlatbufferAccept = "x-irondb-df4-flatbuffer"
+const Df4FlatbufferAccept = snowthapi.ContentTypeDF4Flatbuffer
 
 // FetchValuesFb retrieves data values using the IRONdb fetch API with FlatBuffers.
 func (sc *SnowthClient) FetchValuesFb(node *SnowthNode,
@@ -192,10 +295,11 @@ func (sc *SnowthClient) FetchValuesFbContext(ctx context.Context,
 	buf := bytes.NewBuffer(builder.FinishedBytes())
 
 	hdrs := http.Header{
-		"Content-Type": {FetchFlatbufferContentType},
-		"Accept":       {Df4FlatbufferAccept},
+		snowthapi.HeaderContentType: {FetchFlatbufferContentType},
+		snowthapi.HeaderAccept:      {Df4FlatbufferAccept},
 	}
-	body, _, err := sc.DoRequestContext(ctx, node, "POST", "/fetch", buf, hdrs)
+	body, _, err := sc.DoRequestContext(ctx, node, "POST",
+		snowthapi.PathFetch, buf, hdrs)
 	if err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ package gosnowth
 import (
 	"bytes"
 	"encoding/json"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -162,3 +163,95 @@ func TestFetchQuery(t *testing.T) {
 		t.Errorf("Expected meta label: test, got: %v", res.Meta[0].Label)
 	}
 }
+
+func TestFetchSeries(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.Method == "POST" && r.RequestURI == "/fetch" {
+			fq := &FetchQuery{}
+			if err := json.NewDecoder(r.Body).Decode(&fq); err != nil {
+				t.Error(err)
+			}
+
+			if fq.Period != 300*time.Second || fq.Count != 3 ||
+				fq.Streams[0].Transform != FetchTransformAverage ||
+				fq.Reduce[0].Method != FetchReduceAverage {
+				t.Errorf("Unexpected fetch query: %+v", fq)
+			}
+
+			_, _ = w.Write([]byte(testFetchDF4Response))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	q := &FetchQuery{
+		Start:  time.Unix(300, 0),
+		Period: 300 * time.Second,
+		Count:  3,
+		Streams: []FetchStream{{
+			UUID:      "11223344-5566-7788-9900-aabbccddeeff",
+			Name:      "test",
+			Kind:      FetchKindNumeric,
+			Transform: FetchTransformAverage,
+		}},
+		Reduce: []FetchReduce{{
+			Label:  "test",
+			Method: FetchReduceAverage,
+		}},
+	}
+
+	res, err := sc.FetchSeries(q, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Period != 300*time.Second || len(res.Timestamps) != 3 {
+		t.Errorf("Unexpected result times: %v, %v", res.Period,
+			res.Timestamps)
+	}
+
+	if len(res.Series) != 1 || res.Series[0].Kind != "numeric" ||
+		len(res.Series[0].Tags) != 2 {
+		t.Fatalf("Unexpected result series: %+v", res.Series)
+	}
+
+	if v := res.Series[0].Values; len(v) != 5 || *v[0] != 1 ||
+		*v[1] != math.MaxFloat64 {
+		t.Errorf("Unexpected series values: %v", v)
+	}
+
+	q.Reduce = nil
+	if _, err := sc.FetchSeries(q, node); err == nil {
+		t.Error("Expected error for missing reduce")
+	}
+
+	if _, err := sc.FetchSeries(nil, node); err == nil {
+		t.Error("Expected error for nil query")
+	}
+}
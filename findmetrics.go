@@ -0,0 +1,66 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+)
+
+// FindMetricsOptions values contain optional parameters used to control the
+// behavior of metric name searches.
+type FindMetricsOptions struct {
+	// Regex causes the name pattern to be used as a regular expression,
+	// which is not anchored, instead of a wildcard pattern.
+	Regex bool
+
+	// Query is an additional tag query which found metrics must also match.
+	Query string
+
+	// Limit is the advisory limit of the number of metrics found. The
+	// default is the limit of the node.
+	Limit int64
+}
+
+// FindMetrics finds the metrics of an account with names matching a pattern,
+// without requiring the metrics to have any tags. The pattern is a wildcard
+// pattern, in which * matches any sequence of characters and ? matches any
+// single character, matched against the whole metric name, excluding stream
+// tags, or a regular expression if the Regex option is set.
+func (sc *SnowthClient) FindMetrics(accountID int64, namePattern string,
+	options *FindMetricsOptions,
+	nodes ...*SnowthNode) (*FindTagsResult, error) {
+	return sc.FindMetricsContext(context.Background(), accountID, namePattern,
+		options, nodes...)
+}
+
+// FindMetricsContext is the context aware version of FindMetrics.
+func (sc *SnowthClient) FindMetricsContext(ctx context.Context,
+	accountID int64, namePattern string, options *FindMetricsOptions,
+	nodes ...*SnowthNode) (*FindTagsResult, error) {
+	if namePattern == "" {
+		return nil, fmt.Errorf("metric name pattern cannot be empty")
+	}
+
+	opts := FindMetricsOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	name := TagWildcard("__name", namePattern)
+	if opts.Regex {
+		name = TagRegex("__name", namePattern)
+	}
+
+	tq := And(name)
+	if opts.Query != "" {
+		tq = And(name, TagQuery{q: opts.Query})
+	}
+
+	q, err := tq.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	return sc.FindTagsContext(ctx, accountID, q,
+		&FindTagsOptions{Limit: opts.Limit}, nodes...)
+}
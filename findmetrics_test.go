@@ -0,0 +1,89 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestFindMetrics(t *testing.T) {
+	t.Parallel()
+	b64 := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/find/1/tags" {
+			switch q := r.URL.Query().Get("query"); q {
+			case "and(__name:b/" + b64(`^cpu\..*$`) + "/)":
+				if l := r.Header.Get("X-Snowth-Advisory-Limit"); l != "" {
+					t.Errorf("Unexpected advisory limit: %v", l)
+				}
+			case "and(__name:b/" + b64("^te.t$") + "/,and(env:prod))":
+				if l := r.Header.Get("X-Snowth-Advisory-Limit"); l != "10" {
+					t.Errorf("Expected advisory limit: 10, got: %v", l)
+				}
+			default:
+				t.Errorf("Unexpected query: %v", q)
+			}
+
+			_, _ = w.Write([]byte(tagsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.FindMetrics(1, "cpu.*", nil, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Items) != 1 || res.Items[0].MetricName != "test" {
+		t.Errorf("Unexpected items: %+v", res.Items)
+	}
+
+	if _, err := sc.FindMetrics(1, "^te.t$", &FindMetricsOptions{
+		Regex: true,
+		Query: "and(env:prod)",
+		Limit: 10,
+	}, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sc.FindMetrics(1, "(", &FindMetricsOptions{Regex: true},
+		node); err == nil {
+		t.Error("Expected error for invalid regular expression")
+	}
+
+	if _, err := sc.FindMetrics(1, "", nil, node); err == nil {
+		t.Error("Expected error for empty pattern")
+	}
+}
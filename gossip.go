@@ -4,6 +4,8 @@ package gosnowth
 import (
 	"context"
 	"fmt"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // Gossip values contain gossip information from a node. This structure includes
@@ -45,8 +47,8 @@ func (sc *SnowthClient) GetGossipInfoContext(ctx context.Context,
 	}
 
 	r := &Gossip{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", "/gossip/json",
-		nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		snowthapi.PathGossipJSON, nil, nil)
 	if err != nil {
 		return nil, err
 	}
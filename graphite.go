@@ -0,0 +1,160 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// GraphiteLeafData values contain the IRONdb metric of a Graphite leaf node.
+type GraphiteLeafData struct {
+	UUID           string `json:"uuid"`
+	Name           string `json:"name"`
+	EgressFunction string `json:"egress_function"`
+}
+
+// GraphiteMetric values are the nodes of the Graphite metric tree returned
+// by a Graphite find request. Leaf nodes are metrics, which contain their
+// IRONdb metric data, and other nodes are branches of the tree.
+type GraphiteMetric struct {
+	Leaf     bool              `json:"leaf"`
+	Name     string            `json:"name"`
+	LeafData *GraphiteLeafData `json:"leaf_data,omitempty"`
+}
+
+// GraphiteSeries values contain the data of Graphite series returned by a
+// Graphite series request. The series values are keyed by series name, and
+// contain one value for each step from the From to the Until time, which is
+// nil when there is no data for that step.
+type GraphiteSeries struct {
+	From   int64                 `json:"from"`
+	Until  int64                 `json:"until"`
+	Step   int64                 `json:"step"`
+	Series map[string][]*float64 `json:"series"`
+}
+
+// Times returns the times of the steps of the series data.
+func (gs *GraphiteSeries) Times() []time.Time {
+	if gs.Step <= 0 || gs.Until < gs.From {
+		return []time.Time{}
+	}
+
+	r := make([]time.Time, 0, (gs.Until-gs.From)/gs.Step)
+	for ts := gs.From; ts < gs.Until; ts += gs.Step {
+		r = append(r, time.Unix(ts, 0))
+	}
+
+	return r
+}
+
+// graphitePath returns the path of a Graphite endpoint for an account, with
+// an optional query prefix.
+func graphitePath(accountID int64, prefix, endpoint string) string {
+	if prefix != "" {
+		prefix = url.PathEscape(prefix)
+	}
+
+	return path.Join(snowthapi.PathGraphite,
+		strconv.FormatInt(accountID, 10), prefix, endpoint)
+}
+
+// GraphiteFindMetrics retrieves the Graphite metric tree nodes matching a
+// Graphite style query, such as "servers.*.cpu", for an account. If the
+// prefix is not empty, it is prepended to the query, and the metric names of
+// results are relative to it.
+func (sc *SnowthClient) GraphiteFindMetrics(accountID int64,
+	prefix, query string, nodes ...*SnowthNode) ([]GraphiteMetric, error) {
+	return sc.GraphiteFindMetricsContext(context.Background(), accountID,
+		prefix, query, nodes...)
+}
+
+// GraphiteFindMetricsContext is the context aware version of
+// GraphiteFindMetrics.
+func (sc *SnowthClient) GraphiteFindMetricsContext(ctx context.Context,
+	accountID int64, prefix, query string,
+	nodes ...*SnowthNode) ([]GraphiteMetric, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	r := []GraphiteMetric{}
+	body, _, err := sc.doRequest(ctx, node, sc.activeNodeIDs(), "GET",
+		graphitePath(accountID, prefix, "metrics/find")+"?query="+
+			url.QueryEscape(query), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	return r, nil
+}
+
+// graphiteSeriesQuery values are the request bodies of Graphite series
+// requests.
+type graphiteSeriesQuery struct {
+	Start int64    `json:"start"`
+	End   int64    `json:"end"`
+	Names []string `json:"names"`
+}
+
+// GraphiteGetSeries retrieves the data of Graphite series for an account,
+// between the start and end times. The names are leaf metric names returned
+// by GraphiteFindMetrics, which are relative to the prefix, if it is not
+// empty.
+func (sc *SnowthClient) GraphiteGetSeries(accountID int64, prefix string,
+	start, end time.Time, names []string,
+	nodes ...*SnowthNode) (*GraphiteSeries, error) {
+	return sc.GraphiteGetSeriesContext(context.Background(), accountID,
+		prefix, start, end, names, nodes...)
+}
+
+// GraphiteGetSeriesContext is the context aware version of
+// GraphiteGetSeries.
+func (sc *SnowthClient) GraphiteGetSeriesContext(ctx context.Context,
+	accountID int64, prefix string, start, end time.Time, names []string,
+	nodes ...*SnowthNode) (*GraphiteSeries, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("graphite series names cannot be empty")
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	buf, err := encodeJSON(&graphiteSeriesQuery{
+		Start: start.Unix(),
+		End:   end.Unix(),
+		Names: names,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	r := &GraphiteSeries{}
+	body, _, err := sc.doRequest(ctx, node, sc.activeNodeIDs(), "POST",
+		graphitePath(accountID, prefix, "series_multi"), buf, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,177 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const graphiteFindTestData = `[
+	{
+		"leaf": false,
+		"name": "servers.web1"
+	},
+	{
+		"leaf": true,
+		"name": "servers.web1.cpu",
+		"leaf_data": {
+			"uuid": "11223344-5566-7788-9900-aabbccddeeff",
+			"name": "cpu",
+			"egress_function": "avg"
+		}
+	}
+]`
+
+const graphiteSeriesTestData = `{
+	"from": 1529509020,
+	"until": 1529509200,
+	"step": 60,
+	"series": {
+		"servers.web1.cpu": [1.5, null, 3]
+	}
+}`
+
+func TestGraphiteFindMetrics(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/graphite/1/pre/metrics/find?query=servers.%2A" ||
+			r.RequestURI == "/graphite/1/metrics/find?query=servers.%2A" {
+			_, _ = w.Write([]byte(graphiteFindTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.GraphiteFindMetrics(1, "pre", "servers.*", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("Expected length: 2, got: %v", len(res))
+	}
+
+	if res[0].Leaf || res[0].Name != "servers.web1" || res[0].LeafData != nil {
+		t.Errorf("Unexpected branch: %+v", res[0])
+	}
+
+	exp := &GraphiteLeafData{
+		UUID:           "11223344-5566-7788-9900-aabbccddeeff",
+		Name:           "cpu",
+		EgressFunction: "avg",
+	}
+
+	if !res[1].Leaf || !reflect.DeepEqual(res[1].LeafData, exp) {
+		t.Errorf("Expected leaf data: %+v, got: %+v", exp, res[1].LeafData)
+	}
+
+	if _, err := sc.GraphiteFindMetrics(1, "", "servers.*", node); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGraphiteGetSeries(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/graphite/1/series_multi" && r.Method == "POST" {
+			q := graphiteSeriesQuery{}
+			if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+				t.Error("Unable to decode JSON data")
+			}
+
+			exp := graphiteSeriesQuery{
+				Start: 1529509020,
+				End:   1529509200,
+				Names: []string{"servers.web1.cpu"},
+			}
+
+			if !reflect.DeepEqual(q, exp) {
+				t.Errorf("Expected query: %+v, got: %+v", exp, q)
+			}
+
+			_, _ = w.Write([]byte(graphiteSeriesTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	res, err := sc.GraphiteGetSeries(1, "", time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), []string{"servers.web1.cpu"},
+		&SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Step != 60 {
+		t.Errorf("Expected step: 60, got: %v", res.Step)
+	}
+
+	v := res.Series["servers.web1.cpu"]
+	if len(v) != 3 || v[0] == nil || *v[0] != 1.5 || v[1] != nil {
+		t.Errorf("Unexpected series values: %v", v)
+	}
+
+	ts := res.Times()
+	if len(ts) != 3 || ts[2].Unix() != 1529509140 {
+		t.Errorf("Unexpected series times: %v", ts)
+	}
+
+	if _, err := sc.GraphiteGetSeries(1, "", time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), nil, &SnowthNode{url: u}); err == nil {
+		t.Error("Expected error for empty names")
+	}
+}
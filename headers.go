@@ -0,0 +1,157 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// headersKey is the context key used to store per request header overrides.
+type headersKey struct{}
+
+// WithHeaders returns a copy of a context which will cause all requests made
+// using it to be sent with the specified headers. These headers replace any
+// default headers of the client, and any headers of the same name set by the
+// request itself. A header with no values removes the header from requests.
+// Headers added by earlier calls to WithHeaders on the context are retained,
+// unless they are replaced.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	hdr := headersFromContext(ctx).Clone()
+	if hdr == nil {
+		hdr = http.Header{}
+	}
+
+	for k, v := range h {
+		hdr[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+
+	return context.WithValue(ctx, headersKey{}, hdr)
+}
+
+// headersFromContext returns the header overrides attached to a context using
+// WithHeaders, or nil if there are none.
+func headersFromContext(ctx context.Context) http.Header {
+	if ctx == nil {
+		return nil
+	}
+
+	h, _ := ctx.Value(headersKey{}).(http.Header)
+	return h
+}
+
+// userAgent returns a User-Agent header value identifying an application and
+// its version.
+func userAgent(app, version string) (string, error) {
+	if app == "" || strings.ContainsAny(app, " \t/") ||
+		strings.ContainsAny(version, " \t/") {
+		return "", fmt.Errorf("invalid user agent value")
+	}
+
+	if version == "" {
+		return app, nil
+	}
+
+	return app + "/" + version, nil
+}
+
+// cloneHeaders returns a copy of a header set with canonical header names.
+func cloneHeaders(h http.Header) http.Header {
+	res := make(http.Header, len(h))
+	for k, v := range h {
+		res[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+	}
+
+	return res
+}
+
+// Headers gets the default headers sent with every request.
+func (c *Config) Headers() http.Header {
+	c.RLock()
+	defer c.RUnlock()
+	return cloneHeaders(c.headers)
+}
+
+// SetHeaders sets the default headers sent with every request, such as
+// headers used for request attribution or rate limit exemptions. Headers set
+// by a request itself take precedence over these defaults.
+func (c *Config) SetHeaders(h http.Header) {
+	c.Lock()
+	defer c.Unlock()
+	c.headers = cloneHeaders(h)
+}
+
+// UserAgent gets the User-Agent header sent with every request which does not
+// otherwise set one. If this is empty, the Go HTTP client default is used.
+func (c *Config) UserAgent() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.userAgent
+}
+
+// SetUserAgent sets the User-Agent header sent with every request, in the form
+// app/version, identifying the application using the client. The version may
+// be empty.
+func (c *Config) SetUserAgent(app, version string) error {
+	ua, err := userAgent(app, version)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	c.userAgent = ua
+	return nil
+}
+
+// SetHeaders sets the default headers sent with every request. Headers set by
+// a request itself take precedence over these defaults.
+func (sc *SnowthClient) SetHeaders(h http.Header) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.headers = cloneHeaders(h)
+}
+
+// SetUserAgent sets the User-Agent header sent with every request, in the form
+// app/version, identifying the application using the client. The version may
+// be empty.
+func (sc *SnowthClient) SetUserAgent(app, version string) error {
+	ua, err := userAgent(app, version)
+	if err != nil {
+		return err
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+	sc.userAgent = ua
+	return nil
+}
+
+// applyHeaders adds the default headers and User-Agent of the client which
+// are not set by a request, and then replaces the request headers with any
+// header overrides from the request context.
+func (sc *SnowthClient) applyHeaders(r *http.Request) {
+	sc.RLock()
+	for k, v := range sc.headers {
+		if _, ok := r.Header[k]; !ok {
+			r.Header[k] = append([]string(nil), v...)
+		}
+	}
+
+	if sc.userAgent != "" && r.Header.Get(snowthapi.HeaderUserAgent) == "" {
+		r.Header.Set(snowthapi.HeaderUserAgent, sc.userAgent)
+	}
+
+	sc.RUnlock()
+	for k, v := range headersFromContext(r.Context()) {
+		if len(v) == 0 {
+			r.Header.Del(k)
+			continue
+		}
+
+		r.Header[k] = append([]string(nil), v...)
+	}
+}
@@ -0,0 +1,150 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserAgent(t *testing.T) {
+	t.Parallel()
+	if ua, err := userAgent("app", "1.2.3"); err != nil || ua != "app/1.2.3" {
+		t.Errorf("Expected user agent: app/1.2.3, got: %v, %v", ua, err)
+	}
+
+	if ua, err := userAgent("app", ""); err != nil || ua != "app" {
+		t.Errorf("Expected user agent: app, got: %v, %v", ua, err)
+	}
+
+	for _, v := range [][2]string{{"", "1"}, {"my app", "1"}, {"a/b", "1"},
+		{"app", "1 2"}} {
+		if _, err := userAgent(v[0], v[1]); err == nil {
+			t.Errorf("Expected error for user agent: %v", v)
+		}
+	}
+}
+
+func TestConfigHeaders(t *testing.T) {
+	t.Parallel()
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h := http.Header{"x-team": []string{"metrics"}}
+	cfg.SetHeaders(h)
+	h.Set("X-Team", "changed")
+	if v := cfg.Headers().Get("X-Team"); v != "metrics" {
+		t.Errorf("Expected header: metrics, got: %v", v)
+	}
+
+	if err := cfg.SetUserAgent("", "1"); err == nil {
+		t.Error("Expected error for invalid user agent")
+	}
+
+	if err := cfg.SetUserAgent("app", "1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.UserAgent() != "app/1.0" {
+		t.Errorf("Expected user agent: app/1.0, got: %v", cfg.UserAgent())
+	}
+}
+
+func TestRequestHeaders(t *testing.T) {
+	t.Parallel()
+	var last http.Header
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			last = r.Header.Clone()
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetHeaders(http.Header{
+		"X-Team":   []string{"metrics"},
+		"X-Bypass": []string{"default"},
+	})
+	if err := cfg.SetUserAgent("app", "1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := last.Get("User-Agent"); v != "app/1.0" {
+		t.Errorf("Expected user agent: app/1.0, got: %v", v)
+	}
+
+	if v := last.Get("X-Team"); v != "metrics" {
+		t.Errorf("Expected X-Team: metrics, got: %v", v)
+	}
+
+	node := sc.GetActiveNode()
+	if _, _, err := sc.DoRequest(node, "GET", "/stats.json", nil,
+		http.Header{"X-Team": []string{"request"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := last["X-Team"]; len(v) != 1 || v[0] != "request" {
+		t.Errorf("Expected X-Team: [request], got: %v", v)
+	}
+
+	ctx := WithHeaders(context.Background(), http.Header{
+		"x-bypass": []string{"call"},
+	})
+	ctx = WithHeaders(ctx, http.Header{"User-Agent": []string{"batch/2"}})
+	if _, _, err := sc.DoRequestContext(ctx, node, "GET", "/stats.json", nil,
+		http.Header{"X-Bypass": []string{"request"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := last["X-Bypass"]; len(v) != 1 || v[0] != "call" {
+		t.Errorf("Expected X-Bypass: [call], got: %v", v)
+	}
+
+	if v := last.Get("User-Agent"); v != "batch/2" {
+		t.Errorf("Expected user agent: batch/2, got: %v", v)
+	}
+
+	sc.SetHeaders(nil)
+	if err := sc.SetUserAgent("other", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := last.Get("X-Team"); v != "" {
+		t.Errorf("Expected no X-Team header, got: %v", v)
+	}
+
+	if v := last.Get("User-Agent"); v != "other" {
+		t.Errorf("Expected user agent: other, got: %v", v)
+	}
+}
@@ -0,0 +1,106 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// SetHedgeDelay sets the duration after which a read request which has not yet
+// completed is also sent to a second active node. The first successful
+// response is used and the other request is cancelled. A value of zero
+// disables hedged reads.
+func (sc *SnowthClient) SetHedgeDelay(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.hedgeDelay = d
+}
+
+// hedgeNode returns an active node, other than the specified node, to which a
+// hedged request can be sent, or nil if no other node is active.
+func (sc *SnowthClient) hedgeNode(node *SnowthNode) *SnowthNode {
+	for _, n := range sc.ListActiveNodes() {
+		if n == nil || n.GetURL() == nil {
+			continue
+		}
+
+		if node == nil || node.GetURL() == nil ||
+			n.GetURL().String() != node.GetURL().String() {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// hedgeResult values contain the result of one request of a hedged read.
+type hedgeResult struct {
+	body io.Reader
+	hdr  http.Header
+	err  error
+}
+
+// doHedged sends a request to a node. If the request is a read operation and
+// hedged reads are enabled, the request is also sent to a second node when the
+// first request has not completed after the hedge delay, and the first
+// successful response is returned.
+func (sc *SnowthClient) doHedged(ctx context.Context, node *SnowthNode,
+	method, url string, body []byte,
+	headers http.Header) (io.Reader, http.Header, error) {
+	sc.RLock()
+	delay := sc.hedgeDelay
+	sc.RUnlock()
+	if delay <= 0 || classifyOperation(method, url) != opRead {
+		return sc.do(ctx, node, method, url, bytes.NewBuffer(body), headers)
+	}
+
+	alt := sc.hedgeNode(node)
+	if alt == nil {
+		return sc.do(ctx, node, method, url, bytes.NewBuffer(body), headers)
+	}
+
+	hctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan hedgeResult, 2)
+	send := func(n *SnowthNode, u string) {
+		b, h, err := sc.do(hctx, n, method, u, bytes.NewBuffer(body), headers)
+		results <- hedgeResult{body: b, hdr: h, err: err}
+	}
+
+	go send(node, url)
+	timer := sc.getClock().After(delay)
+	pending := 1
+	var res hedgeResult
+	for pending > 0 {
+		select {
+		case res = <-results:
+			pending--
+			if res.err == nil {
+				return res.body, res.hdr, nil
+			}
+
+			if timer == nil {
+				continue
+			}
+
+			return res.body, res.hdr, res.err
+		case <-timer:
+			timer = nil
+			u := url
+			if node != nil && node.GetURL() != nil {
+				u = strings.Replace(url, node.GetURL().String(), "", 1)
+			}
+
+			sc.LogDebugf("gosnowth sending hedged request: %s %s %v",
+				method, u, alt)
+			pending++
+			go send(alt, u)
+		}
+	}
+
+	return res.body, res.hdr, res.err
+}
@@ -0,0 +1,91 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHedgedRead(t *testing.T) {
+	var slowReads, fastReads int32
+	handler := func(slow bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.RequestURI == "/state" {
+				_, _ = w.Write([]byte(stateTestData))
+				return
+			}
+
+			if r.RequestURI == "/stats.json" {
+				_, _ = w.Write([]byte(statsTestData))
+				return
+			}
+
+			if strings.HasPrefix(r.RequestURI, "/raw/") {
+				if slow {
+					atomic.AddInt32(&slowReads, 1)
+					select {
+					case <-r.Context().Done():
+					case <-time.After(500 * time.Millisecond):
+					}
+
+					_, _ = w.Write([]byte(`[[1000,2]]`))
+					return
+				}
+
+				atomic.AddInt32(&fastReads, 1)
+				_, _ = w.Write([]byte(`[[1000,1]]`))
+				return
+			}
+
+			t.Errorf("Unexpected request: %v", r)
+			w.WriteHeader(500)
+		}
+	}
+
+	slow := httptest.NewServer(handler(true))
+	defer slow.Close()
+	fast := httptest.NewServer(handler(false))
+	defer fast.Close()
+	sc, err := NewSnowthClient(false, slow.URL, fast.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(slow.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	sc.SetHedgeDelay(20 * time.Millisecond)
+	start := time.Now()
+	res, err := sc.ReadRawNumericValues(time.Unix(0, 0), time.Unix(2000, 0),
+		"11223344-5566-7788-9900-aabbccddeeff", "test", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if time.Since(start) >= 500*time.Millisecond {
+		t.Errorf("Expected hedged response before slow response")
+	}
+
+	if len(res) != 1 || res[0].Value != 1 {
+		t.Errorf("Expected hedged response value: 1, got: %v", res)
+	}
+
+	if atomic.LoadInt32(&slowReads) != 1 || atomic.LoadInt32(&fastReads) != 1 {
+		t.Errorf("Expected one read from each node, got: %v, %v",
+			atomic.LoadInt32(&slowReads), atomic.LoadInt32(&fastReads))
+	}
+
+	sc.SetHedgeDelay(0)
+	if alt := sc.hedgeNode(node); alt == nil ||
+		alt.GetURL().String() != fast.URL {
+		t.Errorf("Expected hedge node: %v, got: %v", fast.URL, alt)
+	}
+}
@@ -4,14 +4,21 @@ package gosnowth
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/circonus-labs/circonusllhist"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // HistogramValue values are individual data points of a histogram metric.
@@ -80,7 +87,73 @@ func (hv *HistogramValue) Timestamp() string {
 	return formatTimestamp(hv.Time)
 }
 
-// ReadHistogramValues reads histogram data from a node.
+// Bins returns the bins of the HistogramValue histogram, decoded from the
+// keys of its data, in ascending order of their values. An error is returned
+// if a key is not a valid IRONdb histogram bin.
+func (hv *HistogramValue) Bins() ([]HistogramBin, error) {
+	bins := make([]HistogramBin, 0, len(hv.Data))
+	for k, c := range hv.Data {
+		hb, err := parseHistogramKey(k)
+		if err != nil {
+			return nil, err
+		}
+
+		if c < 0 {
+			return nil, fmt.Errorf("invalid histogram bin count: %s: %d",
+				k, c)
+		}
+
+		hb.Count = uint64(c)
+		bins = append(bins, hb)
+	}
+
+	sort.Slice(bins, func(i, j int) bool {
+		return bins[i].lower() < bins[j].lower()
+	})
+
+	return bins, nil
+}
+
+// Histogram returns the HistogramValue data as a histogram.
+func (hv *HistogramValue) Histogram() (*circonusllhist.Histogram, error) {
+	bins, err := hv.Bins()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewHistogramFromBins(bins)
+}
+
+// parseHistogramKey parses a histogram bin key in the +23e-004 format used by
+// IRONdb histogram reads. The key contains the two significant digits of the
+// bin value, and the exponent of the value of its last digit.
+func parseHistogramKey(k string) (HistogramBin, error) {
+	hb := HistogramBin{}
+	i := strings.IndexAny(k, "eE")
+	if i < 0 {
+		return hb, fmt.Errorf("invalid histogram bin: %s", k)
+	}
+
+	v, err := strconv.ParseInt(k[:i], 10, 8)
+	if err != nil || v != 0 && (v < 10 && v > -10 || v > 99 || v < -99) {
+		return hb, fmt.Errorf("invalid histogram bin value: %s", k)
+	}
+
+	e, err := strconv.ParseInt(k[i+1:], 10, 8)
+	if err != nil || e >= math.MaxInt8 {
+		return hb, fmt.Errorf("invalid histogram bin exponent: %s", k)
+	}
+
+	hb.Value = int8(v)
+	if v != 0 {
+		hb.Exponent = int8(e + 1)
+	}
+
+	return hb, nil
+}
+
+// ReadHistogramValues reads histogram data from a node. The bins of the
+// values read can be decoded using their Bins method.
 func (sc *SnowthClient) ReadHistogramValues(
 	uuid, metric string, period time.Duration,
 	start, end time.Time, nodes ...*SnowthNode) ([]HistogramValue, error) {
@@ -97,7 +170,7 @@ func (sc *SnowthClient) ReadHistogramValuesContext(ctx context.Context,
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(uuid, metric))
+		node = sc.readNode(ctx, uuid, metric)
 	}
 
 	startTS := start.Unix() - start.Unix()%int64(period.Seconds())
@@ -105,7 +178,7 @@ func (sc *SnowthClient) ReadHistogramValuesContext(ctx context.Context,
 		int64(period.Seconds())
 	r := []HistogramValue{}
 	body, _, err := sc.DoRequestContext(ctx, node, "GET",
-		path.Join("/histogram", strconv.FormatInt(startTS, 10),
+		path.Join(snowthapi.PathHistogram, strconv.FormatInt(startTS, 10),
 			strconv.FormatInt(endTS, 10),
 			strconv.FormatInt(int64(period.Seconds()), 10), uuid,
 			url.QueryEscape(metric)), nil, nil)
@@ -113,14 +186,202 @@ func (sc *SnowthClient) ReadHistogramValuesContext(ctx context.Context,
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
 	return r, nil
 }
 
-// HistogramData values represent histogram data records in IRONdb.
+// HistogramWindow values contain the merged histogram data of a metric
+// during one window of time.
+type HistogramWindow struct {
+	Time      time.Time
+	Period    time.Duration
+	Histogram *circonusllhist.Histogram
+}
+
+// ReadHistogramWindows reads histogram data from a node, merged into windows
+// of the specified duration, which must be a multiple of the stored rollup
+// period read. One histogram is returned for each window between the start
+// and end times, which is empty if the metric has no data in that window.
+func (sc *SnowthClient) ReadHistogramWindows(uuid, metric string,
+	period, window time.Duration, start, end time.Time,
+	nodes ...*SnowthNode) ([]HistogramWindow, error) {
+	return sc.ReadHistogramWindowsContext(context.Background(), uuid, metric,
+		period, window, start, end, nodes...)
+}
+
+// ReadHistogramWindowsContext is the context aware version of
+// ReadHistogramWindows.
+func (sc *SnowthClient) ReadHistogramWindowsContext(ctx context.Context,
+	uuid, metric string, period, window time.Duration, start, end time.Time,
+	nodes ...*SnowthNode) ([]HistogramWindow, error) {
+	ps := int64(period / time.Second)
+	if ps <= 0 {
+		return nil, fmt.Errorf("invalid histogram period: %v", period)
+	}
+
+	ws := int64(window / time.Second)
+	if ws <= 0 || ws%ps != 0 {
+		return nil, fmt.Errorf("invalid histogram window: %v: must be a "+
+			"multiple of the period", window)
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.readNode(ctx, uuid, metric)
+	}
+
+	startTS := start.Unix() - start.Unix()%ws
+	endTS := end.Unix() - end.Unix()%ws + ws
+	r := []HistogramValue{}
+	body, _, err := sc.doRequest(ctx, node, sc.metricOwnerIDs(uuid, metric),
+		"GET", fmt.Sprintf("%s?rollup_span=%ds",
+			path.Join(snowthapi.PathHistogram,
+				strconv.FormatInt(startTS, 10), strconv.FormatInt(endTS, 10),
+				strconv.FormatInt(ps, 10), uuid, url.QueryEscape(metric)),
+			ws), nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	res := make([]HistogramWindow, (endTS-startTS)/ws)
+	for i := range res {
+		res[i] = HistogramWindow{
+			Time:      time.Unix(startTS+int64(i)*ws, 0),
+			Period:    time.Duration(ws) * time.Second,
+			Histogram: circonusllhist.New(),
+		}
+	}
+
+	// Values are merged here as well, in case the node returned data at the
+	// stored period rather than merged into windows.
+	for _, v := range r {
+		ts := v.Time.Unix()
+		if ts < startTS || ts >= endTS {
+			continue
+		}
+
+		h, err := v.Histogram()
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode histogram at %s: %w",
+				v.Timestamp(), err)
+		}
+
+		res[(ts-startTS)/ws].Histogram.Merge(h)
+	}
+
+	return res, nil
+}
+
+// HistogramBin values are bins of a log linear histogram. Each bin contains
+// the count of samples with values in the range beginning at Value / 10 *
+// 10^Exponent, where Value is the two significant digits of the bin, with the
+// sign of the range.
+type HistogramBin struct {
+	Value    int8
+	Exponent int8
+	Count    uint64
+}
+
+// lower returns the value at the beginning of the range of the bin.
+func (hb HistogramBin) lower() float64 {
+	return float64(hb.Value) / 10 * math.Pow10(int(hb.Exponent))
+}
+
+// NewHistogramFromBins creates a histogram containing a list of bins. An
+// error is returned if a bin is invalid.
+func NewHistogramFromBins(bins []HistogramBin) (*circonusllhist.Histogram,
+	error) {
+	h := circonusllhist.New()
+	for _, b := range bins {
+		if b.Value != 0 && (b.Value < 10 && b.Value > -10 ||
+			b.Value > 99 || b.Value < -99) {
+			return nil, fmt.Errorf("invalid histogram bin value: %d", b.Value)
+		}
+
+		if b.Count > math.MaxInt64 {
+			return nil, fmt.Errorf("invalid histogram bin count: %d", b.Count)
+		}
+
+		if b.Count == 0 {
+			continue
+		}
+
+		if err := h.RecordIntScales(int64(b.Value), int(b.Exponent)-1,
+			int64(b.Count)); err != nil {
+			return nil, fmt.Errorf("invalid histogram bin: %w", err)
+		}
+	}
+
+	return h, nil
+}
+
+// decodeHistogramBins decodes the bins of a histogram in the serialized
+// binary form used by IRONdb.
+func decodeHistogramBins(b []byte) ([]HistogramBin, error) {
+	if len(b) < 2 {
+		return nil, fmt.Errorf("histogram data too short")
+	}
+
+	n := int(int16(binary.BigEndian.Uint16(b)))
+	if n < 0 {
+		return nil, fmt.Errorf("invalid histogram bin count: %d", n)
+	}
+
+	b = b[2:]
+	bins := make([]HistogramBin, 0, n)
+	for i := 0; i < n; i++ {
+		if len(b) < 3 {
+			return nil, fmt.Errorf("histogram data too short")
+		}
+
+		sz := int(b[2]) + 1
+		if sz > 8 || len(b) < 3+sz {
+			return nil, fmt.Errorf("invalid histogram bin %d", i)
+		}
+
+		hb := HistogramBin{Value: int8(b[0]), Exponent: int8(b[1])}
+		for j := 0; j < sz; j++ {
+			hb.Count |= uint64(b[3+j]) << (uint(j) * 8)
+		}
+
+		bins = append(bins, hb)
+		b = b[3+sz:]
+	}
+
+	return bins, nil
+}
+
+// NewHistogramFromBase64 creates a histogram from its base64 encoded
+// serialized form, as used by IRONdb.
+func NewHistogramFromBase64(s string) (*circonusllhist.Histogram, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 histogram: %w", err)
+	}
+
+	bins, err := decodeHistogramBins(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 histogram: %w", err)
+	}
+
+	return NewHistogramFromBins(bins)
+}
+
+// HistogramData values represent histogram data records in IRONdb. The
+// histogram written can be specified as a histogram value, as a pre-encoded
+// base64 string in the Encoded value, or as a list of bins in the Bins value.
+// Only one of these may be set. The Offset value is the start of the period,
+// in seconds since the epoch, and the Period value is its duration, in
+// seconds.
 type HistogramData struct {
 	AccountID int64                     `json:"account_id"`
 	Metric    string                    `json:"metric"`
@@ -129,10 +390,40 @@ type HistogramData struct {
 	Offset    int64                     `json:"offset"`
 	Period    int64                     `json:"period"`
 	Histogram *circonusllhist.Histogram `json:"histogram"`
+	Encoded   string                    `json:"-"`
+	Bins      []HistogramBin            `json:"-"`
+}
+
+// resolveHistogram sets the histogram value of the record from its encoded
+// histogram or bins, if either is set.
+func (hd *HistogramData) resolveHistogram() error {
+	set := 0
+	for _, ok := range []bool{hd.Histogram != nil, hd.Encoded != "",
+		hd.Bins != nil} {
+		if ok {
+			set++
+		}
+	}
+
+	if set > 1 {
+		return fmt.Errorf("only one of Histogram, Encoded or Bins may be set")
+	}
+
+	var err error
+	switch {
+	case hd.Encoded != "":
+		hd.Histogram, err = NewHistogramFromBase64(hd.Encoded)
+	case hd.Bins != nil:
+		hd.Histogram, err = NewHistogramFromBins(hd.Bins)
+	}
+
+	return err
 }
 
 // WriteHistogram sends a list of histogram data values to be written
-// to an IRONdb node.
+// to an IRONdb node. If the encoded data exceeds the maximum write payload
+// size, it is written in multiple requests. An error is returned if the
+// encoded histogram or bins of any record are invalid.
 func (sc *SnowthClient) WriteHistogram(data []HistogramData,
 	nodes ...*SnowthNode) error {
 	return sc.WriteHistogramContext(context.Background(), data, nodes...)
@@ -141,11 +432,33 @@ func (sc *SnowthClient) WriteHistogram(data []HistogramData,
 // WriteHistogramContext is the context aware version of WriteHistogram.
 func (sc *SnowthClient) WriteHistogramContext(ctx context.Context,
 	data []HistogramData, nodes ...*SnowthNode) error {
+	data = append([]HistogramData(nil), data...)
+	names := make([]string, len(data))
+	for i := range data {
+		if err := data[i].resolveHistogram(); err != nil {
+			return fmt.Errorf("histogram data %d: %w", i, err)
+		}
+
+		data[i].Metric = CanonicalMetricName(data[i].Metric)
+		names[i] = data[i].Metric
+	}
+
+	if err := sc.checkCardinality(names, nil); err != nil {
+		return err
+	}
+
+	if level := sc.getWriteConsistency(ctx); level != WriteConsistencyOne &&
+		(len(nodes) == 0 || nodes[0] == nil) {
+		return sc.writeConsistent(ctx, level, snowthapi.PathHistogramWrite, len(data),
+			func(i int) interface{} { return data[i] },
+			func(i int) (string, string) { return data[i].ID, data[i].Metric })
+	}
+
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else if len(data) > 0 {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(data[0].ID,
+		node = sc.GetActiveNode(sc.FindMetricNodeIDsContext(ctx, data[0].ID,
 			data[0].Metric))
 	}
 
@@ -154,6 +467,6 @@ func (sc *SnowthClient) WriteHistogramContext(ctx context.Context,
 		return fmt.Errorf("failed to encode HistogramData for write: %w", err)
 	}
 
-	_, _, err := sc.DoRequestContext(ctx, node, "POST", "/histogram/write", buf, nil)
-	return err
+	return sc.writeJSON(ctx, node, snowthapi.PathHistogramWrite, buf, len(data),
+		func(i int) interface{} { return data[i] })
 }
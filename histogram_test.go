@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -81,6 +82,67 @@ func TestHistogramValueMarshaling(t *testing.T) {
 	}
 }
 
+func TestHistogramValueBins(t *testing.T) {
+	t.Parallel()
+	v := []HistogramValue{}
+	err := json.NewDecoder(bytes.NewBufferString(histogramTestData)).Decode(&v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bins, err := v[0].Bins()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []HistogramBin{
+		{Value: 23, Exponent: -3, Count: 1},
+		{Value: 85, Exponent: -3, Count: 1},
+	}
+
+	if !reflect.DeepEqual(bins, exp) {
+		t.Errorf("Expected bins: %v, got: %v", exp, bins)
+	}
+
+	h, err := v[0].Histogram()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expS := []string{"H[2.3e-03]=1", "H[8.5e-03]=1"}
+	if !reflect.DeepEqual(h.DecStrings(), expS) {
+		t.Errorf("Expected histogram: %v, got: %v", expS, h.DecStrings())
+	}
+
+	hv := HistogramValue{Data: map[string]int64{
+		"-12e+001": 2,
+		"+00e+000": 1,
+		"+10e-001": 4,
+	}}
+
+	bins, err = hv.Bins()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp = []HistogramBin{
+		{Value: -12, Exponent: 2, Count: 2},
+		{Value: 0, Exponent: 0, Count: 1},
+		{Value: 10, Exponent: 0, Count: 4},
+	}
+
+	if !reflect.DeepEqual(bins, exp) {
+		t.Errorf("Expected bins: %v, got: %v", exp, bins)
+	}
+
+	for _, k := range []string{"+23", "+5e+000", "+123e+000", "+23e+x"} {
+		hv := HistogramValue{Data: map[string]int64{k: 1}}
+		if _, err := hv.Bins(); err == nil {
+			t.Errorf("Expected error for bin: %s", k)
+		}
+	}
+}
+
 func TestReadHistogramValues(t *testing.T) {
 	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
 		r *http.Request) {
@@ -201,3 +263,231 @@ func TestWriteHistogram(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestNewHistogramFromBins(t *testing.T) {
+	t.Parallel()
+	h, err := NewHistogramFromBins([]HistogramBin{
+		{Value: 12, Exponent: 0, Count: 3},
+		{Value: 23, Exponent: -3, Count: 300},
+		{Value: -45, Exponent: 2, Count: 1 << 40},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]bool{
+		"H[1.2e+00]=3":              true,
+		"H[2.3e-03]=300":            true,
+		"H[-4.5e+02]=1099511627776": true,
+	}
+
+	bins := h.DecStrings()
+	if len(bins) != len(exp) {
+		t.Fatalf("Expected bins: %v, got: %v", exp, bins)
+	}
+
+	for _, b := range bins {
+		if !exp[b] {
+			t.Errorf("Unexpected bin: %v", b)
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := h.SerializeB64(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	h2, err := NewHistogramFromBase64(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !h.Equals(h2) {
+		t.Errorf("Expected histogram: %v, got: %v", h.DecStrings(),
+			h2.DecStrings())
+	}
+
+	if _, err := NewHistogramFromBins([]HistogramBin{{Value: 5}}); err == nil {
+		t.Error("Expected error for invalid bin value")
+	}
+
+	if _, err := NewHistogramFromBase64("invalid!"); err == nil {
+		t.Error("Expected error for invalid base64 histogram")
+	}
+}
+
+func TestWriteHistogramEncoded(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/histogram/write" {
+			rb := []struct {
+				Histogram string `json:"histogram"`
+			}{}
+			if err := json.NewDecoder(r.Body).Decode(&rb); err != nil {
+				t.Error("Unable to decode JSON data")
+			}
+
+			if len(rb) != 2 {
+				t.Errorf("Expected records: 2, got: %v", len(rb))
+				return
+			}
+
+			exp := [][]string{
+				{"H[1.2e+00]=3"},
+				{"H[1.2e+00]=1", "H[1.3e+00]=2"},
+			}
+
+			for i, d := range rb {
+				h, err := NewHistogramFromBase64(d.Histogram)
+				if err != nil {
+					t.Error(err)
+					continue
+				}
+
+				if !reflect.DeepEqual(h.DecStrings(), exp[i]) {
+					t.Errorf("Expected histogram: %v, got: %v", exp[i],
+						h.DecStrings())
+				}
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	h, err := NewHistogramFromBins([]HistogramBin{{Value: 12, Count: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := h.SerializeB64(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	data := []HistogramData{{
+		Metric:  "example1",
+		ID:      "ae0f7f90-2a6b-481c-9cf5-21a31837020e",
+		Offset:  1408724400,
+		Period:  60,
+		Encoded: buf.String(),
+	}, {
+		Metric: "example2",
+		ID:     "ae0f7f90-2a6b-481c-9cf5-21a31837020e",
+		Offset: 1408724400,
+		Period: 60,
+		Bins:   []HistogramBin{{Value: 12, Count: 1}, {Value: 13, Count: 2}},
+	}}
+
+	if err := sc.WriteHistogram(data, node); err != nil {
+		t.Fatal(err)
+	}
+
+	data[0].Histogram = h
+	if err := sc.WriteHistogram(data, node); err == nil {
+		t.Error("Expected error for multiple histogram values")
+	}
+}
+
+func TestReadHistogramWindows(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		u := "/histogram/1556290800/1556292000/300/" +
+			"ae0f7f90-2a6b-481c-9cf5-21a31837020e/example1?rollup_span=600s"
+		if r.RequestURI == u {
+			_, _ = w.Write([]byte(histogramTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.ReadHistogramWindows(
+		"ae0f7f90-2a6b-481c-9cf5-21a31837020e", "example1",
+		300*time.Second, 600*time.Second, time.Unix(1556290800, 0),
+		time.Unix(1556291400, 0), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("Expected length: 2, got: %v", len(res))
+	}
+
+	if res[0].Time.Unix() != 1556290800 || res[1].Time.Unix() != 1556291400 {
+		t.Errorf("Expected times: [1556290800 1556291400], got: [%v %v]",
+			res[0].Time.Unix(), res[1].Time.Unix())
+	}
+
+	if res[0].Period != 600*time.Second {
+		t.Errorf("Expected period: 10m0s, got: %v", res[0].Period)
+	}
+
+	exp := []string{"H[2.2e-03]=1", "H[2.3e-03]=3", "H[3.0e-03]=1",
+		"H[8.5e-03]=1", "H[3.9e-02]=1"}
+	if !reflect.DeepEqual(res[0].Histogram.DecStrings(), exp) {
+		t.Errorf("Expected histogram: %v, got: %v", exp,
+			res[0].Histogram.DecStrings())
+	}
+
+	if len(res[1].Histogram.DecStrings()) != 0 {
+		t.Errorf("Expected empty histogram, got: %v",
+			res[1].Histogram.DecStrings())
+	}
+
+	if _, err := sc.ReadHistogramWindows(
+		"ae0f7f90-2a6b-481c-9cf5-21a31837020e", "example1",
+		300*time.Second, 450*time.Second, time.Unix(1556290800, 0),
+		time.Unix(1556291400, 0), node); err == nil {
+		t.Error("Expected error for invalid window")
+	}
+}
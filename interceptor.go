@@ -0,0 +1,58 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// RoundTripFunc values send a request to an IRONdb node and return the
+// response.
+type RoundTripFunc func(r *http.Request) (*http.Response, error)
+
+// Interceptor values wrap the function used to send requests, allowing
+// requests to be modified or replaced before they are sent, and responses or
+// errors to be inspected or replaced before they are handled by the client.
+// An interceptor may send a request more than once by calling next again, or
+// return a response without calling next at all.
+type Interceptor func(next RoundTripFunc) RoundTripFunc
+
+// Use registers interceptors which are applied to every request sent by the
+// client, including every retry. Interceptors are applied in the order they
+// are registered, so that the first registered interceptor sees the request
+// first and the response last. Interceptors are applied after the request
+// function set by SetRequestFunc().
+func (sc *SnowthClient) Use(interceptors ...Interceptor) {
+	sc.Lock()
+	defer sc.Unlock()
+	for _, ic := range interceptors {
+		if ic != nil {
+			sc.interceptors = append(sc.interceptors, ic)
+		}
+	}
+}
+
+// roundTrip returns the function used to send requests with an HTTP client,
+// wrapped by the registered interceptors.
+func (sc *SnowthClient) roundTrip(cli httpClient) RoundTripFunc {
+	sc.RLock()
+	ics := sc.interceptors
+	sc.RUnlock()
+	rt := RoundTripFunc(cli.Do)
+	if len(ics) == 0 {
+		return rt
+	}
+
+	for i := len(ics) - 1; i >= 0; i-- {
+		rt = ics[i](rt)
+	}
+
+	return func(r *http.Request) (*http.Response, error) {
+		resp, err := rt(r)
+		if err == nil && resp == nil {
+			return nil, fmt.Errorf("no response returned by interceptor")
+		}
+
+		return resp, err
+	}
+}
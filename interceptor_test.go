@@ -0,0 +1,109 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	t.Parallel()
+	var auth string
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			auth = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	calls := []string{}
+	trace := func(name string) Interceptor {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				calls = append(calls, name+" request")
+				resp, err := next(r)
+				calls = append(calls, name+" response")
+				return resp, err
+			}
+		}
+	}
+
+	sc.Use(trace("outer"), nil, trace("inner"),
+		func(next RoundTripFunc) RoundTripFunc {
+			return func(r *http.Request) (*http.Response, error) {
+				r.Header.Set("Authorization", "Bearer token")
+				return next(r)
+			}
+		})
+
+	node := sc.GetActiveNode()
+	if _, err := sc.GetStats(node); err != nil {
+		t.Fatal(err)
+	}
+
+	if auth != "Bearer token" {
+		t.Errorf("Expected authorization: Bearer token, got: %v", auth)
+	}
+
+	exp := "outer request,inner request,inner response,outer response"
+	if strings.Join(calls, ",") != exp {
+		t.Errorf("Expected calls: %v, got: %v", exp, calls)
+	}
+
+	sc.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body: ioutil.NopCloser(bytes.NewBufferString(
+					`{"application":"cached"}`)),
+				Request: r,
+			}, nil
+		}
+	})
+
+	auth = ""
+	stats, err := sc.GetStats(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (*stats)["application"] != "cached" || auth != "" {
+		t.Errorf("Expected intercepted response, got: %v, %v", *stats, auth)
+	}
+
+	sc, err = NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			return nil, nil
+		}
+	})
+
+	if _, err := sc.GetStats(sc.GetActiveNode()); err == nil {
+		t.Error("Expected error for missing response")
+	}
+}
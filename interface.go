@@ -0,0 +1,513 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/circonus-labs/gosnowth/fb/fetch"
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// Client values are IRONdb clients. The interface contains every public method
+// of SnowthClient, including the context aware variants, so that applications
+// can substitute a mock implementation in unit tests which do not have access
+// to a live IRONdb cluster. When built with Go 1.23 or later, the interface
+// also contains the methods returning iterators.
+type Client interface {
+	iterators
+
+	ActivateNode(node *SnowthNode)
+	ActivateNodes(nodes ...*SnowthNode)
+	ActivateTopology(hash string, node *SnowthNode) error
+	ActivateTopologyContext(ctx context.Context,
+		hash string, node *SnowthNode) error
+	AddNodes(nodes ...*SnowthNode)
+	ChecksumChunk(chunk *ManifestChunk,
+		nodes ...*SnowthNode) error
+	ChecksumChunkContext(ctx context.Context,
+		chunk *ManifestChunk, nodes ...*SnowthNode) error
+	ClearNodeRateLimit(node *SnowthNode)
+	ClearResolveCache() error
+	ClientStats() *ClientStats
+	Close() error
+	ConnectRetries() int64
+	CountTags(accountID int64, query string,
+		options *FindTagsOptions, nodes ...*SnowthNode) (*FindTagsCount, error)
+	CountTagsContext(ctx context.Context,
+		accountID int64, query string, options *FindTagsOptions,
+		nodes ...*SnowthNode) (*FindTagsCount, error)
+	DeactivateNode(node *SnowthNode)
+	DeactivateNodes(nodes ...*SnowthNode)
+	DeleteByTagQuery(accountID int64, query string,
+		dryRun bool, nodes ...*SnowthNode) (*TagQueryDeleteReport, error)
+	DeleteByTagQueryContext(ctx context.Context,
+		accountID int64, query string, dryRun bool,
+		nodes ...*SnowthNode) (*TagQueryDeleteReport, error)
+	DeleteMetricFull(accountID int64, uuid, metric string,
+		nodes ...*SnowthNode) (*DeleteReport, error)
+	DeleteMetricFullContext(ctx context.Context,
+		accountID int64, uuid, metric string,
+		nodes ...*SnowthNode) (*DeleteReport, error)
+	DeleteNumericBefore(uuid, metric string,
+		cutoff time.Time, nodes ...*SnowthNode) (*DeleteReport, error)
+	DeleteNumericBeforeContext(ctx context.Context,
+		uuid, metric string, cutoff time.Time,
+		nodes ...*SnowthNode) (*DeleteReport, error)
+	DeleteNumericRange(uuid, metric string,
+		start, end time.Time, nodes ...*SnowthNode) (*DeleteReport, error)
+	DeleteNumericRangeContext(ctx context.Context,
+		uuid, metric string, start, end time.Time,
+		nodes ...*SnowthNode) (*DeleteReport, error)
+	DeleteRawNumericPartitioned(uuid, metric string,
+		start, end time.Time, options *PartitionedDeleteOptions,
+		nodes ...*SnowthNode) (*PartitionedDeleteReport, error)
+	DeleteRawNumericPartitionedContext(
+		ctx context.Context, uuid, metric string, start, end time.Time,
+		options *PartitionedDeleteOptions,
+		nodes ...*SnowthNode) (*PartitionedDeleteReport, error)
+	DeleteRawNumericRange(uuid, metric string,
+		start, end time.Time, nodes ...*SnowthNode) error
+	DeleteRawNumericRangeContext(ctx context.Context,
+		uuid, metric string, start, end time.Time,
+		nodes ...*SnowthNode) error
+	DescribeCluster() *ClusterInfo
+	DescribeClusterContext(
+		ctx context.Context) *ClusterInfo
+	DescribeNode(nodes ...*SnowthNode) (*NodeInfo, error)
+	DescribeNodeContext(ctx context.Context,
+		nodes ...*SnowthNode) (*NodeInfo, error)
+	DoRequest(node *SnowthNode,
+		method string, url string, body io.Reader,
+		headers http.Header) (io.Reader, http.Header, error)
+	DoRequestContext(ctx context.Context, node *SnowthNode,
+		method string, url string, body io.Reader,
+		headers http.Header) (io.Reader, http.Header, error)
+	DoRequestStream(node *SnowthNode,
+		method string, url string, body io.Reader,
+		headers http.Header) (io.ReadCloser, http.Header, error)
+	DoRequestStreamContext(ctx context.Context,
+		node *SnowthNode, method string, url string, body io.Reader,
+		headers http.Header) (io.ReadCloser, http.Header, error)
+	ExecLuaExtension(name string,
+		params []ExtParam, nodes ...*SnowthNode) (map[string]interface{}, error)
+	ExecLuaExtensionContext(ctx context.Context,
+		name string, params []ExtParam,
+		nodes ...*SnowthNode) (map[string]interface{}, error)
+	FetchSeries(q *FetchQuery,
+		nodes ...*SnowthNode) (*DF4Result, error)
+	FetchSeriesContext(ctx context.Context,
+		q *FetchQuery, nodes ...*SnowthNode) (*DF4Result, error)
+	FetchValues(q *FetchQuery, nodes ...*SnowthNode) (*DF4Response, error)
+	FetchValuesByName(accountID int64, q *FetchQuery,
+		nodes ...*SnowthNode) (*DF4Response, error)
+	FetchValuesByNameContext(ctx context.Context,
+		accountID int64, q *FetchQuery,
+		nodes ...*SnowthNode) (*DF4Response, error)
+	FetchValuesContext(ctx context.Context,
+		q *FetchQuery, nodes ...*SnowthNode) (*DF4Response, error)
+	FetchValuesFb(node *SnowthNode,
+		q *fetch.FetchT) (*fetch.DF4T, error)
+	FetchValuesFbContext(ctx context.Context,
+		node *SnowthNode, q *fetch.FetchT) (*fetch.DF4T, error)
+	FetchValuesStream(q *FetchQuery,
+		fn func(head *DF4Head, c *DF4Chunk) error,
+		nodes ...*SnowthNode) (*DF4Response, error)
+	FetchValuesStreamContext(ctx context.Context,
+		q *FetchQuery, fn func(head *DF4Head, c *DF4Chunk) error,
+		nodes ...*SnowthNode) (*DF4Response, error)
+	FindMetricNodeIDs(uuid, metric string) []string
+	FindMetricNodeIDsContext(ctx context.Context,
+		uuid, metric string) []string
+	FindMetrics(accountID int64, namePattern string,
+		options *FindMetricsOptions,
+		nodes ...*SnowthNode) (*FindTagsResult, error)
+	FindMetricsContext(ctx context.Context,
+		accountID int64, namePattern string, options *FindMetricsOptions,
+		nodes ...*SnowthNode) (*FindTagsResult, error)
+	FindTagCats(accountID int64, query string,
+		nodes ...*SnowthNode) ([]string, error)
+	FindTagCatsContext(ctx context.Context,
+		accountID int64, query string, nodes ...*SnowthNode) ([]string, error)
+	FindTagCatsInfo(accountID int64, query string,
+		options *SearchOptions,
+		nodes ...*SnowthNode) ([]string, *SearchResultInfo, error)
+	FindTagCatsInfoContext(ctx context.Context,
+		accountID int64, query string, options *SearchOptions,
+		nodes ...*SnowthNode) ([]string, *SearchResultInfo, error)
+	FindTagVals(accountID int64, category, query string,
+		nodes ...*SnowthNode) ([]string, error)
+	FindTagValsContext(ctx context.Context,
+		accountID int64, category, query string,
+		nodes ...*SnowthNode) ([]string, error)
+	FindTagValsInfo(accountID int64, category,
+		query string, options *SearchOptions,
+		nodes ...*SnowthNode) ([]string, *SearchResultInfo, error)
+	FindTagValsInfoContext(ctx context.Context,
+		accountID int64, category, query string, options *SearchOptions,
+		nodes ...*SnowthNode) ([]string, *SearchResultInfo, error)
+	FindTags(accountID int64, query string,
+		options *FindTagsOptions, nodes ...*SnowthNode) (*FindTagsResult, error)
+	FindTagsContext(ctx context.Context, accountID int64,
+		query string, options *FindTagsOptions,
+		nodes ...*SnowthNode) (*FindTagsResult, error)
+	FindTagsStream(accountID int64, query string,
+		options *FindTagsOptions, fn func(item FindTagsItem) error,
+		nodes ...*SnowthNode) (*FindTagsResult, error)
+	FindTagsStreamContext(ctx context.Context,
+		accountID int64, query string, options *FindTagsOptions,
+		fn func(item FindTagsItem) error,
+		nodes ...*SnowthNode) (*FindTagsResult, error)
+	GetActiveNode(idsets ...[]string) *SnowthNode
+	GetActiveNodeWith(filters ...NodeFilter) *SnowthNode
+	GetCAQLQuery(q *CAQLQuery,
+		nodes ...*SnowthNode) (*DF4Response, error)
+	GetCAQLQueryContext(ctx context.Context, q *CAQLQuery,
+		nodes ...*SnowthNode) (*DF4Response, error)
+	GetCapacityReport(
+		opts *CapacityOptions) (*CapacityReport, error)
+	GetCapacityReportContext(ctx context.Context,
+		opts *CapacityOptions) (*CapacityReport, error)
+	GetGossipInfo(
+		nodes ...*SnowthNode) (*Gossip, error)
+	GetGossipInfoContext(ctx context.Context,
+		nodes ...*SnowthNode) (*Gossip, error)
+	GetJournalReplay(
+		nodes ...*SnowthNode) ([]JournalReplayState, error)
+	GetJournalReplayContext(ctx context.Context,
+		nodes ...*SnowthNode) ([]JournalReplayState, error)
+	GetLatestText(accountID int64, query string,
+		nodes ...*SnowthNode) ([]LatestText, error)
+	GetLatestTextContext(ctx context.Context,
+		accountID int64, query string,
+		nodes ...*SnowthNode) ([]LatestText, error)
+	GetLatestValues(accountID int64, query string,
+		nodes ...*SnowthNode) (map[MetricKey]*LatestValue, error)
+	GetLatestValuesContext(ctx context.Context,
+		accountID int64, query string,
+		nodes ...*SnowthNode) (map[MetricKey]*LatestValue, error)
+	GetLuaExtensions(nodes ...*SnowthNode) (LuaExtensions,
+		error)
+	GetLuaExtensionsContext(ctx context.Context,
+		nodes ...*SnowthNode) (LuaExtensions, error)
+	GetNodeJobQueues(
+		nodes ...*SnowthNode) (map[string]NodeJobQueue, error)
+	GetNodeJobQueuesContext(ctx context.Context,
+		nodes ...*SnowthNode) (map[string]NodeJobQueue, error)
+	GetNodeLog(name string, options *NodeLogOptions,
+		nodes ...*SnowthNode) ([]NodeLogLine, error)
+	GetNodeLogContext(ctx context.Context, name string,
+		options *NodeLogOptions, nodes ...*SnowthNode) ([]NodeLogLine, error)
+	GetNodeState(nodes ...*SnowthNode) (*NodeState, error)
+	GetNodeStateContext(ctx context.Context,
+		nodes ...*SnowthNode) (*NodeState, error)
+	GetStats(nodes ...*SnowthNode) (*Stats, error)
+	GetStatsContext(ctx context.Context,
+		nodes ...*SnowthNode) (*Stats, error)
+	GetTopologyInfo(nodes ...*SnowthNode) (*Topology, error)
+	GetTopologyInfoContext(ctx context.Context,
+		nodes ...*SnowthNode) (*Topology, error)
+	GraphiteFindMetrics(accountID int64,
+		prefix, query string, nodes ...*SnowthNode) ([]GraphiteMetric, error)
+	GraphiteFindMetricsContext(ctx context.Context,
+		accountID int64, prefix, query string,
+		nodes ...*SnowthNode) ([]GraphiteMetric, error)
+	GraphiteGetSeries(accountID int64, prefix string,
+		start, end time.Time, names []string,
+		nodes ...*SnowthNode) (*GraphiteSeries, error)
+	GraphiteGetSeriesContext(ctx context.Context,
+		accountID int64, prefix string, start, end time.Time, names []string,
+		nodes ...*SnowthNode) (*GraphiteSeries, error)
+	InvalidateMetricUUID(accountID int64,
+		name string) error
+	InvalidateTagQuery(accountID int64,
+		query string) error
+	ListActiveNodes() []*SnowthNode
+	ListInactiveNodes() []*SnowthNode
+	LoadTopology(hash string, t *Topology,
+		nodes ...*SnowthNode) error
+	LoadTopologyContext(ctx context.Context, hash string,
+		t *Topology, node *SnowthNode) error
+	LocateMetric(uuid string, metric string,
+		node ...*SnowthNode) ([]TopologyNode, error)
+	LocateMetricContext(ctx context.Context, uuid string,
+		metric string, node ...*SnowthNode) ([]TopologyNode, error)
+	LocateMetricRemote(uuid string, metric string,
+		node *SnowthNode) ([]TopologyNode, error)
+	LocateMetricRemoteContext(ctx context.Context,
+		uuid string, metric string, node *SnowthNode) ([]TopologyNode, error)
+	LogDebugf(format string, args ...interface{})
+	LogDebugw(msg string, keysAndValues ...interface{})
+	LogErrorf(format string, args ...interface{})
+	LogErrorw(msg string, keysAndValues ...interface{})
+	LogInfof(format string, args ...interface{})
+	LogInfow(msg string, keysAndValues ...interface{})
+	LogWarnf(format string, args ...interface{})
+	LogWarnw(msg string, keysAndValues ...interface{})
+	LookupSurrogate(id uint64,
+		nodes ...*SnowthNode) (*SurrogateEntry, error)
+	LookupSurrogateContext(ctx context.Context, id uint64,
+		nodes ...*SnowthNode) (*SurrogateEntry, error)
+	LookupSurrogateID(accountID int64, uuid, metric string,
+		nodes ...*SnowthNode) (*SurrogateEntry, error)
+	LookupSurrogateIDContext(ctx context.Context,
+		accountID int64, uuid, metric string,
+		nodes ...*SnowthNode) (*SurrogateEntry, error)
+	MetricCardinality(name string) int64
+	MetricsExist(accountID int64, keys []MetricKey,
+		options *MetricsExistOptions,
+		nodes ...*SnowthNode) (*MetricExistence, error)
+	MetricsExistContext(ctx context.Context,
+		accountID int64, keys []MetricKey, options *MetricsExistOptions,
+		nodes ...*SnowthNode) (*MetricExistence, error)
+	NewBatchWriter(
+		options *BatchWriterOptions) *BatchWriter
+	NewWorkGroup(ctx context.Context,
+		concurrency int) *WorkGroup
+	NodeStats(node *SnowthNode) (NodeRequestStats, bool)
+	OnNodeHealthChange(f func(e *NodeHealthEvent))
+	OnRetryBudgetExhausted(f func(e *RetryBudgetEvent))
+	OnTLSAudit(f func(e *TLSAuditEvent))
+	PauseClusterJournalReplay(peer string,
+		options *JournalControlOptions) error
+	PauseClusterJournalReplayContext(ctx context.Context,
+		peer string, options *JournalControlOptions) error
+	PauseJournalReplay(peer string,
+		options *JournalControlOptions, nodes ...*SnowthNode) error
+	PauseJournalReplayContext(ctx context.Context,
+		peer string, options *JournalControlOptions, nodes ...*SnowthNode) error
+	PromRead(accountID int64, req *PromReadRequest,
+		options *PromReadOptions,
+		nodes ...*SnowthNode) (*PromReadResponse, error)
+	PromReadContext(ctx context.Context, accountID int64,
+		req *PromReadRequest, options *PromReadOptions,
+		nodes ...*SnowthNode) (*PromReadResponse, error)
+	QuarantineNode(node *SnowthNode, d time.Duration)
+	QueryCAQL(accountID int64, q string, start,
+		end time.Time, period int64, nodes ...*SnowthNode) (*CAQLResult, error)
+	QueryCAQLContext(ctx context.Context, accountID int64,
+		q string, start, end time.Time, period int64,
+		nodes ...*SnowthNode) (*CAQLResult, error)
+	ReadHistogramValues(
+		uuid, metric string, period time.Duration,
+		start, end time.Time, nodes ...*SnowthNode) ([]HistogramValue, error)
+	ReadHistogramValuesContext(ctx context.Context,
+		uuid, metric string, period time.Duration,
+		start, end time.Time, nodes ...*SnowthNode) ([]HistogramValue, error)
+	ReadHistogramWindows(uuid, metric string,
+		period, window time.Duration, start, end time.Time,
+		nodes ...*SnowthNode) ([]HistogramWindow, error)
+	ReadHistogramWindowsContext(ctx context.Context,
+		uuid, metric string, period, window time.Duration, start, end time.Time,
+		nodes ...*SnowthNode) ([]HistogramWindow, error)
+	ReadNNTAllValues(start, end time.Time, period int64,
+		id, metric string, nodes ...*SnowthNode) ([]NNTAllValue, error)
+	ReadNNTAllValuesContext(ctx context.Context,
+		start, end time.Time, period int64,
+		id, metric string, nodes ...*SnowthNode) ([]NNTAllValue, error)
+	ReadNNTValues(start, end time.Time, period int64,
+		t, id, metric string, nodes ...*SnowthNode) ([]NNTValue, error)
+	ReadNNTValuesContext(ctx context.Context,
+		start, end time.Time, period int64,
+		t, id, metric string, nodes ...*SnowthNode) ([]NNTValue, error)
+	ReadNumericAllValues(start, end time.Time, period int64,
+		id, metric string, nodes ...*SnowthNode) ([]NumericAllValue, error)
+	ReadNumericAllValuesContext(ctx context.Context,
+		start, end time.Time, period int64,
+		id, metric string, nodes ...*SnowthNode) ([]NumericAllValue, error)
+	ReadNumericValues(start, end time.Time, period int64,
+		t, id, metric string, nodes ...*SnowthNode) ([]NumericValue, error)
+	ReadNumericValuesBulk(requests []NumericReadRequest,
+		concurrency int) ([]NumericReadResult, error)
+	ReadNumericValuesBulkContext(ctx context.Context,
+		requests []NumericReadRequest,
+		concurrency int) ([]NumericReadResult, error)
+	ReadNumericValuesByName(accountID int64, name string,
+		start, end time.Time, period int64, t string,
+		nodes ...*SnowthNode) ([]NumericValue, error)
+	ReadNumericValuesByNameContext(ctx context.Context,
+		accountID int64, name string, start, end time.Time, period int64,
+		t string, nodes ...*SnowthNode) ([]NumericValue, error)
+	ReadNumericValuesContext(ctx context.Context,
+		start, end time.Time, period int64,
+		t, id, metric string, nodes ...*SnowthNode) ([]NumericValue, error)
+	ReadRawNumericValues(start time.Time, end time.Time,
+		uuid string, metric string,
+		nodes ...*SnowthNode) ([]RawNumericValue, error)
+	ReadRawNumericValuesContext(ctx context.Context,
+		start, end time.Time, uuid, metric string,
+		nodes ...*SnowthNode) ([]RawNumericValue, error)
+	ReadRollupAllValues(
+		uuid, metric string, period time.Duration,
+		start, end time.Time, nodes ...*SnowthNode) ([]RollupAllValue, error)
+	ReadRollupAllValuesContext(ctx context.Context,
+		uuid, metric string, period time.Duration,
+		start, end time.Time, nodes ...*SnowthNode) ([]RollupAllValue, error)
+	ReadRollupValues(uuid, metric string, period time.Duration,
+		start, end time.Time, dataType string,
+		nodes ...*SnowthNode) ([]RollupValue, error)
+	ReadRollupValuesByName(accountID int64, name string,
+		period time.Duration, start, end time.Time, dataType string,
+		nodes ...*SnowthNode) ([]RollupValue, error)
+	ReadRollupValuesByNameContext(ctx context.Context,
+		accountID int64, name string, period time.Duration,
+		start, end time.Time, dataType string,
+		nodes ...*SnowthNode) ([]RollupValue, error)
+	ReadRollupValuesContext(ctx context.Context,
+		uuid, metric string, period time.Duration, start, end time.Time,
+		dataType string, nodes ...*SnowthNode) ([]RollupValue, error)
+	ReadTextValues(uuid, metric string,
+		start, end time.Time, nodes ...*SnowthNode) ([]TextValue, error)
+	ReadTextValuesContext(ctx context.Context,
+		uuid, metric string, start, end time.Time,
+		nodes ...*SnowthNode) ([]TextValue, error)
+	RebuildActivity(node *SnowthNode,
+		rebuildRequest []RebuildActivityRequest) (*IRONdbPutResponse, error)
+	RebuildActivityContext(ctx context.Context,
+		node *SnowthNode,
+		rebuildRequest []RebuildActivityRequest) (*IRONdbPutResponse, error)
+	RefreshTopology() error
+	RefreshTopologyContext(ctx context.Context) error
+	ReplaySpool(ctx context.Context) (int, error)
+	ResetClientStats()
+	ResolveMetricUUID(accountID int64, name string,
+		nodes ...*SnowthNode) (string, error)
+	ResolveMetricUUIDContext(ctx context.Context,
+		accountID int64, name string, nodes ...*SnowthNode) (string, error)
+	ResolveTagQuery(accountID int64, query string,
+		nodes ...*SnowthNode) ([]FindTagsItem, error)
+	ResolveTagQueryContext(ctx context.Context,
+		accountID int64, query string,
+		nodes ...*SnowthNode) ([]FindTagsItem, error)
+	ResumeClusterJournalReplay(peer string) error
+	ResumeClusterJournalReplayContext(ctx context.Context,
+		peer string) error
+	ResumeJournalReplay(peer string,
+		nodes ...*SnowthNode) error
+	ResumeJournalReplayContext(ctx context.Context,
+		peer string, nodes ...*SnowthNode) error
+	Retries() int64
+	SaveState() error
+	SetAccount(accountID int64, token string)
+	SetAckLevel(level AckLevel)
+	SetAdminTimeout(d time.Duration)
+	SetCardinalityLimit(limit int64,
+		action CardinalityAction)
+	SetClock(c Clock)
+	SetCompression(enabled bool)
+	SetConnectRetries(num int64)
+	SetDebugDump(enable bool)
+	SetDebugDumpLimit(n int) error
+	SetDisableRedirects(disable bool)
+	SetDiscoverInterval(d time.Duration)
+	SetHeaders(h http.Header)
+	SetHedgeDelay(d time.Duration)
+	SetLog(log Logger)
+	SetMaxConcurrentRequests(n int)
+	SetMaxRedirects(n int)
+	SetMaxWritePayload(n int64)
+	SetMetrics(m *Metrics)
+	SetNodeRateLimit(node *SnowthNode, rps float64,
+		burst int64)
+	SetNodeSelection(ns NodeSelection)
+	SetNonFinitePolicy(p NonFinitePolicy)
+	SetPriorityHeader(name string)
+	SetRateLimit(rps float64, burst int64)
+	SetReadTimeout(d time.Duration)
+	SetRequestFunc(f func(r *http.Request) error)
+	SetRequestIDFunc(f func(ctx context.Context) string)
+	SetResolveCache(store CacheStore, ttl time.Duration)
+	SetRetries(num int64)
+	SetSchemaMode(m SchemaMode)
+	SetRetryBudget(ratio float64, max int64)
+	SetSlowRequestThreshold(d time.Duration)
+	SetSpool(s *Spool)
+	SetStateStore(s StateStore)
+	SetTracerProvider(tp TracerProvider)
+	SetUserAgent(app, version string) error
+	SetWatchFunc(f func(n *SnowthNode))
+	SetWatchInterval(d time.Duration)
+	SetWatchdogFailures(num int64)
+	SetWatchdogInterval(d time.Duration)
+	SetWriteConsistency(level WriteConsistency)
+	SetWritePrecision(digits int)
+	SetWriteTimeout(d time.Duration)
+	Shutdown(ctx context.Context) error
+	StartDiscovery()
+	StartWatchdog(ctx context.Context)
+	State() *ClientState
+	StopDiscovery()
+	StopWatchdog()
+	StreamWrite(data <-chan RawMetric,
+		options *StreamWriteOptions,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	StreamWriteContext(ctx context.Context,
+		data <-chan RawMetric, options *StreamWriteOptions,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	TailNodeLog(ctx context.Context, name string,
+		interval time.Duration, options *NodeLogOptions, f func(l NodeLogLine),
+		nodes ...*SnowthNode) error
+	Topology() (*Topology, error)
+	TopologyContext(ctx context.Context) (*Topology,
+		error)
+	UUIDs() *UUIDClient
+	UpdateCheckTags(uuid string, tags []string,
+		nodes ...*SnowthNode) error
+	UpdateCheckTagsContext(ctx context.Context,
+		uuid string, tags []string, nodes ...*SnowthNode) error
+	Use(interceptors ...Interceptor)
+	VerifyManifest(m *Manifest,
+		options *ManifestVerifyOptions,
+		nodes ...*SnowthNode) (*ManifestReport, error)
+	VerifyManifestContext(ctx context.Context,
+		m *Manifest, options *ManifestVerifyOptions,
+		nodes ...*SnowthNode) (*ManifestReport, error)
+	WaitAsyncWrites(ctx context.Context) error
+	WatchAndUpdate(ctx context.Context)
+	WatchTopology(
+		ctx context.Context) (<-chan TopologyEvent, error)
+	WithConfig(o *ClientOverrides) (*SnowthClient, error)
+	WriteHistogram(data []HistogramData,
+		nodes ...*SnowthNode) error
+	WriteHistogramContext(ctx context.Context,
+		data []HistogramData, nodes ...*SnowthNode) error
+	WriteNNT(data []NNTData, nodes ...*SnowthNode) error
+	WriteNNTContext(ctx context.Context,
+		data []NNTData, nodes ...*SnowthNode) error
+	WriteNumeric(data []NumericWrite,
+		nodes ...*SnowthNode) error
+	WriteNumericContext(ctx context.Context,
+		data []NumericWrite, nodes ...*SnowthNode) error
+	WritePromRequest(req *PromWriteRequest,
+		options *PromWriteOptions,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WritePromRequestContext(ctx context.Context,
+		req *PromWriteRequest, options *PromWriteOptions,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteRaw(data io.Reader,
+		fb bool, dataPoints uint64,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteRawContext(ctx context.Context,
+		data io.Reader, fb bool, dataPoints uint64,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteRawMetricList(metricList *noit.MetricListT,
+		builder *flatbuffers.Builder,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteRawMetricListContext(ctx context.Context,
+		metricList *noit.MetricListT, builder *flatbuffers.Builder,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteRawMetrics(data []RawMetric,
+		nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteRawMetricsContext(ctx context.Context,
+		data []RawMetric, nodes ...*SnowthNode) (*IRONdbPutResponse, error)
+	WriteText(data []TextData, nodes ...*SnowthNode) error
+	WriteTextContext(ctx context.Context,
+		data []TextData, nodes ...*SnowthNode) error
+}
+
+// Ensure SnowthClient implements the Client interface.
+var _ Client = &SnowthClient{}
@@ -0,0 +1,26 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestClientInterface(t *testing.T) {
+	t.Parallel()
+
+	it := reflect.TypeOf((*Client)(nil)).Elem()
+	mt := reflect.TypeOf(&sync.RWMutex{})
+	ct := reflect.TypeOf(&SnowthClient{})
+	for i := 0; i < ct.NumMethod(); i++ {
+		m := ct.Method(i)
+		if _, ok := mt.MethodByName(m.Name); ok {
+			continue
+		}
+
+		if _, ok := it.MethodByName(m.Name); !ok {
+			t.Errorf("Expected Client interface method: %v", m.Name)
+		}
+	}
+}
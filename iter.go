@@ -0,0 +1,224 @@
+//go:build go1.23
+// +build go1.23
+
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// errIterStopped is returned by stream functions to stop decoding a response
+// when the consumer of an iterator stops the iteration.
+var errIterStopped = errors.New("iteration stopped")
+
+// iterReadPageSize is the maximum number of periods of data requested by each
+// read request made by an iterator.
+const iterReadPageSize = 1440
+
+// iterators contains the methods of the Client interface which return
+// iterators. These are available when built with Go 1.23 or later.
+type iterators interface {
+	IterFindTags(ctx context.Context, accountID int64, query string,
+		options *FindTagsOptions,
+		nodes ...*SnowthNode) iter.Seq2[FindTagsItem, error]
+	IterTagVals(ctx context.Context, accountID int64, category, query string,
+		options *SearchOptions, nodes ...*SnowthNode) iter.Seq2[string, error]
+	IterReadNumeric(ctx context.Context, start, end time.Time, period int64,
+		t, id, metric string,
+		nodes ...*SnowthNode) iter.Seq2[NumericValue, error]
+	IterReadText(ctx context.Context, uuid, metric string,
+		start, end time.Time,
+		nodes ...*SnowthNode) iter.Seq2[TextValue, error]
+	IterReadRollup(ctx context.Context, uuid, metric string,
+		period time.Duration, start, end time.Time, dataType string,
+		nodes ...*SnowthNode) iter.Seq2[RollupValue, error]
+	IterReadHistogram(ctx context.Context, uuid, metric string,
+		period time.Duration, start, end time.Time,
+		nodes ...*SnowthNode) iter.Seq2[HistogramValue, error]
+}
+
+// IterFindTags returns an iterator over the metrics matching a tag query. The
+// response is streamed and decoded as the iterator is consumed, so that large
+// result sets are never held in memory. If the request fails, the error is
+// yielded once and the iteration ends.
+func (sc *SnowthClient) IterFindTags(ctx context.Context, accountID int64,
+	query string, options *FindTagsOptions,
+	nodes ...*SnowthNode) iter.Seq2[FindTagsItem, error] {
+	return func(yield func(FindTagsItem, error) bool) {
+		opts := findTagsOptions(options)
+		opts.CountOnly = 0
+		_, err := sc.findTagsStream(ctx, accountID, query, opts,
+			func(item FindTagsItem) error {
+				if !yield(item, nil) {
+					return errIterStopped
+				}
+
+				return nil
+			}, nodes...)
+		if err != nil && !errors.Is(err, errIterStopped) {
+			yield(FindTagsItem{}, err)
+		}
+	}
+}
+
+// IterTagVals returns an iterator over the distinct values of a tag category
+// among the metrics which match a tag query. The response is streamed and
+// decoded as the iterator is consumed, so that large result sets are never
+// held in memory. If the request fails, the error is yielded once and the
+// iteration ends.
+func (sc *SnowthClient) IterTagVals(ctx context.Context, accountID int64,
+	category, query string, options *SearchOptions,
+	nodes ...*SnowthNode) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		if category == "" {
+			yield("", fmt.Errorf("tag category required"))
+			return
+		}
+
+		var node *SnowthNode
+		if len(nodes) > 0 && nodes[0] != nil {
+			node = nodes[0]
+		} else {
+			node = sc.GetActiveNode()
+		}
+
+		opts := SearchOptions{}
+		if options != nil {
+			opts = *options
+		}
+
+		body, _, err := sc.DoRequestStreamContext(ctx, node, "GET",
+			sc.findTagValsURL(node, accountID, category, query), nil,
+			searchHeaders(opts.Limit))
+		if err != nil {
+			yield("", err)
+			return
+		}
+
+		defer body.Close()
+		dec := json.NewDecoder(body)
+		if _, err := dec.Token(); err != nil {
+			yield("", fmt.Errorf("unable to decode IRONdb response: %w",
+				err))
+			return
+		}
+
+		for dec.More() {
+			var v string
+			if err := dec.Decode(&v); err != nil {
+				yield("", fmt.Errorf("unable to decode IRONdb response: %w",
+					err))
+				return
+			}
+
+			if !yield(decodeTagPart(v), nil) {
+				return
+			}
+		}
+	}
+}
+
+// iterPages returns an iterator over values read in pages covering the time
+// range from start to end. Each page is read by the read function and values
+// with timestamps already yielded by a previous page are skipped.
+func iterPages[T any](ctx context.Context, start, end time.Time,
+	page time.Duration, read func(s, e time.Time) ([]T, error),
+	ts func(v T) time.Time) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		var last time.Time
+		for s := start; ; {
+			e := end
+			if page > 0 && s.Add(page).Before(end) {
+				e = s.Add(page)
+			}
+
+			if err := ctx.Err(); err != nil {
+				yield(zero, fmt.Errorf("context terminated: %w", err))
+				return
+			}
+
+			vals, err := read(s, e)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, v := range vals {
+				if !last.IsZero() && !ts(v).After(last) {
+					continue
+				}
+
+				last = ts(v)
+				if !yield(v, nil) {
+					return
+				}
+			}
+
+			if !e.Before(end) {
+				return
+			}
+
+			s = e
+		}
+	}
+}
+
+// IterReadNumeric returns an iterator over numeric data values. Long time
+// ranges are read in pages of at most 1440 periods, as the iterator is
+// consumed. If a request fails, the error is yielded once and the iteration
+// ends.
+func (sc *SnowthClient) IterReadNumeric(ctx context.Context,
+	start, end time.Time, period int64, t, id, metric string,
+	nodes ...*SnowthNode) iter.Seq2[NumericValue, error] {
+	return iterPages(ctx, start, end,
+		time.Duration(period*iterReadPageSize)*time.Second,
+		func(s, e time.Time) ([]NumericValue, error) {
+			return sc.ReadNumericValuesContext(ctx, s, e, period, t, id,
+				metric, nodes...)
+		}, func(v NumericValue) time.Time { return v.Time })
+}
+
+// IterReadText returns an iterator over text data values. If the request
+// fails, the error is yielded once and the iteration ends.
+func (sc *SnowthClient) IterReadText(ctx context.Context, uuid, metric string,
+	start, end time.Time, nodes ...*SnowthNode) iter.Seq2[TextValue, error] {
+	return iterPages(ctx, start, end, 0,
+		func(s, e time.Time) ([]TextValue, error) {
+			return sc.ReadTextValuesContext(ctx, uuid, metric, s, e,
+				nodes...)
+		}, func(v TextValue) time.Time { return v.Time })
+}
+
+// IterReadRollup returns an iterator over rollup data values. Long time ranges
+// are read in pages of at most 1440 periods, as the iterator is consumed. If a
+// request fails, the error is yielded once and the iteration ends.
+func (sc *SnowthClient) IterReadRollup(ctx context.Context,
+	uuid, metric string, period time.Duration, start, end time.Time,
+	dataType string, nodes ...*SnowthNode) iter.Seq2[RollupValue, error] {
+	return iterPages(ctx, start, end, period*iterReadPageSize,
+		func(s, e time.Time) ([]RollupValue, error) {
+			return sc.ReadRollupValuesContext(ctx, uuid, metric, period,
+				s, e, dataType, nodes...)
+		}, func(v RollupValue) time.Time { return v.Time })
+}
+
+// IterReadHistogram returns an iterator over histogram data values. Long time
+// ranges are read in pages of at most 1440 periods, as the iterator is
+// consumed. If a request fails, the error is yielded once and the iteration
+// ends.
+func (sc *SnowthClient) IterReadHistogram(ctx context.Context,
+	uuid, metric string, period time.Duration, start, end time.Time,
+	nodes ...*SnowthNode) iter.Seq2[HistogramValue, error] {
+	return iterPages(ctx, start, end, period*iterReadPageSize,
+		func(s, e time.Time) ([]HistogramValue, error) {
+			return sc.ReadHistogramValuesContext(ctx, uuid, metric, period,
+				s, e, nodes...)
+		}, func(v HistogramValue) time.Time { return v.Time })
+}
@@ -0,0 +1,9 @@
+//go:build !go1.23
+// +build !go1.23
+
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+// iterators contains the methods of the Client interface which return
+// iterators. These are available only when built with Go 1.23 or later.
+type iterators interface{}
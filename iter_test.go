@@ -0,0 +1,226 @@
+//go:build go1.23
+// +build go1.23
+
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIterFindTags(t *testing.T) {
+	t.Parallel()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=test") {
+			_, _ = w.Write([]byte(byNameTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	names := []string{}
+	for item, err := range sc.IterFindTags(context.Background(), 1, "test",
+		nil, node) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names = append(names, item.MetricName)
+	}
+
+	if len(names) != 2 {
+		t.Errorf("Expected items: 2, got: %v", names)
+	}
+
+	n := 0
+	for range sc.IterFindTags(context.Background(), 1, "test", nil, node) {
+		n++
+		break
+	}
+
+	if n != 1 {
+		t.Errorf("Expected items: 1, got: %v", n)
+	}
+}
+
+func TestIterReadNumeric(t *testing.T) {
+	t.Parallel()
+
+	pages := 0
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/read/") {
+			pages++
+			p := strings.Split(r.URL.Path, "/")
+			s, _ := strconv.ParseInt(p[2], 10, 64)
+			e, _ := strconv.ParseInt(p[3], 10, 64)
+			_, _ = fmt.Fprintf(w, "[[%d,1],[%d,2]]", s, e)
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	start := time.Unix(0, 0)
+	end := start.Add(2 * iterReadPageSize * time.Minute)
+	times := []int64{}
+	for v, err := range sc.IterReadNumeric(context.Background(), start, end,
+		60, "average", "fc85e0ab-f568-45e6-86ee-d7443be8277d", "test",
+		node) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		times = append(times, v.Time.Unix())
+	}
+
+	if pages != 2 {
+		t.Errorf("Expected pages: 2, got: %v", pages)
+	}
+
+	exp := []int64{0, iterReadPageSize * 60, 2 * iterReadPageSize * 60}
+	if fmt.Sprint(times) != fmt.Sprint(exp) {
+		t.Errorf("Expected times: %v, got: %v", exp, times)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, err := range sc.IterReadNumeric(ctx, start, end, 60, "average",
+		"fc85e0ab-f568-45e6-86ee-d7443be8277d", "test", node) {
+		if err == nil {
+			t.Error("Expected context error")
+		}
+	}
+}
+
+func TestIterTagVals(t *testing.T) {
+	t.Parallel()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/find/1/tag_vals" {
+			if r.URL.Query().Get("category") != "host" {
+				t.Errorf("Expected category: host, got: %v",
+					r.URL.Query().Get("category"))
+			}
+
+			_, _ = w.Write([]byte(`["web1","b\"d2ViIDI=\"","web3"]`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	vals := []string{}
+	for v, err := range sc.IterTagVals(context.Background(), 1, "host",
+		"and(__name:cpu)", nil, node) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vals = append(vals, v)
+	}
+
+	if len(vals) != 3 || vals[1] != "web 2" {
+		t.Errorf("Expected values: [web1 web 2 web3], got: %v", vals)
+	}
+
+	n := 0
+	for _, err := range sc.IterTagVals(context.Background(), 1, "host",
+		"and(__name:cpu)", nil, node) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n++
+		break
+	}
+
+	if n != 1 {
+		t.Errorf("Expected values: 1, got: %v", n)
+	}
+
+	for _, err := range sc.IterTagVals(context.Background(), 1, "", "test",
+		nil, node) {
+		if err == nil {
+			t.Error("Expected tag category error")
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// Errors returned by the journal replay control functions.
+var (
+	// ErrJournalReplayUnsupported indicates that a node does not allow
+	// journal replay to be queried or controlled.
+	ErrJournalReplayUnsupported = errors.New("journal replay control " +
+		"not supported by node")
+
+	// ErrNotConfirmed indicates that an operation which could affect the
+	// replication of data in the cluster was not explicitly confirmed.
+	ErrNotConfirmed = errors.New("operation not confirmed")
+)
+
+// JournalReplayState values describe the replay of the journal of data which
+// a node is replicating to one of its peers.
+type JournalReplayState struct {
+	Peer    string `json:"peer"`
+	Paused  bool   `json:"paused"`
+	Pending int64  `json:"pending"`
+}
+
+// JournalControlOptions values contain the options used when pausing journal
+// replay.
+type JournalControlOptions struct {
+	// Confirm must be set to true for journal replay to be paused. Data sent
+	// to a node is not replicated to peers while replay is paused.
+	Confirm bool
+
+	// Reason is a description of why journal replay is paused, which is
+	// included in the client log.
+	Reason string
+}
+
+// GetJournalReplay retrieves the state of journal replay from a node to each
+// of its peers, sorted by peer ID.
+func (sc *SnowthClient) GetJournalReplay(
+	nodes ...*SnowthNode) ([]JournalReplayState, error) {
+	return sc.GetJournalReplayContext(context.Background(), nodes...)
+}
+
+// GetJournalReplayContext is the context aware version of GetJournalReplay.
+func (sc *SnowthClient) GetJournalReplayContext(ctx context.Context,
+	nodes ...*SnowthNode) ([]JournalReplayState, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	body, _, err := sc.DoRequestContext(withoutFailover(ctx), node, "GET",
+		snowthapi.PathJournalReplay, nil, nil)
+	if err != nil {
+		return nil, journalReplayError(err)
+	}
+
+	r := map[string]JournalReplayState{}
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	res := make([]JournalReplayState, 0, len(r))
+	for id, s := range r {
+		s.Peer = id
+		res = append(res, s)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Peer < res[j].Peer
+	})
+
+	return res, nil
+}
+
+// PauseJournalReplay pauses the replay of the journal of a node to one of its
+// peers, such as while the peer is under maintenance. If peer is empty,
+// replay to all peers of the node is paused. The options must confirm the
+// operation.
+func (sc *SnowthClient) PauseJournalReplay(peer string,
+	options *JournalControlOptions, nodes ...*SnowthNode) error {
+	return sc.PauseJournalReplayContext(context.Background(), peer, options,
+		nodes...)
+}
+
+// PauseJournalReplayContext is the context aware version of
+// PauseJournalReplay.
+func (sc *SnowthClient) PauseJournalReplayContext(ctx context.Context,
+	peer string, options *JournalControlOptions, nodes ...*SnowthNode) error {
+	if options == nil || !options.Confirm {
+		return fmt.Errorf("unable to pause journal replay: %w",
+			ErrNotConfirmed)
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	sc.LogWarnf("pausing journal replay: node: %v, peer: %q, reason: %q",
+		node, peer, options.Reason)
+	return sc.setJournalReplay(ctx, node, peer, "pause")
+}
+
+// ResumeJournalReplay resumes the replay of the journal of a node to one of
+// its peers. If peer is empty, replay to all peers of the node is resumed.
+func (sc *SnowthClient) ResumeJournalReplay(peer string,
+	nodes ...*SnowthNode) error {
+	return sc.ResumeJournalReplayContext(context.Background(), peer, nodes...)
+}
+
+// ResumeJournalReplayContext is the context aware version of
+// ResumeJournalReplay.
+func (sc *SnowthClient) ResumeJournalReplayContext(ctx context.Context,
+	peer string, nodes ...*SnowthNode) error {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	sc.LogInfof("resuming journal replay: node: %v, peer: %q", node, peer)
+	return sc.setJournalReplay(ctx, node, peer, "resume")
+}
+
+// PauseClusterJournalReplay pauses the replay of the journals of all active
+// nodes to a peer, such as before the peer is taken down for maintenance. If
+// peer is empty, all journal replay in the cluster is paused. The options must
+// confirm the operation. Every active node is attempted, and the returned
+// error combines the errors of all nodes which failed.
+func (sc *SnowthClient) PauseClusterJournalReplay(peer string,
+	options *JournalControlOptions) error {
+	return sc.PauseClusterJournalReplayContext(context.Background(), peer,
+		options)
+}
+
+// PauseClusterJournalReplayContext is the context aware version of
+// PauseClusterJournalReplay.
+func (sc *SnowthClient) PauseClusterJournalReplayContext(ctx context.Context,
+	peer string, options *JournalControlOptions) error {
+	if options == nil || !options.Confirm {
+		return fmt.Errorf("unable to pause journal replay: %w",
+			ErrNotConfirmed)
+	}
+
+	mErr := newMultiError()
+	for _, node := range sc.ListActiveNodes() {
+		if node.identifier == peer {
+			continue
+		}
+
+		if err := sc.PauseJournalReplayContext(ctx, peer, options,
+			node); err != nil {
+			mErr.Add(fmt.Errorf("node %s: %w", node.identifier, err))
+		}
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// ResumeClusterJournalReplay resumes the replay of the journals of all active
+// nodes to a peer. If peer is empty, all journal replay in the cluster is
+// resumed. Every active node is attempted, and the returned error combines
+// the errors of all nodes which failed.
+func (sc *SnowthClient) ResumeClusterJournalReplay(peer string) error {
+	return sc.ResumeClusterJournalReplayContext(context.Background(), peer)
+}
+
+// ResumeClusterJournalReplayContext is the context aware version of
+// ResumeClusterJournalReplay.
+func (sc *SnowthClient) ResumeClusterJournalReplayContext(ctx context.Context,
+	peer string) error {
+	mErr := newMultiError()
+	for _, node := range sc.ListActiveNodes() {
+		if node.identifier == peer {
+			continue
+		}
+
+		if err := sc.ResumeJournalReplayContext(ctx, peer,
+			node); err != nil {
+			mErr.Add(fmt.Errorf("node %s: %w", node.identifier, err))
+		}
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// setJournalReplay sends a request to pause or resume the journal replay of a
+// node to a peer, or to all peers if peer is empty. The request is never sent
+// to any other node.
+func (sc *SnowthClient) setJournalReplay(ctx context.Context,
+	node *SnowthNode, peer, action string) error {
+	p := path.Join(snowthapi.PathJournalReplay, action)
+	if peer != "" {
+		p = path.Join(snowthapi.PathJournalReplay, url.PathEscape(peer),
+			action)
+	}
+
+	if _, _, err := sc.DoRequestContext(withoutFailover(ctx), node, "POST",
+		p, nil, nil); err != nil {
+		return journalReplayError(err)
+	}
+
+	return nil
+}
+
+// journalReplayError converts the errors of nodes which do not provide the
+// journal replay endpoints into ErrJournalReplayUnsupported.
+func journalReplayError(err error) error {
+	if IsNotFound(err) {
+		return fmt.Errorf("%w: %v", ErrJournalReplayUnsupported, err)
+	}
+
+	return err
+}
@@ -0,0 +1,172 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+const journalReplayTestData = `{
+	"1f846f26-0cfd-4df5-b4f1-e0930604e577": {"paused": false, "pending": 12},
+	"765ac4cc-1929-4642-9ef1-d194d08f9538": {"paused": true, "pending": 4096}
+}`
+
+func TestJournalReplay(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	actions := []string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/journal/replay" && r.Method == "GET" {
+			_, _ = w.Write([]byte(journalReplayTestData))
+			return
+		}
+
+		if r.Method == "POST" && (r.RequestURI ==
+			"/journal/replay/765ac4cc-1929-4642-9ef1-d194d08f9538/pause" ||
+			r.RequestURI ==
+				"/journal/replay/765ac4cc-1929-4642-9ef1-d194d08f9538/resume" ||
+			r.RequestURI == "/journal/replay/pause") {
+			mu.Lock()
+			actions = append(actions, r.RequestURI)
+			mu.Unlock()
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	node := sc.GetActiveNode()
+	res, err := sc.GetJournalReplay(node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("Expected length: 2, got: %v", len(res))
+	}
+
+	if res[1].Peer != "765ac4cc-1929-4642-9ef1-d194d08f9538" ||
+		!res[1].Paused || res[1].Pending != 4096 {
+		t.Errorf("Expected paused peer state, got: %+v", res[1])
+	}
+
+	peer := "765ac4cc-1929-4642-9ef1-d194d08f9538"
+	if err := sc.PauseJournalReplay(peer, nil,
+		node); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("Expected error: %v, got: %v", ErrNotConfirmed, err)
+	}
+
+	if err := sc.PauseClusterJournalReplay(peer,
+		&JournalControlOptions{}); !errors.Is(err, ErrNotConfirmed) {
+		t.Errorf("Expected error: %v, got: %v", ErrNotConfirmed, err)
+	}
+
+	if len(actions) != 0 {
+		t.Fatalf("Expected no requests, got: %v", actions)
+	}
+
+	opts := &JournalControlOptions{Confirm: true, Reason: "test"}
+	if err := sc.PauseJournalReplay(peer, opts, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.ResumeJournalReplay(peer, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.PauseClusterJournalReplayContext(context.Background(), "",
+		opts); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := httptest.NewServer(http.NotFoundHandler())
+	du, err := url.Parse(ds.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	ds.Close()
+	down := &SnowthNode{url: du}
+	if err := sc.ResumeJournalReplay(peer, down); err == nil {
+		t.Error("Expected error, got: nil")
+	}
+
+	if _, err := sc.GetJournalReplay(down); err == nil {
+		t.Error("Expected error, got: nil")
+	}
+
+	exp := []string{
+		"/journal/replay/765ac4cc-1929-4642-9ef1-d194d08f9538/pause",
+		"/journal/replay/765ac4cc-1929-4642-9ef1-d194d08f9538/resume",
+		"/journal/replay/pause",
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(actions) != len(exp) {
+		t.Fatalf("Expected requests: %v, got: %v", exp, actions)
+	}
+
+	for i, a := range exp {
+		if actions[i] != a {
+			t.Errorf("Expected request: %v, got: %v", a, actions[i])
+		}
+	}
+}
+
+func TestJournalReplayUnsupported(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		w.WriteHeader(http.StatusNotFound)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	if _, err := sc.GetJournalReplay(); !errors.Is(err,
+		ErrJournalReplayUnsupported) {
+		t.Errorf("Expected error: %v, got: %v", ErrJournalReplayUnsupported,
+			err)
+	}
+
+	if err := sc.ResumeClusterJournalReplay(""); err == nil {
+		t.Error("Expected error for unsupported node")
+	}
+}
@@ -0,0 +1,133 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/circonus-labs/circonusllhist"
+)
+
+// LatestValue values contain the most recent sample of a metric. Kind is the
+// type of the sample, "numeric", "text", or "histogram", and only the value
+// field of that type is set. Histogram values are base64 encoded.
+type LatestValue struct {
+	Kind      string
+	Time      time.Time
+	Numeric   *float64
+	Text      *string
+	Histogram *string
+}
+
+// HistogramData decodes the histogram value of a latest histogram sample.
+func (lv *LatestValue) HistogramData() (*circonusllhist.Histogram, error) {
+	if lv.Histogram == nil {
+		return nil, fmt.Errorf("latest value is not a histogram: %s", lv.Kind)
+	}
+
+	return NewHistogramFromBase64(*lv.Histogram)
+}
+
+// latestValue returns the most recent non-null sample in the latest values of
+// a find tags item, or nil if there is none. If any kinds are specified, only
+// samples of those kinds are considered.
+func latestValue(ftl *FindTagsLatest, kinds ...string) *LatestValue {
+	if ftl == nil {
+		return nil
+	}
+
+	want := func(kind string) bool {
+		if len(kinds) == 0 {
+			return true
+		}
+
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	var r *LatestValue
+	use := func(ms int64) bool {
+		t := time.Unix(0, ms*int64(time.Millisecond))
+		if r != nil && !t.After(r.Time) {
+			return false
+		}
+
+		r = &LatestValue{Time: t}
+		return true
+	}
+
+	if want("numeric") {
+		for _, v := range ftl.Numeric {
+			if v.Value != nil && use(v.Time) {
+				r.Kind, r.Numeric = "numeric", v.Value
+			}
+		}
+	}
+
+	if want("text") {
+		for _, v := range ftl.Text {
+			if v.Value != nil && use(v.Time) {
+				r.Kind, r.Text = "text", v.Value
+			}
+		}
+	}
+
+	if want("histogram") {
+		for _, v := range ftl.Histogram {
+			if v.Value != nil && use(v.Time) {
+				r.Kind, r.Histogram = "histogram", v.Value
+			}
+		}
+	}
+
+	return r
+}
+
+// findLatest finds the metrics matching a tag query, with their most recent
+// samples.
+func (sc *SnowthClient) findLatest(ctx context.Context, accountID int64,
+	query string, nodes ...*SnowthNode) ([]FindTagsItem, error) {
+	res, err := sc.FindTagsContext(ctx, accountID, query,
+		&FindTagsOptions{Latest: 1}, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Items, nil
+}
+
+// GetLatestValues finds the metrics matching a tag query and returns the most
+// recent sample of each, keyed by check UUID and metric name. Metrics without
+// any recent samples are omitted. If a metric has recent samples of more than
+// one type, the most recent sample of any type is returned. The number of
+// metrics is limited by the default search limit of the node.
+func (sc *SnowthClient) GetLatestValues(accountID int64, query string,
+	nodes ...*SnowthNode) (map[MetricKey]*LatestValue, error) {
+	return sc.GetLatestValuesContext(context.Background(), accountID, query,
+		nodes...)
+}
+
+// GetLatestValuesContext is the context aware version of GetLatestValues.
+func (sc *SnowthClient) GetLatestValuesContext(ctx context.Context,
+	accountID int64, query string,
+	nodes ...*SnowthNode) (map[MetricKey]*LatestValue, error) {
+	items, err := sc.findLatest(ctx, accountID, query, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := make(map[MetricKey]*LatestValue, len(items))
+	for _, item := range items {
+		if lv := latestValue(item.Latest); lv != nil {
+			r[MetricKey{UUID: item.UUID, Metric: item.MetricName}] = lv
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,111 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const latestTestData = `[
+	{
+		"uuid": "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+		"metric_name": "test",
+		"type": "numeric,histogram",
+		"latest": {
+			"numeric": [[1561848300000, 1], [1561848360000, null]],
+			"histogram": [[1561848240000, "AAEoAgAB"]]
+		},
+		"account_id": 1
+	},
+	{
+		"uuid": "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+		"metric_name": "status",
+		"type": "text",
+		"latest": {
+			"text": [[1561848300500, "ok"]]
+		},
+		"account_id": 1
+	},
+	{
+		"uuid": "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+		"metric_name": "idle",
+		"type": "numeric",
+		"account_id": 1
+	}
+]`
+
+func TestGetLatestValues(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/find/1/tags" {
+			if r.URL.Query().Get("latest") != "1" {
+				t.Errorf("Expected latest: 1, got: %v",
+					r.URL.Query().Get("latest"))
+			}
+
+			_, _ = w.Write([]byte(latestTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	res, err := sc.GetLatestValues(1, "and(__name:*)", &SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("Expected values: 2, got: %v", len(res))
+	}
+
+	lv := res[MetricKey{UUID: "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+		Metric: "test"}]
+	if lv == nil || lv.Kind != "numeric" || *lv.Numeric != 1 ||
+		lv.Time.Unix() != 1561848300 || lv.Histogram != nil {
+		t.Errorf("Unexpected numeric value: %+v", lv)
+	}
+
+	if _, err := lv.HistogramData(); err == nil {
+		t.Error("Expected error for numeric histogram data")
+	}
+
+	lv = res[MetricKey{UUID: "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+		Metric: "status"}]
+	if lv == nil || lv.Kind != "text" || *lv.Text != "ok" ||
+		lv.Time.UnixNano() != 1561848300500000000 {
+		t.Errorf("Unexpected text value: %+v", lv)
+	}
+
+	lv = latestValue(&FindTagsLatest{
+		Histogram: []FindTagsLatestHistogram{{1, stringPtr("AAEoAgAB")}},
+	})
+	if h, err := lv.HistogramData(); err != nil || len(h.DecStrings()) != 1 {
+		t.Errorf("Unexpected histogram data: %v, %v", h, err)
+	}
+}
@@ -0,0 +1,167 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseLineTimestamp parses a line protocol timestamp, in seconds since the
+// epoch, or in milliseconds if the value is too large to be in seconds. If the
+// timestamp is empty or negative, the zero time is returned, which causes the
+// time of the write to be used.
+func parseLineTimestamp(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, fmt.Errorf("invalid timestamp: %s", s)
+	}
+
+	if f < 0 {
+		return time.Time{}, nil
+	}
+
+	// Values with more than 10 integer digits are in milliseconds.
+	if f >= 1e10 {
+		f /= 1000
+	}
+
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e3))*1e6), nil
+}
+
+// parseLineValue parses a line protocol numeric value.
+func parseLineValue(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid value: %s", s)
+	}
+
+	return v, nil
+}
+
+// ParseGraphiteLine parses a line in the Graphite plaintext protocol format,
+// "<path>[;<tag>=<value>...] <value> [<timestamp>]", into a raw metric which
+// can be written using WriteRawMetrics or StreamWrite. Graphite tags are
+// converted to stream tags. The account and check of the returned metric are
+// not set, and must be set by the caller before it is written.
+func ParseGraphiteLine(line string) (*RawMetric, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || len(fields) > 3 {
+		return nil, fmt.Errorf("invalid graphite line: %q", line)
+	}
+
+	parts := strings.Split(fields[0], ";")
+	if parts[0] == "" {
+		return nil, fmt.Errorf("invalid graphite line: %q: missing metric "+
+			"path", line)
+	}
+
+	m := &RawMetric{Metric: parts[0]}
+	for _, t := range parts[1:] {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid graphite line: %q: invalid tag: "+
+				"%s", line, t)
+		}
+
+		m.Tags = append(m.Tags, kv[0]+":"+kv[1])
+	}
+
+	v, err := parseLineValue(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid graphite line: %q: %w", line, err)
+	}
+
+	m.Value = v
+	if len(fields) == 3 {
+		if m.Timestamp, err = parseLineTimestamp(fields[2]); err != nil {
+			return nil, fmt.Errorf("invalid graphite line: %q: %w", line, err)
+		}
+	}
+
+	return m, nil
+}
+
+// ParseOpenTSDBLine parses a line in the OpenTSDB telnet put format,
+// "put <metric> <timestamp> <value> [<tag>=<value>...]", into a raw metric
+// which can be written using WriteRawMetrics or StreamWrite. The leading put
+// command is optional. OpenTSDB tags are converted to stream tags. The
+// account and check of the returned metric are not set, and must be set by
+// the caller before it is written.
+func ParseOpenTSDBLine(line string) (*RawMetric, error) {
+	fields := strings.Fields(line)
+	if len(fields) > 0 && fields[0] == "put" {
+		fields = fields[1:]
+	}
+
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid opentsdb line: %q", line)
+	}
+
+	ts, err := parseLineTimestamp(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid opentsdb line: %q: %w", line, err)
+	}
+
+	v, err := parseLineValue(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid opentsdb line: %q: %w", line, err)
+	}
+
+	m := &RawMetric{Metric: fields[0], Timestamp: ts, Value: v}
+	for _, t := range fields[3:] {
+		kv := strings.SplitN(t, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return nil, fmt.Errorf("invalid opentsdb line: %q: invalid tag: "+
+				"%s", line, t)
+		}
+
+		m.Tags = append(m.Tags, kv[0]+":"+kv[1])
+	}
+
+	return m, nil
+}
+
+// ParseLines reads lines from a reader and parses them using a line parser,
+// such as ParseGraphiteLine or ParseOpenTSDBLine, calling fn with each metric
+// parsed. Empty lines are skipped. Lines which cannot be parsed are passed to
+// onError, if it is set, and skipped. Parsing stops at the end of the input,
+// or when fn returns an error, which is returned.
+func ParseLines(r io.Reader, parse func(line string) (*RawMetric, error),
+	fn func(m *RawMetric) error, onError func(err error)) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+
+		m, err := parse(line)
+		if err != nil {
+			if onError != nil {
+				onError(err)
+			}
+
+			continue
+		}
+
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		return fmt.Errorf("unable to read lines: %w", err)
+	}
+
+	return nil
+}
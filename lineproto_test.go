@@ -0,0 +1,119 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGraphiteLine(t *testing.T) {
+	t.Parallel()
+	m, err := ParseGraphiteLine("servers.web1.cpu;dc=east;role=web 12.5 " +
+		"1529509020")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &RawMetric{
+		Metric:    "servers.web1.cpu",
+		Tags:      []string{"dc:east", "role:web"},
+		Timestamp: time.Unix(1529509020, 0),
+		Value:     12.5,
+	}
+
+	if !reflect.DeepEqual(m, exp) {
+		t.Errorf("Expected metric: %+v, got: %+v", exp, m)
+	}
+
+	m, err = ParseGraphiteLine("servers.web1.cpu 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !m.Timestamp.IsZero() || m.Value != 3.0 || len(m.Tags) != 0 {
+		t.Errorf("Unexpected metric: %+v", m)
+	}
+
+	m, err = ParseGraphiteLine("test 1 1529509020123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := time.Unix(1529509020, 123e6); !m.Timestamp.Equal(exp) {
+		t.Errorf("Expected timestamp: %v, got: %v", exp, m.Timestamp)
+	}
+
+	for _, l := range []string{"", "test", "test x 1529509020",
+		"test 1 x", ";a=b 1", "test;a 1", "test 1 2 3"} {
+		if _, err := ParseGraphiteLine(l); err == nil {
+			t.Errorf("Expected error for line: %q", l)
+		}
+	}
+}
+
+func TestParseOpenTSDBLine(t *testing.T) {
+	t.Parallel()
+	m, err := ParseOpenTSDBLine("put sys.cpu.user 1529509020 42.5 " +
+		"host=web1 cpu=0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &RawMetric{
+		Metric:    "sys.cpu.user",
+		Tags:      []string{"host:web1", "cpu:0"},
+		Timestamp: time.Unix(1529509020, 0),
+		Value:     42.5,
+	}
+
+	if !reflect.DeepEqual(m, exp) {
+		t.Errorf("Expected metric: %+v, got: %+v", exp, m)
+	}
+
+	if _, err := ParseOpenTSDBLine("sys.cpu.user 1529509020 1"); err != nil {
+		t.Error(err)
+	}
+
+	for _, l := range []string{"put", "put test 1529509020",
+		"put test x 1", "put test 1529509020 x", "put test 1 1 host"} {
+		if _, err := ParseOpenTSDBLine(l); err == nil {
+			t.Errorf("Expected error for line: %q", l)
+		}
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	t.Parallel()
+	in := "a 1 1529509020\n\ninvalid\nb 2 1529509020\nc 3 1529509020\n"
+	names, errs := []string{}, 0
+	err := ParseLines(strings.NewReader(in), ParseGraphiteLine,
+		func(m *RawMetric) error {
+			names = append(names, m.Metric)
+			return nil
+		}, func(err error) {
+			errs++
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(names, []string{"a", "b", "c"}) || errs != 1 {
+		t.Errorf("Expected metrics: [a b c], errors: 1, got: %v, %v", names,
+			errs)
+	}
+
+	stop := errors.New("stop")
+	names = []string{}
+	err = ParseLines(strings.NewReader(in), ParseGraphiteLine,
+		func(m *RawMetric) error {
+			names = append(names, m.Metric)
+			return stop
+		}, nil)
+	if !errors.Is(err, stop) || len(names) != 1 {
+		t.Errorf("Expected error: %v, metrics: 1, got: %v, %v", stop, err,
+			len(names))
+	}
+}
@@ -5,21 +5,14 @@ import (
 	"context"
 	"fmt"
 	"path"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // LocateMetric returns a list of nodes owning the specified metric
 func (sc *SnowthClient) LocateMetric(uuid string, metric string,
 	node ...*SnowthNode) ([]TopologyNode, error) {
-	if len(node) > 0 {
-		return sc.LocateMetricRemote(uuid, metric, node[0])
-	}
-
-	topo, err := sc.Topology()
-	if err != nil {
-		return nil, err
-	}
-
-	return topo.FindMetric(uuid, metric)
+	return sc.LocateMetricContext(context.Background(), uuid, metric, node...)
 }
 
 // LocateMetricContext is the context aware version of LocateMetric
@@ -29,7 +22,7 @@ func (sc *SnowthClient) LocateMetricContext(ctx context.Context, uuid string,
 		return sc.LocateMetricRemoteContext(ctx, uuid, metric, node[0])
 	}
 
-	topo, err := sc.Topology()
+	topo, err := sc.TopologyContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -58,7 +51,7 @@ func (sc *SnowthClient) LocateMetricRemoteContext(ctx context.Context,
 	}
 
 	body, _, err := sc.DoRequestContext(ctx, node, "GET",
-		path.Join("/locate/xml", uuid, metric), nil, nil)
+		path.Join(snowthapi.PathLocateXML, uuid, metric), nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package gosnowth
 
 import (
 	"bytes"
+	"context"
 	"encoding/xml"
 	"net/http"
 	"net/http/httptest"
@@ -207,3 +208,54 @@ func TestLocateMetricFindMetric(t *testing.T) {
 		t.Errorf("Expected tertiary node ID: %v, got: %v", exp, res[2].ID)
 	}
 }
+
+func TestTopologyContext(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml") {
+			_, _ = w.Write([]byte(topologyXMLTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if topo, err := sc.TopologyContext(ctx); err == nil || topo != nil {
+		t.Errorf("Expected error for cancelled context, got: %v", topo)
+	}
+
+	if ids := sc.FindMetricNodeIDsContext(ctx,
+		"1f846f26-0cfd-4df5-b4f1-e0930604e577", "test"); len(ids) != 0 {
+		t.Errorf("Expected no node IDs, got: %v", ids)
+	}
+
+	ids := sc.FindMetricNodeIDsContext(context.Background(),
+		"1f846f26-0cfd-4df5-b4f1-e0930604e577", "test")
+	if len(ids) != 3 {
+		t.Errorf("Expected node IDs: 3, got: %v", len(ids))
+	}
+
+	if _, err := sc.TopologyContext(ctx); err != nil {
+		t.Errorf("Expected cached topology, got error: %v", err)
+	}
+}
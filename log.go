@@ -0,0 +1,221 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LogLevel values are the severity levels of log entries.
+type LogLevel int
+
+// Log entry severity levels.
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// String returns the name of the log level.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+// FieldLogger values implement structured logging, in addition to the
+// behavior of Logger values. Each entry has a message and a list of
+// alternating keys and values, such as "node", "host:8112". The client logs
+// retries, node state changes and slow requests as structured entries. If the
+// logger assigned to the client does not implement this interface, the fields
+// are appended to the message and logged using the Logger interface. A zap
+// SugaredLogger implements this interface, and LogFunc values can be used to
+// adapt other structured loggers, such as slog or logrus.
+type FieldLogger interface {
+	Logger
+	Debugw(msg string, keysAndValues ...interface{})
+	Errorw(msg string, keysAndValues ...interface{})
+	Infow(msg string, keysAndValues ...interface{})
+	Warnw(msg string, keysAndValues ...interface{})
+}
+
+// LogFunc values are functions which write structured log entries. A LogFunc
+// implements the FieldLogger interface, so that any structured logger can be
+// assigned to the client by wrapping it in a function. Entries written using
+// the Logger interface are passed to the function with no fields.
+type LogFunc func(level LogLevel, msg string, keysAndValues ...interface{})
+
+// Debugf writes a log entry at the debug level.
+func (f LogFunc) Debugf(format string, args ...interface{}) {
+	f(LogLevelDebug, fmt.Sprintf(format, args...))
+}
+
+// Errorf writes a log entry at the error level.
+func (f LogFunc) Errorf(format string, args ...interface{}) {
+	f(LogLevelError, fmt.Sprintf(format, args...))
+}
+
+// Infof writes a log entry at the information level.
+func (f LogFunc) Infof(format string, args ...interface{}) {
+	f(LogLevelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warnf writes a log entry at the warning level.
+func (f LogFunc) Warnf(format string, args ...interface{}) {
+	f(LogLevelWarn, fmt.Sprintf(format, args...))
+}
+
+// Debugw writes a structured log entry at the debug level.
+func (f LogFunc) Debugw(msg string, keysAndValues ...interface{}) {
+	f(LogLevelDebug, msg, keysAndValues...)
+}
+
+// Errorw writes a structured log entry at the error level.
+func (f LogFunc) Errorw(msg string, keysAndValues ...interface{}) {
+	f(LogLevelError, msg, keysAndValues...)
+}
+
+// Infow writes a structured log entry at the information level.
+func (f LogFunc) Infow(msg string, keysAndValues ...interface{}) {
+	f(LogLevelInfo, msg, keysAndValues...)
+}
+
+// Warnw writes a structured log entry at the warning level.
+func (f LogFunc) Warnw(msg string, keysAndValues ...interface{}) {
+	f(LogLevelWarn, msg, keysAndValues...)
+}
+
+// formatFields returns a log message with key-value fields appended, for
+// loggers which do not implement the FieldLogger interface. A value without a
+// key is logged with the key "extra".
+func formatFields(msg string, keysAndValues []interface{}) string {
+	if len(keysAndValues) == 0 {
+		return msg
+	}
+
+	fields := make([]string, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		if i+1 == len(keysAndValues) {
+			fields = append(fields, fmt.Sprintf("extra=%v", keysAndValues[i]))
+			break
+		}
+
+		fields = append(fields, fmt.Sprintf("%v=%v", keysAndValues[i],
+			keysAndValues[i+1]))
+	}
+
+	return msg + ": " + strings.Join(fields, " ")
+}
+
+// logw writes a structured log entry at a level.
+func (sc *SnowthClient) logw(level LogLevel, msg string,
+	keysAndValues ...interface{}) {
+	if sc.log == nil {
+		return
+	}
+
+	if fl, ok := sc.log.(FieldLogger); ok {
+		switch level {
+		case LogLevelDebug:
+			fl.Debugw(msg, keysAndValues...)
+		case LogLevelInfo:
+			fl.Infow(msg, keysAndValues...)
+		case LogLevelWarn:
+			fl.Warnw(msg, keysAndValues...)
+		default:
+			fl.Errorw(msg, keysAndValues...)
+		}
+
+		return
+	}
+
+	msg = formatFields(msg, keysAndValues)
+	switch level {
+	case LogLevelDebug:
+		sc.log.Debugf("%s", msg)
+	case LogLevelInfo:
+		sc.log.Infof("%s", msg)
+	case LogLevelWarn:
+		sc.log.Warnf("%s", msg)
+	default:
+		sc.log.Errorf("%s", msg)
+	}
+}
+
+// LogDebugw writes a structured log entry at the debug level.
+func (sc *SnowthClient) LogDebugw(msg string, keysAndValues ...interface{}) {
+	sc.logw(LogLevelDebug, msg, keysAndValues...)
+}
+
+// LogInfow writes a structured log entry at the information level.
+func (sc *SnowthClient) LogInfow(msg string, keysAndValues ...interface{}) {
+	sc.logw(LogLevelInfo, msg, keysAndValues...)
+}
+
+// LogWarnw writes a structured log entry at the warning level.
+func (sc *SnowthClient) LogWarnw(msg string, keysAndValues ...interface{}) {
+	sc.logw(LogLevelWarn, msg, keysAndValues...)
+}
+
+// LogErrorw writes a structured log entry at the error level.
+func (sc *SnowthClient) LogErrorw(msg string, keysAndValues ...interface{}) {
+	sc.logw(LogLevelError, msg, keysAndValues...)
+}
+
+// SlowRequestThreshold gets the request latency at or above which requests
+// are logged as slow requests. A value of zero disables logging slow requests.
+func (c *Config) SlowRequestThreshold() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.slowRequestThreshold
+}
+
+// SetSlowRequestThreshold sets the request latency at or above which requests
+// are logged, at the warning level, as slow requests. A value of zero, the
+// default, disables logging slow requests.
+func (c *Config) SetSlowRequestThreshold(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("invalid slow request threshold value")
+	}
+
+	c.Lock()
+	c.slowRequestThreshold = d
+	c.Unlock()
+	return nil
+}
+
+// SetSlowRequestThreshold sets the request latency at or above which requests
+// are logged as slow requests. A value of zero disables logging slow requests.
+func (sc *SnowthClient) SetSlowRequestThreshold(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.slowRequestThreshold = d
+}
+
+// logSlowRequest logs a request to a node if its latency is at or above the
+// slow request threshold. A status of zero indicates that the request failed
+// without a response.
+func (sc *SnowthClient) logSlowRequest(node *SnowthNode, endpoint string,
+	latency time.Duration, status int) {
+	sc.RLock()
+	threshold := sc.slowRequestThreshold
+	sc.RUnlock()
+	if threshold <= 0 || latency < threshold {
+		return
+	}
+
+	sc.LogWarnw("gosnowth slow request", "node", nodeHost(node),
+		"endpoint", endpointName(endpoint), "latency", latency,
+		"status", status)
+}
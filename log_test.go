@@ -0,0 +1,162 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fieldLogEntry struct {
+	level  LogLevel
+	msg    string
+	fields map[string]interface{}
+}
+
+type fieldLog struct {
+	sync.Mutex
+	entries []fieldLogEntry
+}
+
+func (l *fieldLog) log(level LogLevel, msg string,
+	keysAndValues ...interface{}) {
+	e := fieldLogEntry{
+		level:  level,
+		msg:    msg,
+		fields: map[string]interface{}{},
+	}
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		e.fields[fmt.Sprint(keysAndValues[i])] = keysAndValues[i+1]
+	}
+
+	l.Lock()
+	l.entries = append(l.entries, e)
+	l.Unlock()
+}
+
+func (l *fieldLog) find(msg string) *fieldLogEntry {
+	l.Lock()
+	defer l.Unlock()
+	for _, e := range l.entries {
+		if e.msg == msg {
+			return &e
+		}
+	}
+
+	return nil
+}
+
+func TestLogLevel(t *testing.T) {
+	t.Parallel()
+	if s := LogLevelWarn.String(); s != "warn" {
+		t.Errorf("Expected level: warn, got: %v", s)
+	}
+
+	if s := LogLevel(7).String(); s != "level(7)" {
+		t.Errorf("Expected level: level(7), got: %v", s)
+	}
+}
+
+func TestLogFields(t *testing.T) {
+	t.Parallel()
+	sc := &SnowthClient{}
+	sc.LogInfow("ignored", "key", "value")
+	ml := &mockLog{}
+	sc.SetLog(ml)
+	sc.LogInfow("test", "node", "n1:8112", "status", 200, "odd")
+	exp := "INFO test: node=n1:8112 status=200 extra=odd"
+	if ml.last != exp {
+		t.Errorf("Expected log entry: %v, got: %v", exp, ml.last)
+	}
+
+	sc.LogErrorw("test")
+	exp = "ERROR test"
+	if ml.last != exp {
+		t.Errorf("Expected log entry: %v, got: %v", exp, ml.last)
+	}
+
+	fl := &fieldLog{}
+	sc.SetLog(LogFunc(fl.log))
+	sc.LogWarnw("test", "node", "n1:8112")
+	sc.LogDebugf("test %d", 1)
+	if e := fl.find("test"); e == nil || e.level != LogLevelWarn ||
+		e.fields["node"] != "n1:8112" {
+		t.Errorf("Expected structured log entry, got: %+v", e)
+	}
+
+	if e := fl.find("test 1"); e == nil || e.level != LogLevelDebug {
+		t.Errorf("Expected formatted log entry, got: %+v", e)
+	}
+}
+
+func TestClientLogEvents(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			time.Sleep(10 * time.Millisecond)
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetSlowRequestThreshold(-1); err == nil {
+		t.Error("Expected error for invalid slow request threshold")
+	}
+
+	if err := cfg.SetSlowRequestThreshold(time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	fl := &fieldLog{}
+	sc.SetLog(LogFunc(fl.log))
+	node := sc.GetActiveNode()
+	if _, err := sc.GetStats(node); err != nil {
+		t.Fatal(err)
+	}
+
+	e := fl.find("gosnowth slow request")
+	if e == nil {
+		t.Fatal("Expected slow request log entry")
+	}
+
+	if e.level != LogLevelWarn || e.fields["endpoint"] != "/stats.json" ||
+		e.fields["status"] != 200 {
+		t.Errorf("Expected slow request fields, got: %+v", e)
+	}
+
+	sc.DeactivateNodes(node)
+	if e := fl.find("gosnowth node deactivated"); e == nil ||
+		e.fields["node"] != node.GetURL().Host {
+		t.Errorf("Expected node deactivated log entry, got: %+v", e)
+	}
+
+	sc.ActivateNodes(node)
+	if e := fl.find("gosnowth node activated"); e == nil ||
+		e.level != LogLevelInfo {
+		t.Errorf("Expected node activated log entry, got: %+v", e)
+	}
+}
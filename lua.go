@@ -7,6 +7,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // ExtensionParam values contain information about an extension parameter.
@@ -138,14 +140,14 @@ func (sc *SnowthClient) GetLuaExtensionsContext(ctx context.Context,
 		node = sc.GetActiveNode()
 	}
 
-	u := sc.getURL(node, "/extension/lua")
+	u := sc.getURL(node, snowthapi.PathLua)
 	r := LuaExtensions{}
 	body, _, err := sc.DoRequestContext(ctx, node, "GET", u, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -177,7 +179,7 @@ func (sc *SnowthClient) ExecLuaExtensionContext(ctx context.Context,
 		node = sc.GetActiveNode()
 	}
 
-	u := sc.getURL(node, "/extension/lua/"+name)
+	u := sc.getURL(node, snowthapi.PathLua+"/"+name)
 	if len(params) > 0 {
 		qp := url.Values{}
 		for _, p := range params {
@@ -193,7 +195,7 @@ func (sc *SnowthClient) ExecLuaExtensionContext(ctx context.Context,
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
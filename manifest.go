@@ -0,0 +1,348 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrChecksumMismatch is returned when data read from IRONdb does not match
+// the checksum recorded in a manifest.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// Manifest format version and checksum algorithm.
+const (
+	ManifestVersion   = 1
+	ManifestAlgorithm = "sha256"
+)
+
+// Kinds of data contained in manifest chunks.
+const (
+	ChunkNumeric   = "numeric"
+	ChunkText      = "text"
+	ChunkHistogram = "histogram"
+)
+
+// ManifestChunk values describe a chunk of exported data: the data of one
+// metric within a time range, the number of values it contains, and the
+// checksum of those values. The Period value is the rollup period of
+// histogram data, in seconds, and is not used for other kinds of data.
+type ManifestChunk struct {
+	UUID     string    `json:"uuid"`
+	Metric   string    `json:"metric"`
+	Kind     string    `json:"kind"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Period   int64     `json:"period,omitempty"`
+	Count    int64     `json:"count"`
+	Checksum string    `json:"checksum"`
+}
+
+// Manifest values record the checksums of chunks of data exported from an
+// IRONdb cluster, so that the data can be verified after it is imported into
+// another cluster. Manifests are encoded in JSON format.
+type Manifest struct {
+	Version   int             `json:"version"`
+	Algorithm string          `json:"algorithm"`
+	Created   time.Time       `json:"created"`
+	Source    string          `json:"source,omitempty"`
+	Chunks    []ManifestChunk `json:"chunks"`
+}
+
+// NewManifest creates a new, empty, manifest. The source value describes the
+// origin of the exported data, such as the name of the source cluster.
+func NewManifest(source string) *Manifest {
+	return &Manifest{
+		Version:   ManifestVersion,
+		Algorithm: ManifestAlgorithm,
+		Created:   time.Now().UTC(),
+		Source:    source,
+		Chunks:    []ManifestChunk{},
+	}
+}
+
+// Add adds chunks to the manifest.
+func (m *Manifest) Add(chunks ...ManifestChunk) {
+	m.Chunks = append(m.Chunks, chunks...)
+}
+
+// Encode writes the manifest in JSON format.
+func (m *Manifest) Encode(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(m); err != nil {
+		return fmt.Errorf("unable to encode manifest: %w", err)
+	}
+
+	return nil
+}
+
+// DecodeManifest reads a manifest in JSON format, returning an error if the
+// manifest version or checksum algorithm is not supported.
+func DecodeManifest(r io.Reader) (*Manifest, error) {
+	m := &Manifest{}
+	if err := json.NewDecoder(r).Decode(m); err != nil {
+		return nil, fmt.Errorf("unable to decode manifest: %w", err)
+	}
+
+	if m.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version: %d", m.Version)
+	}
+
+	if m.Algorithm != ManifestAlgorithm {
+		return nil, fmt.Errorf("unsupported manifest checksum algorithm: %s",
+			m.Algorithm)
+	}
+
+	return m, nil
+}
+
+// checksumLines returns the checksum of a list of canonical value lines,
+// which are sorted so that the checksum does not depend on the order in which
+// values were read.
+func checksumLines(lines []string) string {
+	sort.Strings(lines)
+	h := sha256.New()
+	for _, l := range lines {
+		_, _ = io.WriteString(h, l)
+		_, _ = io.WriteString(h, "\n")
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// checksumTime returns the canonical representation of a value timestamp, in
+// milliseconds, padded so that lines sort in time order.
+func checksumTime(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano()/int64(time.Millisecond))
+}
+
+// ChecksumNumeric returns the checksum of a list of raw numeric values.
+func ChecksumNumeric(values []RawNumericValue) string {
+	lines := make([]string, len(values))
+	for i, v := range values {
+		lines[i] = checksumTime(v.Time) + "\t" +
+			strconv.FormatFloat(v.Value, 'g', -1, 64)
+	}
+
+	return checksumLines(lines)
+}
+
+// ChecksumText returns the checksum of a list of text values.
+func ChecksumText(values []TextValue) string {
+	lines := make([]string, len(values))
+	for i, v := range values {
+		s := "null"
+		if v.Value != nil {
+			s = strconv.Quote(*v.Value)
+		}
+
+		lines[i] = checksumTime(v.Time) + "\t" + s
+	}
+
+	return checksumLines(lines)
+}
+
+// ChecksumHistogram returns the checksum of a list of histogram values.
+func ChecksumHistogram(values []HistogramValue) string {
+	lines := make([]string, len(values))
+	for i, v := range values {
+		bins := make([]string, 0, len(v.Data))
+		for b, n := range v.Data {
+			bins = append(bins, b+"="+strconv.FormatInt(n, 10))
+		}
+
+		sort.Strings(bins)
+		lines[i] = checksumTime(v.Time) + "\t" +
+			strconv.FormatInt(int64(v.Period.Seconds()), 10) + "\t" +
+			strings.Join(bins, ",")
+	}
+
+	return checksumLines(lines)
+}
+
+// ChecksumChunk reads the data of a manifest chunk from IRONdb, and sets the
+// count and checksum of the chunk. This is used to record the checksum of
+// data as it is exported.
+func (sc *SnowthClient) ChecksumChunk(chunk *ManifestChunk,
+	nodes ...*SnowthNode) error {
+	return sc.ChecksumChunkContext(context.Background(), chunk, nodes...)
+}
+
+// ChecksumChunkContext is the context aware version of ChecksumChunk.
+func (sc *SnowthClient) ChecksumChunkContext(ctx context.Context,
+	chunk *ManifestChunk, nodes ...*SnowthNode) error {
+	if chunk == nil {
+		return fmt.Errorf("invalid manifest chunk: nil")
+	}
+
+	count, sum, err := sc.readChecksum(ctx, chunk, nodes...)
+	if err != nil {
+		return err
+	}
+
+	chunk.Count, chunk.Checksum = count, sum
+	return nil
+}
+
+// readChecksum reads the data of a manifest chunk from IRONdb, and returns the
+// number of values read and their checksum.
+func (sc *SnowthClient) readChecksum(ctx context.Context,
+	chunk *ManifestChunk, nodes ...*SnowthNode) (int64, string, error) {
+	switch chunk.Kind {
+	case ChunkNumeric:
+		v, err := sc.ReadRawNumericValuesContext(ctx, chunk.Start, chunk.End,
+			chunk.UUID, chunk.Metric, nodes...)
+		if err != nil {
+			return 0, "", err
+		}
+
+		return int64(len(v)), ChecksumNumeric(v), nil
+	case ChunkText:
+		v, err := sc.ReadTextValuesContext(ctx, chunk.UUID, chunk.Metric,
+			chunk.Start, chunk.End, nodes...)
+		if err != nil {
+			return 0, "", err
+		}
+
+		return int64(len(v)), ChecksumText(v), nil
+	case ChunkHistogram:
+		if chunk.Period <= 0 {
+			return 0, "", fmt.Errorf("invalid histogram chunk period: %d",
+				chunk.Period)
+		}
+
+		v, err := sc.ReadHistogramValuesContext(ctx, chunk.UUID, chunk.Metric,
+			time.Duration(chunk.Period)*time.Second, chunk.Start, chunk.End,
+			nodes...)
+		if err != nil {
+			return 0, "", err
+		}
+
+		return int64(len(v)), ChecksumHistogram(v), nil
+	default:
+		return 0, "", fmt.Errorf("invalid manifest chunk kind: %q", chunk.Kind)
+	}
+}
+
+// ManifestVerifyOptions values contain optional parameters used to control
+// the verification of a manifest.
+type ManifestVerifyOptions struct {
+	// Concurrency is the maximum number of chunks verified in parallel. The
+	// default is 4.
+	Concurrency int
+}
+
+// ChunkVerification values contain the result of verifying one chunk of a
+// manifest. The Count and Checksum values are those of the data read from
+// IRONdb.
+type ChunkVerification struct {
+	Chunk    ManifestChunk
+	Count    int64
+	Checksum string
+	Err      error
+}
+
+// ManifestReport values contain the results of verifying a manifest.
+type ManifestReport struct {
+	Chunks   []ChunkVerification
+	Verified int
+	Failed   int
+}
+
+// Err returns an error combining the errors of all chunks which failed
+// verification, or nil if every chunk was verified.
+func (r *ManifestReport) Err() error {
+	mErr := newMultiError()
+	for _, c := range r.Chunks {
+		if c.Err != nil {
+			mErr.Add(fmt.Errorf("chunk %s %s %s-%s: %w", c.Chunk.UUID,
+				c.Chunk.Metric, formatTimestamp(c.Chunk.Start),
+				formatTimestamp(c.Chunk.End), c.Err))
+		}
+	}
+
+	if mErr.HasError() {
+		return mErr
+	}
+
+	return nil
+}
+
+// VerifyManifest reads the data of every chunk of a manifest from IRONdb, and
+// verifies that it matches the count and checksum recorded in the manifest.
+// This is used to prove that data imported into a cluster matches the data
+// exported from the source cluster. Chunks which do not match fail with
+// ErrChecksumMismatch, and the returned error combines the errors of all
+// chunks which failed.
+func (sc *SnowthClient) VerifyManifest(m *Manifest,
+	options *ManifestVerifyOptions,
+	nodes ...*SnowthNode) (*ManifestReport, error) {
+	return sc.VerifyManifestContext(context.Background(), m, options,
+		nodes...)
+}
+
+// VerifyManifestContext is the context aware version of VerifyManifest.
+func (sc *SnowthClient) VerifyManifestContext(ctx context.Context,
+	m *Manifest, options *ManifestVerifyOptions,
+	nodes ...*SnowthNode) (*ManifestReport, error) {
+	if m == nil {
+		return nil, fmt.Errorf("invalid manifest: nil")
+	}
+
+	if m.Algorithm != ManifestAlgorithm {
+		return nil, fmt.Errorf("unsupported manifest checksum algorithm: %s",
+			m.Algorithm)
+	}
+
+	opts := ManifestVerifyOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+
+	r := &ManifestReport{Chunks: make([]ChunkVerification, len(m.Chunks))}
+	g := sc.NewWorkGroup(ctx, opts.Concurrency)
+	for i := range m.Chunks {
+		cv := &r.Chunks[i]
+		cv.Chunk = m.Chunks[i]
+		if err := g.Go(func(ctx context.Context) error {
+			cv.Count, cv.Checksum, cv.Err = sc.readChecksum(ctx, &cv.Chunk,
+				nodes...)
+			if cv.Err == nil && (cv.Count != cv.Chunk.Count ||
+				cv.Checksum != cv.Chunk.Checksum) {
+				cv.Err = fmt.Errorf("%w: expected %d values, got %d",
+					ErrChecksumMismatch, cv.Chunk.Count, cv.Count)
+			}
+
+			return nil
+		}); err != nil {
+			cv.Err = err
+		}
+	}
+
+	// Errors are recorded in each chunk, and returned by the report.
+	_ = g.Wait()
+	for _, c := range r.Chunks {
+		if c.Err != nil {
+			r.Failed++
+			continue
+		}
+
+		r.Verified++
+	}
+
+	return r, r.Err()
+}
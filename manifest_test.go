@@ -0,0 +1,172 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChecksums(t *testing.T) {
+	t.Parallel()
+	a := []RawNumericValue{
+		{Time: time.Unix(1, 0), Value: 1.5},
+		{Time: time.Unix(2, 0), Value: 2},
+	}
+
+	b := []RawNumericValue{a[1], a[0]}
+	if ChecksumNumeric(a) != ChecksumNumeric(b) {
+		t.Error("Expected checksum to be independent of value order")
+	}
+
+	b[0].Value = 3
+	if ChecksumNumeric(a) == ChecksumNumeric(b) {
+		t.Error("Expected checksum to change with values")
+	}
+
+	s := "test"
+	if ChecksumText([]TextValue{{Time: time.Unix(1, 0), Value: &s}}) ==
+		ChecksumText([]TextValue{{Time: time.Unix(1, 0)}}) {
+		t.Error("Expected checksum to distinguish null text values")
+	}
+
+	h := []HistogramValue{{
+		Time:   time.Unix(300, 0),
+		Period: 300 * time.Second,
+		Data:   map[string]int64{"+23e-004": 1, "+85e-004": 2},
+	}}
+
+	if ChecksumHistogram(h) == ChecksumHistogram(nil) {
+		t.Error("Expected checksum to change with histogram values")
+	}
+}
+
+func TestManifestEncoding(t *testing.T) {
+	t.Parallel()
+	m := NewManifest("source")
+	m.Add(ManifestChunk{
+		UUID:     "11223344-5566-7788-9900-aabbccddeeff",
+		Metric:   "test",
+		Kind:     ChunkNumeric,
+		Start:    time.Unix(0, 0).UTC(),
+		End:      time.Unix(300, 0).UTC(),
+		Count:    3,
+		Checksum: "abc",
+	})
+
+	buf := &bytes.Buffer{}
+	if err := m.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := DecodeManifest(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Source != "source" || len(res.Chunks) != 1 ||
+		res.Chunks[0] != m.Chunks[0] {
+		t.Errorf("Expected manifest: %+v, got: %+v", m, res)
+	}
+
+	if _, err := DecodeManifest(strings.NewReader(
+		`{"version":2,"algorithm":"sha256"}`)); err == nil {
+		t.Error("Expected error for unsupported manifest version")
+	}
+
+	if _, err := DecodeManifest(strings.NewReader(
+		`{"version":1,"algorithm":"md5"}`)); err == nil {
+		t.Error("Expected error for unsupported checksum algorithm")
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			_, _ = w.Write([]byte(
+				`[[1529509063064,0],[1529509122985,1],[1529509183764,2]]`))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/read/") {
+			_, _ = w.Write([]byte(textTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	node := sc.GetActiveNode()
+	num := ManifestChunk{
+		UUID:   "11223344-5566-7788-9900-aabbccddeeff",
+		Metric: "test",
+		Kind:   ChunkNumeric,
+		Start:  time.Unix(1529509020, 0),
+		End:    time.Unix(1529509200, 0),
+	}
+
+	if err := sc.ChecksumChunk(&num, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if num.Count != 3 || num.Checksum == "" {
+		t.Errorf("Expected chunk count: 3 and checksum, got: %+v", num)
+	}
+
+	text := num
+	text.Kind = ChunkText
+	if err := sc.ChecksumChunk(&text, node); err != nil {
+		t.Fatal(err)
+	}
+
+	bad := num
+	bad.Kind = "invalid"
+	if err := sc.ChecksumChunk(&bad, node); err == nil {
+		t.Error("Expected error for invalid chunk kind")
+	}
+
+	m := NewManifest("source")
+	m.Add(num, text)
+	r, err := sc.VerifyManifest(m, nil, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Verified != 2 || r.Failed != 0 {
+		t.Errorf("Expected verified chunks: 2, got: %+v", r)
+	}
+
+	m.Chunks[1].Checksum = "mismatch"
+	r, err = sc.VerifyManifest(m, nil, node)
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Expected error: %v, got: %v", ErrChecksumMismatch, err)
+	}
+
+	if r.Verified != 1 || r.Failed != 1 ||
+		!errors.Is(r.Chunks[1].Err, ErrChecksumMismatch) {
+		t.Errorf("Expected failed chunk: 1, got: %+v", r)
+	}
+}
@@ -0,0 +1,192 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/circonusllhist"
+	flatbuffers "github.com/google/flatbuffers/go"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// MetricListBuilder values build FlatBuffers metric lists containing numeric,
+// text and histogram samples of a check, to be written to IRONdb using
+// WriteRawMetricList. This is the most efficient way to write data to IRONdb.
+// Metric names may contain stream tags, and additional stream tags may be
+// specified for each sample. Metric list builders are not safe for concurrent
+// use.
+type MetricListBuilder struct {
+	accountID int32
+	checkUUID string
+	checkName string
+	list      *noit.MetricListT
+}
+
+// NewMetricListBuilder creates a new metric list builder for samples of the
+// check with the specified account ID, UUID and name.
+func NewMetricListBuilder(accountID int32, checkUUID,
+	checkName string) *MetricListBuilder {
+	return &MetricListBuilder{
+		accountID: accountID,
+		checkUUID: checkUUID,
+		checkName: checkName,
+		list:      &noit.MetricListT{Metrics: []*noit.MetricT{}},
+	}
+}
+
+// fbTimestamp returns the FlatBuffers representation of a sample timestamp,
+// in milliseconds since the epoch.
+func fbTimestamp(ts time.Time) uint64 {
+	return uint64(ts.UnixNano() / int64(time.Millisecond))
+}
+
+// add adds a sample with a value to the metric list.
+func (b *MetricListBuilder) add(name string, ts time.Time,
+	t noit.MetricValueUnion, v interface{}, tags []string) {
+	ms := fbTimestamp(ts)
+	var st []string
+	if len(tags) > 0 {
+		st = append([]string(nil), tags...)
+	}
+
+	b.list.Metrics = append(b.list.Metrics, &noit.MetricT{
+		Timestamp: ms,
+		CheckName: b.checkName,
+		CheckUuid: b.checkUUID,
+		AccountId: b.accountID,
+		Value: &noit.MetricValueT{
+			Name:       name,
+			Timestamp:  ms,
+			Value:      &noit.MetricValueUnionT{Type: t, Value: v},
+			StreamTags: st,
+		},
+	})
+}
+
+// AddNumeric adds a floating point numeric sample to the metric list.
+func (b *MetricListBuilder) AddNumeric(name string, ts time.Time,
+	value float64, tags ...string) *MetricListBuilder {
+	b.add(name, ts, noit.MetricValueUnionDoubleValue,
+		&noit.DoubleValueT{Value: value}, tags)
+	return b
+}
+
+// AddInteger adds an integer numeric sample to the metric list. Integer
+// samples are written without loss of precision.
+func (b *MetricListBuilder) AddInteger(name string, ts time.Time,
+	value int64, tags ...string) *MetricListBuilder {
+	b.add(name, ts, noit.MetricValueUnionLongValue,
+		&noit.LongValueT{Value: value}, tags)
+	return b
+}
+
+// AddText adds a text sample to the metric list.
+func (b *MetricListBuilder) AddText(name string, ts time.Time,
+	value string, tags ...string) *MetricListBuilder {
+	b.add(name, ts, noit.MetricValueUnionStringValue,
+		&noit.StringValueT{Value: value}, tags)
+	return b
+}
+
+// AddHistogram adds a histogram sample to the metric list. An error is
+// returned if the histogram cannot be encoded.
+func (b *MetricListBuilder) AddHistogram(name string, ts time.Time,
+	value *circonusllhist.Histogram, tags ...string) error {
+	h, err := fbHistogram(value)
+	if err != nil {
+		return err
+	}
+
+	b.add(name, ts, noit.MetricValueUnionHistogram, h, tags)
+	return nil
+}
+
+// fbHistogram converts a histogram into its FlatBuffers representation.
+func fbHistogram(h *circonusllhist.Histogram) (*noit.HistogramT, error) {
+	res := &noit.HistogramT{Buckets: []*noit.HistogramBucketT{}}
+	if h == nil {
+		return res, nil
+	}
+
+	for _, s := range h.DecStrings() {
+		bk, err := parseHistogramBucket(s)
+		if err != nil {
+			return nil, err
+		}
+
+		res.Buckets = append(res.Buckets, bk)
+	}
+
+	return res, nil
+}
+
+// parseHistogramBucket parses a histogram bin in the H[1.2e+03]=4 format used
+// by circonusllhist into a FlatBuffers histogram bucket.
+func parseHistogramBucket(s string) (*noit.HistogramBucketT, error) {
+	if !strings.HasPrefix(s, "H[") {
+		return nil, fmt.Errorf("invalid histogram bin: %s", s)
+	}
+
+	i := strings.Index(s, "]=")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid histogram bin: %s", s)
+	}
+
+	count, err := strconv.ParseUint(s[i+2:], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid histogram bin count: %s", s)
+	}
+
+	parts := strings.SplitN(s[2:i], "e", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid histogram bin value: %s", s)
+	}
+
+	m, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid histogram bin value: %s", s)
+	}
+
+	exp, err := strconv.ParseInt(parts[1], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid histogram bin exponent: %s", s)
+	}
+
+	return &noit.HistogramBucketT{
+		Val:   int8(math.Round(m * 10)),
+		Exp:   int8(exp),
+		Count: count,
+	}, nil
+}
+
+// Len returns the number of samples in the metric list.
+func (b *MetricListBuilder) Len() int {
+	return len(b.list.Metrics)
+}
+
+// Reset removes all samples from the metric list.
+func (b *MetricListBuilder) Reset() {
+	b.list = &noit.MetricListT{Metrics: []*noit.MetricT{}}
+}
+
+// MetricList returns the metric list containing the samples added to the
+// builder, to be written using WriteRawMetricList.
+func (b *MetricListBuilder) MetricList() *noit.MetricListT {
+	return b.list
+}
+
+// Bytes returns the metric list encoded in the FlatBuffers format used by the
+// IRONdb raw write endpoint, to be written using WriteRaw. If the builder
+// argument is not nil, it is reused to encode the data.
+func (b *MetricListBuilder) Bytes(builder *flatbuffers.Builder) []byte {
+	if builder == nil {
+		builder = flatbuffers.NewBuilder(1024)
+	}
+
+	return packMetricList(builder, b.list.Metrics)
+}
@@ -0,0 +1,173 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonusllhist"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+func TestParseHistogramBucket(t *testing.T) {
+	t.Parallel()
+	bk, err := parseHistogramBucket("H[1.2e+03]=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bk.Val != 12 || bk.Exp != 3 || bk.Count != 4 {
+		t.Errorf("Expected bucket: {12 3 4}, got: %+v", bk)
+	}
+
+	bk, err = parseHistogramBucket("H[-2.3e-01]=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bk.Val != -23 || bk.Exp != -1 || bk.Count != 1 {
+		t.Errorf("Expected bucket: {-23 -1 1}, got: %+v", bk)
+	}
+
+	for _, s := range []string{"1.2e+03=4", "H[1.2e+03]", "H[1.2]=4",
+		"H[1.2e+03]=x"} {
+		if _, err := parseHistogramBucket(s); err == nil {
+			t.Errorf("Expected error for bin: %s", s)
+		}
+	}
+}
+
+func TestMetricListBuilder(t *testing.T) {
+	t.Parallel()
+	ts := time.Unix(1529509020, 0)
+	h := circonusllhist.New()
+	if err := h.RecordValues(1.2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewMetricListBuilder(1, "11223344-5566-7788-9900-aabbccddeeff",
+		"test")
+	b.AddNumeric("num", ts, 1.5, "a:b").AddInteger("int", ts, 1<<60).
+		AddText("text", ts, "value")
+	if err := b.AddHistogram("hist", ts, h); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.Len() != 4 {
+		t.Fatalf("Expected samples: 4, got: %v", b.Len())
+	}
+
+	buf := b.Bytes(nil)
+	if string(buf[4:8]) != "CIML" {
+		t.Errorf("Expected file identifier: CIML, got: %s", buf[4:8])
+	}
+
+	ml := noit.GetRootAsMetricList(buf, 0).UnPack()
+	if len(ml.Metrics) != 4 {
+		t.Fatalf("Expected metrics: 4, got: %v", len(ml.Metrics))
+	}
+
+	m := ml.Metrics[0]
+	if m.Timestamp != 1529509020000 || m.AccountId != 1 ||
+		m.CheckName != "test" || m.Value.Name != "num" ||
+		len(m.Value.StreamTags) != 1 || m.Value.StreamTags[0] != "a:b" {
+		t.Errorf("Unexpected metric: %+v %+v", m, m.Value)
+	}
+
+	if v, ok := m.Value.Value.Value.(*noit.DoubleValueT); !ok ||
+		v.Value != 1.5 {
+		t.Errorf("Expected value: 1.5, got: %+v", m.Value.Value.Value)
+	}
+
+	if v, ok := ml.Metrics[1].Value.Value.Value.(*noit.LongValueT); !ok ||
+		v.Value != 1<<60 {
+		t.Errorf("Expected value: %v, got: %+v", int64(1<<60),
+			ml.Metrics[1].Value.Value.Value)
+	}
+
+	if v, ok := ml.Metrics[2].Value.Value.Value.(*noit.StringValueT); !ok ||
+		v.Value != "value" {
+		t.Errorf("Expected value: value, got: %+v",
+			ml.Metrics[2].Value.Value.Value)
+	}
+
+	hv, ok := ml.Metrics[3].Value.Value.Value.(*noit.HistogramT)
+	if !ok || len(hv.Buckets) != 1 {
+		t.Fatalf("Expected histogram with 1 bucket, got: %+v",
+			ml.Metrics[3].Value.Value.Value)
+	}
+
+	if bk := hv.Buckets[0]; bk.Val != 12 || bk.Exp != 0 || bk.Count != 3 {
+		t.Errorf("Expected bucket: {12 0 3}, got: %+v", bk)
+	}
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			if ct := r.Header.Get("Content-Type"); ct !=
+				MetriclistFlatbufferContentType {
+				t.Errorf("Expected content type: %v, got: %v",
+					MetriclistFlatbufferContentType, ct)
+			}
+
+			b, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				t.Error("Unable to read request body")
+			}
+
+			ml := noit.GetRootAsMetricList(b, 0).UnPack()
+			if len(ml.Metrics) != 4 {
+				t.Errorf("Expected metrics: 4, got: %v", len(ml.Metrics))
+			}
+
+			_, _ = w.Write([]byte(`{"records":4,"updated":4,` +
+				`"misdirected":0,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	res, err := sc.WriteRawMetricList(b.MetricList(), nil,
+		&SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 4 {
+		t.Errorf("Expected records: 4, got: %v", res.Records)
+	}
+
+	b.Reset()
+	if b.Len() != 0 {
+		t.Errorf("Expected samples: 0, got: %v", b.Len())
+	}
+}
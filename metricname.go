@@ -0,0 +1,81 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetricTag values represent stream tags, with decoded categories and values.
+type MetricTag struct {
+	Category string
+	Value    string
+}
+
+// MetricName values represent metric names, separated into the base name and
+// the stream tags. The String method composes the canonical form of the name.
+type MetricName struct {
+	Base       string
+	StreamTags []MetricTag
+}
+
+// ParseMetricName parses a metric name, such as "foo|ST[a:b,c:d]", into its
+// base name and stream tags. Base64 encoded tag categories and values are
+// decoded. An error is returned if the stream tags are malformed.
+func ParseMetricName(name string) (*MetricName, error) {
+	if strings.Contains(name, "|ST[") && !strings.HasSuffix(name, "]") {
+		return nil, fmt.Errorf("invalid metric name, unterminated stream "+
+			"tags: %s", name)
+	}
+
+	base, tags := splitMetricName(name)
+	mn := &MetricName{Base: base}
+	for _, t := range tags {
+		kv := strings.SplitN(t, ":", 2)
+		if kv[0] == "" {
+			return nil, fmt.Errorf("invalid metric name, empty stream tag "+
+				"category: %s", name)
+		}
+
+		mt := MetricTag{Category: decodeTagPart(kv[0])}
+		if len(kv) == 2 {
+			mt.Value = decodeTagPart(kv[1])
+		}
+
+		mn.StreamTags = append(mn.StreamTags, mt)
+	}
+
+	return mn, nil
+}
+
+// String returns the canonical form of the metric name, with the stream tags
+// encoded, sorted, and deduplicated, as described by CanonicalMetricName.
+func (mn *MetricName) String() string {
+	tags := make([]string, 0, len(mn.StreamTags))
+	for _, t := range mn.StreamTags {
+		if t.Category == "" {
+			continue
+		}
+
+		tag := canonicalTagPart(t.Category, false)
+		if t.Value != "" {
+			tag += ":" + canonicalTagPart(t.Value, true)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return CanonicalMetricName(mn.Base, tags...)
+}
+
+// Tag returns the value of the first stream tag with the specified category,
+// and whether such a tag exists.
+func (mn *MetricName) Tag(cat string) (string, bool) {
+	for _, t := range mn.StreamTags {
+		if t.Category == cat {
+			return t.Value, true
+		}
+	}
+
+	return "", false
+}
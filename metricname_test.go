@@ -0,0 +1,56 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMetricName(t *testing.T) {
+	t.Parallel()
+	mn, err := ParseMetricName(`foo|ST[c:d,b"YSBi":b"eDp5",flag]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &MetricName{Base: "foo", StreamTags: []MetricTag{
+		{Category: "c", Value: "d"},
+		{Category: "a b", Value: "x:y"},
+		{Category: "flag"},
+	}}
+	if !reflect.DeepEqual(mn, exp) {
+		t.Errorf("Expected metric name: %+v, got: %+v", exp, mn)
+	}
+
+	if v, ok := mn.Tag("a b"); !ok || v != "x:y" {
+		t.Errorf("Expected tag value: x:y, got: %v", v)
+	}
+
+	if _, ok := mn.Tag("z"); ok {
+		t.Error("Expected missing tag")
+	}
+
+	if s := mn.String(); s != `foo|ST[b"YSBi":x:y,c:d,flag]` {
+		t.Errorf("Expected name: foo|ST[b\"YSBi\":x:y,c:d,flag], got: %v", s)
+	}
+
+	mn = &MetricName{Base: "bar", StreamTags: []MetricTag{
+		{Category: "z", Value: "1"},
+		{Category: "a", Value: "with space"},
+		{Category: "z", Value: "1"},
+		{},
+	}}
+	if s := mn.String(); s != `bar|ST[a:b"d2l0aCBzcGFjZQ==",z:1]` {
+		t.Errorf("Unexpected name: %v", s)
+	}
+
+	if mn, err = ParseMetricName("plain"); err != nil || mn.String() != "plain" {
+		t.Errorf("Unexpected plain name: %v, %v", mn, err)
+	}
+
+	for _, n := range []string{"foo|ST[a:b", "foo|ST[:b]", "foo|ST[a:b]x"} {
+		if _, err := ParseMetricName(n); err == nil {
+			t.Errorf("Expected error for name: %v", n)
+		}
+	}
+}
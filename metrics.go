@@ -0,0 +1,324 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bucket upper bounds of the histograms of request latencies, in seconds, and
+// of write batch sizes, in records.
+var (
+	latencyBuckets   = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	batchSizeBuckets = []float64{1, 10, 100, 1000, 10000, 100000, 1000000}
+)
+
+// endpointSegment matches the URL path segments which are part of endpoint
+// names, rather than parameters such as check UUIDs and metric names.
+var endpointSegment = regexp.MustCompile(`^[a-z_][a-z0-9_.]{0,23}$`)
+
+// endpointName returns the name of the endpoint of a request reference, which
+// is the URL path without any parameters, such as "/find/tags" for a request
+// to "/find/1/tags".
+func endpointName(ref string) string {
+	p := ref
+	if u, err := url.Parse(ref); err == nil {
+		p = u.Path
+	}
+
+	name := ""
+	for _, s := range strings.Split(strings.TrimPrefix(p, "/"), "/") {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			continue
+		}
+
+		if !endpointSegment.MatchString(s) || strings.Count(name, "/") > 3 {
+			break
+		}
+
+		name += "/" + s
+	}
+
+	if name == "" {
+		return "/"
+	}
+
+	return name
+}
+
+// metricsHistogram values count observations in cumulative buckets.
+type metricsHistogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+// observe adds an observation to the histogram.
+func (h *metricsHistogram) observe(v float64) {
+	for i, b := range h.buckets {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+
+	h.sum += v
+	h.count++
+}
+
+// metricsKey values contain the label values of a metric series.
+type metricsKey struct {
+	node     string
+	endpoint string
+	code     string
+}
+
+// Metrics values record metrics describing the requests sent by clients, and
+// expose them in the Prometheus text exposition format. A Metrics value may be
+// shared by multiple clients, and is set using Config.SetMetrics() or
+// SetMetrics(). The gosnowth_requests_total counter records the requests sent
+// to each node and endpoint by response status code, or "error" for requests
+// which failed without a response, and gosnowth_request_duration_seconds
+// records their latencies. The gosnowth_retries_total counter records failed
+// requests which were retried, and gosnowth_circuit_breaker_trips_total
+// records nodes removed from rotation, due to failures, quarantine or manual
+// deactivation. The gosnowth_write_batch_size histogram records the number of
+// records sent by write requests.
+type Metrics struct {
+	sync.Mutex
+	requests map[metricsKey]uint64
+	latency  map[metricsKey]*metricsHistogram
+	retries  map[metricsKey]uint64
+	trips    map[metricsKey]uint64
+	batches  map[metricsKey]*metricsHistogram
+}
+
+// NewMetrics creates a new, empty, Metrics value.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: map[metricsKey]uint64{},
+		latency:  map[metricsKey]*metricsHistogram{},
+		retries:  map[metricsKey]uint64{},
+		trips:    map[metricsKey]uint64{},
+		batches:  map[metricsKey]*metricsHistogram{},
+	}
+}
+
+// observeHistogram adds an observation to a histogram series, creating the
+// series if needed. The Metrics value must be locked.
+func observeHistogram(m map[metricsKey]*metricsHistogram, k metricsKey,
+	buckets []float64, v float64) {
+	h, ok := m[k]
+	if !ok {
+		h = &metricsHistogram{
+			buckets: buckets,
+			counts:  make([]uint64, len(buckets)),
+		}
+
+		m[k] = h
+	}
+
+	h.observe(v)
+}
+
+// request records a request sent to a node, and its latency. A status of
+// zero indicates that the request failed without a response.
+func (m *Metrics) request(node, endpoint string, status int,
+	latency time.Duration) {
+	if m == nil {
+		return
+	}
+
+	code := "error"
+	if status != 0 {
+		code = strconv.Itoa(status)
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	m.requests[metricsKey{node: node, endpoint: endpoint, code: code}]++
+	observeHistogram(m.latency, metricsKey{node: node, endpoint: endpoint},
+		latencyBuckets, latency.Seconds())
+}
+
+// retry records a failed request to a node which is retried.
+func (m *Metrics) retry(node, endpoint string) {
+	if m == nil {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	m.retries[metricsKey{node: node, endpoint: endpoint}]++
+}
+
+// trip records the removal of a node from rotation.
+func (m *Metrics) trip(node string) {
+	if m == nil {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	m.trips[metricsKey{node: node}]++
+}
+
+// batch records the number of records sent by a write request.
+func (m *Metrics) batch(node, endpoint string, records int) {
+	if m == nil {
+		return
+	}
+
+	m.Lock()
+	defer m.Unlock()
+	observeHistogram(m.batches, metricsKey{node: node, endpoint: endpoint},
+		batchSizeBuckets, float64(records))
+}
+
+// sortKeys sorts the keys of metric series in a stable order.
+func sortKeys(keys []metricsKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.node != b.node {
+			return a.node < b.node
+		}
+
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+
+		return a.code < b.code
+	})
+}
+
+// labels returns the Prometheus label set of a metric series, with any
+// additional label appended.
+func (k metricsKey) labels(extra ...string) string {
+	l := []string{fmt.Sprintf("node=%q", k.node)}
+	if k.endpoint != "" {
+		l = append(l, fmt.Sprintf("endpoint=%q", k.endpoint))
+	}
+
+	if k.code != "" {
+		l = append(l, fmt.Sprintf("code=%q", k.code))
+	}
+
+	return "{" + strings.Join(append(l, extra...), ",") + "}"
+}
+
+// writeCounter writes a counter metric in the Prometheus text format.
+func writeCounter(w io.Writer, name, help string,
+	m map[metricsKey]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+	keys := make([]metricsKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sortKeys(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "%s%s %d\n", name, k.labels(), m[k])
+	}
+}
+
+// writeHistogram writes a histogram metric in the Prometheus text format.
+func writeHistogram(w io.Writer, name, help string,
+	m map[metricsKey]*metricsHistogram) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	keys := make([]metricsKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sortKeys(keys)
+	for _, k := range keys {
+		h := m[k]
+		for i, b := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, k.labels(fmt.Sprintf(
+				"le=%q", strconv.FormatFloat(b, 'g', -1, 64))), h.counts[i])
+		}
+
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, k.labels(`le="+Inf"`),
+			h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, k.labels(),
+			strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, k.labels(), h.count)
+	}
+}
+
+// WritePrometheus writes all metrics in the Prometheus text exposition
+// format.
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	m.Lock()
+	writeCounter(bw, "gosnowth_requests_total",
+		"Requests sent to IRONdb nodes.", m.requests)
+	writeHistogram(bw, "gosnowth_request_duration_seconds",
+		"Latency of requests sent to IRONdb nodes.", m.latency)
+	writeCounter(bw, "gosnowth_retries_total",
+		"Failed requests to IRONdb nodes which were retried.", m.retries)
+	writeCounter(bw, "gosnowth_circuit_breaker_trips_total",
+		"IRONdb nodes removed from rotation.", m.trips)
+	writeHistogram(bw, "gosnowth_write_batch_size",
+		"Records sent by write requests to IRONdb nodes.", m.batches)
+	m.Unlock()
+	return bw.Flush()
+}
+
+// ServeHTTP implements the http.Handler interface, serving the metrics in the
+// Prometheus text exposition format, so that they can be scraped directly.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := m.WritePrometheus(w); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// Metrics gets the metrics registry in which client metrics are recorded.
+func (c *Config) Metrics() *Metrics {
+	c.RLock()
+	defer c.RUnlock()
+	return c.metrics
+}
+
+// SetMetrics sets the metrics registry in which client metrics are recorded.
+// By default, metrics are not recorded.
+func (c *Config) SetMetrics(m *Metrics) {
+	c.Lock()
+	c.metrics = m
+	c.Unlock()
+}
+
+// SetMetrics sets the metrics registry in which client metrics are recorded.
+// A nil value disables recording metrics.
+func (sc *SnowthClient) SetMetrics(m *Metrics) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.metrics = m
+}
+
+// getMetrics returns the metrics registry of the client, or nil if metrics
+// are not recorded.
+func (sc *SnowthClient) getMetrics() *Metrics {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.metrics
+}
+
+// nodeHost returns the address of a node used in metric labels.
+func nodeHost(node *SnowthNode) string {
+	if node == nil || node.GetURL() == nil {
+		return ""
+	}
+
+	return node.GetURL().Host
+}
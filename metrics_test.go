@@ -0,0 +1,130 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEndpointName(t *testing.T) {
+	t.Parallel()
+	for ref, exp := range map[string]string{
+		"/find/1/tags?query=and(a:b)":                         "/find/tags",
+		"http://localhost:8112/stats.json":                    "/stats.json",
+		"/read/1/2/60/fc85e0ab-f568-45e6-86ee-d7443be8277d/x": "/read",
+		"/raw/fc85e0ab-f568-45e6-86ee-d7443be8277d/metric":    "/raw",
+		"/extension/lua/public/caql_v1?format=DF4":            "/extension/lua/public/caql_v1",
+		"/write/nnt": "/write/nnt",
+		"":           "/",
+	} {
+		if name := endpointName(ref); name != exp {
+			t.Errorf("Expected endpoint name for %s: %v, got: %v", ref, exp,
+				name)
+		}
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+	m := NewMetrics()
+	m.request("n1:8112", "/find/tags", 200, 30*time.Millisecond)
+	m.request("n1:8112", "/find/tags", 0, 2*time.Second)
+	m.retry("n1:8112", "/find/tags")
+	m.trip("n1:8112")
+	m.batch("n1:8112", "/write/numeric", 500)
+	buf := &bytes.Buffer{}
+	if err := m.WritePrometheus(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, exp := range []string{
+		"# TYPE gosnowth_requests_total counter\n",
+		`gosnowth_requests_total{node="n1:8112",endpoint="/find/tags",` +
+			`code="200"} 1`,
+		`gosnowth_requests_total{node="n1:8112",endpoint="/find/tags",` +
+			`code="error"} 1`,
+		`gosnowth_request_duration_seconds_bucket{node="n1:8112",` +
+			`endpoint="/find/tags",le="0.05"} 1`,
+		`gosnowth_request_duration_seconds_bucket{node="n1:8112",` +
+			`endpoint="/find/tags",le="+Inf"} 2`,
+		`gosnowth_request_duration_seconds_sum{node="n1:8112",` +
+			`endpoint="/find/tags"} 2.03`,
+		`gosnowth_retries_total{node="n1:8112",endpoint="/find/tags"} 1`,
+		`gosnowth_circuit_breaker_trips_total{node="n1:8112"} 1`,
+		`gosnowth_write_batch_size_bucket{node="n1:8112",` +
+			`endpoint="/write/numeric",le="1000"} 1`,
+	} {
+		if !strings.Contains(buf.String(), exp) {
+			t.Errorf("Expected metrics to contain: %s, got: %s", exp,
+				buf.String())
+		}
+	}
+
+	var nilMetrics *Metrics
+	nilMetrics.request("n1", "/", 200, 0)
+}
+
+func TestClientMetrics(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/numeric" {
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	m := NewMetrics()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetMetrics(m)
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	node := sc.GetActiveNode()
+	if err := sc.WriteNumeric([]NumericWrite{{
+		Metric: "test", ID: "fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		Offset: 1, Count: 1, Value: 1,
+	}}, node); err != nil {
+		t.Fatal(err)
+	}
+
+	sc.DeactivateNodes(node)
+	host := node.GetURL().Host
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	for _, exp := range []string{
+		`gosnowth_requests_total{node="` + host +
+			`",endpoint="/write/numeric",code="200"} 1`,
+		`gosnowth_circuit_breaker_trips_total{node="` + host + `"} 1`,
+		`gosnowth_write_batch_size_count{node="` + host +
+			`",endpoint="/write/numeric"} 1`,
+	} {
+		if !strings.Contains(rec.Body.String(), exp) {
+			t.Errorf("Expected metrics to contain: %s, got: %s", exp,
+				rec.Body.String())
+		}
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"path"
 	"strconv"
 	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // NNTAllValueResponse values represent NNT data responses from IRONdb.
@@ -141,7 +143,8 @@ func (p *Parts) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// WriteNNT writes NNT data to a node.
+// WriteNNT writes NNT data to a node. If the encoded data exceeds the maximum
+// write payload size, it is written in multiple requests.
 func (sc *SnowthClient) WriteNNT(data []NNTData, nodes ...*SnowthNode) error {
 	return sc.WriteNNTContext(context.Background(), data, nodes...)
 }
@@ -149,21 +152,39 @@ func (sc *SnowthClient) WriteNNT(data []NNTData, nodes ...*SnowthNode) error {
 // WriteNNTContext is the context aware version of WriteNNT.
 func (sc *SnowthClient) WriteNNTContext(ctx context.Context,
 	data []NNTData, nodes ...*SnowthNode) error {
+	data = append([]NNTData(nil), data...)
+	names := make([]string, len(data))
+	for i := range data {
+		data[i].Metric = CanonicalMetricName(data[i].Metric)
+		names[i] = data[i].Metric
+	}
+
+	if err := sc.checkCardinality(names, nil); err != nil {
+		return err
+	}
+
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(data); err != nil {
 		return fmt.Errorf("failed to encode NNTData for write: %w", err)
 	}
 
+	if level := sc.getWriteConsistency(ctx); level != WriteConsistencyOne &&
+		(len(nodes) == 0 || nodes[0] == nil) {
+		return sc.writeConsistent(ctx, level, snowthapi.PathWriteNNT, len(data),
+			func(i int) interface{} { return &data[i] },
+			func(i int) (string, string) { return data[i].ID, data[i].Metric })
+	}
+
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else if len(data) > 0 {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(data[0].ID,
+		node = sc.GetActiveNode(sc.FindMetricNodeIDsContext(ctx, data[0].ID,
 			data[0].Metric))
 	}
 
-	_, _, err := sc.DoRequestContext(ctx, node, "POST", "/write/nnt", buf, nil)
-	return err
+	return sc.writeJSON(ctx, node, snowthapi.PathWriteNNT, buf, len(data),
+		func(i int) interface{} { return &data[i] })
 }
 
 // ReadNNTValues reads NNT data from a node.
@@ -181,19 +202,20 @@ func (sc *SnowthClient) ReadNNTValuesContext(ctx context.Context,
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(id, metric))
+		node = sc.readNode(ctx, id, metric)
 	}
 
 	r := &NNTValueResponse{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/read",
-		strconv.FormatInt(start.Unix(), 10),
-		strconv.FormatInt(end.Unix(), 10),
-		strconv.FormatInt(period, 10), id, t, metric), nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		path.Join(snowthapi.PathRead,
+			strconv.FormatInt(start.Unix(), 10),
+			strconv.FormatInt(end.Unix(), 10),
+			strconv.FormatInt(period, 10), id, t, metric), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -215,19 +237,20 @@ func (sc *SnowthClient) ReadNNTAllValuesContext(ctx context.Context,
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(id, metric))
+		node = sc.readNode(ctx, id, metric)
 	}
 
 	r := &NNTAllValueResponse{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/read",
-		strconv.FormatInt(start.Unix(), 10),
-		strconv.FormatInt(end.Unix(), 10),
-		strconv.FormatInt(period, 10), id, "all", metric), nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		path.Join(snowthapi.PathRead,
+			strconv.FormatInt(start.Unix(), 10),
+			strconv.FormatInt(end.Unix(), 10),
+			strconv.FormatInt(period, 10), id, "all", metric), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 	return r.Data, nil
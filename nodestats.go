@@ -0,0 +1,206 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindow is the number of most recent request latencies of each node
+// used to calculate latency percentiles.
+const latencyWindow = 256
+
+// NodeRequestStats values contain request statistics for a node.
+type NodeRequestStats struct {
+	// URL is the address of the node.
+	URL string `json:"url"`
+
+	// Requests is the number of requests sent to the node, and Errors is the
+	// number of those requests which failed due to connection errors,
+	// timeouts, throttling or server errors. Requests which failed due to
+	// client errors, such as requests for missing data, are not counted as
+	// errors.
+	Requests int64 `json:"requests"`
+	Errors   int64 `json:"errors"`
+
+	// ErrorRate is the ratio of errors to requests.
+	ErrorRate float64 `json:"error_rate"`
+
+	// LatencyP50, LatencyP90, LatencyP99 and LatencyMax are the latency
+	// percentiles and maximum latency of the most recent requests.
+	LatencyP50 time.Duration `json:"latency_p50"`
+	LatencyP90 time.Duration `json:"latency_p90"`
+	LatencyP99 time.Duration `json:"latency_p99"`
+	LatencyMax time.Duration `json:"latency_max"`
+
+	// LastError is the time of the most recent error.
+	LastError time.Time `json:"last_error,omitempty"`
+}
+
+// ClientStats values contain request statistics for all nodes used by a
+// client.
+type ClientStats struct {
+	Requests int64              `json:"requests"`
+	Errors   int64              `json:"errors"`
+	Nodes    []NodeRequestStats `json:"nodes"`
+}
+
+// nodeRecorder values record the requests sent to a node.
+type nodeRecorder struct {
+	requests  int64
+	errors    int64
+	lastError time.Time
+	latencies [latencyWindow]time.Duration
+	next      int
+	full      bool
+}
+
+// requestStats values record the requests sent to each node by a client.
+type requestStats struct {
+	sync.Mutex
+	nodes map[string]*nodeRecorder
+}
+
+// record records a request sent to a node.
+func (rs *requestStats) record(node string, latency time.Duration,
+	failed bool, now time.Time) {
+	rs.Lock()
+	defer rs.Unlock()
+	if rs.nodes == nil {
+		rs.nodes = map[string]*nodeRecorder{}
+	}
+
+	nr, ok := rs.nodes[node]
+	if !ok {
+		nr = &nodeRecorder{}
+		rs.nodes[node] = nr
+	}
+
+	nr.requests++
+	if failed {
+		nr.errors++
+		nr.lastError = now
+	}
+
+	nr.latencies[nr.next] = latency
+	nr.next = (nr.next + 1) % latencyWindow
+	if nr.next == 0 {
+		nr.full = true
+	}
+}
+
+// latencyPercentile returns the percentile value of a sorted slice of
+// latencies.
+func latencyPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+
+	return sorted[i]
+}
+
+// snapshot returns the statistics recorded for a node.
+func (nr *nodeRecorder) snapshot(node string) NodeRequestStats {
+	n := nr.next
+	if nr.full {
+		n = latencyWindow
+	}
+
+	lats := make([]time.Duration, n)
+	copy(lats, nr.latencies[:n])
+	sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+	ns := NodeRequestStats{
+		URL:        node,
+		Requests:   nr.requests,
+		Errors:     nr.errors,
+		LatencyP50: latencyPercentile(lats, 0.5),
+		LatencyP90: latencyPercentile(lats, 0.9),
+		LatencyP99: latencyPercentile(lats, 0.99),
+		LastError:  nr.lastError,
+	}
+
+	if n > 0 {
+		ns.LatencyMax = lats[n-1]
+	}
+
+	if nr.requests > 0 {
+		ns.ErrorRate = float64(nr.errors) / float64(nr.requests)
+	}
+
+	return ns
+}
+
+// ClientStats returns the request counts, error counts and rolling latency
+// percentiles of every node to which the client has sent requests, sorted by
+// node URL.
+func (sc *SnowthClient) ClientStats() *ClientStats {
+	sc.reqStats.Lock()
+	defer sc.reqStats.Unlock()
+	cs := &ClientStats{Nodes: []NodeRequestStats{}}
+	for node, nr := range sc.reqStats.nodes {
+		ns := nr.snapshot(node)
+		cs.Requests += ns.Requests
+		cs.Errors += ns.Errors
+		cs.Nodes = append(cs.Nodes, ns)
+	}
+
+	sort.Slice(cs.Nodes, func(i, j int) bool {
+		return cs.Nodes[i].URL < cs.Nodes[j].URL
+	})
+
+	return cs
+}
+
+// NodeStats returns the request statistics of a node, and whether any
+// requests have been sent to the node.
+func (sc *SnowthClient) NodeStats(node *SnowthNode) (NodeRequestStats, bool) {
+	if node == nil || node.GetURL() == nil {
+		return NodeRequestStats{}, false
+	}
+
+	u := node.GetURL().String()
+	sc.reqStats.Lock()
+	defer sc.reqStats.Unlock()
+	nr, ok := sc.reqStats.nodes[u]
+	if !ok {
+		return NodeRequestStats{URL: u}, false
+	}
+
+	return nr.snapshot(u), true
+}
+
+// ResetClientStats discards all recorded request statistics.
+func (sc *SnowthClient) ResetClientStats() {
+	sc.reqStats.Lock()
+	defer sc.reqStats.Unlock()
+	sc.reqStats.nodes = nil
+}
+
+// recordRequest records the latency and outcome of a request sent to a node,
+// and to an endpoint in the client metrics. Only errors which indicate a
+// problem with the node are counted as errors.
+func (sc *SnowthClient) recordRequest(node *SnowthNode, endpoint string,
+	start time.Time, status int, err error) {
+	if node == nil || node.GetURL() == nil {
+		return
+	}
+
+	now := sc.getClock().Now()
+	failed := IsRetryable(err) || status == http.StatusTooManyRequests ||
+		status >= http.StatusInternalServerError
+	sc.reqStats.record(node.GetURL().String(), now.Sub(start), failed, now)
+	sc.getMetrics().request(node.GetURL().Host, endpointName(endpoint),
+		status, now.Sub(start))
+	sc.logSlowRequest(node, endpoint, now.Sub(start), status)
+}
@@ -0,0 +1,97 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRequestStatsRecord(t *testing.T) {
+	t.Parallel()
+	rs := &requestStats{}
+	now := time.Unix(1, 0)
+	for i := 1; i <= latencyWindow+100; i++ {
+		rs.record("node", time.Duration(i)*time.Millisecond, i%10 == 0, now)
+	}
+
+	ns := rs.nodes["node"].snapshot("node")
+	if ns.Requests != latencyWindow+100 || ns.Errors != 35 {
+		t.Errorf("Expected requests/errors: %d/35, got: %d/%d",
+			latencyWindow+100, ns.Requests, ns.Errors)
+	}
+
+	// Only the most recent latencies, 101ms to 356ms, are used.
+	if ns.LatencyP50 != 228*time.Millisecond {
+		t.Errorf("Expected p50: 228ms, got: %v", ns.LatencyP50)
+	}
+
+	if ns.LatencyMax != 356*time.Millisecond {
+		t.Errorf("Expected max: 356ms, got: %v", ns.LatencyMax)
+	}
+
+	if !ns.LastError.Equal(now) {
+		t.Errorf("Expected last error: %v, got: %v", now, ns.LastError)
+	}
+}
+
+func TestClientStats(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		switch r.RequestURI {
+		case "/state":
+			_, _ = w.Write([]byte(stateTestData))
+		case "/stats.json":
+			_, _ = w.Write([]byte(statsTestData))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			t.Errorf("Unexpected request: %v", r)
+			w.WriteHeader(500)
+		}
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	sc.ResetClientStats()
+	if _, err := sc.GetStats(node); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, _ = sc.DoRequest(node, "GET", "/missing", nil, nil)
+	_, _, _ = sc.DoRequest(node, "GET", "/fail", nil, nil)
+	cs := sc.ClientStats()
+	if cs.Requests != 3 || cs.Errors != 1 || len(cs.Nodes) != 1 {
+		t.Fatalf("Expected requests/errors/nodes: 3/1/1, got: %+v", cs)
+	}
+
+	ns, ok := sc.NodeStats(node)
+	if !ok || ns.URL != ms.URL || ns.Requests != 3 {
+		t.Errorf("Expected node stats: %v, got: %+v", ms.URL, ns)
+	}
+
+	if ns.ErrorRate < 0.33 || ns.ErrorRate > 0.34 {
+		t.Errorf("Expected error rate: 0.33, got: %v", ns.ErrorRate)
+	}
+
+	if ns.LastError.IsZero() {
+		t.Error("Expected last error time")
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"path"
 	"strconv"
 	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // NumericAllValueResponse values represent numeric data responses from IRONdb.
@@ -144,7 +147,8 @@ func (p *NumericParts) MarshalJSON() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
-// WriteNumeric writes numeric data to a node.
+// WriteNumeric writes numeric data to a node. If the encoded data exceeds the
+// maximum write payload size, it is written in multiple requests.
 func (sc *SnowthClient) WriteNumeric(data []NumericWrite,
 	nodes ...*SnowthNode) error {
 	return sc.WriteNumericContext(context.Background(), data, nodes...)
@@ -153,25 +157,44 @@ func (sc *SnowthClient) WriteNumeric(data []NumericWrite,
 // WriteNumericContext is the context aware version of WriteNumeric.
 func (sc *SnowthClient) WriteNumericContext(ctx context.Context,
 	data []NumericWrite, nodes ...*SnowthNode) error {
+	data = append([]NumericWrite(nil), data...)
+	names := make([]string, len(data))
+	for i := range data {
+		data[i].Metric = CanonicalMetricName(data[i].Metric)
+		names[i] = data[i].Metric
+	}
+
+	if err := sc.checkCardinality(names, nil); err != nil {
+		return err
+	}
+
 	buf := new(bytes.Buffer)
 	if err := json.NewEncoder(buf).Encode(data); err != nil {
 		return fmt.Errorf("failed to encode NumericWrite for write: %w", err)
 	}
 
+	if level := sc.getWriteConsistency(ctx); level != WriteConsistencyOne &&
+		(len(nodes) == 0 || nodes[0] == nil) {
+		return sc.writeConsistent(ctx, level, snowthapi.PathWriteNumeric, len(data),
+			func(i int) interface{} { return data[i] },
+			func(i int) (string, string) { return data[i].ID, data[i].Metric })
+	}
+
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else if len(data) > 0 {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(data[0].ID,
+		node = sc.GetActiveNode(sc.FindMetricNodeIDsContext(ctx, data[0].ID,
 			data[0].Metric))
 	}
 
-	_, _, err := sc.DoRequestContext(ctx, node, "POST",
-		"/write/numeric", buf, nil)
-	return err
+	return sc.writeJSON(ctx, node, snowthapi.PathWriteNumeric, buf, len(data),
+		func(i int) interface{} { return data[i] })
 }
 
-// ReadNumericValues reads numeric data from a node.
+// ReadNumericValues reads numeric data from a node. The metric name may include
+// stream tags, such as a name returned by MetricName.String(), and is read in
+// its canonical form, as written by all write operations.
 func (sc *SnowthClient) ReadNumericValues(start, end time.Time, period int64,
 	t, id, metric string, nodes ...*SnowthNode) ([]NumericValue, error) {
 	return sc.ReadNumericValuesContext(context.Background(), start, end,
@@ -182,30 +205,36 @@ func (sc *SnowthClient) ReadNumericValues(start, end time.Time, period int64,
 func (sc *SnowthClient) ReadNumericValuesContext(ctx context.Context,
 	start, end time.Time, period int64,
 	t, id, metric string, nodes ...*SnowthNode) ([]NumericValue, error) {
+	mn, err := ParseMetricName(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	metric = mn.String()
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(id, metric))
+		node = sc.readNode(ctx, id, metric)
 	}
 
 	r := &NumericValueResponse{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/read",
-		strconv.FormatInt(start.Unix(), 10),
-		strconv.FormatInt(end.Unix(), 10),
-		strconv.FormatInt(period, 10), id, t, metric), nil, nil)
+	body, _, err := sc.doRequest(ctx, node, sc.metricOwnerIDs(id, metric),
+		"GET", numericReadPath(start, end, period, id, t, metric), nil,
+		nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
 	return r.Data, nil
 }
 
-// ReadNumericAllValues reads all numeric data from a node.
+// ReadNumericAllValues reads all numeric data from a node. The metric name may
+// include stream tags, and is read in its canonical form.
 func (sc *SnowthClient) ReadNumericAllValues(start, end time.Time, period int64,
 	id, metric string, nodes ...*SnowthNode) ([]NumericAllValue, error) {
 	return sc.ReadNumericAllValuesContext(context.Background(), start, end,
@@ -217,24 +246,38 @@ func (sc *SnowthClient) ReadNumericAllValues(start, end time.Time, period int64,
 func (sc *SnowthClient) ReadNumericAllValuesContext(ctx context.Context,
 	start, end time.Time, period int64,
 	id, metric string, nodes ...*SnowthNode) ([]NumericAllValue, error) {
+	mn, err := ParseMetricName(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	metric = mn.String()
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(id, metric))
+		node = sc.readNode(ctx, id, metric)
 	}
 
 	r := &NumericAllValueResponse{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/read",
-		strconv.FormatInt(start.Unix(), 10),
-		strconv.FormatInt(end.Unix(), 10),
-		strconv.FormatInt(period, 10), id, "all", metric), nil, nil)
+	body, _, err := sc.doRequest(ctx, node, sc.metricOwnerIDs(id, metric),
+		"GET", numericReadPath(start, end, period, id, "all", metric), nil,
+		nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 	return r.Data, nil
 }
+
+// numericReadPath returns the path of a numeric data read request, with the
+// metric name, which may include stream tags, escaped.
+func numericReadPath(start, end time.Time, period int64,
+	id, kind, metric string) string {
+	return path.Join(snowthapi.PathRead, strconv.FormatInt(start.Unix(), 10),
+		strconv.FormatInt(end.Unix(), 10), strconv.FormatInt(period, 10), id,
+		kind, url.QueryEscape(metric))
+}
@@ -151,6 +151,22 @@ func TestNumericReadWrite(t *testing.T) {
 			return
 		}
 
+		u = "/read/1529509020/1529509200/1/" +
+			"fc85e0ab-f568-45e6-86ee-d7443be8277d/count/" +
+			"online%7CST%5Ba%3A1%2Cb%3A2%5D"
+		if r.RequestURI == u {
+			_, _ = w.Write([]byte(numericTestData))
+			return
+		}
+
+		u = "/read/1529509020/1529509200/1/" +
+			"fc85e0ab-f568-45e6-86ee-d7443be8277d/all/" +
+			"online%7CST%5Ba%3A1%2Cb%3A2%5D"
+		if r.RequestURI == u {
+			_, _ = w.Write([]byte(numericTestAllData))
+			return
+		}
+
 		u = "/write/nnt"
 		if strings.HasPrefix(r.RequestURI, u) {
 			w.WriteHeader(200)
@@ -200,6 +216,39 @@ func TestNumericReadWrite(t *testing.T) {
 		t.Errorf("Expected value: 10, got: %v", resA[0].Value)
 	}
 
+	mn := MetricName{Base: "online", StreamTags: []MetricTag{
+		{Category: "b", Value: "2"},
+		{Category: "a", Value: "1"},
+	}}
+	res, err = sc.ReadNumericValues(time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), 1, "count",
+		"fc85e0ab-f568-45e6-86ee-d7443be8277d", mn.String(), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Fatalf("Expected results: 2, got: %v", len(res))
+	}
+
+	resA, err = sc.ReadNumericAllValues(time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), 1, "fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		"online|ST[b:2,a:1]", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resA) != 3 {
+		t.Fatalf("Expected results: 3, got: %v", len(resA))
+	}
+
+	_, err = sc.ReadNumericValues(time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), 1, "count",
+		"fc85e0ab-f568-45e6-86ee-d7443be8277d", "online|ST[a:1", node)
+	if err == nil {
+		t.Error("Expected error for invalid metric name")
+	}
+
 	nv := []NumericWrite{}
 	err = json.NewDecoder(bytes.NewBufferString(numericTestWriteData)).Decode(&nv)
 	if err != nil {
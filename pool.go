@@ -0,0 +1,139 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"time"
+)
+
+// Default connection pool settings of the HTTP transport used by clients.
+const (
+	defaultMaxIdleConns        = 10
+	defaultMaxIdleConnsPerHost = 1
+	defaultIdleConnTimeout     = 5 * time.Second
+	defaultKeepAlivePeriod     = 30 * time.Second
+)
+
+// KeepAlives gets whether connections to IRONdb nodes are reused for multiple
+// requests.
+func (c *Config) KeepAlives() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.keepAlives
+}
+
+// SetKeepAlives sets whether connections to IRONdb nodes are reused for
+// multiple requests. By default, a new connection is used for every request.
+// Enabling keep-alives allows heavy writers to keep warm connections to every
+// node, up to the maximum number of idle connections per host.
+func (c *Config) SetKeepAlives(enable bool) {
+	c.Lock()
+	c.keepAlives = enable
+	c.Unlock()
+}
+
+// KeepAlivePeriod gets the interval between TCP keep-alive probes sent on
+// connections to IRONdb nodes.
+func (c *Config) KeepAlivePeriod() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.keepAlivePeriod
+}
+
+// SetKeepAlivePeriod sets the interval between TCP keep-alive probes sent on
+// connections to IRONdb nodes. The default value is 30 seconds. A value of
+// zero uses the system default, and a negative value disables the probes.
+// This is not used if a custom dial function is set.
+func (c *Config) SetKeepAlivePeriod(d time.Duration) {
+	c.Lock()
+	c.keepAlivePeriod = d
+	c.Unlock()
+}
+
+// MaxIdleConns gets the maximum number of idle connections kept open to all
+// IRONdb nodes.
+func (c *Config) MaxIdleConns() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.maxIdleConns
+}
+
+// SetMaxIdleConns sets the maximum number of idle connections kept open to
+// all IRONdb nodes. The default value is 10. A value of zero means no limit.
+func (c *Config) SetMaxIdleConns(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max idle connections value")
+	}
+
+	c.Lock()
+	c.maxIdleConns = n
+	c.Unlock()
+	return nil
+}
+
+// MaxIdleConnsPerHost gets the maximum number of idle connections kept open
+// to each IRONdb node.
+func (c *Config) MaxIdleConnsPerHost() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.maxIdleConnsPerHost
+}
+
+// SetMaxIdleConnsPerHost sets the maximum number of idle connections kept
+// open to each IRONdb node. The default value is 1. Clients sending many
+// requests in parallel should increase this along with enabling keep-alives.
+func (c *Config) SetMaxIdleConnsPerHost(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max idle connections per host value")
+	}
+
+	c.Lock()
+	c.maxIdleConnsPerHost = n
+	c.Unlock()
+	return nil
+}
+
+// MaxConnsPerHost gets the maximum number of connections, including those in
+// use, to each IRONdb node.
+func (c *Config) MaxConnsPerHost() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.maxConnsPerHost
+}
+
+// SetMaxConnsPerHost sets the maximum number of connections, including those
+// in use, to each IRONdb node. Requests wait for a connection to become
+// available when the limit is reached. A value of zero, the default, means no
+// limit.
+func (c *Config) SetMaxConnsPerHost(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max connections per host value")
+	}
+
+	c.Lock()
+	c.maxConnsPerHost = n
+	c.Unlock()
+	return nil
+}
+
+// IdleConnTimeout gets the duration after which idle connections to IRONdb
+// nodes are closed.
+func (c *Config) IdleConnTimeout() time.Duration {
+	c.RLock()
+	defer c.RUnlock()
+	return c.idleConnTimeout
+}
+
+// SetIdleConnTimeout sets the duration after which idle connections to IRONdb
+// nodes are closed. The default value is 5 seconds. A value of zero means
+// idle connections are not closed.
+func (c *Config) SetIdleConnTimeout(d time.Duration) error {
+	if d < 0 {
+		return fmt.Errorf("invalid idle connection timeout value")
+	}
+
+	c.Lock()
+	c.idleConnTimeout = d
+	c.Unlock()
+	return nil
+}
@@ -0,0 +1,153 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestConfigPool(t *testing.T) {
+	t.Parallel()
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.KeepAlives() {
+		t.Error("Expected keep-alives to be disabled")
+	}
+
+	if cfg.MaxIdleConns() != defaultMaxIdleConns {
+		t.Errorf("Expected max idle conns: %v, got: %v",
+			defaultMaxIdleConns, cfg.MaxIdleConns())
+	}
+
+	if cfg.MaxIdleConnsPerHost() != defaultMaxIdleConnsPerHost {
+		t.Errorf("Expected max idle conns per host: %v, got: %v",
+			defaultMaxIdleConnsPerHost, cfg.MaxIdleConnsPerHost())
+	}
+
+	if cfg.IdleConnTimeout() != defaultIdleConnTimeout {
+		t.Errorf("Expected idle conn timeout: %v, got: %v",
+			defaultIdleConnTimeout, cfg.IdleConnTimeout())
+	}
+
+	if cfg.KeepAlivePeriod() != defaultKeepAlivePeriod {
+		t.Errorf("Expected keep-alive period: %v, got: %v",
+			defaultKeepAlivePeriod, cfg.KeepAlivePeriod())
+	}
+
+	if err := cfg.SetMaxIdleConns(-1); err == nil {
+		t.Error("Expected error for invalid max idle conns")
+	}
+
+	if err := cfg.SetMaxIdleConnsPerHost(-1); err == nil {
+		t.Error("Expected error for invalid max idle conns per host")
+	}
+
+	if err := cfg.SetMaxConnsPerHost(-1); err == nil {
+		t.Error("Expected error for invalid max conns per host")
+	}
+
+	if err := cfg.SetIdleConnTimeout(-time.Second); err == nil {
+		t.Error("Expected error for invalid idle conn timeout")
+	}
+
+	cfg.SetKeepAlivePeriod(time.Minute)
+	if cfg.KeepAlivePeriod() != time.Minute {
+		t.Errorf("Expected keep-alive period: 1m, got: %v",
+			cfg.KeepAlivePeriod())
+	}
+}
+
+func TestClientPool(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	addrs := []string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			mu.Lock()
+			addrs = append(addrs, r.RemoteAddr)
+			mu.Unlock()
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetKeepAlives(true)
+	if err := cfg.SetMaxIdleConns(100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetMaxIdleConnsPerHost(16); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetMaxConnsPerHost(32); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetIdleConnTimeout(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetInsecureNodes("localhost"); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	nt, ok := sc.c.(*http.Client).Transport.(*nodeTLSTransport)
+	if !ok {
+		t.Fatalf("Expected node TLS transport, got: %T",
+			sc.c.(*http.Client).Transport)
+	}
+
+	for _, tr := range []*http.Transport{nt.secure, nt.insecure} {
+		if tr.DisableKeepAlives {
+			t.Error("Expected keep-alives to be enabled")
+		}
+
+		if tr.MaxIdleConns != 100 || tr.MaxIdleConnsPerHost != 16 ||
+			tr.MaxConnsPerHost != 32 || tr.IdleConnTimeout != time.Minute {
+			t.Errorf("Expected pool settings, got: %v %v %v %v",
+				tr.MaxIdleConns, tr.MaxIdleConnsPerHost, tr.MaxConnsPerHost,
+				tr.IdleConnTimeout)
+		}
+	}
+
+	node := sc.GetActiveNode()
+	for i := 0; i < 2; i++ {
+		if _, err := sc.GetStats(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	n := len(addrs)
+	if addrs[n-1] != addrs[n-2] {
+		t.Errorf("Expected connection reuse, got: %v", addrs)
+	}
+}
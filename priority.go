@@ -0,0 +1,250 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Priority values describe the importance of requests, which controls the
+// order in which requests waiting to be sent are started when the client is
+// saturated.
+type Priority int
+
+// Request priorities.
+const (
+	// PriorityNormal is the priority of requests which do not specify one.
+	PriorityNormal Priority = iota
+
+	// PriorityLow requests, such as bulk backfills, are only started when no
+	// requests of higher priority are waiting.
+	PriorityLow
+
+	// PriorityHigh requests, such as interactive dashboard reads, are started
+	// before any waiting requests of lower priority.
+	PriorityHigh
+)
+
+// String returns a string representation of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// rank returns the order in which requests of the priority are started,
+// starting from zero for the highest priority.
+func (p Priority) rank() int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// priorityKey is the context key used to store request priorities.
+type priorityKey struct{}
+
+// WithPriority returns a copy of the context which specifies the priority of
+// the requests made with it.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the request priority specified by a context, or
+// PriorityNormal if it does not specify one.
+func PriorityFromContext(ctx context.Context) Priority {
+	if ctx == nil {
+		return PriorityNormal
+	}
+
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+
+	return PriorityNormal
+}
+
+// requestQueue values limit the number of requests in progress. Requests
+// which cannot be started wait in a queue for each priority, and are started
+// in priority order as other requests complete.
+type requestQueue struct {
+	sync.Mutex
+	max     int
+	active  int
+	waiters [3][]chan struct{}
+}
+
+// newRequestQueue creates a new request queue which allows max requests in
+// progress, or returns nil if max is not positive.
+func newRequestQueue(max int) *requestQueue {
+	if max <= 0 {
+		return nil
+	}
+
+	return &requestQueue{max: max}
+}
+
+// acquire waits until a request with the specified priority may be started,
+// or the context is terminated.
+func (q *requestQueue) acquire(ctx context.Context, p Priority) error {
+	if q == nil {
+		return nil
+	}
+
+	q.Lock()
+	if q.active < q.max {
+		q.active++
+		q.Unlock()
+		return nil
+	}
+
+	r := p.rank()
+	ch := make(chan struct{})
+	q.waiters[r] = append(q.waiters[r], ch)
+	q.Unlock()
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	for i, w := range q.waiters[r] {
+		if w == ch {
+			q.waiters[r] = append(q.waiters[r][:i], q.waiters[r][i+1:]...)
+			return fmt.Errorf("request queue wait terminated: %w", ctx.Err())
+		}
+	}
+
+	// The request was started while the context was terminated, so the
+	// slot is passed on.
+	q.releaseLocked()
+	return fmt.Errorf("request queue wait terminated: %w", ctx.Err())
+}
+
+// release completes a request, starting the waiting request of the highest
+// priority, if any.
+func (q *requestQueue) release() {
+	if q == nil {
+		return
+	}
+
+	q.Lock()
+	defer q.Unlock()
+	q.releaseLocked()
+}
+
+// releaseLocked completes a request. The queue must be locked.
+func (q *requestQueue) releaseLocked() {
+	for r := range q.waiters {
+		if len(q.waiters[r]) > 0 {
+			ch := q.waiters[r][0]
+			q.waiters[r] = q.waiters[r][1:]
+			close(ch)
+			return
+		}
+	}
+
+	q.active--
+}
+
+// MaxConcurrentRequests gets the maximum number of requests the client sends
+// at the same time.
+func (c *Config) MaxConcurrentRequests() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.maxConcurrentRequests
+}
+
+// SetMaxConcurrentRequests sets the maximum number of requests the client
+// sends at the same time. Requests beyond the limit wait until others
+// complete, and are started in the order of their priority, set using
+// WithPriority(). A value of zero, the default, means no limit.
+func (c *Config) SetMaxConcurrentRequests(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max concurrent requests value")
+	}
+
+	c.Lock()
+	c.maxConcurrentRequests = n
+	c.Unlock()
+	return nil
+}
+
+// PriorityHeader gets the name of the request header used to send request
+// priorities to IRONdb nodes.
+func (c *Config) PriorityHeader() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.priorityHeader
+}
+
+// SetPriorityHeader sets the name of a request header used to send the
+// priority of every request to IRONdb nodes which support one, such as
+// through a proxy which prioritizes requests. The header value is the string
+// representation of the priority. By default, priorities are not sent.
+func (c *Config) SetPriorityHeader(name string) {
+	c.Lock()
+	c.priorityHeader = http.CanonicalHeaderKey(name)
+	c.Unlock()
+}
+
+// SetMaxConcurrentRequests sets the maximum number of requests the client
+// sends at the same time. A value of zero means no limit. Requests already
+// waiting for the previous limit are not affected.
+func (sc *SnowthClient) SetMaxConcurrentRequests(n int) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.requestQueue = newRequestQueue(n)
+}
+
+// SetPriorityHeader sets the name of a request header used to send request
+// priorities to IRONdb nodes. An empty name disables sending priorities.
+func (sc *SnowthClient) SetPriorityHeader(name string) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.priorityHeader = http.CanonicalHeaderKey(name)
+}
+
+// waitRequestQueue waits until a request made with a context may be started,
+// returning the function which must be called when the request completes.
+func (sc *SnowthClient) waitRequestQueue(ctx context.Context) (func(),
+	error) {
+	sc.RLock()
+	q := sc.requestQueue
+	sc.RUnlock()
+	if err := q.acquire(ctx, PriorityFromContext(ctx)); err != nil {
+		return nil, err
+	}
+
+	return q.release, nil
+}
+
+// setPriorityHeader adds the priority header to a request, if priorities are
+// sent to IRONdb nodes.
+func (sc *SnowthClient) setPriorityHeader(r *http.Request) {
+	sc.RLock()
+	name := sc.priorityHeader
+	sc.RUnlock()
+	if name != "" && r.Header.Get(name) == "" {
+		r.Header.Set(name, PriorityFromContext(r.Context()).String())
+	}
+}
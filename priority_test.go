@@ -0,0 +1,146 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPriorityFromContext(t *testing.T) {
+	t.Parallel()
+	if p := PriorityFromContext(context.Background()); p != PriorityNormal {
+		t.Errorf("Expected priority: normal, got: %v", p)
+	}
+
+	ctx := WithPriority(context.Background(), PriorityLow)
+	if p := PriorityFromContext(ctx); p != PriorityLow {
+		t.Errorf("Expected priority: low, got: %v", p)
+	}
+
+	if s := PriorityHigh.String(); s != "high" {
+		t.Errorf("Expected string: high, got: %v", s)
+	}
+}
+
+func TestRequestQueue(t *testing.T) {
+	t.Parallel()
+	if q := newRequestQueue(0); q != nil {
+		t.Fatal("Expected no request queue")
+	}
+
+	q := newRequestQueue(1)
+	if err := q.acquire(context.Background(), PriorityNormal); err != nil {
+		t.Fatal(err)
+	}
+
+	waiting := func(n int) {
+		for i := 0; i < 1000; i++ {
+			q.Lock()
+			w := len(q.waiters[0]) + len(q.waiters[1]) + len(q.waiters[2])
+			q.Unlock()
+			if w == n {
+				return
+			}
+
+			time.Sleep(time.Millisecond)
+		}
+
+		t.Fatalf("Expected waiting requests: %v", n)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 1)
+	go func() {
+		errs <- q.acquire(ctx, PriorityHigh)
+	}()
+
+	waiting(1)
+	cancel()
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	order := make(chan Priority, 3)
+	for i, p := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		go func(p Priority) {
+			if err := q.acquire(context.Background(), p); err != nil {
+				t.Error(err)
+			}
+
+			order <- p
+		}(p)
+
+		waiting(i + 1)
+	}
+
+	for _, exp := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		q.release()
+		if p := <-order; p != exp {
+			t.Errorf("Expected priority: %v, got: %v", exp, p)
+		}
+	}
+
+	q.release()
+	if q.active != 0 {
+		t.Errorf("Expected active requests: 0, got: %v", q.active)
+	}
+}
+
+func TestRequestPriority(t *testing.T) {
+	t.Parallel()
+	var last string
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			last = r.Header.Get("X-Priority")
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetMaxConcurrentRequests(-1); err == nil {
+		t.Error("Expected error for invalid max concurrent requests")
+	}
+
+	if err := cfg.SetMaxConcurrentRequests(1); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetPriorityHeader("x-priority")
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	ctx := WithPriority(context.Background(), PriorityLow)
+	if _, err := sc.GetStatsContext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if last != "low" {
+		t.Errorf("Expected priority header: low, got: %v", last)
+	}
+
+	if sc.requestQueue.active != 0 {
+		t.Errorf("Expected active requests: 0, got: %v",
+			sc.requestQueue.active)
+	}
+}
@@ -0,0 +1,495 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Default Prometheus remote read settings.
+const defaultPromReadPeriod = time.Minute
+
+// PromMatchType values are the types of Prometheus label matchers.
+type PromMatchType int
+
+// Prometheus label matcher types.
+const (
+	PromMatchEqual PromMatchType = iota
+	PromMatchNotEqual
+	PromMatchRegexp
+	PromMatchNotRegexp
+)
+
+// PromLabelMatcher values are the label matchers of Prometheus queries.
+type PromLabelMatcher struct {
+	Type  PromMatchType
+	Name  string
+	Value string
+}
+
+// PromQuery values are the queries of Prometheus remote read requests. The
+// start and end times are in milliseconds since the epoch.
+type PromQuery struct {
+	StartTimestampMs int64
+	EndTimestampMs   int64
+	Matchers         []PromLabelMatcher
+}
+
+// PromReadRequest values are Prometheus remote read requests.
+type PromReadRequest struct {
+	Queries []PromQuery
+}
+
+// PromQueryResult values contain the time series returned for one query of
+// a Prometheus remote read request.
+type PromQueryResult struct {
+	Timeseries []PromTimeSeries
+
+	// Info describes the completeness of the metrics found for the query.
+	// It is not encoded in remote read responses.
+	Info *SearchResultInfo
+}
+
+// PromReadResponse values are Prometheus remote read responses, containing
+// one result for each query of the request.
+type PromReadResponse struct {
+	Results []PromQueryResult
+}
+
+// DecodePromReadRequest reads a Prometheus remote read request body, which
+// is a snappy compressed protocol buffers encoded ReadRequest message, and
+// decodes it.
+func DecodePromReadRequest(r io.Reader) (*PromReadRequest, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read remote read request: %w", err)
+	}
+
+	if b, err = snappyDecode(b); err != nil {
+		return nil, fmt.Errorf("unable to decompress remote read request: %w",
+			err)
+	}
+
+	req := &PromReadRequest{}
+	if err := req.unmarshal(b); err != nil {
+		return nil, fmt.Errorf("unable to decode remote read request: %w", err)
+	}
+
+	return req, nil
+}
+
+// unmarshal decodes a protocol buffers encoded ReadRequest message.
+func (req *PromReadRequest) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if f != 1 || wt != 2 {
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		v, err := p.bytes()
+		if err != nil {
+			return err
+		}
+
+		q := PromQuery{}
+		if err := q.unmarshal(v); err != nil {
+			return fmt.Errorf("query %d: %w", len(req.Queries), err)
+		}
+
+		req.Queries = append(req.Queries, q)
+	}
+
+	return nil
+}
+
+// unmarshal decodes a protocol buffers encoded Query message.
+func (q *PromQuery) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case (f == 1 || f == 2) && wt == 0:
+			v, err := p.varint()
+			if err != nil {
+				return err
+			}
+
+			if f == 1 {
+				q.StartTimestampMs = int64(v)
+			} else {
+				q.EndTimestampMs = int64(v)
+			}
+		case f == 3 && wt == 2:
+			v, err := p.bytes()
+			if err != nil {
+				return err
+			}
+
+			m := PromLabelMatcher{}
+			if err := m.unmarshal(v); err != nil {
+				return err
+			}
+
+			q.Matchers = append(q.Matchers, m)
+		default:
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// unmarshal decodes a protocol buffers encoded LabelMatcher message.
+func (m *PromLabelMatcher) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case f == 1 && wt == 0:
+			v, err := p.varint()
+			if err != nil {
+				return err
+			}
+
+			m.Type = PromMatchType(v)
+		case (f == 2 || f == 3) && wt == 2:
+			v, err := p.bytes()
+			if err != nil {
+				return err
+			}
+
+			if f == 2 {
+				m.Name = string(v)
+			} else {
+				m.Value = string(v)
+			}
+		default:
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// appendUvarint appends a varint value to a protocol buffers message.
+func appendUvarint(b []byte, v uint64) []byte {
+	buf := make([]byte, binary.MaxVarintLen64)
+	return append(b, buf[:binary.PutUvarint(buf, v)]...)
+}
+
+// appendProtoBytes appends a length delimited field to a protocol buffers
+// message.
+func appendProtoBytes(b []byte, field int, v []byte) []byte {
+	b = appendUvarint(b, uint64(field<<3|2))
+	b = appendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// marshal encodes a time series as a protocol buffers TimeSeries message.
+func (ts *PromTimeSeries) marshal() []byte {
+	b := []byte{}
+	for _, l := range ts.Labels {
+		lb := appendProtoBytes(nil, 1, []byte(l.Name))
+		lb = appendProtoBytes(lb, 2, []byte(l.Value))
+		b = appendProtoBytes(b, 1, lb)
+	}
+
+	for _, s := range ts.Samples {
+		sb := make([]byte, 9, 20)
+		sb[0] = 1<<3 | 1
+		binary.LittleEndian.PutUint64(sb[1:], math.Float64bits(s.Value))
+		sb = append(sb, 2<<3)
+		sb = appendUvarint(sb, uint64(s.Timestamp))
+		b = appendProtoBytes(b, 2, sb)
+	}
+
+	return b
+}
+
+// snappyEncode encodes data in the snappy block format. The data is stored
+// as literals, without compression, which all snappy decoders accept.
+func snappyEncode(src []byte) []byte {
+	b := appendUvarint(nil, uint64(len(src)))
+	for len(src) > 0 {
+		n := minInt(len(src), 1<<16)
+		switch {
+		case n <= 60:
+			b = append(b, byte(n-1)<<2)
+		case n <= 1<<8:
+			b = append(b, 60<<2, byte(n-1))
+		default:
+			b = append(b, 61<<2, byte(n-1), byte((n-1)>>8))
+		}
+
+		b = append(b, src[:n]...)
+		src = src[n:]
+	}
+
+	return b
+}
+
+// Encode writes the response as a Prometheus remote read response body,
+// which is a snappy compressed protocol buffers encoded ReadResponse
+// message.
+func (resp *PromReadResponse) Encode(w io.Writer) error {
+	b := []byte{}
+	for _, r := range resp.Results {
+		rb := []byte{}
+		for i := range r.Timeseries {
+			rb = appendProtoBytes(rb, 1, r.Timeseries[i].marshal())
+		}
+
+		b = appendProtoBytes(b, 1, rb)
+	}
+
+	if _, err := w.Write(snappyEncode(b)); err != nil {
+		return fmt.Errorf("unable to write remote read response: %w", err)
+	}
+
+	return nil
+}
+
+// promMatcherQuery returns the tag search query expression of a Prometheus
+// label matcher. Prometheus regular expressions are fully anchored.
+func promMatcherQuery(m PromLabelMatcher) (string, error) {
+	cat := "__name"
+	if m.Name != "__name__" {
+		cat = encodeTagQueryPart(m.Name)
+	}
+
+	re := func(s string) string {
+		return cat + ":" + encodeTagRegex(s)
+	}
+
+	switch m.Type {
+	case PromMatchEqual:
+		if m.Value == "" {
+			return "not(" + re(".*") + ")", nil
+		}
+
+		return cat + ":" + encodeTagQueryPart(m.Value), nil
+	case PromMatchNotEqual:
+		if m.Value == "" {
+			return re(".*"), nil
+		}
+
+		return "not(" + cat + ":" + encodeTagQueryPart(m.Value) + ")", nil
+	case PromMatchRegexp, PromMatchNotRegexp:
+		if _, err := regexp.Compile(m.Value); err != nil {
+			return "", fmt.Errorf("invalid label matcher regular expression: "+
+				"%s: %w", m.Value, err)
+		}
+
+		q := re("^(?:" + m.Value + ")$")
+		if m.Type == PromMatchNotRegexp {
+			q = "not(" + q + ")"
+		}
+
+		return q, nil
+	default:
+		return "", fmt.Errorf("invalid label matcher type: %d", m.Type)
+	}
+}
+
+// PromTagQuery returns the IRONdb tag search query which finds the metrics
+// matching a list of Prometheus label matchers. The __name__ label matches
+// the metric name, and all other labels match stream tags.
+func PromTagQuery(matchers []PromLabelMatcher) (string, error) {
+	if len(matchers) == 0 {
+		return "", fmt.Errorf("label matchers cannot be empty")
+	}
+
+	parts := make([]string, len(matchers))
+	for i, m := range matchers {
+		q, err := promMatcherQuery(m)
+		if err != nil {
+			return "", err
+		}
+
+		parts[i] = q
+	}
+
+	return "and(" + strings.Join(parts, ",") + ")", nil
+}
+
+// promLabels returns the Prometheus labels of a metric name, containing the
+// __name__ label and a label for each stream tag, sorted by name.
+func promLabels(name string) []PromLabel {
+	base, tags := splitMetricName(name)
+	r := []PromLabel{{Name: "__name__", Value: base}}
+	for _, t := range tags {
+		kv := strings.SplitN(t, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		r = append(r, PromLabel{
+			Name:  decodeTagPart(kv[0]),
+			Value: decodeTagPart(kv[1]),
+		})
+	}
+
+	sort.Slice(r, func(i, j int) bool {
+		return r[i].Name < r[j].Name
+	})
+
+	return r
+}
+
+// PromReadOptions values contain optional parameters used to control the
+// behavior of Prometheus remote reads.
+type PromReadOptions struct {
+	// Period is the period of the data points returned. The default is one
+	// minute.
+	Period time.Duration
+
+	// Limit is the advisory limit of the number of metrics found for each
+	// query. The default is the limit of the node.
+	Limit int64
+}
+
+// PromRead performs the queries of a Prometheus remote read request, finding
+// the metrics of an account which match the label matchers of each query, and
+// fetching their numeric data between the start and end times of the query.
+// The returned response contains one result for each query.
+func (sc *SnowthClient) PromRead(accountID int64, req *PromReadRequest,
+	options *PromReadOptions,
+	nodes ...*SnowthNode) (*PromReadResponse, error) {
+	return sc.PromReadContext(context.Background(), accountID, req, options,
+		nodes...)
+}
+
+// PromReadContext is the context aware version of PromRead.
+func (sc *SnowthClient) PromReadContext(ctx context.Context, accountID int64,
+	req *PromReadRequest, options *PromReadOptions,
+	nodes ...*SnowthNode) (*PromReadResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("remote read request cannot be nil")
+	}
+
+	opts := PromReadOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.Period < time.Second {
+		opts.Period = defaultPromReadPeriod
+	}
+
+	resp := &PromReadResponse{Results: make([]PromQueryResult,
+		len(req.Queries))}
+	for i, q := range req.Queries {
+		ts, info, err := sc.promQuery(ctx, accountID, q, &opts, nodes...)
+		if err != nil {
+			return nil, fmt.Errorf("query %d: %w", i, err)
+		}
+
+		resp.Results[i].Timeseries = ts
+		resp.Results[i].Info = info
+	}
+
+	return resp, nil
+}
+
+// promQuery performs one query of a Prometheus remote read request.
+func (sc *SnowthClient) promQuery(ctx context.Context, accountID int64,
+	q PromQuery, opts *PromReadOptions,
+	nodes ...*SnowthNode) ([]PromTimeSeries, *SearchResultInfo, error) {
+	tq, err := PromTagQuery(q.Matchers)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	start := time.Unix(0, q.StartTimestampMs*int64(time.Millisecond))
+	end := time.Unix(0, q.EndTimestampMs*int64(time.Millisecond))
+	if end.Before(start) {
+		return nil, nil, fmt.Errorf("query end is before start")
+	}
+
+	fr, err := sc.FindTagsContext(ctx, accountID, tq, &FindTagsOptions{
+		Start: start,
+		End:   end,
+		Limit: opts.Limit,
+	}, nodes...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ts := []PromTimeSeries{}
+	if len(fr.Items) == 0 {
+		return ts, fr.Info, nil
+	}
+
+	p := int64(opts.Period / time.Second)
+	startTS := start.Unix() - start.Unix()%p
+	fq := &FetchQuery{
+		Start:  time.Unix(startTS, 0),
+		Period: time.Duration(p) * time.Second,
+		Count:  (end.Unix()-startTS)/p + 1,
+		Reduce: []FetchReduce{{Label: "pass", Method: FetchReducePass}},
+	}
+
+	for _, item := range fr.Items {
+		fq.Streams = append(fq.Streams, FetchStream{
+			UUID:      item.UUID,
+			Name:      item.MetricName,
+			Kind:      FetchKindNumeric,
+			Transform: FetchTransformAverage,
+		})
+	}
+
+	df, err := sc.FetchValuesContext(ctx, fq, nodes...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i, item := range fr.Items {
+		s := PromTimeSeries{Labels: promLabels(item.MetricName)}
+		if i < len(df.Data) {
+			for j, v := range df.Data[i] {
+				fv, ok := v.(float64)
+				if !ok {
+					continue
+				}
+
+				s.Samples = append(s.Samples, PromSample{
+					Value:     fv,
+					Timestamp: (df.Head.Start + int64(j)*df.Head.Period) * 1000,
+				})
+			}
+		}
+
+		ts = append(ts, s)
+	}
+
+	return ts, fr.Info, nil
+}
@@ -0,0 +1,268 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const promFindTestData = `[
+	{
+		"uuid": "fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		"metric_name": "up|ST[instance:b\"aG9zdDo5MTAw\",job:api]",
+		"account_id": 1
+	},
+	{
+		"uuid": "fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		"metric_name": "up|ST[job:web]",
+		"account_id": 1
+	}
+]`
+
+const promFetchTestData = `{
+	"version": "DF4",
+	"head": {
+		"count": 3,
+		"start": 1529509020,
+		"period": 60
+	},
+	"meta": [],
+	"data": [
+		[1, null, 0],
+		[null, null, null]
+	]
+}`
+
+func TestPromTagQuery(t *testing.T) {
+	t.Parallel()
+	re := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	q, err := PromTagQuery([]PromLabelMatcher{
+		{Type: PromMatchEqual, Name: "__name__", Value: "up"},
+		{Type: PromMatchNotEqual, Name: "job", Value: "web"},
+		{Type: PromMatchRegexp, Name: "env", Value: "prod|stage"},
+		{Type: PromMatchNotRegexp, Name: "dc", Value: "east.*"},
+		{Type: PromMatchEqual, Name: "region", Value: ""},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `and(__name:b"dXA=",not(b"am9i":b"d2Vi"),` +
+		`b"ZW52":b/` + re("^(?:prod|stage)$") + `/,` +
+		`not(b"ZGM=":b/` + re("^(?:east.*)$") + `/),` +
+		`not(b"cmVnaW9u":b/` + re(".*") + `/))`
+	if q != exp {
+		t.Errorf("Expected query: %v, got: %v", exp, q)
+	}
+
+	for _, m := range [][]PromLabelMatcher{
+		nil,
+		{{Type: PromMatchRegexp, Name: "a", Value: "("}},
+		{{Type: PromMatchType(9), Name: "a", Value: "b"}},
+	} {
+		if _, err := PromTagQuery(m); err == nil {
+			t.Errorf("Expected error for matchers: %+v", m)
+		}
+	}
+}
+
+func TestDecodePromReadRequest(t *testing.T) {
+	t.Parallel()
+	m := appendUvarint([]byte{1 << 3}, uint64(PromMatchRegexp))
+	m = appendProtoBytes(m, 2, []byte("job"))
+	m = appendProtoBytes(m, 3, []byte("api|web"))
+	q := appendUvarint([]byte{1 << 3}, 1529509020000)
+	q = append(q, 2<<3)
+	q = appendUvarint(q, 1529509200000)
+	q = appendProtoBytes(q, 3, m)
+	// Unknown hints field, which is skipped.
+	q = appendProtoBytes(q, 4, []byte{1 << 3, 60})
+	req, err := DecodePromReadRequest(bytes.NewReader(snappyEncode(
+		appendProtoBytes(nil, 1, q))))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := &PromReadRequest{Queries: []PromQuery{{
+		StartTimestampMs: 1529509020000,
+		EndTimestampMs:   1529509200000,
+		Matchers: []PromLabelMatcher{
+			{Type: PromMatchRegexp, Name: "job", Value: "api|web"},
+		},
+	}}}
+
+	if !reflect.DeepEqual(req, exp) {
+		t.Errorf("Expected request: %+v, got: %+v", exp, req)
+	}
+}
+
+func TestPromReadResponseEncode(t *testing.T) {
+	t.Parallel()
+	ts := PromTimeSeries{
+		Labels:  []PromLabel{{Name: "__name__", Value: "up"}},
+		Samples: []PromSample{{Value: 1, Timestamp: 1529509020000}},
+	}
+
+	resp := &PromReadResponse{Results: []PromQueryResult{
+		{Timeseries: []PromTimeSeries{ts}},
+		{},
+	}}
+
+	buf := &bytes.Buffer{}
+	if err := resp.Encode(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := snappyDecode(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &protoReader{b: b}
+	results := [][]byte{}
+	for len(p.b) > 0 {
+		if _, _, err := p.next(); err != nil {
+			t.Fatal(err)
+		}
+
+		v, err := p.bytes()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results = append(results, v)
+	}
+
+	if len(results) != 2 || len(results[1]) != 0 {
+		t.Fatalf("Expected results: 2, got: %v", results)
+	}
+
+	rp := &protoReader{b: results[0]}
+	if _, _, err := rp.next(); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := rp.bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dts := PromTimeSeries{}
+	if err := dts.unmarshal(v); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(dts, ts) {
+		t.Errorf("Expected time series: %+v, got: %+v", ts, dts)
+	}
+}
+
+func TestPromRead(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=") {
+			if q := r.URL.Query().Get("query"); q != `and(__name:b"dXA=")` {
+				t.Errorf("Expected query: %v, got: %v",
+					`and(__name:b"dXA=")`, q)
+			}
+
+			_, _ = w.Write([]byte(promFindTestData))
+			return
+		}
+
+		if r.RequestURI == "/fetch" {
+			fq := map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&fq); err != nil {
+				t.Error(err)
+			}
+
+			if fq["start"] != 1529509020.0 || fq["period"] != 60.0 ||
+				fq["count"] != 4.0 {
+				t.Errorf("Unexpected fetch query: %v", fq)
+			}
+
+			_, _ = w.Write([]byte(promFetchTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	req := &PromReadRequest{Queries: []PromQuery{{
+		StartTimestampMs: 1529509050000,
+		EndTimestampMs:   1529509200000,
+		Matchers: []PromLabelMatcher{
+			{Type: PromMatchEqual, Name: "__name__", Value: "up"},
+		},
+	}}}
+
+	resp, err := sc.PromRead(1, req, &PromReadOptions{Period: time.Minute},
+		&SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(resp.Results) != 1 || len(resp.Results[0].Timeseries) != 2 {
+		t.Fatalf("Unexpected response: %+v", resp)
+	}
+
+	if info := resp.Results[0].Info; info == nil || info.Returned != 2 {
+		t.Errorf("Unexpected search result info: %+v", info)
+	}
+
+	exp := PromTimeSeries{
+		Labels: []PromLabel{
+			{Name: "__name__", Value: "up"},
+			{Name: "instance", Value: "host:9100"},
+			{Name: "job", Value: "api"},
+		},
+		Samples: []PromSample{
+			{Value: 1, Timestamp: 1529509020000},
+			{Value: 0, Timestamp: 1529509140000},
+		},
+	}
+
+	if !reflect.DeepEqual(resp.Results[0].Timeseries[0], exp) {
+		t.Errorf("Expected time series: %+v, got: %+v", exp,
+			resp.Results[0].Timeseries[0])
+	}
+
+	if s := resp.Results[0].Timeseries[1]; len(s.Samples) != 0 ||
+		s.Labels[1].Value != "web" {
+		t.Errorf("Unexpected time series: %+v", s)
+	}
+}
@@ -0,0 +1,500 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"strings"
+	"time"
+)
+
+// maxPromDecodedLen is the maximum decoded size of a Prometheus remote write
+// request body.
+const maxPromDecodedLen = 64 << 20
+
+// PromLabel values are the labels of Prometheus time series.
+type PromLabel struct {
+	Name  string
+	Value string
+}
+
+// PromSample values are the samples of Prometheus time series. The timestamp
+// is in milliseconds since the epoch.
+type PromSample struct {
+	Value     float64
+	Timestamp int64
+}
+
+// PromTimeSeries values are Prometheus time series, identified by their
+// labels, including the __name__ label containing the metric name.
+type PromTimeSeries struct {
+	Labels  []PromLabel
+	Samples []PromSample
+}
+
+// PromWriteRequest values are Prometheus remote write requests.
+type PromWriteRequest struct {
+	Timeseries []PromTimeSeries
+}
+
+// DecodePromWriteRequest reads a Prometheus remote write request body, which
+// is a snappy compressed protocol buffers encoded WriteRequest message, and
+// decodes it.
+func DecodePromWriteRequest(r io.Reader) (*PromWriteRequest, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read remote write request: %w", err)
+	}
+
+	if b, err = snappyDecode(b); err != nil {
+		return nil, fmt.Errorf("unable to decompress remote write request: %w",
+			err)
+	}
+
+	req := &PromWriteRequest{}
+	if err := req.unmarshal(b); err != nil {
+		return nil, fmt.Errorf("unable to decode remote write request: %w",
+			err)
+	}
+
+	return req, nil
+}
+
+// snappyDecode decodes data in the snappy block format used by Prometheus
+// remote write requests.
+func snappyDecode(src []byte) ([]byte, error) {
+	n, i := binary.Uvarint(src)
+	if i <= 0 || n > maxPromDecodedLen {
+		return nil, fmt.Errorf("invalid snappy data length")
+	}
+
+	dst := make([]byte, 0, n)
+	for s := i; s < len(src); {
+		tag := src[s]
+		length, offset := 0, 0
+		switch tag & 3 {
+		case 0:
+			length = int(tag >> 2)
+			s++
+			if length >= 60 {
+				nb := length - 59
+				if s+nb > len(src) {
+					return nil, fmt.Errorf("invalid snappy literal")
+				}
+
+				length = 0
+				for j := 0; j < nb; j++ {
+					length |= int(src[s+j]) << (uint(j) * 8)
+				}
+
+				s += nb
+			}
+
+			length++
+			if length <= 0 || length > len(src)-s {
+				return nil, fmt.Errorf("invalid snappy literal")
+			}
+
+			dst = append(dst, src[s:s+length]...)
+			s += length
+			continue
+		case 1:
+			if s+2 > len(src) {
+				return nil, fmt.Errorf("invalid snappy copy")
+			}
+
+			length = 4 + int(tag>>2)&7
+			offset = int(tag&0xe0)<<3 | int(src[s+1])
+			s += 2
+		case 2:
+			if s+3 > len(src) {
+				return nil, fmt.Errorf("invalid snappy copy")
+			}
+
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[s+1:]))
+			s += 3
+		case 3:
+			if s+5 > len(src) {
+				return nil, fmt.Errorf("invalid snappy copy")
+			}
+
+			length = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[s+1:]))
+			s += 5
+		}
+
+		if offset <= 0 || offset > len(dst) ||
+			uint64(len(dst)+length) > n {
+			return nil, fmt.Errorf("invalid snappy copy")
+		}
+
+		for j := 0; j < length; j++ {
+			dst = append(dst, dst[len(dst)-offset])
+		}
+	}
+
+	if uint64(len(dst)) != n {
+		return nil, fmt.Errorf("invalid snappy data length")
+	}
+
+	return dst, nil
+}
+
+// protoReader values read protocol buffers encoded messages.
+type protoReader struct {
+	b []byte
+}
+
+// next reads the field number and wire type of the next field.
+func (p *protoReader) next() (int, int, error) {
+	k, err := p.varint()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return int(k >> 3), int(k & 7), nil
+}
+
+// varint reads a varint value.
+func (p *protoReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(p.b)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint")
+	}
+
+	p.b = p.b[n:]
+	return v, nil
+}
+
+// bytes reads a length delimited value.
+func (p *protoReader) bytes() ([]byte, error) {
+	l, err := p.varint()
+	if err != nil {
+		return nil, err
+	}
+
+	if l > uint64(len(p.b)) {
+		return nil, fmt.Errorf("invalid field length")
+	}
+
+	v := p.b[:l]
+	p.b = p.b[l:]
+	return v, nil
+}
+
+// fixed64 reads a fixed 64 bit value.
+func (p *protoReader) fixed64() (uint64, error) {
+	if len(p.b) < 8 {
+		return 0, fmt.Errorf("invalid fixed64 value")
+	}
+
+	v := binary.LittleEndian.Uint64(p.b)
+	p.b = p.b[8:]
+	return v, nil
+}
+
+// skip skips a value of a field with the specified wire type.
+func (p *protoReader) skip(wt int) error {
+	var err error
+	switch wt {
+	case 0:
+		_, err = p.varint()
+	case 1:
+		_, err = p.fixed64()
+	case 2:
+		_, err = p.bytes()
+	case 5:
+		if len(p.b) < 4 {
+			return fmt.Errorf("invalid fixed32 value")
+		}
+
+		p.b = p.b[4:]
+	default:
+		err = fmt.Errorf("unsupported wire type: %d", wt)
+	}
+
+	return err
+}
+
+// unmarshal decodes a protocol buffers encoded WriteRequest message.
+func (req *PromWriteRequest) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if f != 1 || wt != 2 {
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		v, err := p.bytes()
+		if err != nil {
+			return err
+		}
+
+		ts := PromTimeSeries{}
+		if err := ts.unmarshal(v); err != nil {
+			return fmt.Errorf("time series %d: %w", len(req.Timeseries), err)
+		}
+
+		req.Timeseries = append(req.Timeseries, ts)
+	}
+
+	return nil
+}
+
+// unmarshal decodes a protocol buffers encoded TimeSeries message.
+func (ts *PromTimeSeries) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if (f != 1 && f != 2) || wt != 2 {
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		v, err := p.bytes()
+		if err != nil {
+			return err
+		}
+
+		if f == 1 {
+			l := PromLabel{}
+			if err := l.unmarshal(v); err != nil {
+				return err
+			}
+
+			ts.Labels = append(ts.Labels, l)
+			continue
+		}
+
+		s := PromSample{}
+		if err := s.unmarshal(v); err != nil {
+			return err
+		}
+
+		ts.Samples = append(ts.Samples, s)
+	}
+
+	return nil
+}
+
+// unmarshal decodes a protocol buffers encoded Label message.
+func (l *PromLabel) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		if (f != 1 && f != 2) || wt != 2 {
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		v, err := p.bytes()
+		if err != nil {
+			return err
+		}
+
+		if f == 1 {
+			l.Name = string(v)
+		} else {
+			l.Value = string(v)
+		}
+	}
+
+	return nil
+}
+
+// unmarshal decodes a protocol buffers encoded Sample message.
+func (s *PromSample) unmarshal(b []byte) error {
+	p := &protoReader{b: b}
+	for len(p.b) > 0 {
+		f, wt, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case f == 1 && wt == 1:
+			v, err := p.fixed64()
+			if err != nil {
+				return err
+			}
+
+			s.Value = math.Float64frombits(v)
+		case f == 2 && wt == 0:
+			v, err := p.varint()
+			if err != nil {
+				return err
+			}
+
+			s.Timestamp = int64(v)
+		default:
+			if err := p.skip(wt); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// SanitizePromMetricName returns a metric name which contains only the
+// characters allowed in Prometheus metric names, replacing all other
+// characters with underscores. This prevents metric names from containing
+// characters which IRONdb interprets as stream tag syntax.
+func SanitizePromMetricName(name string) string {
+	b := strings.Builder{}
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+
+	return b.String()
+}
+
+// PromWriteOptions values contain optional parameters used to control the
+// behavior of Prometheus remote write conversion.
+type PromWriteOptions struct {
+	// AccountID, CheckUUID and CheckName identify the check the samples
+	// are written to.
+	AccountID int32
+	CheckUUID string
+	CheckName string
+
+	// BatchSize is the maximum number of samples written in a single write
+	// request. The default is 1000.
+	BatchSize int
+}
+
+// RawMetrics converts the samples of a Prometheus remote write request into
+// raw metrics, for the account and check specified by the options. Metric
+// names are taken from the __name__ label and sanitized, and all other labels
+// are converted to stream tags. Samples with NaN or infinite values, which
+// include Prometheus staleness markers, and time series without a metric
+// name, are skipped.
+func (req *PromWriteRequest) RawMetrics(options *PromWriteOptions) []RawMetric {
+	opts := PromWriteOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	r := []RawMetric{}
+	for _, ts := range req.Timeseries {
+		name := ""
+		tags := make([]string, 0, len(ts.Labels))
+		for _, l := range ts.Labels {
+			if l.Name == "__name__" {
+				name = SanitizePromMetricName(l.Value)
+				continue
+			}
+
+			if l.Name != "" && l.Value != "" {
+				tags = append(tags, l.Name+":"+l.Value)
+			}
+		}
+
+		if name == "" {
+			continue
+		}
+
+		for _, s := range ts.Samples {
+			if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+				continue
+			}
+
+			r = append(r, RawMetric{
+				AccountID: opts.AccountID,
+				CheckUUID: opts.CheckUUID,
+				CheckName: opts.CheckName,
+				Metric:    name,
+				Tags:      tags,
+				Timestamp: time.Unix(0, s.Timestamp*int64(time.Millisecond)),
+				Value:     s.Value,
+			})
+		}
+	}
+
+	return r
+}
+
+// WritePromRequest writes the samples of a Prometheus remote write request
+// to IRONdb, in batches of at most the batch size of the options. The samples
+// are converted as described by PromWriteRequest.RawMetrics. The returned
+// response contains the combined results of all batches, and if any batch
+// failed, the returned error is a WriteSummary describing the failures.
+func (sc *SnowthClient) WritePromRequest(req *PromWriteRequest,
+	options *PromWriteOptions,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	return sc.WritePromRequestContext(context.Background(), req, options,
+		nodes...)
+}
+
+// WritePromRequestContext is the context aware version of WritePromRequest.
+func (sc *SnowthClient) WritePromRequestContext(ctx context.Context,
+	req *PromWriteRequest, options *PromWriteOptions,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	if req == nil {
+		return nil, fmt.Errorf("remote write request cannot be nil")
+	}
+
+	opts := PromWriteOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	data := req.RawMetrics(&opts)
+	ws := &WriteSummary{}
+	for i := 0; i < len(data); i += opts.BatchSize {
+		n := minInt(opts.BatchSize, len(data)-i)
+		wc := WriteChunk{First: i, Records: n}
+		var r *IRONdbPutResponse
+		if ctx.Err() != nil {
+			wc.Err = fmt.Errorf("context terminated: %w", ctx.Err())
+		} else {
+			r, wc.Err = sc.WriteRawMetricsContext(ctx, data[i:i+n], nodes...)
+		}
+
+		ws.add(wc, r)
+	}
+
+	return &ws.Response, ws.err()
+}
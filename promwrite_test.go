@@ -0,0 +1,205 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestSnappyDecode(t *testing.T) {
+	t.Parallel()
+	// A literal "abc", followed by a copy of 6 bytes at offset 3.
+	b, err := snappyDecode([]byte{9, 2 << 2, 'a', 'b', 'c', 1 | 2<<2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "abcabcabc" {
+		t.Errorf("Expected data: abcabcabc, got: %s", b)
+	}
+
+	in := []byte(strings.Repeat("0123456789", 50))
+	if b, err = snappyDecode(snappyEncode(in)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(b, in) {
+		t.Errorf("Expected data: %s, got: %s", in, b)
+	}
+
+	for _, d := range [][]byte{{}, {3, 0, 'a'}, {4, 1 | 2<<2, 3},
+		{3, 8 << 2, 'a'}} {
+		if _, err := snappyDecode(d); err == nil {
+			t.Errorf("Expected error for data: %v", d)
+		}
+	}
+}
+
+func TestDecodePromWriteRequest(t *testing.T) {
+	t.Parallel()
+	ts := []PromTimeSeries{{
+		Labels: []PromLabel{
+			{Name: "__name__", Value: "http_requests_total"},
+			{Name: "job", Value: "api"},
+		},
+		Samples: []PromSample{
+			{Value: 1.5, Timestamp: 1529509020000},
+			{Value: math.NaN(), Timestamp: 1529509080000},
+		},
+	}, {
+		Labels: []PromLabel{
+			{Name: "__name__", Value: "node.load|1"},
+		},
+		Samples: []PromSample{{Value: 2, Timestamp: 1529509020500}},
+	}, {
+		Labels:  []PromLabel{{Name: "job", Value: "api"}},
+		Samples: []PromSample{{Value: 3, Timestamp: 1529509020000}},
+	}}
+
+	b := []byte{}
+	for _, s := range ts {
+		b = appendProtoBytes(b, 1, s.marshal())
+	}
+
+	// An unknown metadata field, which is skipped.
+	b = appendProtoBytes(b, 3, []byte{1<<3 | 0, 1})
+	req, err := DecodePromWriteRequest(bytes.NewReader(
+		snappyEncode(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(req.Timeseries) != 3 {
+		t.Fatalf("Expected time series: 3, got: %v", len(req.Timeseries))
+	}
+
+	if !reflect.DeepEqual(req.Timeseries[0].Labels, ts[0].Labels) ||
+		req.Timeseries[0].Samples[0] != ts[0].Samples[0] ||
+		!math.IsNaN(req.Timeseries[0].Samples[1].Value) {
+		t.Errorf("Expected time series: %+v, got: %+v", ts[0],
+			req.Timeseries[0])
+	}
+
+	ms := req.RawMetrics(&PromWriteOptions{AccountID: 1, CheckName: "prom"})
+	if len(ms) != 2 {
+		t.Fatalf("Expected metrics: 2, got: %v", len(ms))
+	}
+
+	if ms[0].Metric != "http_requests_total" || ms[0].AccountID != 1 ||
+		ms[0].CheckName != "prom" || ms[0].Value != 1.5 ||
+		!reflect.DeepEqual(ms[0].Tags, []string{"job:api"}) ||
+		ms[0].Timestamp.Unix() != 1529509020 {
+		t.Errorf("Unexpected metric: %+v", ms[0])
+	}
+
+	if ms[1].Metric != "node_load_1" ||
+		ms[1].Timestamp.UnixNano() != 1529509020500000000 {
+		t.Errorf("Unexpected metric: %+v", ms[1])
+	}
+
+	if _, err := DecodePromWriteRequest(bytes.NewReader(
+		snappyEncode([]byte{1<<3 | 2, 5}))); err == nil {
+		t.Error("Expected error for invalid message")
+	}
+}
+
+func TestSanitizePromMetricName(t *testing.T) {
+	t.Parallel()
+	for in, exp := range map[string]string{
+		"http_requests_total": "http_requests_total",
+		"node:load1":          "node:load1",
+		"1xx.count|ST[a:b]":   "_1xx_count_ST_a:b_",
+	} {
+		if s := SanitizePromMetricName(in); s != exp {
+			t.Errorf("Expected name: %v, got: %v", exp, s)
+		}
+	}
+}
+
+func TestWritePromRequest(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	batches := []int{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			data := []map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			batches = append(batches, len(data))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"records":` + strconv.Itoa(len(data)) +
+				`,"updated":0,"misdirected":0,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	req := &PromWriteRequest{Timeseries: []PromTimeSeries{{
+		Labels: []PromLabel{{Name: "__name__", Value: "up"}},
+	}}}
+
+	for i := 0; i < 5; i++ {
+		req.Timeseries[0].Samples = append(req.Timeseries[0].Samples,
+			PromSample{Value: 1, Timestamp: 1529509020000 + int64(i)*1000})
+	}
+
+	res, err := sc.WritePromRequest(req, &PromWriteOptions{BatchSize: 2},
+		&SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 5 {
+		t.Errorf("Expected records: 5, got: %v", res.Records)
+	}
+
+	mu.Lock()
+	if !reflect.DeepEqual(batches, []int{2, 2, 1}) {
+		t.Errorf("Expected batches: [2 2 1], got: %v", batches)
+	}
+
+	mu.Unlock()
+	ms.Close()
+	_, err = sc.WritePromRequest(req, nil, &SnowthNode{url: u})
+	ws := &WriteSummary{}
+	if !errors.As(err, &ws) || ws.Failed != 1 {
+		t.Errorf("Expected write summary error, got: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy gets the URL of the HTTP proxy through which the client connects to
+// IRONdb nodes. If no proxy is configured, the default, the proxy is selected
+// using the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+func (c *Config) Proxy() string {
+	c.RLock()
+	defer c.RUnlock()
+	return c.proxy
+}
+
+// SetProxy sets the URL of the HTTP proxy through which the client connects to
+// IRONdb nodes. The http, https and socks5 proxy schemes are supported. Proxy
+// credentials can be included in the URL, or set using SetProxyAuth. An empty
+// value restores the use of the proxy environment variables.
+func (c *Config) SetProxy(proxyURL string) error {
+	if proxyURL != "" {
+		u, err := url.Parse(proxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid proxy URL: %w", err)
+		}
+
+		switch u.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return fmt.Errorf("invalid proxy URL scheme: %s", u.Scheme)
+		}
+
+		if u.Host == "" {
+			return fmt.Errorf("invalid proxy URL: host required")
+		}
+	}
+
+	c.Lock()
+	c.proxy = proxyURL
+	c.Unlock()
+	return nil
+}
+
+// ProxyAuth gets the username and password used to authenticate with the
+// configured HTTP proxy.
+func (c *Config) ProxyAuth() (string, string) {
+	c.RLock()
+	defer c.RUnlock()
+	return c.proxyUser, c.proxyPass
+}
+
+// SetProxyAuth sets the username and password used to authenticate with the
+// configured HTTP proxy. These override any credentials in the proxy URL.
+func (c *Config) SetProxyAuth(username, password string) {
+	c.Lock()
+	c.proxyUser = username
+	c.proxyPass = password
+	c.Unlock()
+}
+
+// NoProxy gets the list of hosts which are connected to directly, rather than
+// through the configured HTTP proxy.
+func (c *Config) NoProxy() []string {
+	c.RLock()
+	defer c.RUnlock()
+	r := make([]string, len(c.noProxy))
+	copy(r, c.noProxy)
+	return r
+}
+
+// SetNoProxy sets the list of hosts which are connected to directly, rather
+// than through the configured HTTP proxy. Entries use the same format as the
+// NO_PROXY environment variable: host names, which also match their
+// subdomains, IP addresses, CIDR ranges, or * to match all hosts.
+func (c *Config) SetNoProxy(hosts ...string) {
+	c.Lock()
+	c.noProxy = append([]string{}, hosts...)
+	c.Unlock()
+}
+
+// proxyFunc returns the function used by the HTTP transport of the client to
+// select the proxy for each request.
+func (c *Config) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	u, err := url.Parse(c.proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+
+	if c.proxyUser != "" {
+		u.User = url.UserPassword(c.proxyUser, c.proxyPass)
+	}
+
+	noProxy := append([]string{}, c.noProxy...)
+	return func(r *http.Request) (*url.URL, error) {
+		if matchNoProxy(r.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+
+		return u, nil
+	}, nil
+}
+
+// matchNoProxy returns whether a host matches any entry of a no proxy list.
+func matchNoProxy(host string, noProxy []string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, p := range noProxy {
+		p = strings.ToLower(strings.TrimSpace(p))
+		if p == "" {
+			continue
+		}
+
+		if p == "*" {
+			return true
+		}
+
+		if h, _, err := net.SplitHostPort(p); err == nil {
+			p = h
+		}
+
+		if _, cidr, err := net.ParseCIDR(p); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		if pip := net.ParseIP(p); pip != nil {
+			if ip != nil && pip.Equal(ip) {
+				return true
+			}
+
+			continue
+		}
+
+		p = strings.TrimPrefix(p, "*")
+		if host == strings.TrimPrefix(p, ".") || strings.HasSuffix(host,
+			"."+strings.TrimPrefix(p, ".")) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"time"
+)
+
+// DeactivateNode removes a node from rotation until it is returned to rotation
+// by ActivateNode. Unlike DeactivateNodes, a node deactivated using this method
+// is not reactivated by the watchdog or by WatchAndUpdate when it is healthy.
+func (sc *SnowthClient) DeactivateNode(node *SnowthNode) {
+	if node == nil || node.GetURL() == nil {
+		return
+	}
+
+	if sc.parent != nil {
+		sc.parent.DeactivateNode(node)
+		return
+	}
+
+	sc.Lock()
+	sc.quarantine[node.GetURL().String()] = time.Time{}
+	sc.Unlock()
+	sc.LogInfof("deactivating node: %s", node.GetURL().Host)
+	sc.DeactivateNodes(node)
+	sc.persistState()
+}
+
+// ActivateNode returns a node to rotation, ending any manual deactivation or
+// quarantine of the node.
+func (sc *SnowthClient) ActivateNode(node *SnowthNode) {
+	if node == nil || node.GetURL() == nil {
+		return
+	}
+
+	if sc.parent != nil {
+		sc.parent.ActivateNode(node)
+		return
+	}
+
+	sc.Lock()
+	delete(sc.quarantine, node.GetURL().String())
+	sc.Unlock()
+	sc.LogInfof("activating node: %s", node.GetURL().Host)
+	sc.ActivateNodes(node)
+	sc.persistState()
+}
+
+// QuarantineNode removes a node from rotation for the specified duration,
+// after which the node is returned to rotation. The watchdog and WatchAndUpdate
+// do not reactivate a quarantined node, even when it is healthy.
+func (sc *SnowthClient) QuarantineNode(node *SnowthNode, d time.Duration) {
+	if node == nil || node.GetURL() == nil {
+		return
+	}
+
+	if sc.parent != nil {
+		sc.parent.QuarantineNode(node, d)
+		return
+	}
+
+	if d <= 0 {
+		sc.ActivateNode(node)
+		return
+	}
+
+	key := node.GetURL().String()
+	clock := sc.getClock()
+	until := clock.Now().Add(d)
+	sc.Lock()
+	sc.quarantine[key] = until
+	sc.Unlock()
+	sc.LogInfof("quarantining node: %s until %v", node.GetURL().Host, until)
+	sc.DeactivateNodes(node)
+	sc.persistState()
+	timer := clock.After(d)
+	go func() {
+		<-timer
+		sc.Lock()
+		u, ok := sc.quarantine[key]
+		if !ok || !u.Equal(until) {
+			// The quarantine was ended or replaced.
+			sc.Unlock()
+			return
+		}
+
+		delete(sc.quarantine, key)
+		sc.Unlock()
+		sc.LogInfof("quarantine ended for node: %s", node.GetURL().Host)
+		sc.ActivateNodes(node)
+		sc.persistState()
+	}()
+}
+
+// isQuarantined returns whether a node has been manually deactivated or is
+// quarantined.
+func (sc *SnowthClient) isQuarantined(node *SnowthNode) bool {
+	if node == nil || node.GetURL() == nil {
+		return false
+	}
+
+	if sc.parent != nil {
+		return sc.parent.isQuarantined(node)
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	_, ok := sc.quarantine[node.GetURL().String()]
+	return ok
+}
@@ -0,0 +1,76 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestQuarantineNode(t *testing.T) {
+	t.Parallel()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mc := NewManualClock(time.Unix(1600000000, 0))
+	sc.SetClock(mc)
+	node := sc.ListActiveNodes()[0]
+	sc.DeactivateNode(node)
+	if len(sc.ListActiveNodes()) != 0 {
+		t.Fatal("Expected node to be deactivated")
+	}
+
+	sc.checkNodes(context.Background(), time.Second)
+	if len(sc.ListActiveNodes()) != 0 {
+		t.Error("Expected watchdog not to reactivate node")
+	}
+
+	sc.ActivateNode(node)
+	if len(sc.ListActiveNodes()) != 1 {
+		t.Fatal("Expected node to be activated")
+	}
+
+	sc.QuarantineNode(node, time.Minute)
+	if len(sc.ListActiveNodes()) != 0 || !sc.isQuarantined(node) {
+		t.Fatal("Expected node to be quarantined")
+	}
+
+	mc.Advance(time.Minute)
+	deadline := time.Now().Add(time.Second)
+	for len(sc.ListActiveNodes()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if len(sc.ListActiveNodes()) != 1 || sc.isQuarantined(node) {
+		t.Fatal("Expected node to be activated after quarantine")
+	}
+
+	sc.QuarantineNode(node, time.Minute)
+	sc.DeactivateNode(node)
+	mc.Advance(time.Minute)
+	time.Sleep(10 * time.Millisecond)
+	if len(sc.ListActiveNodes()) != 0 {
+		t.Error("Expected replaced quarantine not to activate node")
+	}
+}
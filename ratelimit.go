@@ -0,0 +1,175 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter values implement a token bucket used to limit the rate of
+// requests sent to a single node. A rateLimiter with a non-positive rate does
+// not limit requests.
+type rateLimiter struct {
+	sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	override bool
+}
+
+// newRateLimiter creates a new token bucket with the specified rate, in
+// requests per second, and burst size.
+func newRateLimiter(rate float64, burst int64, override bool) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &rateLimiter{
+		rate:     rate,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		override: override,
+	}
+}
+
+// reserve takes a token from the bucket and returns the duration the caller
+// must wait before the token may be used.
+func (rl *rateLimiter) reserve(now time.Time) time.Duration {
+	if rl == nil || rl.rate <= 0 {
+		return 0
+	}
+
+	rl.Lock()
+	defer rl.Unlock()
+	if !rl.last.IsZero() && now.After(rl.last) {
+		rl.tokens = math.Min(rl.burst,
+			rl.tokens+now.Sub(rl.last).Seconds()*rl.rate)
+	}
+
+	if now.After(rl.last) {
+		rl.last = now
+	}
+
+	rl.tokens--
+	if rl.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+}
+
+// cancel returns a reserved token which was not used to the bucket.
+func (rl *rateLimiter) cancel() {
+	if rl == nil || rl.rate <= 0 {
+		return
+	}
+
+	rl.Lock()
+	defer rl.Unlock()
+	rl.tokens = math.Min(rl.burst, rl.tokens+1)
+}
+
+// SetRateLimit sets the default maximum sustained rate, in requests per
+// second, and burst size used to limit the requests sent to each node. A rate
+// of zero disables rate limiting. Nodes with a rate limit assigned by
+// SetNodeRateLimit() are not affected. Rate limits are shared by a client and
+// the clients created from it using WithConfig.
+func (sc *SnowthClient) SetRateLimit(rps float64, burst int64) {
+	root := sc.root()
+	root.Lock()
+	defer root.Unlock()
+	root.rateLimit = rps
+	root.rateBurst = burst
+	for k, rl := range root.limiters {
+		if !rl.override {
+			delete(root.limiters, k)
+		}
+	}
+}
+
+// SetNodeRateLimit overrides the default rate limit for a single node. A rate
+// of zero disables rate limiting of requests to the node.
+func (sc *SnowthClient) SetNodeRateLimit(node *SnowthNode, rps float64,
+	burst int64) {
+	if node == nil || node.GetURL() == nil {
+		return
+	}
+
+	root := sc.root()
+	root.Lock()
+	defer root.Unlock()
+	if root.limiters == nil {
+		root.limiters = map[string]*rateLimiter{}
+	}
+
+	root.limiters[node.GetURL().String()] = newRateLimiter(rps, burst, true)
+}
+
+// ClearNodeRateLimit removes a rate limit override from a node, so that the
+// default rate limit applies to it again.
+func (sc *SnowthClient) ClearNodeRateLimit(node *SnowthNode) {
+	if node == nil || node.GetURL() == nil {
+		return
+	}
+
+	root := sc.root()
+	root.Lock()
+	defer root.Unlock()
+	delete(root.limiters, node.GetURL().String())
+}
+
+// nodeRateLimiter returns the token bucket used to limit requests to a node,
+// or nil if requests to the node are not rate limited.
+func (sc *SnowthClient) nodeRateLimiter(node *SnowthNode) *rateLimiter {
+	if node == nil || node.GetURL() == nil {
+		return nil
+	}
+
+	key := node.GetURL().String()
+	root := sc.root()
+	root.Lock()
+	defer root.Unlock()
+	if rl, ok := root.limiters[key]; ok {
+		return rl
+	}
+
+	if root.rateLimit <= 0 {
+		return nil
+	}
+
+	if root.limiters == nil {
+		root.limiters = map[string]*rateLimiter{}
+	}
+
+	rl := newRateLimiter(root.rateLimit, root.rateBurst, false)
+	root.limiters[key] = rl
+	return rl
+}
+
+// waitRateLimit blocks until the rate limit of a node allows another request
+// to be sent, or the context is terminated.
+func (sc *SnowthClient) waitRateLimit(ctx context.Context,
+	node *SnowthNode) error {
+	rl := sc.nodeRateLimiter(node)
+	if rl == nil {
+		return nil
+	}
+
+	clock := sc.getClock()
+	d := rl.reserve(clock.Now())
+	if d <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		rl.cancel()
+		return fmt.Errorf("rate limit wait terminated: %w", ctx.Err())
+	case <-clock.After(d):
+		return nil
+	}
+}
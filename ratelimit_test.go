@@ -0,0 +1,115 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	rl := newRateLimiter(10, 2, false)
+	for i := 0; i < 2; i++ {
+		if d := rl.reserve(now); d != 0 {
+			t.Errorf("Expected wait: 0, got: %v", d)
+		}
+	}
+
+	if d := rl.reserve(now); d != 100*time.Millisecond {
+		t.Errorf("Expected wait: %v, got: %v", 100*time.Millisecond, d)
+	}
+
+	rl.cancel()
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if d := rl.reserve(now); d != 0 {
+			t.Errorf("Expected wait: 0, got: %v", d)
+		}
+	}
+
+	var nl *rateLimiter
+	if d := nl.reserve(now); d != 0 {
+		t.Errorf("Expected wait: 0, got: %v", d)
+	}
+}
+
+func TestSnowthClientRateLimit(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	mc := NewManualClock(time.Unix(1000, 0))
+	sc.SetClock(mc)
+	sc.SetRateLimit(1, 1)
+	if _, err := sc.GetNodeState(node); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := sc.GetNodeState(node)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Expected request to wait for rate limit")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	mc.Advance(time.Second)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected request to proceed after rate limit wait")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := sc.GetNodeStateContext(ctx, node); err == nil {
+		t.Error("Expected rate limit wait error")
+	}
+
+	sc.SetNodeRateLimit(node, 0, 0)
+	for i := 0; i < 3; i++ {
+		if _, err := sc.GetNodeState(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sc.ClearNodeRateLimit(node)
+	if rl := sc.nodeRateLimiter(node); rl == nil || rl.override {
+		t.Error("Expected default node rate limiter")
+	}
+}
@@ -16,11 +16,12 @@ import (
 	flatbuffers "github.com/google/flatbuffers/go"
 
 	"github.com/circonus-labs/gosnowth/fb/noit"
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // MetriclistFlatbufferContentType is the content type header for flatbuffer
 // raw data.
-const MetriclistFlatbufferContentType = "application/x-circonus-metric-list-flatbuffer"
+const MetriclistFlatbufferContentType = snowthapi.ContentTypeMetricListFlatbuffer
 
 // RawNumericValueResponse values represent raw numeric data responses
 // from IRONdb.
@@ -77,7 +78,7 @@ func (sc *SnowthClient) ReadRawNumericValuesContext(ctx context.Context,
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(uuid, metric))
+		node = sc.GetActiveNode(sc.FindMetricNodeIDsContext(ctx, uuid, metric))
 	}
 
 	qp := url.Values{}
@@ -85,13 +86,13 @@ func (sc *SnowthClient) ReadRawNumericValuesContext(ctx context.Context,
 	qp.Add("end_ts", formatTimestamp(end))
 
 	r := &RawNumericValueResponse{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/raw",
-		uuid, metric)+"?"+qp.Encode(), nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		path.Join(snowthapi.PathRaw, uuid, metric)+"?"+qp.Encode(), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 	return r.Data, nil
@@ -109,6 +110,18 @@ func (sc *SnowthClient) WriteRaw(data io.Reader,
 func (sc *SnowthClient) WriteRawContext(ctx context.Context,
 	data io.Reader, fb bool, dataPoints uint64,
 	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	ct := ""
+	if fb { // is flatbuffer?
+		ct = MetriclistFlatbufferContentType
+	}
+
+	return sc.writeRaw(ctx, data, ct, dataPoints, nodes...)
+}
+
+// writeRaw writes raw IRONdb data with a content type to a node.
+func (sc *SnowthClient) writeRaw(ctx context.Context, data io.Reader,
+	contentType string, dataPoints uint64,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
@@ -117,27 +130,60 @@ func (sc *SnowthClient) WriteRawContext(ctx context.Context,
 	}
 
 	hdrs := http.Header{
-		"X-Snowth-Datapoints": {strconv.FormatUint(dataPoints, 10)},
+		snowthapi.HeaderDatapoints: {strconv.FormatUint(dataPoints, 10)},
 	}
 
-	if fb { // is flatbuffer?
-		hdrs["Content-Type"] = []string{MetriclistFlatbufferContentType}
+	if contentType != "" {
+		hdrs[snowthapi.HeaderContentType] = []string{contentType}
 	}
 
-	body, _, err := sc.DoRequestContext(ctx, node, "POST", "/raw", data, hdrs)
+	sc.getMetrics().batch(nodeHost(node), snowthapi.PathRaw, int(dataPoints))
+	level := sc.getAckLevel(ctx)
+	if level == AckNone {
+		buf := &bytes.Buffer{}
+		if data != nil {
+			if _, err := buf.ReadFrom(data); err != nil {
+				return nil, fmt.Errorf("unable to read write data: %w", err)
+			}
+		}
+
+		if err := sc.writeAsync(ctx, func(ctx context.Context) error {
+			_, _, err := sc.postWrite(ctx, node, snowthapi.PathRaw, buf, hdrs)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+
+		return &IRONdbPutResponse{}, nil
+	}
+
+	body, spooled, err := sc.postWrite(ctx, node, snowthapi.PathRaw, data,
+		hdrs)
 	if err != nil {
 		return nil, err
 	}
 
+	if spooled {
+		return &IRONdbPutResponse{}, nil
+	}
+
 	r := &IRONdbPutResponse{}
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
+	if level == AckJournaled && (r.Errors > 0 || r.Misdirected > 0) {
+		return r, fmt.Errorf("write not fully journaled: %d errors, "+
+			"%d misdirected of %d records", r.Errors, r.Misdirected,
+			r.Records)
+	}
+
 	return r, nil
 }
 
-// WriteRawMetricList writes raw IRONdb data to a node with FlatBuffers.
+// WriteRawMetricList writes raw IRONdb data to a node with FlatBuffers. If the
+// encoded data exceeds the maximum write payload size, it is written in
+// multiple requests and the responses are combined.
 func (sc *SnowthClient) WriteRawMetricList(metricList *noit.MetricListT,
 	builder *flatbuffers.Builder,
 	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
@@ -158,6 +204,20 @@ func (sc *SnowthClient) WriteRawMetricListContext(ctx context.Context,
 		return nil, fmt.Errorf("metric list cannot be empty")
 	}
 
+	metricList, err := sc.applyWritePolicy(canonicalMetricList(metricList))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sc.checkMetricListCardinality(metricList); err != nil {
+		return nil, err
+	}
+
+	if datapoints = uint64(len(metricList.Metrics)); datapoints == 0 {
+		sc.LogDebugf("no metrics remain to write after applying write policy")
+		return &IRONdbPutResponse{}, nil
+	}
+
 	if builder == nil {
 		builder = flatbuffers.NewBuilder(1024)
 	} else {
@@ -166,6 +226,12 @@ func (sc *SnowthClient) WriteRawMetricListContext(ctx context.Context,
 
 	offset := noit.MetricListPack(builder, metricList)
 	builder.FinishWithFileIdentifier(offset, []byte("CIML"))
+	if max := sc.getMaxWritePayload(); max > 0 &&
+		int64(len(builder.FinishedBytes())) > max {
+		return sc.writeRawMetricListChunks(ctx, metricList, builder, max,
+			nodes...)
+	}
+
 	reader := bytes.NewReader(builder.FinishedBytes())
 
 	return sc.WriteRawContext(ctx, reader, true, datapoints, nodes...)
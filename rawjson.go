@@ -0,0 +1,200 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/circonus-labs/circonusllhist"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// Raw metric value types.
+const (
+	RawMetricNumeric   = "n"
+	RawMetricText      = "s"
+	RawMetricHistogram = "h"
+)
+
+// RawMetric values contain a measurement written to the IRONdb raw write
+// endpoint in JSON format. The metric name may contain stream tags, and the
+// Tags value may contain additional stream tags, which are combined with
+// those of the name. The Value must be a numeric value of any integer or
+// floating point type, a string for text metrics, or a histogram. If the
+// Timestamp is zero, the current time of the client clock is used.
+type RawMetric struct {
+	AccountID int32
+	CheckUUID string
+	CheckName string
+	Metric    string
+	Tags      []string
+	Timestamp time.Time
+	Value     interface{}
+}
+
+// rawMetricJSON values are the JSON representation of raw metrics.
+type rawMetricJSON struct {
+	AccountID int32       `json:"account_id"`
+	CheckUUID string      `json:"check_uuid"`
+	CheckName string      `json:"check_name"`
+	Metric    string      `json:"metric_name"`
+	Timestamp int64       `json:"timestamp"`
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value"`
+}
+
+// rawMetricType returns the raw metric value type of a value, or an error if
+// the value cannot be written as a raw metric.
+func rawMetricType(v interface{}) (string, error) {
+	switch tv := v.(type) {
+	case float64, float32, int, int8, int16, int32, int64, uint, uint8,
+		uint16, uint32, uint64:
+		return RawMetricNumeric, nil
+	case string:
+		return RawMetricText, nil
+	case *circonusllhist.Histogram:
+		if tv == nil {
+			return "", fmt.Errorf("invalid raw metric value: nil histogram")
+		}
+
+		return RawMetricHistogram, nil
+	default:
+		return "", fmt.Errorf("invalid raw metric value type: %T", v)
+	}
+}
+
+// encodeRawMetric encodes a raw metric in JSON format, and returns it along
+// with its canonical metric name. If the metric has no timestamp, the
+// specified current time is used. The write policy is applied to floating
+// point values, and if it drops the value, no data is returned.
+func encodeRawMetric(m RawMetric, now time.Time,
+	wp writePolicy) ([]byte, string, error) {
+	t, err := rawMetricType(m.Value)
+	if err != nil {
+		return nil, "", err
+	}
+
+	v := m.Value
+	if !wp.noop() {
+		var ok bool
+		switch fv := v.(type) {
+		case float64:
+			v, ok, err = wp.value(fv)
+		case float32:
+			v, ok, err = wp.value(float64(fv))
+		default:
+			ok = true
+		}
+
+		if err != nil {
+			return nil, "", err
+		}
+
+		if !ok {
+			return nil, "", nil
+		}
+	}
+
+	ts := m.Timestamp
+	if ts.IsZero() {
+		ts = now
+	}
+
+	name := CanonicalMetricName(m.Metric, m.Tags...)
+	b, err := json.Marshal(&rawMetricJSON{
+		AccountID: m.AccountID,
+		CheckUUID: m.CheckUUID,
+		CheckName: m.CheckName,
+		Metric:    name,
+		Timestamp: ts.UnixNano() / int64(time.Millisecond),
+		Type:      t,
+		Value:     v,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode raw metric for write: %w",
+			err)
+	}
+
+	return b, name, nil
+}
+
+// WriteRawMetrics writes measurements to the IRONdb raw write endpoint in
+// JSON format. Metric names are written in canonical form, combining the
+// stream tags in the names with the tags of each measurement. If the encoded
+// data exceeds the maximum write payload size, it is written in multiple
+// requests and the responses are combined. The NaN and infinite value policy
+// and write precision of the client are applied to floating point values.
+func (sc *SnowthClient) WriteRawMetrics(data []RawMetric,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	return sc.WriteRawMetricsContext(context.Background(), data, nodes...)
+}
+
+// WriteRawMetricsContext is the context aware version of WriteRawMetrics.
+func (sc *SnowthClient) WriteRawMetricsContext(ctx context.Context,
+	data []RawMetric, nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("raw metrics cannot be empty")
+	}
+
+	now := sc.getClock().Now()
+	wp := sc.getWritePolicy()
+	names := make([]string, 0, len(data))
+	recs := make([][]byte, 0, len(data))
+	size := 3
+	for i, m := range data {
+		b, name, err := encodeRawMetric(m, now, wp)
+		if err != nil {
+			return nil, fmt.Errorf("raw metric %d: %w", i, err)
+		}
+
+		if b == nil {
+			continue
+		}
+
+		names = append(names, name)
+		recs = append(recs, b)
+		size += len(b) + 1
+	}
+
+	if len(recs) == 0 {
+		return &IRONdbPutResponse{}, nil
+	}
+
+	if err := sc.checkCardinality(names, nil); err != nil {
+		return nil, err
+	}
+
+	max := sc.getMaxWritePayload()
+	if max <= 0 || int64(size) <= max {
+		max = int64(size)
+	}
+
+	chunks, err := splitJSONRecords(recs, max)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(chunks) == 1 {
+		return sc.writeRaw(ctx, chunks[0].buf, snowthapi.ContentTypeJSON,
+			uint64(len(recs)), nodes...)
+	}
+
+	ws := &WriteSummary{}
+	for _, c := range chunks {
+		wc := WriteChunk{First: c.first, Records: c.records, Bytes: c.buf.Len()}
+		var r *IRONdbPutResponse
+		if ctx.Err() != nil {
+			wc.Err = fmt.Errorf("context terminated: %w", ctx.Err())
+		} else {
+			r, wc.Err = sc.writeRaw(ctx, c.buf, snowthapi.ContentTypeJSON,
+				uint64(c.records), nodes...)
+		}
+
+		ws.add(wc, r)
+	}
+
+	return &ws.Response, ws.err()
+}
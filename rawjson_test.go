@@ -0,0 +1,164 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/circonusllhist"
+)
+
+func TestWriteRawMetrics(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	received := []map[string]interface{}{}
+	requests := 0
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected content type: application/json, got: %v",
+					ct)
+			}
+
+			data := []map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				t.Error(err)
+			}
+
+			if n := r.Header.Get("X-Snowth-Datapoints"); n !=
+				strconv.Itoa(len(data)) {
+				t.Errorf("Expected datapoints header: %v, got: %v",
+					len(data), n)
+			}
+
+			mu.Lock()
+			received = append(received, data...)
+			requests++
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"records":` + strconv.Itoa(len(data)) +
+				`,"updated":0,"misdirected":0,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	h := circonusllhist.New()
+	if err := h.RecordValue(1); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := time.Unix(1529509020, 0)
+	data := []RawMetric{{
+		AccountID: 1,
+		CheckUUID: "11223344-5566-7788-9900-aabbccddeeff",
+		CheckName: "test",
+		Metric:    "cpu|ST[b:2]",
+		Tags:      []string{"a:1"},
+		Timestamp: ts,
+		Value:     1.5,
+	}, {
+		AccountID: 1,
+		CheckUUID: "11223344-5566-7788-9900-aabbccddeeff",
+		Metric:    "status",
+		Timestamp: ts,
+		Value:     "ok",
+	}, {
+		AccountID: 1,
+		CheckUUID: "11223344-5566-7788-9900-aabbccddeeff",
+		Metric:    "latency",
+		Timestamp: ts,
+		Value:     h,
+	}}
+
+	res, err := sc.WriteRawMetrics(data, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 3 {
+		t.Errorf("Expected records: 3, got: %v", res.Records)
+	}
+
+	mu.Lock()
+	if len(received) != 3 {
+		t.Fatalf("Expected metrics: 3, got: %v", len(received))
+	}
+
+	if v := received[0]["metric_name"]; v != "cpu|ST[a:1,b:2]" {
+		t.Errorf("Expected metric name: cpu|ST[a:1,b:2], got: %v", v)
+	}
+
+	if v := received[0]["timestamp"]; v != float64(1529509020000) {
+		t.Errorf("Expected timestamp: 1529509020000, got: %v", v)
+	}
+
+	for i, exp := range []string{"n", "s", "h"} {
+		if v := received[i]["type"]; v != exp {
+			t.Errorf("Expected type: %v, got: %v", exp, v)
+		}
+	}
+
+	if v := received[1]["value"]; v != "ok" {
+		t.Errorf("Expected value: ok, got: %v", v)
+	}
+
+	received = received[:0]
+	mu.Unlock()
+	sc.SetMaxWritePayload(200)
+	res, err = sc.WriteRawMetrics(data[:2], node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Records != 2 {
+		t.Errorf("Expected records: 2, got: %v", res.Records)
+	}
+
+	mu.Lock()
+	if requests != 3 {
+		t.Errorf("Expected requests: 3, got: %v", requests)
+	}
+
+	mu.Unlock()
+	_, err = sc.WriteRawMetrics([]RawMetric{{Metric: "bad",
+		Value: []int{1}}}, node)
+	if err == nil {
+		t.Error("Expected error for invalid value type")
+	}
+
+	if _, err = sc.WriteRawMetrics(nil, node); err == nil {
+		t.Error("Expected error for empty metrics")
+	}
+}
@@ -0,0 +1,158 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// defaultMaxRedirects is the default maximum number of redirects followed by a
+// request, which is the same as the net/http default.
+const defaultMaxRedirects = 10
+
+// errRedirectLimit is returned by requests which exceed the maximum number of
+// redirects. These requests are not retried.
+var errRedirectLimit = errors.New("redirect limit exceeded")
+
+// CallInfo values collect troubleshooting details about the HTTP requests made
+// by a client call. A CallInfo value is attached to the context passed to a
+// context aware client method using WithCallInfo.
+type CallInfo struct {
+	mu         sync.Mutex
+	redirects  []string
+	requestIDs []string
+}
+
+// callInfoKey is the context key used to store CallInfo values.
+type callInfoKey struct{}
+
+// WithCallInfo returns a copy of a context which will collect details of the
+// requests made using it into the CallInfo value.
+func WithCallInfo(ctx context.Context, ci *CallInfo) context.Context {
+	return context.WithValue(ctx, callInfoKey{}, ci)
+}
+
+// callInfoFrom returns the CallInfo value attached to a context, if any.
+func callInfoFrom(ctx context.Context) *CallInfo {
+	if ctx == nil {
+		return nil
+	}
+
+	ci, _ := ctx.Value(callInfoKey{}).(*CallInfo)
+	return ci
+}
+
+// Redirects returns the URLs of the redirects received by the requests of the
+// call, in the order they were received, including any redirect which was not
+// followed because redirects are disabled or the redirect limit was reached.
+func (ci *CallInfo) Redirects() []string {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	r := make([]string, len(ci.redirects))
+	copy(r, ci.redirects)
+	return r
+}
+
+// RequestIDs returns the request IDs sent with the requests of the call, in
+// the order the requests were sent.
+func (ci *CallInfo) RequestIDs() []string {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	r := make([]string, len(ci.requestIDs))
+	copy(r, ci.requestIDs)
+	return r
+}
+
+// addRequestID records the request ID of a request.
+func (ci *CallInfo) addRequestID(id string) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.requestIDs = append(ci.requestIDs, id)
+}
+
+// addRedirect records a followed redirect.
+func (ci *CallInfo) addRedirect(u string) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.redirects = append(ci.redirects, u)
+}
+
+// MaxRedirects gets the maximum number of redirects a request will follow.
+// The default value is 10.
+func (c *Config) MaxRedirects() int {
+	c.RLock()
+	defer c.RUnlock()
+	return c.maxRedirects
+}
+
+// SetMaxRedirects sets the maximum number of redirects a request will follow
+// before failing.
+func (c *Config) SetMaxRedirects(n int) error {
+	if n < 0 {
+		return fmt.Errorf("invalid max redirects value")
+	}
+
+	c.Lock()
+	c.maxRedirects = n
+	c.Unlock()
+	return nil
+}
+
+// DisableRedirects gets whether redirect responses are returned as errors
+// rather than followed.
+func (c *Config) DisableRedirects() bool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.disableRedirects
+}
+
+// SetDisableRedirects sets whether redirect responses are returned as errors
+// rather than followed.
+func (c *Config) SetDisableRedirects(disable bool) {
+	c.Lock()
+	c.disableRedirects = disable
+	c.Unlock()
+}
+
+// SetMaxRedirects sets the maximum number of redirects a request will follow
+// before failing.
+func (sc *SnowthClient) SetMaxRedirects(n int) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.maxRedirects = n
+}
+
+// SetDisableRedirects sets whether redirect responses are returned as errors
+// rather than followed.
+func (sc *SnowthClient) SetDisableRedirects(disable bool) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.disableRedirects = disable
+}
+
+// checkRedirect is used by the HTTP client to decide whether to follow a
+// redirect, after recording the redirect in the CallInfo of the request.
+func (sc *SnowthClient) checkRedirect(req *http.Request,
+	via []*http.Request) error {
+	if ci := callInfoFrom(req.Context()); ci != nil {
+		ci.addRedirect(req.URL.String())
+	}
+
+	sc.RLock()
+	disable, max := sc.disableRedirects, sc.maxRedirects
+	sc.RUnlock()
+	if disable {
+		return http.ErrUseLastResponse
+	}
+
+	if len(via) >= max {
+		return fmt.Errorf("%w: stopped after %d redirects", errRedirectLimit,
+			max)
+	}
+
+	sc.LogDebugf("gosnowth following redirect: %s", req.URL.String())
+	return nil
+}
@@ -0,0 +1,119 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestConfigRedirects(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MaxRedirects() != 10 {
+		t.Errorf("Expected max redirects: 10, got: %v", cfg.MaxRedirects())
+	}
+
+	if err := cfg.SetMaxRedirects(-1); err == nil {
+		t.Error("Expected invalid max redirects error")
+	}
+
+	if err := cfg.SetMaxRedirects(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.MaxRedirects() != 2 {
+		t.Errorf("Expected max redirects: 2, got: %v", cfg.MaxRedirects())
+	}
+
+	cfg.SetDisableRedirects(true)
+	if !cfg.DisableRedirects() {
+		t.Error("Expected redirects disabled")
+	}
+}
+
+func TestRedirects(t *testing.T) {
+	t.Parallel()
+
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		switch r.RequestURI {
+		case "/state":
+			_, _ = w.Write([]byte(stateTestData))
+		case "/stats.json":
+			_, _ = w.Write([]byte(statsTestData))
+		case "/once":
+			http.Redirect(w, r, "/target", http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/target":
+			_, _ = w.Write([]byte("ok"))
+		default:
+			t.Errorf("Unexpected request: %v", r)
+		}
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	ci := &CallInfo{}
+	ctx := WithCallInfo(context.Background(), ci)
+	if _, _, err := sc.DoRequestContext(ctx, node, "GET", "/once", nil,
+		nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := ci.Redirects(); len(r) != 1 || !strings.HasSuffix(r[0],
+		"/target") {
+		t.Errorf("Expected redirects: [/target], got: %v", r)
+	}
+
+	sc.SetMaxRedirects(2)
+	ci = &CallInfo{}
+	ctx = WithCallInfo(context.Background(), ci)
+	_, _, err = sc.DoRequestContext(ctx, node, "GET", "/loop", nil, nil)
+	if !errors.Is(err, errRedirectLimit) {
+		t.Errorf("Expected redirect limit error, got: %v", err)
+	}
+
+	if IsRetryable(err) {
+		t.Error("Expected redirect limit error not to be retryable")
+	}
+
+	if r := ci.Redirects(); len(r) != 2 {
+		t.Errorf("Expected redirects: 2, got: %v", r)
+	}
+
+	sc.SetDisableRedirects(true)
+	ci = &CallInfo{}
+	ctx = WithCallInfo(context.Background(), ci)
+	_, _, err = sc.DoRequestContext(ctx, node, "GET", "/once", nil, nil)
+	var se *SnowthError
+	if !errors.As(err, &se) || se.Status != http.StatusFound {
+		t.Errorf("Expected redirect status error, got: %v", err)
+	}
+
+	if r := ci.Redirects(); len(r) != 1 {
+		t.Errorf("Expected redirects: 1, got: %v", r)
+	}
+}
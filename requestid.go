@@ -0,0 +1,76 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// RequestIDHeader is the name of the HTTP header containing the request ID
+// sent with every request, which can be used to correlate client requests
+// with IRONdb node logs.
+const RequestIDHeader = snowthapi.HeaderRequestID
+
+// requestIDKey is the context key used to store request IDs.
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of a context which will cause all requests made
+// using it to be sent with the specified request ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID attached to a context using
+// WithRequestID, or an empty string if there is none.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// SetRequestIDFunc sets the function used to generate the request ID of
+// requests made with a context which does not contain one. By default, a
+// random UUID is generated for each request.
+func (sc *SnowthClient) SetRequestIDFunc(f func(ctx context.Context) string) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.requestID = f
+}
+
+// setRequestID sets the request ID header of a request, if it is not already
+// set, using the ID from the request context or a newly generated ID, and
+// records the ID in the CallInfo of the request.
+func (sc *SnowthClient) setRequestID(r *http.Request) string {
+	id := r.Header.Get(RequestIDHeader)
+	if id == "" {
+		id = RequestIDFromContext(r.Context())
+	}
+
+	if id == "" {
+		sc.RLock()
+		f := sc.requestID
+		sc.RUnlock()
+		if f != nil {
+			id = f(r.Context())
+		} else {
+			id = uuid.New().String()
+		}
+	}
+
+	if id != "" {
+		r.Header.Set(RequestIDHeader, id)
+	}
+
+	if ci := callInfoFrom(r.Context()); ci != nil {
+		ci.addRequestID(id)
+	}
+
+	return id
+}
@@ -0,0 +1,103 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	ids := make(chan string, 10)
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		ids <- r.Header.Get(RequestIDHeader)
+		if r.RequestURI == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte("ok"))
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	if _, _, err := sc.DoRequest(node, "GET", "/test", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uuid.Parse(<-ids); err != nil {
+		t.Errorf("Expected generated request ID: %v", err)
+	}
+
+	ci := &CallInfo{}
+	ctx := WithCallInfo(WithRequestID(context.Background(), "test-id"), ci)
+	if RequestIDFromContext(ctx) != "test-id" {
+		t.Errorf("Expected context request ID: test-id, got: %v",
+			RequestIDFromContext(ctx))
+	}
+
+	_, _, err = sc.DoRequestContext(ctx, node, "GET", "/missing", nil, nil)
+	if id := <-ids; id != "test-id" {
+		t.Errorf("Expected request ID: test-id, got: %v", id)
+	}
+
+	var se *SnowthError
+	if !errors.As(err, &se) || se.RequestID != "test-id" {
+		t.Errorf("Expected error request ID: test-id, got: %v", err)
+	}
+
+	if r := ci.RequestIDs(); len(r) != 1 || r[0] != "test-id" {
+		t.Errorf("Expected call request IDs: [test-id], got: %v", r)
+	}
+
+	sc.SetRequestIDFunc(func(ctx context.Context) string {
+		return "generated"
+	})
+
+	if _, _, err := sc.DoRequest(node, "GET", "/test", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if id := <-ids; id != "generated" {
+		t.Errorf("Expected request ID: generated, got: %v", id)
+	}
+
+	hdrs := http.Header{}
+	hdrs.Set(RequestIDHeader, "header-id")
+	if _, _, err := sc.DoRequest(node, "GET", "/test", nil,
+		hdrs); err != nil {
+		t.Fatal(err)
+	}
+
+	if id := <-ids; id != "header-id" {
+		t.Errorf("Expected request ID: header-id, got: %v", id)
+	}
+}
@@ -0,0 +1,455 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// CacheEntry values contain a value stored in a resolution cache.
+type CacheEntry struct {
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+// CacheStore values store the cached resolutions of metric names to check
+// UUIDs, and of tag queries to the metrics they match. Implementations must be
+// safe for concurrent use.
+type CacheStore interface {
+	// Get returns the entry stored with a key, or nil if there is none.
+	Get(key string) (*CacheEntry, error)
+
+	// Put stores an entry with a key.
+	Put(key string, e *CacheEntry) error
+
+	// Delete removes the entry stored with a key.
+	Delete(key string) error
+
+	// Clear removes all stored entries.
+	Clear() error
+}
+
+// defaultMetricUUIDTTL is the duration for which metric name resolutions are
+// cached in memory when no resolution cache store is set.
+const defaultMetricUUIDTTL = 5 * time.Minute
+
+// minMemoryCachePrune is the minimum number of entries in a memory cache store
+// before expired entries are removed.
+const minMemoryCachePrune = 1024
+
+// fileCacheSaveDelay is the delay after a change to a file cache store before
+// its entries are written to the cache file, so that the changes made during
+// the delay are written together.
+const fileCacheSaveDelay = time.Second
+
+// MemoryCacheStore values are cache stores which keep entries in memory only.
+// Expired entries are removed as the number of stored entries grows.
+type MemoryCacheStore struct {
+	sync.RWMutex
+	entries map[string]*CacheEntry
+	prune   int
+}
+
+// NewMemoryCacheStore creates a new in memory cache store.
+func NewMemoryCacheStore() *MemoryCacheStore {
+	return &MemoryCacheStore{entries: map[string]*CacheEntry{}}
+}
+
+// Get returns the entry stored with a key, or nil if there is none.
+func (ms *MemoryCacheStore) Get(key string) (*CacheEntry, error) {
+	ms.RLock()
+	defer ms.RUnlock()
+	return ms.entries[key], nil
+}
+
+// Put stores an entry with a key.
+func (ms *MemoryCacheStore) Put(key string, e *CacheEntry) error {
+	ms.Lock()
+	defer ms.Unlock()
+	if len(ms.entries) >= ms.prune {
+		ms.removeExpired()
+	}
+
+	ms.entries[key] = e
+	return nil
+}
+
+// removeExpired removes all expired entries. The caller must hold the lock.
+func (ms *MemoryCacheStore) removeExpired() {
+	now := time.Now()
+	for k, v := range ms.entries {
+		if !v.Expires.IsZero() && !now.Before(v.Expires) {
+			delete(ms.entries, k)
+		}
+	}
+
+	ms.prune = 2 * len(ms.entries)
+	if ms.prune < minMemoryCachePrune {
+		ms.prune = minMemoryCachePrune
+	}
+}
+
+// Delete removes the entry stored with a key.
+func (ms *MemoryCacheStore) Delete(key string) error {
+	ms.Lock()
+	defer ms.Unlock()
+	delete(ms.entries, key)
+	return nil
+}
+
+// Clear removes all stored entries.
+func (ms *MemoryCacheStore) Clear() error {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.entries = map[string]*CacheEntry{}
+	return nil
+}
+
+// FileCacheStore values are cache stores which persist entries to a JSON file,
+// so that cached resolutions survive restarts of the application. Changes are
+// written to the file in the background, one second after the first unsaved
+// change, and expired entries are removed before the file is written. Flush
+// writes any unsaved changes immediately, and should be called before the
+// application exits.
+type FileCacheStore struct {
+	MemoryCacheStore
+	path    string
+	pending *time.Timer
+	saveErr error
+}
+
+// NewFileCacheStore creates a new cache store persisted to the file at the
+// specified path, loading any entries previously stored in the file.
+func NewFileCacheStore(path string) (*FileCacheStore, error) {
+	fs := &FileCacheStore{
+		MemoryCacheStore: MemoryCacheStore{entries: map[string]*CacheEntry{}},
+		path:             path,
+	}
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fs, nil
+		}
+
+		return nil, fmt.Errorf("unable to read cache file: %w", err)
+	}
+
+	if err := json.Unmarshal(b, &fs.entries); err != nil {
+		return nil, fmt.Errorf("unable to decode cache file: %w", err)
+	}
+
+	if fs.entries == nil {
+		fs.entries = map[string]*CacheEntry{}
+	}
+
+	return fs, nil
+}
+
+// save removes expired entries and writes the remaining entries to the cache
+// file, replacing it atomically. The caller must hold the lock.
+func (fs *FileCacheStore) save() error {
+	if fs.pending != nil {
+		fs.pending.Stop()
+		fs.pending = nil
+	}
+
+	fs.removeExpired()
+	b, err := json.Marshal(fs.entries)
+	if err != nil {
+		return fmt.Errorf("unable to encode cache file: %w", err)
+	}
+
+	if err := writeFileAtomic(fs.path, b); err != nil {
+		return fmt.Errorf("unable to write cache file: %w", err)
+	}
+
+	return nil
+}
+
+// writeFileAtomic writes data to a file by writing a temporary file in the
+// same directory and renaming it, so that the file is replaced atomically.
+func writeFileAtomic(path string, b []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+
+	return nil
+}
+
+// scheduleSave schedules a background write of the cache file, unless one is
+// already pending, and returns the error of the last failed background write,
+// if any. The caller must hold the lock.
+func (fs *FileCacheStore) scheduleSave() error {
+	if fs.pending == nil {
+		fs.pending = time.AfterFunc(fileCacheSaveDelay, func() {
+			fs.Lock()
+			defer fs.Unlock()
+			if fs.pending == nil {
+				return
+			}
+
+			fs.pending = nil
+			fs.saveErr = fs.save()
+		})
+	}
+
+	err := fs.saveErr
+	fs.saveErr = nil
+	return err
+}
+
+// Put stores an entry with a key and schedules the change to be persisted to
+// the cache file. The error of the last failed background write of the file,
+// if any, is returned.
+func (fs *FileCacheStore) Put(key string, e *CacheEntry) error {
+	fs.Lock()
+	defer fs.Unlock()
+	fs.entries[key] = e
+	return fs.scheduleSave()
+}
+
+// Delete removes the entry stored with a key and schedules the change to be
+// persisted to the cache file. The error of the last failed background write
+// of the file, if any, is returned.
+func (fs *FileCacheStore) Delete(key string) error {
+	fs.Lock()
+	defer fs.Unlock()
+	if _, ok := fs.entries[key]; !ok {
+		return nil
+	}
+
+	delete(fs.entries, key)
+	return fs.scheduleSave()
+}
+
+// Flush writes any unsaved changes to the cache file.
+func (fs *FileCacheStore) Flush() error {
+	fs.Lock()
+	defer fs.Unlock()
+	if fs.pending == nil {
+		err := fs.saveErr
+		fs.saveErr = nil
+		return err
+	}
+
+	fs.saveErr = nil
+	return fs.save()
+}
+
+// Clear removes all stored entries and persists the change to the cache file.
+func (fs *FileCacheStore) Clear() error {
+	fs.Lock()
+	defer fs.Unlock()
+	fs.entries = map[string]*CacheEntry{}
+	return fs.save()
+}
+
+// resolveCache values contain the resolution cache configuration of a client.
+type resolveCache struct {
+	store CacheStore
+	ttl   time.Duration
+}
+
+// SetResolveCache sets the store used to cache resolutions of metric names to
+// check UUIDs, by ResolveMetricUUID, and of tag queries to the metrics they
+// match, by ResolveTagQuery. Cached resolutions expire after the TTL, or never
+// if the TTL is zero. A nil store disables the cache, in which case metric
+// name resolutions are still cached in memory for five minutes, and tag query
+// resolutions are not cached.
+func (sc *SnowthClient) SetResolveCache(store CacheStore, ttl time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	if store == nil {
+		sc.resolveCache = nil
+		return
+	}
+
+	sc.resolveCache = &resolveCache{store: store, ttl: ttl}
+}
+
+// getResolveCache returns the resolution cache of the client, if enabled.
+func (sc *SnowthClient) getResolveCache() *resolveCache {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.resolveCache
+}
+
+// metricUUIDCache returns the cache used for metric name resolutions. This is
+// the resolution cache of the client, if enabled, or its in memory cache.
+func (sc *SnowthClient) metricUUIDCache() *resolveCache {
+	sc.RLock()
+	defer sc.RUnlock()
+	if sc.resolveCache != nil {
+		return sc.resolveCache
+	}
+
+	return sc.uuidCache
+}
+
+// metricUUIDKey returns the cache key of a metric name resolution.
+func metricUUIDKey(accountID int64, name string) string {
+	return "uuid:" + strconv.FormatInt(accountID, 10) + ":" + name
+}
+
+// tagQueryKey returns the cache key of a tag query resolution.
+func tagQueryKey(accountID int64, query string) string {
+	return "query:" + strconv.FormatInt(accountID, 10) + ":" + query
+}
+
+// cacheGet decodes an unexpired cached value from a cache into v, returning
+// whether a value was found. Cache errors are logged and treated as a cache
+// miss.
+func (sc *SnowthClient) cacheGet(rc *resolveCache, key string,
+	v interface{}) bool {
+	if rc == nil {
+		return false
+	}
+
+	e, err := rc.store.Get(key)
+	if err != nil {
+		sc.LogWarnf("unable to read resolution cache: %v", err)
+		return false
+	}
+
+	if e == nil {
+		return false
+	}
+
+	if !e.Expires.IsZero() && !sc.getClock().Now().Before(e.Expires) {
+		return false
+	}
+
+	if err := json.Unmarshal(e.Value, v); err != nil {
+		sc.LogWarnf("unable to decode resolution cache entry: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// cachePut stores a value in a cache. Cache errors are logged.
+func (sc *SnowthClient) cachePut(rc *resolveCache, key string,
+	v interface{}) {
+	if rc == nil {
+		return
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		sc.LogWarnf("unable to encode resolution cache entry: %v", err)
+		return
+	}
+
+	e := &CacheEntry{Value: b}
+	if rc.ttl > 0 {
+		e.Expires = sc.getClock().Now().Add(rc.ttl)
+	}
+
+	if err := rc.store.Put(key, e); err != nil {
+		sc.LogWarnf("unable to write resolution cache: %v", err)
+	}
+}
+
+// InvalidateMetricUUID removes the cached check UUID resolution of a metric
+// name.
+func (sc *SnowthClient) InvalidateMetricUUID(accountID int64,
+	name string) error {
+	if rc := sc.metricUUIDCache(); rc != nil {
+		return rc.store.Delete(metricUUIDKey(accountID, name))
+	}
+
+	return nil
+}
+
+// InvalidateTagQuery removes the cached resolution of a tag query.
+func (sc *SnowthClient) InvalidateTagQuery(accountID int64,
+	query string) error {
+	if rc := sc.getResolveCache(); rc != nil {
+		return rc.store.Delete(tagQueryKey(accountID, query))
+	}
+
+	return nil
+}
+
+// ClearResolveCache removes all cached resolutions.
+func (sc *SnowthClient) ClearResolveCache() error {
+	sc.RLock()
+	uc := sc.uuidCache
+	sc.RUnlock()
+	if uc != nil {
+		if err := uc.store.Clear(); err != nil {
+			return err
+		}
+	}
+
+	if rc := sc.getResolveCache(); rc != nil {
+		return rc.store.Clear()
+	}
+
+	return nil
+}
+
+// ResolveTagQuery finds the metrics matching a tag query, using the resolution
+// cache when it is enabled. Only the identifying fields of the returned items
+// are populated.
+func (sc *SnowthClient) ResolveTagQuery(accountID int64, query string,
+	nodes ...*SnowthNode) ([]FindTagsItem, error) {
+	return sc.ResolveTagQueryContext(context.Background(), accountID, query,
+		nodes...)
+}
+
+// ResolveTagQueryContext is the context aware version of ResolveTagQuery.
+func (sc *SnowthClient) ResolveTagQueryContext(ctx context.Context,
+	accountID int64, query string,
+	nodes ...*SnowthNode) ([]FindTagsItem, error) {
+	key := tagQueryKey(accountID, query)
+	rc := sc.getResolveCache()
+	r := []FindTagsItem{}
+	if sc.cacheGet(rc, key, &r) {
+		return r, nil
+	}
+
+	res, err := sc.FindTagsContext(ctx, accountID, query,
+		&FindTagsOptions{}, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range res.Items {
+		r = append(r, FindTagsItem{
+			UUID:       item.UUID,
+			CheckTags:  item.CheckTags,
+			MetricName: item.MetricName,
+			Type:       item.Type,
+			AccountID:  item.AccountID,
+		})
+	}
+
+	sc.cachePut(rc, key, r)
+	return r, nil
+}
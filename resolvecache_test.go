@@ -0,0 +1,242 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFileCacheStore(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "gosnowth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.json")
+	fs, err := NewFileCacheStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := time.Now().Add(time.Hour).Truncate(time.Second).UTC()
+	if err := fs.Put("a", &CacheEntry{Value: []byte(`"x"`),
+		Expires: exp}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Put("b", &CacheEntry{Value: []byte(`"y"`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Put("c", &CacheEntry{Value: []byte(`"z"`),
+		Expires: time.Unix(1600000000, 0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fs.Delete("b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected no cache file before flush, got: %v", err)
+	}
+
+	if err := fs.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err = NewFileCacheStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := fs.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e == nil || string(e.Value) != `"x"` || !e.Expires.Equal(exp) {
+		t.Errorf("Expected entry: x %v, got: %+v", exp, e)
+	}
+
+	if e, _ := fs.Get("b"); e != nil {
+		t.Errorf("Expected deleted entry, got: %+v", e)
+	}
+
+	if e, _ := fs.Get("c"); e != nil {
+		t.Errorf("Expected expired entry to be removed, got: %+v", e)
+	}
+
+	if err := fs.Put("d", &CacheEntry{Value: []byte(`"w"`)}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(fileCacheSaveDelay + 100*time.Millisecond)
+	if b, err := ioutil.ReadFile(path); err != nil ||
+		!strings.Contains(string(b), `"d"`) {
+		t.Errorf("Expected background write of entry d, got: %s %v", b, err)
+	}
+
+	if err := fs.Clear(); err != nil {
+		t.Fatal(err)
+	}
+
+	fs, err = NewFileCacheStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e, _ := fs.Get("a"); e != nil {
+		t.Errorf("Expected cleared entry, got: %+v", e)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("bad"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFileCacheStore(path); err == nil {
+		t.Error("Expected decode error")
+	}
+}
+
+func TestResolveCache(t *testing.T) {
+	t.Parallel()
+
+	var finds int64
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=") {
+			atomic.AddInt64(&finds, 1)
+			_, _ = w.Write([]byte(byNameTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &SnowthNode{url: u}
+	mc := NewManualClock(time.Unix(1600000000, 0))
+	sc.SetClock(mc)
+	sc.SetResolveCache(NewMemoryCacheStore(), time.Minute)
+	for i := 0; i < 2; i++ {
+		id, err := sc.ResolveMetricUUID(1, "test|ST[b:2,a:1]", node)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != "11223344-5566-7788-9900-aabbccddeeff" {
+			t.Errorf("Expected UUID: 11223344-5566-7788-9900-aabbccddeeff, "+
+				"got: %v", id)
+		}
+	}
+
+	if n := atomic.LoadInt64(&finds); n != 1 {
+		t.Errorf("Expected find requests: 1, got: %v", n)
+	}
+
+	mc.Advance(2 * time.Minute)
+	if _, err := sc.ResolveMetricUUID(1, "test|ST[b:2,a:1]",
+		node); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt64(&finds); n != 2 {
+		t.Errorf("Expected find requests: 2, got: %v", n)
+	}
+
+	if err := sc.InvalidateMetricUUID(1, "test|ST[b:2,a:1]"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sc.ResolveMetricUUID(1, "test|ST[b:2,a:1]",
+		node); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt64(&finds); n != 3 {
+		t.Errorf("Expected find requests: 3, got: %v", n)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := sc.ResolveTagQuery(1, "and(__name:test)", node)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(res) != 2 || res[1].MetricName != "test|ST[a:1,b:2,c:3]" {
+			t.Errorf("Expected metrics: 2, got: %+v", res)
+		}
+	}
+
+	if n := atomic.LoadInt64(&finds); n != 4 {
+		t.Errorf("Expected find requests: 4, got: %v", n)
+	}
+
+	if err := sc.InvalidateTagQuery(1, "and(__name:test)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.ClearResolveCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	sc.SetResolveCache(nil, 0)
+	if _, err := sc.ResolveTagQuery(1, "and(__name:test)", node); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt64(&finds); n != 5 {
+		t.Errorf("Expected find requests: 5, got: %v", n)
+	}
+}
+
+func TestMemoryCacheStorePrune(t *testing.T) {
+	ms := NewMemoryCacheStore()
+	expired := &CacheEntry{Expires: time.Now().Add(-time.Minute)}
+	for i := 0; i < minMemoryCachePrune; i++ {
+		if err := ms.Put(strconv.Itoa(i), expired); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := ms.Put("current", &CacheEntry{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ms.entries) != 1 {
+		t.Errorf("Expected entries: 1, got: %v", len(ms.entries))
+	}
+}
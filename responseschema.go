@@ -0,0 +1,104 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SchemaMode values determine how IRONdb responses which do not match the
+// shape of the library types are handled. IRONdb occasionally adds fields to
+// responses across versions.
+type SchemaMode int
+
+// Modes for handling unexpected response shapes.
+const (
+	// SchemaTolerant ignores unknown response fields. This is the default
+	// mode.
+	SchemaTolerant SchemaMode = iota
+
+	// SchemaStrict fails requests with responses containing unknown fields,
+	// so that response shape changes can be detected early, such as in a
+	// staging environment.
+	SchemaStrict
+)
+
+// String returns a string representation of the mode.
+func (m SchemaMode) String() string {
+	switch m {
+	case SchemaTolerant:
+		return "tolerant"
+	case SchemaStrict:
+		return "strict"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(m))
+	}
+}
+
+// SchemaMode gets the mode used to handle IRONdb responses which contain
+// unknown fields. The default value is SchemaTolerant, which ignores them.
+func (c *Config) SchemaMode() SchemaMode {
+	c.RLock()
+	defer c.RUnlock()
+	return c.schemaMode
+}
+
+// SetSchemaMode sets a new mode for handling unknown response fields.
+func (c *Config) SetSchemaMode(m SchemaMode) error {
+	if m != SchemaTolerant && m != SchemaStrict {
+		return fmt.Errorf("invalid schema mode value")
+	}
+
+	c.Lock()
+	c.schemaMode = m
+	c.Unlock()
+	return nil
+}
+
+// SetSchemaMode sets the mode used to handle IRONdb responses which contain
+// unknown fields.
+func (sc *SnowthClient) SetSchemaMode(m SchemaMode) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.schemaMode = m
+}
+
+// strictSchema returns true if the client fails responses containing unknown
+// fields.
+func (sc *SnowthClient) strictSchema() bool {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.schemaMode == SchemaStrict
+}
+
+// decodeResponse decodes an IRONdb JSON response into v, in a single pass,
+// applying the schema mode of the client.
+func (sc *SnowthClient) decodeResponse(r io.Reader, v interface{}) error {
+	if !sc.strictSchema() {
+		return decodeJSON(r, v)
+	}
+
+	if r == nil {
+		return fmt.Errorf("unable to decode from nil reader")
+	}
+
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return schemaError(dec.Decode(v))
+}
+
+// schemaError wraps an error returned by a strict JSON decoder, identifying
+// errors caused by unknown fields.
+func schemaError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if strings.HasPrefix(err.Error(), "json: unknown field ") {
+		return fmt.Errorf("unexpected IRONdb response shape: %w", err)
+	}
+
+	return fmt.Errorf("failed to decode JSON: %w", err)
+}
@@ -0,0 +1,131 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDecodeResponse(t *testing.T) {
+	t.Parallel()
+	sc := &SnowthClient{}
+	data := `{"count":2,"estimate":true,"extra":{"a":1}}`
+	r := &FindTagsCount{}
+	if err := sc.decodeResponse(bytes.NewBufferString(data), &r); err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Count != 2 || !r.Estimate {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+
+	sc.SetSchemaMode(SchemaStrict)
+	r = &FindTagsCount{}
+	err := sc.decodeResponse(bytes.NewBufferString(data), &r)
+	if err == nil || !strings.Contains(err.Error(), "unexpected IRONdb") {
+		t.Errorf("Expected unknown field error, got: %v", err)
+	}
+
+	r = &FindTagsCount{}
+	err = sc.decodeResponse(bytes.NewBufferString(`{"count":"a"}`), &r)
+	if err == nil || strings.Contains(err.Error(), "unexpected IRONdb") {
+		t.Errorf("Expected decode error, got: %v", err)
+	}
+
+	if err := sc.decodeResponse(nil, &r); err == nil {
+		t.Error("Expected error for nil reader")
+	}
+}
+
+func TestSchemaModeConfig(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=items") {
+			_, _ = w.Write([]byte(`[{"uuid":"` +
+				"3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d" +
+				`","metric_name":"test","new":1}]`))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=test") {
+			_, _ = w.Write([]byte(`{"count":1,"estimate":false,"new":1}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetSchemaMode(SchemaMode(5)); err == nil {
+		t.Error("Expected error for invalid schema mode")
+	}
+
+	if err := cfg.SetSchemaMode(SchemaStrict); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.SchemaMode() != SchemaStrict {
+		t.Errorf("Expected schema mode: strict, got: %v", cfg.SchemaMode())
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	opts := &FindTagsOptions{CountOnly: 1}
+	if _, err := sc.FindTags(1, "test", opts, node); err == nil {
+		t.Error("Expected error for unknown response field")
+	}
+
+	_, err = sc.FindTags(1, "items", nil, node)
+	if err == nil || !strings.Contains(err.Error(), "unexpected IRONdb") {
+		t.Errorf("Expected unknown field error, got: %v", err)
+	}
+
+	sc.SetSchemaMode(SchemaTolerant)
+	items, err := sc.FindTags(1, "items", nil, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items.Items) != 1 || items.Items[0].MetricName != "test" {
+		t.Errorf("Unexpected items: %+v", items.Items)
+	}
+
+	res, err := sc.FindTags(1, "test", opts, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.FindCount == nil || res.FindCount.Count != 1 {
+		t.Errorf("Unexpected count: %+v", res.FindCount)
+	}
+}
@@ -0,0 +1,239 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// defaultRetryBudgetRatio is the default number of retry budget tokens
+// returned to the budget by each successful request.
+const defaultRetryBudgetRatio = 0.1
+
+// ErrRetryBudgetExhausted indicates that a failed request was not retried
+// because the retry budget of the client was exhausted.
+var ErrRetryBudgetExhausted = errors.New("retry budget exhausted")
+
+// retryBudgetError values are returned by requests which were not retried
+// because the retry budget was exhausted. They match ErrRetryBudgetExhausted
+// using errors.Is(), and unwrap to the error of the last attempt.
+type retryBudgetError struct {
+	err error
+}
+
+// Error implements the error interface for retryBudgetError values.
+func (e *retryBudgetError) Error() string {
+	return fmt.Sprintf("%v: %v", ErrRetryBudgetExhausted, e.err)
+}
+
+// Unwrap returns the error of the last attempt of the request.
+func (e *retryBudgetError) Unwrap() error {
+	return e.err
+}
+
+// Is reports whether the error matches ErrRetryBudgetExhausted.
+func (e *retryBudgetError) Is(target error) bool {
+	return target == ErrRetryBudgetExhausted
+}
+
+// RetryBudgetEvent values describe a request which was not retried because the
+// retry budget became exhausted.
+type RetryBudgetEvent struct {
+	// Method is the HTTP method of the request, and Endpoint is its URL
+	// path, without identifiers or the query string.
+	Method   string
+	Endpoint string
+
+	// Node is the address of the node to which the request was last sent,
+	// and Err is the error returned by that attempt.
+	Node string
+	Err  error
+
+	Time time.Time
+}
+
+// retryBudget values implement a token bucket limiting the retries of all
+// requests sent by a client. Every retry takes one token from the bucket, and
+// every successful request returns ratio tokens to it, up to max tokens.
+type retryBudget struct {
+	sync.Mutex
+	ratio     float64
+	max       float64
+	tokens    float64
+	exhausted bool
+}
+
+// newRetryBudget creates a new retry budget which is initially full, or
+// returns nil if max is not positive.
+func newRetryBudget(ratio float64, max int64) *retryBudget {
+	if max <= 0 {
+		return nil
+	}
+
+	return &retryBudget{
+		ratio:  ratio,
+		max:    float64(max),
+		tokens: float64(max),
+	}
+}
+
+// deposit returns tokens to the budget after a successful request.
+func (rb *retryBudget) deposit() {
+	if rb == nil {
+		return
+	}
+
+	rb.Lock()
+	defer rb.Unlock()
+	rb.tokens = math.Min(rb.max, rb.tokens+rb.ratio)
+	if rb.tokens >= 1 {
+		rb.exhausted = false
+	}
+}
+
+// withdraw takes a token from the budget for a retry. It returns whether the
+// retry is allowed, and whether this is the first retry denied since the
+// budget was last available.
+func (rb *retryBudget) withdraw() (bool, bool) {
+	if rb == nil {
+		return true, false
+	}
+
+	rb.Lock()
+	defer rb.Unlock()
+	if rb.tokens >= 1 {
+		rb.tokens--
+		return true, false
+	}
+
+	first := !rb.exhausted
+	rb.exhausted = true
+	return false, first
+}
+
+// RetryBudgetRatio gets the number of retry budget tokens returned to the
+// budget by each successful request.
+func (c *Config) RetryBudgetRatio() float64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.retryBudgetRatio
+}
+
+// SetRetryBudgetRatio sets the number of retry budget tokens returned to the
+// budget by each successful request. The default ratio of 0.1 allows one
+// retry for every ten successful requests, once the budget is empty.
+func (c *Config) SetRetryBudgetRatio(r float64) error {
+	if r < 0 || math.IsNaN(r) || math.IsInf(r, 0) {
+		return fmt.Errorf("invalid retry budget ratio value")
+	}
+
+	c.Lock()
+	c.retryBudgetRatio = r
+	c.Unlock()
+	return nil
+}
+
+// RetryBudgetMax gets the maximum number of tokens in the retry budget.
+func (c *Config) RetryBudgetMax() int64 {
+	c.RLock()
+	defer c.RUnlock()
+	return c.retryBudgetMax
+}
+
+// SetRetryBudgetMax sets the maximum number of tokens in the retry budget,
+// which is shared by all requests sent by the client. Every retry of a failed
+// request, including attempts on other nodes, takes one token, and requests
+// are not retried when the budget is empty, so that the aggregate retry
+// traffic remains bounded while a cluster is degraded. A value of zero, the
+// default, disables the retry budget.
+func (c *Config) SetRetryBudgetMax(n int64) error {
+	if n < 0 {
+		return fmt.Errorf("invalid retry budget max value")
+	}
+
+	c.Lock()
+	c.retryBudgetMax = n
+	c.Unlock()
+	return nil
+}
+
+// SetRetryBudget sets the number of tokens returned to the retry budget by
+// each successful request, and the maximum number of tokens in the budget.
+// The budget is reset to the maximum. A maximum of zero disables the retry
+// budget.
+func (sc *SnowthClient) SetRetryBudget(ratio float64, max int64) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.retryBudget = newRetryBudget(ratio, max)
+}
+
+// OnRetryBudgetExhausted registers a callback function which will be invoked
+// when a failed request is not retried because the retry budget is exhausted.
+// The function is invoked only for the first such request until the budget
+// is replenished by successful requests.
+func (sc *SnowthClient) OnRetryBudgetExhausted(f func(e *RetryBudgetEvent)) {
+	if f == nil {
+		return
+	}
+
+	if sc.parent != nil {
+		sc.parent.OnRetryBudgetExhausted(f)
+		return
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+	sc.retryBudgetFuncs = append(sc.retryBudgetFuncs, f)
+}
+
+// allowRetry takes a token from the retry budget for a retry of a request,
+// returning whether the retry is allowed. The retry budget callbacks are
+// notified the first time a retry is denied.
+func (sc *SnowthClient) allowRetry(method, endpoint string, node *SnowthNode,
+	err error) bool {
+	sc.RLock()
+	rb := sc.retryBudget
+	sc.RUnlock()
+	ok, first := rb.withdraw()
+	if ok || !first {
+		return ok
+	}
+
+	host := ""
+	if node != nil && node.GetURL() != nil {
+		host = node.GetURL().Host
+	}
+
+	sc.LogWarnw("gosnowth retry budget exhausted, not retrying request",
+		"method", method, "endpoint", endpointName(endpoint), "node", host,
+		"error", err)
+	root := sc.root()
+	root.RLock()
+	fns := make([]func(e *RetryBudgetEvent), len(root.retryBudgetFuncs))
+	copy(fns, root.retryBudgetFuncs)
+	root.RUnlock()
+	e := &RetryBudgetEvent{
+		Method:   method,
+		Endpoint: endpointName(endpoint),
+		Node:     host,
+		Err:      err,
+		Time:     sc.getClock().Now(),
+	}
+
+	for _, f := range fns {
+		f(e)
+	}
+
+	return false
+}
+
+// depositRetry returns tokens to the retry budget after a successful request.
+func (sc *SnowthClient) depositRetry() {
+	sc.RLock()
+	rb := sc.retryBudget
+	sc.RUnlock()
+	rb.deposit()
+}
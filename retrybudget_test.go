@@ -0,0 +1,176 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+	rb := newRetryBudget(0.5, 2)
+	for i := 0; i < 2; i++ {
+		if ok, _ := rb.withdraw(); !ok {
+			t.Errorf("Expected retry %d to be allowed", i)
+		}
+	}
+
+	if ok, first := rb.withdraw(); ok || !first {
+		t.Errorf("Expected first denied retry, got: %v, %v", ok, first)
+	}
+
+	if ok, first := rb.withdraw(); ok || first {
+		t.Errorf("Expected denied retry, got: %v, %v", ok, first)
+	}
+
+	rb.deposit()
+	if ok, _ := rb.withdraw(); ok {
+		t.Error("Expected retry to be denied")
+	}
+
+	rb.deposit()
+	if ok, _ := rb.withdraw(); !ok {
+		t.Error("Expected retry to be allowed")
+	}
+
+	for i := 0; i < 10; i++ {
+		rb.deposit()
+	}
+
+	if rb.tokens != 2 {
+		t.Errorf("Expected tokens: 2, got: %v", rb.tokens)
+	}
+
+	if newRetryBudget(1, 0) != nil {
+		t.Error("Expected disabled retry budget")
+	}
+
+	var nilBudget *retryBudget
+	if ok, _ := nilBudget.withdraw(); !ok {
+		t.Error("Expected retry to be allowed without a budget")
+	}
+}
+
+func TestConfigRetryBudget(t *testing.T) {
+	t.Parallel()
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.RetryBudgetRatio() != defaultRetryBudgetRatio {
+		t.Errorf("Expected ratio: %v, got: %v", defaultRetryBudgetRatio,
+			cfg.RetryBudgetRatio())
+	}
+
+	if err := cfg.SetRetryBudgetRatio(-1); err == nil {
+		t.Error("Expected error for invalid ratio")
+	}
+
+	if err := cfg.SetRetryBudgetMax(-1); err == nil {
+		t.Error("Expected error for invalid max")
+	}
+
+	if err := cfg.SetRetryBudgetMax(20); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.RetryBudgetMax() != 20 {
+		t.Errorf("Expected max: 20, got: %v", cfg.RetryBudgetMax())
+	}
+}
+
+func TestRetryBudgetRequests(t *testing.T) {
+	t.Parallel()
+	var fails int64
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/fail" {
+			atomic.AddInt64(&fails, 1)
+			w.WriteHeader(500)
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(3)
+	sc.SetConnectRetries(0)
+	sc.SetRetryBudget(0.5, 2)
+	events := []*RetryBudgetEvent{}
+	sc.OnRetryBudgetExhausted(func(e *RetryBudgetEvent) {
+		events = append(events, e)
+	})
+
+	node := sc.GetActiveNode()
+	_, _, err = sc.DoRequest(node, "GET", "/fail?query=secret", nil, nil)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("Expected error: %v, got: %v", ErrRetryBudgetExhausted, err)
+	}
+
+	var se *SnowthError
+	if !errors.As(err, &se) || se.Status != 500 {
+		t.Errorf("Expected IRONdb error status: 500, got: %v", err)
+	}
+
+	if n := atomic.LoadInt64(&fails); n != 3 {
+		t.Errorf("Expected requests: 3, got: %v", n)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("Expected events: 1, got: %v", len(events))
+	}
+
+	if events[0].Endpoint != "/fail" || events[0].Node != node.GetURL().Host {
+		t.Errorf("Expected event for /fail, got: %+v", events[0])
+	}
+
+	_, _, err = sc.DoRequest(node, "GET", "/fail", nil, nil)
+	if !errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Fatalf("Expected error: %v, got: %v", ErrRetryBudgetExhausted, err)
+	}
+
+	if n := atomic.LoadInt64(&fails); n != 4 {
+		t.Errorf("Expected requests: 4, got: %v", n)
+	}
+
+	if len(events) != 1 {
+		t.Errorf("Expected events: 1, got: %v", len(events))
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := sc.GetStats(node); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sc.SetRetries(1)
+	_, _, err = sc.DoRequest(node, "GET", "/fail", nil, nil)
+	if errors.Is(err, ErrRetryBudgetExhausted) {
+		t.Errorf("Expected retry to be allowed, got: %v", err)
+	}
+
+	if n := atomic.LoadInt64(&fails); n != 6 {
+		t.Errorf("Expected requests: 6, got: %v", n)
+	}
+}
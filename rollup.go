@@ -9,6 +9,8 @@ import (
 	"path"
 	"strconv"
 	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // RollupValue values are individual data points of a rollup.
@@ -183,7 +185,8 @@ func (rv *RollupAllValue) Timestamp() string {
 	return formatTimestamp(rv.Time)
 }
 
-// ReadRollupValues reads rollup data from a node.
+// ReadRollupValues reads rollup data from a node. The metric name is read in
+// its canonical form, as written by all write operations.
 func (sc *SnowthClient) ReadRollupValues(uuid, metric string, period time.Duration,
 	start, end time.Time, dataType string, nodes ...*SnowthNode) ([]RollupValue, error) {
 	return sc.ReadRollupValuesContext(context.Background(), uuid, metric,
@@ -194,11 +197,17 @@ func (sc *SnowthClient) ReadRollupValues(uuid, metric string, period time.Durati
 func (sc *SnowthClient) ReadRollupValuesContext(ctx context.Context,
 	uuid, metric string, period time.Duration, start, end time.Time,
 	dataType string, nodes ...*SnowthNode) ([]RollupValue, error) {
+	mn, err := ParseMetricName(metric)
+	if err != nil {
+		return nil, err
+	}
+
+	metric = mn.String()
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(uuid, metric))
+		node = sc.readNode(ctx, uuid, metric)
 	}
 
 	if dataType == "" {
@@ -217,15 +226,16 @@ func (sc *SnowthClient) ReadRollupValuesContext(ctx context.Context,
 	endTS := end.Unix() - end.Unix()%int64(period/time.Second) +
 		int64(period/time.Second)
 	r := []RollupValue{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+	body, _, err := sc.doRequest(ctx, node, sc.metricOwnerIDs(uuid, metric),
+		"GET",
 		fmt.Sprintf("%s?start_ts=%d&end_ts=%d&rollup_span=%ds&type=%s",
-			path.Join("/rollup", uuid, url.QueryEscape(metric)),
+			path.Join(snowthapi.PathRollup, uuid, url.QueryEscape(metric)),
 			startTS, endTS, int64(period/time.Second), dataType), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -248,22 +258,23 @@ func (sc *SnowthClient) ReadRollupAllValuesContext(ctx context.Context,
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(uuid, metric))
+		node = sc.readNode(ctx, uuid, metric)
 	}
 
 	startTS := start.Unix() - start.Unix()%int64(period/time.Second)
 	endTS := end.Unix() - end.Unix()%int64(period/time.Second) +
 		int64(period/time.Second)
 	r := []RollupAllValue{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+	body, _, err := sc.doRequest(ctx, node, sc.metricOwnerIDs(uuid, metric),
+		"GET",
 		fmt.Sprintf("%s?start_ts=%d&end_ts=%d&rollup_span=%ds&type=all",
-			path.Join("/rollup", uuid, url.QueryEscape(metric)),
+			path.Join(snowthapi.PathRollup, uuid, url.QueryEscape(metric)),
 			startTS, endTS, int64(period/time.Second)), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
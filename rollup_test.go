@@ -177,6 +177,16 @@ func TestReadRollupValues(t *testing.T) {
 			_, _ = w.Write([]byte(rollupTestData))
 			return
 		}
+
+		if r.URL.Query().Get("type") == "count" {
+			if p := r.URL.EscapedPath(); p != "/rollup/fc85e0ab-f568-45e6-"+
+				"86ee-d7443be8277d/online%7CST%5Ba%3A1%2Cb%3A2%5D" {
+				t.Errorf("Unexpected canonical rollup path: %v", p)
+			}
+
+			_, _ = w.Write([]byte(rollupTestData))
+			return
+		}
 	}))
 
 	defer ms.Close()
@@ -191,6 +201,18 @@ func TestReadRollupValues(t *testing.T) {
 	}
 
 	node := &SnowthNode{url: u}
+	if _, err := sc.ReadRollupValues("fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		"online|ST[b:2,a:1]", time.Second, time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), "count", node); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := sc.ReadRollupValues("fc85e0ab-f568-45e6-86ee-d7443be8277d",
+		"online|ST[a:1", time.Second, time.Unix(1529509020, 0),
+		time.Unix(1529509200, 0), "count", node); err == nil {
+		t.Error("Expected error for invalid metric name")
+	}
+
 	res, err := sc.ReadRollupValues(
 		"fc85e0ab-f568-45e6-86ee-d7443be8277d", "online", time.Second,
 		time.Unix(1529509020, 0), time.Unix(1529509200, 0), "average", node)
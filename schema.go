@@ -0,0 +1,320 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/circonusllhist"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect of the schemas produced by
+// JSONSchema() and WireJSONSchema().
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// wireTypes contains the library types which are sent to, or received from,
+// IRONdb in JSON format, and which are described by WireJSONSchema().
+var wireTypes = []interface{}{
+	FindTagsItem{},
+	FindTagsCount{},
+	NumericWrite{},
+	NNTData{},
+	TextData{},
+	HistogramData{},
+	HistogramValue{},
+	FetchQuery{},
+	DF4Response{},
+}
+
+// jsonSchemaer values are implemented by types which have a custom JSON
+// encoding, and so must describe their own schema.
+type jsonSchemaer interface {
+	jsonSchema(g *schemaGenerator) map[string]interface{}
+}
+
+var (
+	jsonSchemaerType = reflect.TypeOf((*jsonSchemaer)(nil)).Elem()
+	rawMessageType   = reflect.TypeOf(json.RawMessage{})
+	timeType         = reflect.TypeOf(time.Time{})
+	histogramType    = reflect.TypeOf(circonusllhist.Histogram{})
+)
+
+// schemaGenerator values build JSON schemas from Go types, collecting the
+// schemas of named struct types as definitions.
+type schemaGenerator struct {
+	defs map[string]interface{}
+}
+
+// schemaRef returns a reference to the definition of a named type.
+func schemaRef(name string) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/definitions/" + name}
+}
+
+// schema returns the schema of a Go type.
+func (g *schemaGenerator) schema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Name() != "" && (t.Kind() == reflect.Struct ||
+		reflect.PtrTo(t).Implements(jsonSchemaerType)) &&
+		t != timeType && t != histogramType {
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Reserve the definition first, so recursive types terminate.
+			g.defs[t.Name()] = nil
+			g.defs[t.Name()] = g.define(t)
+		}
+
+		return schemaRef(t.Name())
+	}
+
+	return g.define(t)
+}
+
+// define returns the schema of a Go type without using references.
+func (g *schemaGenerator) define(t reflect.Type) map[string]interface{} {
+	if reflect.PtrTo(t).Implements(jsonSchemaerType) {
+		return reflect.New(t).Interface().(jsonSchemaer).jsonSchema(g)
+	}
+
+	switch {
+	case t == rawMessageType:
+		return map[string]interface{}{}
+	case t == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case t == histogramType:
+		return map[string]interface{}{
+			"type":            "string",
+			"contentEncoding": "base64",
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr:
+		return map[string]interface{}{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{
+				"type":            "string",
+				"contentEncoding": "base64",
+			}
+		}
+
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.schema(t.Elem()),
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": g.schema(t.Elem()),
+		}
+	case reflect.Struct:
+		return g.object(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// object returns the schema of a struct type, using the same field names and
+// rules as the encoding/json package.
+func (g *schemaGenerator) object(t reflect.Type) map[string]interface{} {
+	props := map[string]interface{}{}
+	required := []string{}
+	g.fields(t, props, &required)
+	s := map[string]interface{}{
+		"type":       "object",
+		"properties": props,
+	}
+
+	if len(required) > 0 {
+		s["required"] = required
+	}
+
+	return s
+}
+
+// fields adds the schemas of the encoded fields of a struct type to a set of
+// properties, including the fields of embedded structs.
+func (g *schemaGenerator) fields(t reflect.Type, props map[string]interface{},
+	required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		opts := strings.Split(tag, ",")
+		name := opts[0]
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			for ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				g.fields(ft, props, required)
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		omit, quoted := false, false
+		for _, o := range opts[1:] {
+			switch o {
+			case "omitempty":
+				omit = true
+			case "string":
+				quoted = true
+			}
+		}
+
+		if quoted {
+			props[name] = map[string]interface{}{"type": "string"}
+		} else {
+			props[name] = g.schema(ft)
+		}
+
+		if !omit {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// schemaTuple returns the schema of a JSON array containing a fixed sequence
+// of values.
+func schemaTuple(items ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type":     "array",
+		"items":    items,
+		"minItems": len(items),
+		"maxItems": len(items),
+	}
+}
+
+// schemaNullable returns a schema which also accepts a null value.
+func schemaNullable(s map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"anyOf": []interface{}{s, map[string]interface{}{"type": "null"}},
+	}
+}
+
+// jsonSchema describes the custom JSON encoding of FindTagsLatestNumeric
+// values.
+func (ftl *FindTagsLatestNumeric) jsonSchema(
+	g *schemaGenerator) map[string]interface{} {
+	return schemaTuple(g.schema(reflect.TypeOf(int64(0))),
+		schemaNullable(g.schema(reflect.TypeOf(float64(0)))))
+}
+
+// jsonSchema describes the custom JSON encoding of FindTagsLatestText values.
+func (ftl *FindTagsLatestText) jsonSchema(
+	g *schemaGenerator) map[string]interface{} {
+	return schemaTuple(g.schema(reflect.TypeOf(int64(0))),
+		schemaNullable(g.schema(reflect.TypeOf(""))))
+}
+
+// jsonSchema describes the custom JSON encoding of FindTagsLatestHistogram
+// values.
+func (ftl *FindTagsLatestHistogram) jsonSchema(
+	g *schemaGenerator) map[string]interface{} {
+	return schemaTuple(g.schema(reflect.TypeOf(int64(0))),
+		schemaNullable(g.schema(reflect.TypeOf(""))))
+}
+
+// jsonSchema describes the custom JSON encoding of NumericParts values.
+func (p *NumericParts) jsonSchema(g *schemaGenerator) map[string]interface{} {
+	return schemaTuple(g.schema(reflect.TypeOf(int64(0))),
+		g.schema(reflect.TypeOf([]NumericPartsData{})))
+}
+
+// jsonSchema describes the custom JSON encoding of Parts values.
+func (p *Parts) jsonSchema(g *schemaGenerator) map[string]interface{} {
+	return schemaTuple(g.schema(reflect.TypeOf(int64(0))),
+		g.schema(reflect.TypeOf([]NNTPartsData{})))
+}
+
+// jsonSchema describes the custom JSON encoding of HistogramValue values.
+func (hv *HistogramValue) jsonSchema(
+	g *schemaGenerator) map[string]interface{} {
+	return schemaTuple(g.schema(reflect.TypeOf(float64(0))),
+		g.schema(reflect.TypeOf(float64(0))),
+		g.schema(reflect.TypeOf(map[string]int64{})))
+}
+
+// jsonSchema describes the custom JSON encoding of FetchQuery values.
+func (fq *FetchQuery) jsonSchema(g *schemaGenerator) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"start":   g.schema(reflect.TypeOf(float64(0))),
+			"period":  g.schema(reflect.TypeOf(float64(0))),
+			"count":   g.schema(reflect.TypeOf(int64(0))),
+			"streams": g.schema(reflect.TypeOf([]FetchStream{})),
+			"reduce":  g.schema(reflect.TypeOf([]FetchReduce{})),
+		},
+		"required": []string{"start", "period", "count", "streams", "reduce"},
+	}
+}
+
+// JSONSchema returns a JSON Schema document describing the JSON encoding of
+// the type of a value, such as a FindTagsItem or NumericWrite value. The
+// schemas of named struct types are placed in the definitions of the document,
+// so that non-Go services can validate data against the same shapes used by
+// this library.
+func JSONSchema(v interface{}) ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("unable to create schema for nil value")
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := &schemaGenerator{defs: map[string]interface{}{}}
+	s := g.define(t)
+	s["$schema"] = JSONSchemaDraft
+	if len(g.defs) > 0 {
+		s["definitions"] = g.defs
+	}
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// WireJSONSchema returns a JSON Schema document containing definitions for all
+// library types which are sent to, or received from, IRONdb in JSON format.
+// These include find tags results, numeric, NNT, text and histogram write
+// payloads, fetch queries and DF4 fetch results.
+func WireJSONSchema() ([]byte, error) {
+	g := &schemaGenerator{defs: map[string]interface{}{}}
+	for _, v := range wireTypes {
+		g.schema(reflect.TypeOf(v))
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"$schema":     JSONSchemaDraft,
+		"definitions": g.defs,
+	}, "", "  ")
+}
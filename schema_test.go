@@ -0,0 +1,87 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+	if _, err := JSONSchema(nil); err == nil {
+		t.Error("Expected error for nil value")
+	}
+
+	b, err := JSONSchema(&FindTagsItem{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := map[string]interface{}{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s["$schema"] != JSONSchemaDraft {
+		t.Errorf("Expected schema: %v, got: %v", JSONSchemaDraft, s["$schema"])
+	}
+
+	props, _ := s["properties"].(map[string]interface{})
+	exp := map[string]interface{}{"type": "string"}
+	if !reflect.DeepEqual(props["uuid"], exp) {
+		t.Errorf("Expected uuid schema: %v, got: %v", exp, props["uuid"])
+	}
+
+	exp = map[string]interface{}{"$ref": "#/definitions/FindTagsLatest"}
+	if !reflect.DeepEqual(props["latest"], exp) {
+		t.Errorf("Expected latest schema: %v, got: %v", exp, props["latest"])
+	}
+
+	req, _ := s["required"].([]interface{})
+	for _, r := range req {
+		if r == "activity" || r == "latest" {
+			t.Errorf("Expected optional property: %v", r)
+		}
+	}
+
+	defs, _ := s["definitions"].(map[string]interface{})
+	num, _ := defs["FindTagsLatestNumeric"].(map[string]interface{})
+	if num["type"] != "array" || num["minItems"] != 2.0 {
+		t.Errorf("Expected latest numeric tuple schema, got: %v", num)
+	}
+}
+
+func TestWireJSONSchema(t *testing.T) {
+	t.Parallel()
+	b, err := WireJSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := map[string]interface{}{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	defs, _ := s["definitions"].(map[string]interface{})
+	for _, name := range []string{"FindTagsItem", "NumericWrite", "NumericParts",
+		"NNTData", "TextData", "HistogramData", "FetchQuery", "FetchStream",
+		"DF4Response", "DF4Head"} {
+		if _, ok := defs[name].(map[string]interface{}); !ok {
+			t.Errorf("Expected definition: %v", name)
+		}
+	}
+
+	hd, _ := defs["HistogramData"].(map[string]interface{})
+	props, _ := hd["properties"].(map[string]interface{})
+	exp := map[string]interface{}{
+		"type":            "string",
+		"contentEncoding": "base64",
+	}
+
+	if !reflect.DeepEqual(props["histogram"], exp) {
+		t.Errorf("Expected histogram schema: %v, got: %v", exp,
+			props["histogram"])
+	}
+}
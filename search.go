@@ -0,0 +1,59 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// SearchOptions values contain optional parameters common to IRONdb search
+// requests.
+type SearchOptions struct {
+	// Limit is the advisory limit of the number of results returned. The
+	// default is the limit of the node.
+	Limit int64
+}
+
+// SearchResultInfo values describe the completeness of the results of an
+// IRONdb search request.
+type SearchResultInfo struct {
+	// Limit is the advisory limit sent with the request, or zero if none was.
+	Limit int64
+
+	// Returned is the number of results returned.
+	Returned int64
+
+	// Count is the total number of matching results reported by IRONdb, or
+	// the number of results returned if IRONdb did not report a count.
+	Count int64
+
+	// Truncated is set when IRONdb reports more matching results than were
+	// returned.
+	Truncated bool
+}
+
+// searchHeaders returns the headers of a search request with an advisory
+// limit.
+func searchHeaders(limit int64) http.Header {
+	hdrs := http.Header{}
+	if limit != 0 {
+		hdrs.Set(snowthapi.HeaderAdvisoryLimit, strconv.FormatInt(limit, 10))
+	}
+
+	return hdrs
+}
+
+// newSearchResultInfo returns the result information of a search request, from
+// the response headers and the number of results returned.
+func newSearchResultInfo(header http.Header, limit,
+	n int64) *SearchResultInfo {
+	c := findTagsCount(header, n)
+	return &SearchResultInfo{
+		Limit:     limit,
+		Returned:  n,
+		Count:     c,
+		Truncated: c > n,
+	}
+}
@@ -0,0 +1,31 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestSearchResultInfo(t *testing.T) {
+	t.Parallel()
+	hdrs := searchHeaders(10)
+	if l := hdrs.Get("X-Snowth-Advisory-Limit"); l != "10" {
+		t.Errorf("Expected advisory limit: 10, got: %v", l)
+	}
+
+	if hdrs := searchHeaders(0); len(hdrs) != 0 {
+		t.Errorf("Expected no headers, got: %v", hdrs)
+	}
+
+	info := newSearchResultInfo(nil, 0, 2)
+	if info.Count != 2 || info.Returned != 2 || info.Truncated {
+		t.Errorf("Unexpected result info: %+v", info)
+	}
+
+	header := http.Header{}
+	header.Set("X-Snowth-Search-Result-Count", "20")
+	info = newSearchResultInfo(header, 10, 10)
+	if info.Count != 20 || info.Limit != 10 || !info.Truncated {
+		t.Errorf("Unexpected result info: %+v", info)
+	}
+}
@@ -0,0 +1,112 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+)
+
+// NodeSelection values control which of the nodes owning a metric are used to
+// read its data.
+type NodeSelection int
+
+// Node selection modes.
+const (
+	// SelectPrimaryOwner reads metric data from the first active node owning
+	// the metric in the topology. This is the default mode.
+	SelectPrimaryOwner NodeSelection = iota
+
+	// SelectRendezvous reads metric data from an active owner chosen by
+	// rendezvous hashing of the metric over its owners. Reads of the same
+	// metric are consistently sent to the same replica, improving node cache
+	// hit rates, while reads of different metrics are spread evenly across
+	// replicas. When the chosen node is inactive, only the metrics assigned
+	// to it move to another owner.
+	SelectRendezvous
+)
+
+// String returns the name of a node selection mode.
+func (ns NodeSelection) String() string {
+	switch ns {
+	case SelectPrimaryOwner:
+		return "primary"
+	case SelectRendezvous:
+		return "rendezvous"
+	default:
+		return fmt.Sprintf("NodeSelection(%d)", int(ns))
+	}
+}
+
+// NodeSelection gets the mode used to select which node owning a metric is
+// used to read its data. The default mode is SelectPrimaryOwner.
+func (c *Config) NodeSelection() NodeSelection {
+	c.RLock()
+	defer c.RUnlock()
+	return c.nodeSelection
+}
+
+// SetNodeSelection sets the mode used to select which node owning a metric is
+// used to read its data.
+func (c *Config) SetNodeSelection(ns NodeSelection) error {
+	if ns != SelectPrimaryOwner && ns != SelectRendezvous {
+		return fmt.Errorf("invalid node selection value")
+	}
+
+	c.Lock()
+	c.nodeSelection = ns
+	c.Unlock()
+	return nil
+}
+
+// SetNodeSelection sets the mode used to select which node owning a metric is
+// used to read its data.
+func (sc *SnowthClient) SetNodeSelection(ns NodeSelection) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.nodeSelection = ns
+}
+
+// rendezvousScore returns the rendezvous hashing score of a node for a metric.
+func rendezvousScore(id, uuid, metric string) uint64 {
+	f := fnv.New64a()
+	_, _ = f.Write([]byte(id))
+	_, _ = f.Write([]byte{0})
+	_, _ = f.Write([]byte(uuid))
+	_, _ = f.Write([]byte{0})
+	_, _ = f.Write([]byte(metric))
+	x := f.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	return x
+}
+
+// orderOwners orders the identifiers of the nodes owning a metric by the
+// preference of the node selection mode of the client.
+func (sc *SnowthClient) orderOwners(uuid, metric string,
+	ids []string) []string {
+	sc.RLock()
+	ns := sc.nodeSelection
+	sc.RUnlock()
+	if ns != SelectRendezvous || len(ids) < 2 {
+		return ids
+	}
+
+	r := make([]string, len(ids))
+	copy(r, ids)
+	sort.SliceStable(r, func(i, j int) bool {
+		return rendezvousScore(r[i], uuid, metric) >
+			rendezvousScore(r[j], uuid, metric)
+	})
+
+	return r
+}
+
+// readNode returns the active node used to read data for a metric.
+func (sc *SnowthClient) readNode(ctx context.Context,
+	uuid, metric string) *SnowthNode {
+	return sc.GetActiveNode(sc.orderOwners(uuid, metric,
+		sc.FindMetricNodeIDsContext(ctx, uuid, metric)))
+}
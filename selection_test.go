@@ -0,0 +1,74 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestConfigNodeSelection(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.NodeSelection() != SelectPrimaryOwner {
+		t.Errorf("Expected node selection: primary, got: %v",
+			cfg.NodeSelection())
+	}
+
+	if err := cfg.SetNodeSelection(NodeSelection(5)); err == nil {
+		t.Error("Expected invalid node selection error")
+	}
+
+	if err := cfg.SetNodeSelection(SelectRendezvous); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.NodeSelection().String() != "rendezvous" {
+		t.Errorf("Expected node selection: rendezvous, got: %v",
+			cfg.NodeSelection())
+	}
+}
+
+func TestOrderOwners(t *testing.T) {
+	t.Parallel()
+
+	ids := []string{"a", "b", "c"}
+	sc := &SnowthClient{}
+	if r := sc.orderOwners("uuid", "metric", ids); !reflect.DeepEqual(r, ids) {
+		t.Errorf("Expected owners: %v, got: %v", ids, r)
+	}
+
+	sc.SetNodeSelection(SelectRendezvous)
+	first := map[string]int{}
+	for i := 0; i < 300; i++ {
+		m := fmt.Sprintf("metric%d", i)
+		r := sc.orderOwners("uuid", m, ids)
+		if !reflect.DeepEqual(r, sc.orderOwners("uuid", m, ids)) {
+			t.Fatalf("Expected consistent order for: %v", m)
+		}
+
+		s := append([]string{}, r...)
+		sort.Strings(s)
+		if !reflect.DeepEqual(s, ids) {
+			t.Fatalf("Expected owners: %v, got: %v", ids, r)
+		}
+
+		first[r[0]]++
+	}
+
+	for _, id := range ids {
+		if first[id] < 50 {
+			t.Errorf("Expected reads spread across owners, got: %v", first)
+		}
+	}
+
+	if ids[0] != "a" {
+		t.Error("Expected owner list not to be modified")
+	}
+}
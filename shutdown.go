@@ -0,0 +1,107 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrClosed is returned by requests made using a client which has been closed.
+var ErrClosed = errors.New("client closed")
+
+// Close shuts down the client, waiting without limit for outstanding requests
+// and asynchronous writes to complete. See Shutdown.
+func (sc *SnowthClient) Close() error {
+	return sc.Shutdown(context.Background())
+}
+
+// Shutdown shuts down the client. The node health watchdog, node discovery and
+// WatchAndUpdate processes are stopped, topology watch channels are closed,
+// and Shutdown waits for outstanding asynchronous writes and in-flight
+// requests to complete, or for the context to be cancelled, before closing
+// idle connections. Requests made after Shutdown is called fail with
+// ErrClosed. Calling Shutdown more than once does nothing.
+func (sc *SnowthClient) Shutdown(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sc.Lock()
+	if sc.closing {
+		sc.Unlock()
+		return nil
+	}
+
+	sc.closing = true
+	if sc.done != nil {
+		close(sc.done)
+	}
+
+	tws := sc.topologyWatchers
+	sc.topologyWatchers = nil
+	sc.Unlock()
+	sc.StopWatchdog()
+	sc.StopDiscovery()
+	for _, tw := range tws {
+		tw.close()
+	}
+
+	// Asynchronous writes are allowed to send their requests before new
+	// requests are rejected.
+	err := sc.WaitAsyncWrites(ctx)
+	sc.Lock()
+	sc.closed = true
+	sc.Unlock()
+	if err == nil {
+		done := make(chan struct{})
+		go func() {
+			sc.inflight.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-ctx.Done():
+			err = fmt.Errorf("context terminated: %w", ctx.Err())
+		case <-done:
+		}
+	}
+
+	sc.persistState()
+	sc.RLock()
+	cli, parent := sc.c, sc.parent
+	sc.RUnlock()
+	if parent != nil {
+		// The transport is shared with the original client.
+		return err
+	}
+
+	if ic, ok := cli.(interface{ CloseIdleConnections() }); ok {
+		ic.CloseIdleConnections()
+	}
+
+	return err
+}
+
+// startRequest registers an in-flight request, returning ErrClosed if the
+// client has been closed. The done function must be called when the request
+// completes.
+func (sc *SnowthClient) startRequest() (func(), error) {
+	sc.RLock()
+	defer sc.RUnlock()
+	if sc.closed {
+		return nil, ErrClosed
+	}
+
+	if sc.parent != nil {
+		sc.parent.RLock()
+		closed := sc.parent.closed
+		sc.parent.RUnlock()
+		if closed {
+			return nil, ErrClosed
+		}
+	}
+
+	sc.inflight.Add(1)
+	return sc.inflight.Done, nil
+}
@@ -0,0 +1,138 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestClose(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetWatchdogInterval(time.Hour)
+	sc.StartWatchdog(context.Background())
+	sc.SetDiscoverInterval(time.Hour)
+	sc.StartDiscovery()
+	ch, err := sc.WatchTopology(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := <-ch; ok {
+		t.Error("Expected topology watch channel to be closed")
+	}
+
+	sc.RLock()
+	running := sc.watchdogStop != nil || sc.discoverStop != nil
+	sc.RUnlock()
+	if running {
+		t.Error("Expected background processes to be stopped")
+	}
+
+	sc.StartDiscovery()
+	sc.RLock()
+	running = sc.discoverStop != nil
+	sc.RUnlock()
+	if running {
+		t.Error("Expected discovery not to restart after close")
+	}
+
+	_, err = sc.GetStats()
+	if !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+
+	if err := sc.Close(); err != nil {
+		t.Errorf("Expected no error closing twice, got: %v", err)
+	}
+}
+
+func TestShutdownInFlight(t *testing.T) {
+	t.Parallel()
+	entered := make(chan struct{}, 2)
+	release := make(chan struct{})
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/slow" {
+			entered <- struct{}{}
+			<-release
+			_, _ = w.Write([]byte("ok"))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		_, _, err := sc.DoRequest(node, "GET", "/slow", nil, nil)
+		errs <- err
+	}()
+
+	<-entered
+	ctx, cancel := context.WithTimeout(context.Background(),
+		20*time.Millisecond)
+	defer cancel()
+	err = sc.Shutdown(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected error: %v, got: %v", context.DeadlineExceeded, err)
+	}
+
+	close(release)
+	if err := <-errs; err != nil {
+		t.Errorf("Expected in-flight request to complete, got: %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+// Package snowthapi contains the endpoint paths, header names and content
+// types of the IRONdb HTTP API used by the gosnowth client library. These are
+// available for use when making raw requests using DoRequest.
+package snowthapi
+
+// Endpoint paths of the IRONdb HTTP API. Paths which take parameters are
+// prefixes, to which the parameters are appended as path elements.
+const (
+	PathState                    = "/state"
+	PathStats                    = "/stats.json"
+	PathGossip                   = "/gossip"
+	PathGossipJSON               = "/gossip/json"
+	PathTopology                 = "/topology"
+	PathTopologyXML              = "/topology/xml"
+	PathActivate                 = "/activate"
+	PathLocate                   = "/locate"
+	PathLocateXML                = "/locate/xml"
+	PathToporing                 = "/toporing"
+	PathEventer                  = "/eventer"
+	PathEventerLogs              = "/eventer/logs"
+	PathEventerJobQueues         = "/eventer/jobq.json"
+	PathJournalReplay            = "/journal/replay"
+	PathFetch                    = "/fetch"
+	PathFind                     = "/find"
+	PathRead                     = "/read"
+	PathRaw                      = "/raw"
+	PathRollup                   = "/rollup"
+	PathHistogram                = "/histogram"
+	PathHistogramWrite           = "/histogram/write"
+	PathWrite                    = "/write/"
+	PathWriteNumeric             = "/write/numeric"
+	PathWriteNNT                 = "/write/nnt"
+	PathWriteText                = "/write/text"
+	PathSurrogate                = "/surrogate/"
+	PathSurrogateActivityRebuild = "/surrogate/activity_rebuild"
+	PathSurrogateLookup          = "/surrogate/lookup"
+	PathLua                      = "/extension/lua"
+	PathCAQL                     = "/extension/lua/public/caql_v1"
+	PathGraphite                 = "/graphite"
+	PathFullCanonical            = "/full/canonical"
+	PathNNT                      = "/nnt"
+	PathCheckTags                = "/check_tags"
+)
+
+// Header names used in requests to and responses from IRONdb.
+const (
+	// HeaderAdvisoryLimit limits the number of results of a search request.
+	HeaderAdvisoryLimit = "X-Snowth-Advisory-Limit"
+
+	// HeaderSearchResultCount contains the total number of results of a
+	// search, which can exceed the number of results returned.
+	HeaderSearchResultCount = "X-Snowth-Search-Result-Count"
+
+	// HeaderDatapoints contains the number of data points in a raw write.
+	HeaderDatapoints = "X-Snowth-Datapoints"
+
+	// HeaderDeleteTime contains the time before which data is deleted by
+	// a numeric data delete.
+	HeaderDeleteTime = "X-Snowth-Delete-Time"
+
+	// HeaderTopology contains the current topology hash of a node.
+	HeaderTopology = "X-Topo-0"
+
+	// HeaderRequestID contains the request ID, which can be used to correlate
+	// client requests with IRONdb node logs.
+	HeaderRequestID = "X-Request-Id"
+
+	// HeaderAccount contains the ID of the account on whose behalf a request
+	// is made, used by multi-tenant proxies in front of IRONdb.
+	HeaderAccount = "X-Circonus-Account"
+
+	// HeaderAuthToken contains the API token authorizing requests made on
+	// behalf of an account.
+	HeaderAuthToken = "X-Circonus-Auth-Token"
+
+	HeaderAccept          = "Accept"
+	HeaderAcceptEncoding  = "Accept-Encoding"
+	HeaderContentEncoding = "Content-Encoding"
+	HeaderContentType     = "Content-Type"
+	HeaderUserAgent       = "User-Agent"
+)
+
+// Content types of IRONdb request and response bodies.
+const (
+	ContentTypeJSON                 = "application/json"
+	ContentTypeMetricListFlatbuffer = "application/x-circonus-metric-list-flatbuffer"
+	ContentTypeFetchFlatbuffer      = "x-irondb-fetch-flatbuffer"
+	ContentTypeDF4Flatbuffer        = "x-irondb-df4-flatbuffer"
+	EncodingGzip                    = "gzip"
+)
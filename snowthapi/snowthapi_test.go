@@ -0,0 +1,36 @@
+package snowthapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestHeaders(t *testing.T) {
+	t.Parallel()
+	for _, h := range []string{HeaderAdvisoryLimit, HeaderSearchResultCount,
+		HeaderDatapoints, HeaderDeleteTime, HeaderTopology, HeaderRequestID,
+		HeaderAccept, HeaderAcceptEncoding, HeaderContentEncoding,
+		HeaderContentType, HeaderUserAgent, HeaderAccount, HeaderAuthToken} {
+		if http.CanonicalHeaderKey(h) != h {
+			t.Errorf("Expected canonical header name: %v, got: %v",
+				http.CanonicalHeaderKey(h), h)
+		}
+	}
+}
+
+func TestPaths(t *testing.T) {
+	t.Parallel()
+	for _, p := range []string{PathState, PathStats, PathGossip, PathGossipJSON,
+		PathTopology, PathTopologyXML, PathActivate, PathLocate, PathLocateXML,
+		PathToporing, PathEventer, PathEventerLogs, PathEventerJobQueues,
+		PathJournalReplay, PathFetch, PathFind, PathRead, PathRaw, PathRollup,
+		PathHistogram, PathHistogramWrite, PathWrite, PathWriteNumeric,
+		PathWriteNNT, PathWriteText, PathSurrogate,
+		PathSurrogateActivityRebuild, PathSurrogateLookup, PathLua, PathCAQL,
+		PathGraphite, PathFullCanonical, PathNNT, PathCheckTags} {
+		if !strings.HasPrefix(p, "/") {
+			t.Errorf("Expected absolute path, got: %v", p)
+		}
+	}
+}
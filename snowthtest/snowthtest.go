@@ -0,0 +1,257 @@
+// Package snowthtest provides a fake IRONdb server, backed by an httptest
+// server, for testing code which uses the gosnowth client library without a
+// live IRONdb cluster.
+package snowthtest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/circonus-labs/gosnowth"
+)
+
+// Identifiers reported by the fake IRONdb node.
+const (
+	// Identity is the node identifier reported by the fake server.
+	Identity = "bb6f7162-4828-11df-bab8-6bac200dcc2a"
+
+	// TopologyHash is the current topology hash reported by the fake server.
+	TopologyHash = "294cbd39999c2270964029691e8bc5e231a867d525ccba62181dc8988ff218dc"
+)
+
+// Default fixture response bodies.
+const (
+	stateFixture = `{
+	"identity": "` + Identity + `",
+	"current": "` + TopologyHash + `",
+	"next": "-",
+	"base_rollup": 60,
+	"rollups": [60, 600, 7200, 86400]
+}`
+
+	statsFixture = `{
+	"application": {"_type": "s", "_value": "snowth"},
+	"identity": {"_type": "s", "_value": "` + Identity + `"},
+	"topology": {
+		"next": {"_type": "s", "_value": "-"},
+		"current": {"_type": "s", "_value": "` + TopologyHash + `"}
+	},
+	"semver": {"_type": "s", "_value": "0.1.1570000000"}
+}`
+
+	putFixture = `{"errors":0,"misdirected":0,"records":0,"updated":0}`
+
+	df4Fixture = `{"version":"DF4","head":{"count":0,"start":0,"period":0},` +
+		`"meta":[],"data":[]}`
+)
+
+// Request values contain a request received by the fake server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Header http.Header
+	Body   []byte
+}
+
+// route values contain a handler for requests matching a method and path.
+type route struct {
+	method  string
+	path    string
+	handler http.HandlerFunc
+}
+
+// match returns whether a request matches the route. Routes with a path
+// ending in a slash match all paths with that prefix.
+func (rt *route) match(method, path string) bool {
+	if rt.method != "" && rt.method != method {
+		return false
+	}
+
+	if strings.HasSuffix(rt.path, "/") {
+		return strings.HasPrefix(path, rt.path)
+	}
+
+	return path == rt.path
+}
+
+// Server values are fake IRONdb nodes. By default, a Server responds to state,
+// stats, and topology requests with a single node cluster containing itself,
+// accepts all write requests, and returns empty results for read requests.
+// The response to any request can be replaced using Handle or HandleFunc.
+type Server struct {
+	*httptest.Server
+	mu         sync.Mutex
+	routes     []*route
+	requests   []Request
+	unexpected []Request
+}
+
+// NewServer creates and starts a new fake IRONdb server. The caller should
+// call Close when finished, to shut it down.
+func NewServer() *Server {
+	s := &Server{}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.Handle("GET", "/state", http.StatusOK, stateFixture)
+	s.Handle("GET", "/stats.json", http.StatusOK, statsFixture)
+	s.HandleFunc("GET", "/topology/xml/", s.serveTopology)
+	s.Handle("POST", "/write/", http.StatusOK, "")
+	s.Handle("POST", "/histogram/write", http.StatusOK, "")
+	s.Handle("POST", "/raw", http.StatusOK, putFixture)
+	s.Handle("PUT", "/raw", http.StatusOK, putFixture)
+	s.Handle("GET", "/read/", http.StatusOK, "[]")
+	s.Handle("GET", "/rollup/", http.StatusOK, "[]")
+	s.Handle("GET", "/histogram/", http.StatusOK, "[]")
+	s.Handle("GET", "/find/", http.StatusOK, "[]")
+	s.Handle("POST", "/fetch", http.StatusOK, df4Fixture)
+	return s
+}
+
+// Handle sets a fixture response for requests with the specified method and
+// path. An empty method matches all methods, and a path ending with a slash
+// matches all paths with that prefix. Fixtures set later take precedence over
+// earlier fixtures and the defaults.
+func (s *Server) Handle(method, path string, status int, body string) {
+	s.HandleFunc(method, path, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	})
+}
+
+// HandleFunc sets a handler function for requests with the specified method
+// and path, following the same matching rules as Handle.
+func (s *Server) HandleFunc(method, path string, f http.HandlerFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes = append(s.routes, &route{method: method, path: path, handler: f})
+}
+
+// serveHTTP records and responds to requests received by the server.
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req := Request{
+		Method: r.Method,
+		Path:   r.URL.Path,
+		Query:  r.URL.Query(),
+		Header: r.Header.Clone(),
+		Body:   body,
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var h http.HandlerFunc
+	for i := len(s.routes) - 1; i >= 0; i-- {
+		if s.routes[i].match(r.Method, r.URL.Path) {
+			h = s.routes[i].handler
+			break
+		}
+	}
+
+	if h == nil {
+		s.unexpected = append(s.unexpected, req)
+	}
+
+	s.mu.Unlock()
+	if h == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	h(w, r)
+}
+
+// serveTopology responds to topology requests with a topology containing only
+// the server itself.
+func (s *Server) serveTopology(w http.ResponseWriter, r *http.Request) {
+	host, port, err := net.SplitHostPort(s.Listener.Addr().String())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_, _ = fmt.Fprintf(w, `<nodes n="1">`+
+		`<node id="%s" address="%s" port="%s" apiport="%s" weight="32"/>`+
+		`</nodes>`, Identity, host, port, port)
+}
+
+// Requests returns the requests received by the server.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]Request, len(s.requests))
+	copy(r, s.requests)
+	return r
+}
+
+// Unexpected returns the requests received by the server which did not match
+// any fixture.
+func (s *Server) Unexpected() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r := make([]Request, len(s.unexpected))
+	copy(r, s.unexpected)
+	return r
+}
+
+// Reset discards the recorded requests.
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.unexpected = nil
+}
+
+// Find returns the recorded requests with the specified method and path,
+// following the same matching rules as Handle.
+func (s *Server) Find(method, path string) []Request {
+	rt := &route{method: method, path: path}
+	r := []Request{}
+	for _, req := range s.Requests() {
+		if rt.match(req.Method, req.Path) {
+			r = append(r, req)
+		}
+	}
+
+	return r
+}
+
+// AssertRequested fails the test if the server did not receive a request with
+// the specified method and path.
+func (s *Server) AssertRequested(t testing.TB, method, path string) {
+	t.Helper()
+	if len(s.Find(method, path)) == 0 {
+		t.Errorf("Expected request: %s %s", method, path)
+	}
+}
+
+// AssertRequestCount fails the test if the server did not receive exactly n
+// requests with the specified method and path.
+func (s *Server) AssertRequestCount(t testing.TB, method, path string, n int) {
+	t.Helper()
+	if c := len(s.Find(method, path)); c != n {
+		t.Errorf("Expected requests: %s %s: %d, got: %d", method, path, n, c)
+	}
+}
+
+// AssertNoUnexpected fails the test if the server received any request which
+// did not match a fixture.
+func (s *Server) AssertNoUnexpected(t testing.TB) {
+	t.Helper()
+	for _, r := range s.Unexpected() {
+		t.Errorf("Unexpected request: %s %s", r.Method, r.Path)
+	}
+}
+
+// NewClient creates a new gosnowth client connected to the server.
+func (s *Server) NewClient(discover bool) (*gosnowth.SnowthClient, error) {
+	return gosnowth.NewSnowthClient(discover, s.URL)
+}
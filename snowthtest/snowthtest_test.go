@@ -0,0 +1,82 @@
+// Package snowthtest provides a fake IRONdb server, backed by an httptest
+// server, for testing code which uses the gosnowth client library without a
+// live IRONdb cluster.
+package snowthtest
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/gosnowth"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	s := NewServer()
+	defer s.Close()
+	sc, err := s.NewClient(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sc.StopDiscovery()
+	if n := len(sc.ListActiveNodes()); n != 1 {
+		t.Fatalf("Expected active nodes: 1, got: %v", n)
+	}
+
+	s.AssertRequested(t, "GET", "/stats.json")
+	s.AssertRequested(t, "GET", "/topology/xml/"+TopologyHash)
+	s.Handle("GET", "/read/", http.StatusOK,
+		"[[1380000000,50],[1380000300,60]]")
+	node := sc.GetActiveNode()
+	res, err := sc.ReadNumericValues(time.Unix(1380000000, 0),
+		time.Unix(1380000600, 0), 300, "average",
+		"11223344-5566-7788-9900-aabbccddeeff", "test", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 2 {
+		t.Errorf("Expected values: 2, got: %v", len(res))
+	}
+
+	err = sc.WriteNumeric([]gosnowth.NumericWrite{{
+		Metric: "test",
+		ID:     "11223344-5566-7788-9900-aabbccddeeff",
+		Offset: 1380000000,
+		Count:  1,
+		Value:  1,
+	}}, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.AssertRequestCount(t, "POST", "/write/numeric", 1)
+	reqs := s.Find("POST", "/write/numeric")
+	if len(reqs) != 1 || len(reqs[0].Body) == 0 {
+		t.Errorf("Expected recorded write body, got: %v", reqs)
+	}
+
+	s.AssertNoUnexpected(t)
+	s.Reset()
+	if len(s.Requests()) != 0 {
+		t.Errorf("Expected no requests, got: %v", len(s.Requests()))
+	}
+
+	s.Handle("", "/state", http.StatusServiceUnavailable, "unavailable")
+	if _, err := sc.GetNodeState(node); !gosnowth.IsThrottled(err) {
+		t.Errorf("Expected throttled error, got: %v", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	if _, _, err := sc.DoRequest(node, "GET", "/missing", nil, nil); err == nil {
+		t.Error("Expected not found error")
+	}
+
+	if n := len(s.Unexpected()); n != 1 {
+		t.Errorf("Expected unexpected requests: 1, got: %v", n)
+	}
+}
@@ -0,0 +1,396 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrSpoolFull is returned when a write cannot be spooled because the write
+// spool has reached its maximum size.
+var ErrSpoolFull = errors.New("write spool is full")
+
+// Spool defaults and file name extension.
+const (
+	defaultSpoolMaxBytes = 256 << 20
+	spoolExt             = ".spool"
+)
+
+// SpoolOptions values contain optional parameters used to control the limits
+// of a write spool.
+type SpoolOptions struct {
+	// MaxBytes is the maximum total size, in bytes, of the spooled writes.
+	// The default is 256 MiB.
+	MaxBytes int64
+
+	// MaxEntries is the maximum number of spooled writes. A value of zero,
+	// the default, means no limit is enforced.
+	MaxEntries int
+}
+
+// spoolHeader values describe a spooled write request. Each spool file
+// contains the JSON encoded header on its first line, followed by the body of
+// the request.
+type spoolHeader struct {
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+}
+
+// spoolEntry values identify a spooled write file.
+type spoolEntry struct {
+	name string
+	size int64
+}
+
+// Spool values are file backed write-ahead spools, which capture write
+// requests when no IRONdb node can be reached, and replay them in the order
+// they were written once connectivity is restored. Each spooled write is
+// stored in its own file in the spool directory, so that spooled writes
+// survive restarts of the process. Spools are safe for concurrent use.
+type Spool struct {
+	sync.Mutex
+	dir      string
+	opts     SpoolOptions
+	seq      uint64
+	entries  []spoolEntry
+	size     int64
+	replayMu sync.Mutex
+}
+
+// OpenSpool opens the write spool stored in the specified directory, creating
+// the directory if it does not exist. Writes already spooled in the directory
+// are replayed before any new writes.
+func OpenSpool(dir string, options *SpoolOptions) (*Spool, error) {
+	opts := SpoolOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultSpoolMaxBytes
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("unable to create spool directory: %w", err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read spool directory: %w", err)
+	}
+
+	s := &Spool{dir: dir, opts: opts, entries: []spoolEntry{}}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() {
+			continue
+		}
+
+		// Remove partially written files left by an interrupted write.
+		if strings.Contains(name, spoolExt+".tmp") {
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+
+		if !strings.HasSuffix(name, spoolExt) {
+			continue
+		}
+
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, spoolExt),
+			10, 64)
+		if err != nil {
+			continue
+		}
+
+		if seq > s.seq {
+			s.seq = seq
+		}
+
+		s.entries = append(s.entries, spoolEntry{name: name, size: f.Size()})
+		s.size += f.Size()
+	}
+
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.entries[i].name < s.entries[j].name
+	})
+
+	return s, nil
+}
+
+// Dir returns the directory in which writes are spooled.
+func (s *Spool) Dir() string {
+	return s.dir
+}
+
+// Options returns the options of the spool, with defaults applied.
+func (s *Spool) Options() SpoolOptions {
+	return s.opts
+}
+
+// Len returns the number of spooled writes.
+func (s *Spool) Len() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.entries)
+}
+
+// Size returns the total size, in bytes, of the spooled writes.
+func (s *Spool) Size() int64 {
+	s.Lock()
+	defer s.Unlock()
+	return s.size
+}
+
+// add stores a write request in the spool, returning ErrSpoolFull if storing
+// it would exceed the limits of the spool.
+func (s *Spool) add(url string, headers http.Header, body []byte) error {
+	hdr, err := json.Marshal(&spoolHeader{URL: url, Headers: headers})
+	if err != nil {
+		return fmt.Errorf("unable to encode spooled write: %w", err)
+	}
+
+	b := make([]byte, 0, len(hdr)+len(body)+1)
+	b = append(append(append(b, hdr...), '\n'), body...)
+	s.Lock()
+	defer s.Unlock()
+	if s.size+int64(len(b)) > s.opts.MaxBytes ||
+		(s.opts.MaxEntries > 0 && len(s.entries) >= s.opts.MaxEntries) {
+		return ErrSpoolFull
+	}
+
+	name := fmt.Sprintf("%020d%s", s.seq+1, spoolExt)
+	if err := writeFileAtomic(filepath.Join(s.dir, name), b); err != nil {
+		return fmt.Errorf("unable to write spool file: %w", err)
+	}
+
+	s.seq++
+	s.entries = append(s.entries, spoolEntry{name: name, size: int64(len(b))})
+	s.size += int64(len(b))
+	return nil
+}
+
+// first returns the oldest spooled write, and whether the spool has any.
+func (s *Spool) first() (spoolEntry, bool) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.entries) == 0 {
+		return spoolEntry{}, false
+	}
+
+	return s.entries[0], true
+}
+
+// read reads the header and body of a spooled write.
+func (s *Spool) read(e spoolEntry) (*spoolHeader, []byte, error) {
+	b, err := ioutil.ReadFile(filepath.Join(s.dir, e.name))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to read spool file: %w", err)
+	}
+
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return nil, nil, fmt.Errorf("invalid spool file: %s", e.name)
+	}
+
+	hdr := &spoolHeader{}
+	if err := json.Unmarshal(b[:i], hdr); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode spool file: %w", err)
+	}
+
+	return hdr, b[i+1:], nil
+}
+
+// remove removes the oldest spooled write, if it is the specified entry.
+func (s *Spool) remove(e spoolEntry) {
+	s.Lock()
+	defer s.Unlock()
+	if len(s.entries) == 0 || s.entries[0].name != e.name {
+		return
+	}
+
+	_ = os.Remove(filepath.Join(s.dir, e.name))
+	s.entries = s.entries[1:]
+	s.size -= e.size
+}
+
+// Spool gets the write spool used to capture writes when no node can be
+// reached.
+func (c *Config) Spool() *Spool {
+	c.RLock()
+	defer c.RUnlock()
+	return c.spool
+}
+
+// SetSpool sets the write spool used to capture writes when no node can be
+// reached. By default, no spool is used, and such writes fail.
+func (c *Config) SetSpool(s *Spool) {
+	c.Lock()
+	c.spool = s
+	c.Unlock()
+}
+
+// SetSpool sets the write spool used by the client. When a spool is set,
+// numeric, NNT, text, histogram and raw writes which cannot be sent because
+// no node can be reached are stored in the spool and reported as successful.
+// While the spool contains writes, new writes are also added to it, so that
+// all writes reach IRONdb in the order they were made. Spooled writes are
+// replayed by the node watch process once a node is active, or when
+// ReplaySpool is called. Writes made with a write consistency level other
+// than WriteConsistencyOne are never spooled. A nil value disables spooling.
+func (sc *SnowthClient) SetSpool(s *Spool) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.spool = s
+}
+
+// getSpool returns the write spool used by the client.
+func (sc *SnowthClient) getSpool() *Spool {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.spool
+}
+
+// isUnreachable returns whether an error indicates that a request failed
+// without reaching a node, rather than being rejected by one.
+func isUnreachable(err error) bool {
+	var se *SnowthError
+	if errors.As(err, &se) {
+		return se.Status == 0 && se.Err != nil
+	}
+
+	var nerr net.Error
+	return errors.As(err, &nerr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// postWrite sends a write request to a node. If the client has a write spool,
+// the request is spooled instead when no node can be reached, or when earlier
+// writes are waiting in the spool. The returned boolean value indicates
+// whether the request was spooled, in which case there is no response body.
+func (sc *SnowthClient) postWrite(ctx context.Context, node *SnowthNode,
+	url string, body io.Reader,
+	headers http.Header) (io.Reader, bool, error) {
+	sp := sc.getSpool()
+	if sp == nil || isNoFailover(ctx) {
+		bdy, _, err := sc.DoRequestContext(ctx, node, "POST", url, body,
+			headers)
+		return bdy, false, err
+	}
+
+	b := []byte{}
+	if body != nil {
+		var err error
+		if b, err = ioutil.ReadAll(body); err != nil {
+			return nil, false, fmt.Errorf("unable to read write data: %w", err)
+		}
+	}
+
+	if node == nil {
+		node = sc.GetActiveNode()
+	}
+
+	if node != nil && sp.Len() == 0 {
+		bdy, _, err := sc.DoRequestContext(ctx, node, "POST", url,
+			bytes.NewReader(b), headers)
+		if err == nil || ctx.Err() != nil || !isUnreachable(err) {
+			return bdy, false, err
+		}
+
+		sc.LogWarnw("gosnowth spooling write", "endpoint", endpointName(url),
+			"error", err)
+	}
+
+	if err := sp.add(url, headers, b); err != nil {
+		return nil, false, fmt.Errorf("unable to spool write: %w", err)
+	}
+
+	return nil, true, nil
+}
+
+// ReplaySpool sends the writes stored in the write spool of the client to
+// IRONdb, in the order they were spooled, returning the number of writes
+// sent. Replay stops at the first write which cannot be sent because no node
+// can be reached, or which fails with a retryable error, leaving it and all
+// later writes in the spool. Spooled writes which are rejected by IRONdb with
+// a permanent error are logged and discarded.
+func (sc *SnowthClient) ReplaySpool(ctx context.Context) (int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sp := sc.getSpool()
+	if sp == nil {
+		return 0, nil
+	}
+
+	sp.replayMu.Lock()
+	defer sp.replayMu.Unlock()
+	n := 0
+	for {
+		e, ok := sp.first()
+		if !ok {
+			return n, nil
+		}
+
+		node := sc.GetActiveNode()
+		if node == nil {
+			return n, fmt.Errorf("unable to replay spooled writes: " +
+				"no active nodes")
+		}
+
+		hdr, body, err := sp.read(e)
+		if err != nil {
+			sc.LogErrorw("gosnowth discarded spooled write", "file", e.name,
+				"error", err)
+			sp.remove(e)
+			continue
+		}
+
+		_, _, err = sc.DoRequestContext(ctx, node, "POST", hdr.URL,
+			bytes.NewReader(body), hdr.Headers)
+		if err != nil {
+			if ctx.Err() != nil || IsRetryable(err) {
+				return n, fmt.Errorf("unable to replay spooled write: %w", err)
+			}
+
+			sc.LogErrorw("gosnowth discarded spooled write", "file", e.name,
+				"endpoint", endpointName(hdr.URL), "error", err)
+			sp.remove(e)
+			continue
+		}
+
+		sp.remove(e)
+		n++
+	}
+}
+
+// replaySpoolWatch replays any spooled writes from the node watch process,
+// when at least one node is active.
+func (sc *SnowthClient) replaySpoolWatch(ctx context.Context) {
+	sp := sc.getSpool()
+	if sp == nil || sp.Len() == 0 || len(sc.ListActiveNodes()) == 0 {
+		return
+	}
+
+	n, err := sc.ReplaySpool(ctx)
+	if n > 0 {
+		sc.LogInfow("gosnowth replayed spooled writes", "writes", n,
+			"remaining", sp.Len())
+	}
+
+	if err != nil {
+		sc.LogWarnw("gosnowth spool replay failed", "error", err)
+	}
+}
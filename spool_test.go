@@ -0,0 +1,245 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestOpenSpool(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "gosnowth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	sp, err := OpenSpool(dir, &SpoolOptions{MaxEntries: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sp.add("/write/text", nil, []byte("first")); err != nil {
+		t.Fatal(err)
+	}
+
+	hdrs := http.Header{"X-Snowth-Datapoints": {"1"}}
+	if err := sp.add("/raw", hdrs, []byte("second")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sp.add("/raw", nil, []byte("third")); !errors.Is(err,
+		ErrSpoolFull) {
+		t.Errorf("Expected error: %v, got: %v", ErrSpoolFull, err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "1.spool.tmp123"),
+		[]byte("partial"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	sp, err = OpenSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sp.Len() != 2 {
+		t.Fatalf("Expected spooled writes: 2, got: %v", sp.Len())
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "1.spool.tmp123")); !os.IsNotExist(
+		err) {
+		t.Errorf("Expected partial spool file to be removed, got: %v", err)
+	}
+
+	for _, exp := range []string{"first", "second"} {
+		e, ok := sp.first()
+		if !ok {
+			t.Fatal("Expected spooled write")
+		}
+
+		hdr, body, err := sp.read(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != exp {
+			t.Errorf("Expected body: %v, got: %s", exp, body)
+		}
+
+		if exp == "second" && hdr.Headers.Get("X-Snowth-Datapoints") != "1" {
+			t.Errorf("Expected headers: %v, got: %v", hdrs, hdr.Headers)
+		}
+
+		sp.remove(e)
+	}
+
+	if sp.Len() != 0 || sp.Size() != 0 {
+		t.Errorf("Expected empty spool, got: %v writes, %v bytes", sp.Len(),
+			sp.Size())
+	}
+
+	if err := sp.add("/raw", nil, []byte("fourth")); err != nil {
+		t.Fatal(err)
+	}
+
+	if e, _ := sp.first(); e.name != "00000000000000000003.spool" {
+		t.Errorf("Expected spool file: 00000000000000000003.spool, got: %v",
+			e.name)
+	}
+}
+
+func TestSpoolReplay(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	received := []string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/write/text" {
+			data := []TextData{}
+			if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			for _, d := range data {
+				received = append(received, d.Value)
+			}
+
+			mu.Unlock()
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	down := false
+	sc.Use(func(next RoundTripFunc) RoundTripFunc {
+		return func(r *http.Request) (*http.Response, error) {
+			mu.Lock()
+			d := down
+			mu.Unlock()
+			if d {
+				return nil, &net.OpError{Op: "dial", Net: "tcp",
+					Err: errors.New("connection refused")}
+			}
+
+			return next(r)
+		}
+	})
+
+	dir, err := ioutil.TempDir("", "gosnowth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	sp, err := OpenSpool(dir, &SpoolOptions{MaxEntries: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sc.SetSpool(sp)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	write := func(v string) error {
+		return sc.WriteText([]TextData{{
+			Metric: "test",
+			ID:     "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d",
+			Offset: "1",
+			Value:  v,
+		}}, node)
+	}
+
+	mu.Lock()
+	down = true
+	mu.Unlock()
+	for _, v := range []string{"1", "2"} {
+		if err := write(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n, err := sc.ReplaySpool(context.Background()); err == nil || n != 0 {
+		t.Errorf("Expected replay failure, got: %v, %v", n, err)
+	}
+
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	if err := write("3"); err != nil {
+		t.Fatal(err)
+	}
+
+	if sp.Len() != 3 {
+		t.Fatalf("Expected spooled writes: 3, got: %v", sp.Len())
+	}
+
+	if err := write("4"); !errors.Is(err, ErrSpoolFull) {
+		t.Errorf("Expected error: %v, got: %v", ErrSpoolFull, err)
+	}
+
+	n, err := sc.ReplaySpool(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 3 {
+		t.Errorf("Expected replayed writes: 3, got: %v", n)
+	}
+
+	if err := write("5"); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	exp := []string{"1", "2", "3", "5"}
+	if len(received) != len(exp) {
+		t.Fatalf("Expected writes: %v, got: %v", exp, received)
+	}
+
+	for i, v := range exp {
+		if received[i] != v {
+			t.Errorf("Expected writes: %v, got: %v", exp, received)
+			break
+		}
+	}
+
+	if sp.Len() != 0 {
+		t.Errorf("Expected spooled writes: 0, got: %v", sp.Len())
+	}
+}
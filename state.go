@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // GetNodeState retrieves the state of an IRONdb node.
@@ -24,12 +26,13 @@ func (sc *SnowthClient) GetNodeStateContext(ctx context.Context,
 	}
 
 	r := &NodeState{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", "/state", nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		snowthapi.PathState, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -0,0 +1,336 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ClientState values contain the state of a client which can be persisted
+// across restarts, so that a new client can warm start using the nodes
+// discovered by a previous one rather than discovering them again, with the
+// node circuit breaker state and write spool of the previous client.
+type ClientState struct {
+	// Topology is the current topology hash of the cluster.
+	Topology string `json:"topology"`
+
+	// Nodes contains every node known to the client.
+	Nodes []NodeRecord `json:"nodes"`
+
+	// Quarantine contains the URLs of the nodes which were manually
+	// deactivated or quarantined, with the time their quarantine ends, or a
+	// zero time if they remain inactive until reactivated.
+	Quarantine map[string]time.Time `json:"quarantine,omitempty"`
+
+	// Failures contains the number of consecutive failed health checks of
+	// the nodes, keyed by URL. The node health watchdog trips the circuit
+	// breaker of an active node, deactivating it, when this reaches the
+	// watchdog failure threshold. Nodes whose breaker has tripped are stored
+	// as inactive nodes.
+	Failures map[string]int64 `json:"failures,omitempty"`
+
+	// Spool is a checkpoint of the write spool of the client, if one is set.
+	Spool *SpoolCheckpoint `json:"spool,omitempty"`
+
+	// Saved is the time the state was saved.
+	Saved time.Time `json:"saved"`
+}
+
+// NodeRecord values describe a node in a persisted client state.
+type NodeRecord struct {
+	URL    string `json:"url"`
+	ID     string `json:"id,omitempty"`
+	SemVer string `json:"semver,omitempty"`
+	Active bool   `json:"active"`
+}
+
+// SpoolCheckpoint values describe the write spool of a persisted client
+// state. Spooled writes are stored in the spool directory, so a checkpoint
+// records only where they are, how many were outstanding when the state was
+// saved, and the limits the spool is reopened with.
+type SpoolCheckpoint struct {
+	Dir        string `json:"dir"`
+	Entries    int    `json:"entries"`
+	Bytes      int64  `json:"bytes"`
+	MaxBytes   int64  `json:"max_bytes,omitempty"`
+	MaxEntries int    `json:"max_entries,omitempty"`
+}
+
+// StateStore values persist client state across restarts. Implementations must
+// be safe for concurrent use.
+type StateStore interface {
+	// LoadState returns the persisted client state, or nil if there is none.
+	LoadState() (*ClientState, error)
+
+	// SaveState persists a client state, replacing any previous state.
+	SaveState(st *ClientState) error
+}
+
+// FileStateStore values are state stores which persist client state to a JSON
+// file.
+type FileStateStore struct {
+	sync.Mutex
+	path string
+}
+
+// NewFileStateStore creates a new state store persisted to the file at the
+// specified path.
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// LoadState returns the client state stored in the state file, or nil if the
+// file does not exist.
+func (fs *FileStateStore) LoadState() (*ClientState, error) {
+	fs.Lock()
+	defer fs.Unlock()
+	b, err := ioutil.ReadFile(fs.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("unable to read state file: %w", err)
+	}
+
+	st := &ClientState{}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, fmt.Errorf("unable to decode state file: %w", err)
+	}
+
+	return st, nil
+}
+
+// SaveState writes a client state to the state file, replacing it atomically.
+func (fs *FileStateStore) SaveState(st *ClientState) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("unable to encode state file: %w", err)
+	}
+
+	fs.Lock()
+	defer fs.Unlock()
+	if err := writeFileAtomic(fs.path, b); err != nil {
+		return fmt.Errorf("unable to write state file: %w", err)
+	}
+
+	return nil
+}
+
+// StateStore gets the store used to persist client state across restarts.
+func (c *Config) StateStore() StateStore {
+	c.RLock()
+	defer c.RUnlock()
+	return c.stateStore
+}
+
+// SetStateStore sets the store used to persist client state across restarts.
+// When set, a new client with node discovery enabled restores the nodes
+// persisted by a previous client instead of discovering them, provided the
+// cluster topology has not changed, and restores any manual deactivation or
+// quarantine of the nodes and their health check failure counts. If no write
+// spool is set, and the spool of the previous client still contains writes,
+// it is reopened so that those writes are replayed.
+func (c *Config) SetStateStore(s StateStore) {
+	c.Lock()
+	c.stateStore = s
+	c.Unlock()
+}
+
+// SetStateStore sets the store used to persist client state. The state is
+// saved whenever the known nodes or their active state change.
+func (sc *SnowthClient) SetStateStore(s StateStore) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.stateStore = s
+}
+
+// State returns a snapshot of the current client state.
+func (sc *SnowthClient) State() *ClientState {
+	if sc.parent != nil {
+		return sc.parent.State()
+	}
+
+	clock := sc.getClock()
+	sc.RLock()
+	defer sc.RUnlock()
+	st := &ClientState{
+		Topology:   sc.currentTopology,
+		Nodes:      []NodeRecord{},
+		Quarantine: make(map[string]time.Time, len(sc.quarantine)),
+		Failures:   make(map[string]int64, len(sc.healthFailures)),
+		Saved:      clock.Now(),
+	}
+
+	if sc.spool != nil {
+		opts := sc.spool.Options()
+		st.Spool = &SpoolCheckpoint{
+			Dir:        sc.spool.Dir(),
+			Entries:    sc.spool.Len(),
+			Bytes:      sc.spool.Size(),
+			MaxBytes:   opts.MaxBytes,
+			MaxEntries: opts.MaxEntries,
+		}
+	}
+
+	for _, n := range sc.activeNodes {
+		st.Nodes = append(st.Nodes, NodeRecord{
+			URL:    n.GetURL().String(),
+			ID:     n.identifier,
+			SemVer: n.semVer,
+			Active: true,
+		})
+	}
+
+	for _, n := range sc.inactiveNodes {
+		st.Nodes = append(st.Nodes, NodeRecord{
+			URL:    n.GetURL().String(),
+			ID:     n.identifier,
+			SemVer: n.semVer,
+		})
+	}
+
+	sort.Slice(st.Nodes, func(i, j int) bool {
+		return st.Nodes[i].URL < st.Nodes[j].URL
+	})
+
+	for k, v := range sc.quarantine {
+		st.Quarantine[k] = v
+	}
+
+	for k, v := range sc.healthFailures {
+		st.Failures[k] = v
+	}
+
+	return st
+}
+
+// SaveState saves the current client state to the state store, if one is set.
+func (sc *SnowthClient) SaveState() error {
+	sc.RLock()
+	store := sc.stateStore
+	sc.RUnlock()
+	if store == nil {
+		return nil
+	}
+
+	return store.SaveState(sc.State())
+}
+
+// persistState saves the current client state, logging any error.
+func (sc *SnowthClient) persistState() {
+	if err := sc.SaveState(); err != nil {
+		sc.LogWarnf("unable to save client state: %v", err)
+	}
+}
+
+// findNode returns the known node with the specified URL, or nil if there is
+// none.
+func (sc *SnowthClient) findNode(u string) *SnowthNode {
+	if sc.parent != nil {
+		return sc.parent.findNode(u)
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	for _, n := range append(sc.activeNodes, sc.inactiveNodes...) {
+		if n.GetURL().String() == u {
+			return n
+		}
+	}
+
+	return nil
+}
+
+// restoreState restores a persisted client state. Persisted nodes are added to
+// the client if addNodes is true, and manual deactivations, quarantines and
+// health check failure counts are restored for all known nodes. The write
+// spool of the state is reopened if the client has none and it still
+// contains writes.
+func (sc *SnowthClient) restoreState(st *ClientState, addNodes bool) {
+	if st == nil {
+		return
+	}
+
+	if addNodes {
+		for _, nr := range st.Nodes {
+			if sc.findNode(nr.URL) != nil {
+				continue
+			}
+
+			u, err := url.Parse(nr.URL)
+			if err != nil {
+				sc.LogWarnf("invalid persisted node url: %s: %v", nr.URL, err)
+				continue
+			}
+
+			node := &SnowthNode{
+				url:             u,
+				identifier:      nr.ID,
+				currentTopology: st.Topology,
+				semVer:          nr.SemVer,
+			}
+
+			sc.AddNodes(node)
+			if nr.Active {
+				sc.ActivateNodes(node)
+			}
+		}
+	}
+
+	now := sc.getClock().Now()
+	for u, until := range st.Quarantine {
+		node := sc.findNode(u)
+		switch {
+		case node == nil:
+		case until.IsZero():
+			sc.DeactivateNode(node)
+		case until.After(now):
+			sc.QuarantineNode(node, until.Sub(now))
+		}
+	}
+
+	for u, n := range st.Failures {
+		if n <= 0 || sc.findNode(u) == nil {
+			continue
+		}
+
+		sc.Lock()
+		if sc.healthFailures == nil {
+			sc.healthFailures = map[string]int64{}
+		}
+
+		sc.healthFailures[u] = n
+		sc.Unlock()
+	}
+
+	if st.Spool == nil || st.Spool.Dir == "" || sc.getSpool() != nil {
+		return
+	}
+
+	if _, err := os.Stat(st.Spool.Dir); err != nil {
+		return
+	}
+
+	s, err := OpenSpool(st.Spool.Dir, &SpoolOptions{
+		MaxBytes:   st.Spool.MaxBytes,
+		MaxEntries: st.Spool.MaxEntries,
+	})
+	if err != nil {
+		sc.LogWarnf("unable to reopen persisted write spool: %s: %v",
+			st.Spool.Dir, err)
+		return
+	}
+
+	if s.Len() > 0 {
+		sc.LogInfof("reopened persisted write spool: %s: %d writes",
+			st.Spool.Dir, s.Len())
+		sc.SetSpool(s)
+	}
+}
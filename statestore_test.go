@@ -0,0 +1,216 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type memStateStore struct {
+	sync.Mutex
+	st    *ClientState
+	saves int
+}
+
+func (ms *memStateStore) LoadState() (*ClientState, error) {
+	ms.Lock()
+	defer ms.Unlock()
+	return ms.st, nil
+}
+
+func (ms *memStateStore) SaveState(st *ClientState) error {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.st = st
+	ms.saves++
+	return nil
+}
+
+func TestFileStateStore(t *testing.T) {
+	t.Parallel()
+	tmp, err := ioutil.TempDir("", "gosnowth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(tmp)
+	fs := NewFileStateStore(filepath.Join(tmp, "state.json"))
+	st, err := fs.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st != nil {
+		t.Errorf("Expected state: nil, got: %+v", st)
+	}
+
+	exp := &ClientState{
+		Topology: "test",
+		Nodes: []NodeRecord{{
+			URL:    "http://localhost:8112",
+			ID:     "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+			Active: true,
+		}},
+		Quarantine: map[string]time.Time{"http://localhost:8112": {}},
+		Failures:   map[string]int64{"http://localhost:8112": 2},
+		Spool:      &SpoolCheckpoint{Dir: "spool", Entries: 3, Bytes: 100},
+	}
+
+	if err := fs.SaveState(exp); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = fs.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if st.Topology != exp.Topology || len(st.Nodes) != 1 ||
+		st.Nodes[0] != exp.Nodes[0] || len(st.Quarantine) != 1 {
+		t.Errorf("Expected state: %+v, got: %+v", exp, st)
+	}
+
+	if st.Failures["http://localhost:8112"] != 2 {
+		t.Errorf("Expected failures: 2, got: %v", st.Failures)
+	}
+
+	if st.Spool == nil || *st.Spool != *exp.Spool {
+		t.Errorf("Expected spool checkpoint: %+v, got: %+v", exp.Spool,
+			st.Spool)
+	}
+}
+
+func TestClientStateWarmStart(t *testing.T) {
+	t.Parallel()
+	var topoReqs int64
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml/") {
+			atomic.AddInt64(&topoReqs, 1)
+			_, _ = w.Write([]byte(topologyXMLTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	tmp, err := ioutil.TempDir("", "gosnowth")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(tmp)
+	dir := filepath.Join(tmp, "spool")
+	spool, err := OpenSpool(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := spool.add("/write/numeric", nil, []byte("[]")); err != nil {
+		t.Fatal(err)
+	}
+
+	hash := "294cbd39999c2270964029691e8bc5e231a867d525ccba62181dc8988ff218dc"
+	store := &memStateStore{st: &ClientState{
+		Topology: hash,
+		Nodes: []NodeRecord{{
+			URL:    ms.URL,
+			ID:     "bb6f7162-4828-11df-bab8-6bac200dcc2a",
+			Active: true,
+		}, {
+			URL:    "http://127.0.0.1:1",
+			ID:     "other",
+			Active: true,
+		}},
+		Quarantine: map[string]time.Time{"http://127.0.0.1:1": {}},
+		Failures:   map[string]int64{ms.URL: 2, "http://unknown:1": 1},
+		Spool: &SpoolCheckpoint{Dir: dir, Entries: 1, MaxBytes: 4096,
+			MaxEntries: 10},
+	}}
+
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetDiscover(true)
+	cfg.SetStateStore(store)
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sc.StopDiscovery()
+	if n := atomic.LoadInt64(&topoReqs); n != 0 {
+		t.Errorf("Expected topology requests: 0, got: %v", n)
+	}
+
+	if len(sc.ListActiveNodes()) != 1 {
+		t.Errorf("Expected active nodes: 1, got: %v",
+			len(sc.ListActiveNodes()))
+	}
+
+	in := sc.ListInactiveNodes()
+	if len(in) != 1 || in[0].identifier != "other" {
+		t.Fatalf("Expected inactive node: other, got: %v", in)
+	}
+
+	if !sc.isQuarantined(in[0]) {
+		t.Error("Expected node to remain deactivated")
+	}
+
+	st := sc.State()
+	if st.Topology != hash || len(st.Nodes) != 2 {
+		t.Errorf("Expected state nodes: 2, got: %+v", st)
+	}
+
+	if len(st.Failures) != 1 || st.Failures[ms.URL] != 2 {
+		t.Errorf("Expected restored failures: map[%s:2], got: %v", ms.URL,
+			st.Failures)
+	}
+
+	if st.Spool == nil || st.Spool.Dir != dir || st.Spool.Entries != 1 ||
+		st.Spool.MaxBytes != 4096 || st.Spool.MaxEntries != 10 {
+		t.Errorf("Expected reopened spool checkpoint, got: %+v", st.Spool)
+	}
+
+	store.Lock()
+	saves := store.saves
+	store.Unlock()
+	if saves == 0 {
+		t.Error("Expected client state to be saved")
+	}
+
+	store.Lock()
+	store.st.Topology = "changed"
+	store.Unlock()
+	sc2, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer sc2.StopDiscovery()
+	if n := atomic.LoadInt64(&topoReqs); n != 1 {
+		t.Errorf("Expected topology requests: 1, got: %v", n)
+	}
+}
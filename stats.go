@@ -4,6 +4,10 @@ package gosnowth
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // GetStats retrieves the metrics about the status of an IRONdb node.
@@ -22,12 +26,13 @@ func (sc *SnowthClient) GetStatsContext(ctx context.Context,
 	}
 
 	r := &Stats{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", "/stats.json", nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		snowthapi.PathStats, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -124,3 +129,31 @@ func (ns *Stats) NextTopology() string {
 
 	return next
 }
+
+// Uptime returns the uptime value from a node state value, if the node reports
+// one.
+func (ns *Stats) Uptime() time.Duration {
+	if ns == nil {
+		return 0
+	}
+
+	m, ok := (*ns)["uptime"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+
+	var secs float64
+	switch v := m["_value"].(type) {
+	case float64:
+		secs = v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0
+		}
+
+		secs = f
+	}
+
+	return time.Duration(secs * float64(time.Second))
+}
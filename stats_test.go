@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 )
 
 const statsTestData = `{
@@ -94,3 +95,22 @@ func TestGetStats(t *testing.T) {
 		t.Errorf("Expected next: %v, got: %v", exp, res.NextTopology())
 	}
 }
+
+func TestStatsUptime(t *testing.T) {
+	t.Parallel()
+	s := &Stats{"uptime": map[string]interface{}{"_type": "L", "_value": 90.0}}
+	if s.Uptime() != 90*time.Second {
+		t.Errorf("Expected uptime: %v, got: %v", 90*time.Second, s.Uptime())
+	}
+
+	s = &Stats{"uptime": map[string]interface{}{"_type": "s", "_value": "1.5"}}
+	if s.Uptime() != 1500*time.Millisecond {
+		t.Errorf("Expected uptime: %v, got: %v", 1500*time.Millisecond,
+			s.Uptime())
+	}
+
+	s = nil
+	if s.Uptime() != 0 {
+		t.Errorf("Expected uptime: 0, got: %v", s.Uptime())
+	}
+}
@@ -0,0 +1,242 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// streamBody values are the response bodies returned by streamed requests.
+// Closing the body releases the connection and all other resources held by
+// the request.
+type streamBody struct {
+	io.Reader
+	closers []func() error
+	once    sync.Once
+	err     error
+}
+
+// Close closes the response body and releases the resources of the request.
+func (b *streamBody) Close() error {
+	b.once.Do(func() {
+		for _, f := range b.closers {
+			if err := f(); err != nil && b.err == nil {
+				b.err = err
+			}
+		}
+	})
+
+	return b.err
+}
+
+// DoRequestStream sends a request to IRONdb and returns the response body
+// without reading it into memory, allowing large read and find results to be
+// processed as they are received. The caller must close the returned body.
+// If the client is set to retry using other nodes on network failures, this
+// will perform those retries until a response is received.
+func (sc *SnowthClient) DoRequestStream(node *SnowthNode,
+	method string, url string, body io.Reader,
+	headers http.Header) (io.ReadCloser, http.Header, error) {
+	return sc.DoRequestStreamContext(context.Background(), node, method, url,
+		body, headers)
+}
+
+// DoRequestStreamContext is the context aware version of DoRequestStream.
+// The context applies to the whole request, including reading the response
+// body.
+func (sc *SnowthClient) DoRequestStreamContext(ctx context.Context,
+	node *SnowthNode, method string, url string, body io.Reader,
+	headers http.Header) (io.ReadCloser, http.Header, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	ctx, span := sc.startSpan(ctx, method, url)
+	rc, hdr, err := sc.retryStream(ctx, node, method, url, body, headers)
+	endSpan(span, err)
+	return rc, hdr, err
+}
+
+// retryStream sends a streamed request to IRONdb, retrying using other nodes
+// on failures until a response is received.
+func (sc *SnowthClient) retryStream(ctx context.Context, node *SnowthNode,
+	method string, url string, body io.Reader,
+	headers http.Header) (io.ReadCloser, http.Header, error) {
+	bBody := []byte{}
+	var err error
+	if body != nil {
+		bBody, err = ioutil.ReadAll(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read request body: %w", err)
+		}
+	}
+
+	if bBody, headers, err = sc.compressBody(method, url, bBody,
+		headers); err != nil {
+		return nil, nil, err
+	}
+
+	connRetries := sc.ConnectRetries()
+	nodes := append([]*SnowthNode{node}, sc.ListActiveNodes()...)
+	var last *SnowthNode
+	for _, sn := range nodes {
+		if sn == nil {
+			continue
+		}
+
+		if last != nil {
+			if connRetries == 0 || ctx.Err() != nil {
+				break
+			}
+
+			if !sc.allowRetry(method, url, last, err) {
+				return nil, nil, &retryBudgetError{err: err}
+			}
+
+			sc.getMetrics().retry(nodeHost(last), endpointName(url))
+			sc.LogInfow("gosnowth retrying request", "method", method,
+				"endpoint", endpointName(url), "node", nodeHost(last),
+				"retry_node", nodeHost(sn), "error", err)
+
+			connRetries--
+		}
+
+		last = sn
+		sc.LogDebugf("gosnowth attempting streamed request: %s %s %v",
+			method, url, sn)
+		var rc io.ReadCloser
+		var hdr http.Header
+		rc, hdr, err = sc.doStream(ctx, sn, method, url,
+			bytes.NewBuffer(bBody), headers)
+		if err == nil {
+			sc.depositRetry()
+			return rc, hdr, nil
+		}
+
+		sc.LogDebugf("gosnowth streamed request error: %s %s %v",
+			method, url, err)
+		if !IsRetryable(err) || !isFailoverError(err) {
+			return nil, hdr, err
+		}
+	}
+
+	return nil, nil, err
+}
+
+// doStream sends a request to IRONdb and returns the response body, which must
+// be closed by the caller, without reading it. The body of unsuccessful
+// responses is read and returned in a SnowthError.
+func (sc *SnowthClient) doStream(ctx context.Context, node *SnowthNode,
+	method, url string, body io.Reader,
+	headers http.Header) (io.ReadCloser, http.Header, error) {
+	finish, err := sc.startRequest()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cancel context.CancelFunc
+	if d := sc.operationTimeout(method, url); d > 0 {
+		ctx, cancel = context.WithTimeout(ctx, d)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+
+	done, err := sc.waitRequestQueue(ctx)
+	if err != nil {
+		cancel()
+		finish()
+		return nil, nil, err
+	}
+
+	release := func() {
+		done()
+		cancel()
+		finish()
+	}
+
+	if err := sc.waitRateLimit(ctx, node); err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	r, reqID, err := sc.newRequest(ctx, node, method, url, body, headers)
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	sc.LogDebugf("gosnowth request %s: %+v", reqID, r)
+	debugDump, dumpLimit := sc.debugDumpEnabled(ctx)
+	if debugDump {
+		sc.dumpRequest(r, reqID, dumpLimit)
+	}
+
+	start := sc.getClock().Now()
+	sc.RLock()
+	cli := sc.c
+	sc.RUnlock()
+	resp, err := sc.roundTrip(cli)(r)
+	if err != nil {
+		release()
+		se := &SnowthError{
+			Node:      r.URL.Host,
+			Method:    r.Method,
+			Endpoint:  r.URL.Path,
+			RequestID: r.Header.Get(RequestIDHeader),
+			Err:       err,
+		}
+
+		sc.recordRequest(node, r.URL.Path, start, 0, se)
+		return nil, nil, se
+	}
+
+	sc.updateTopology(node, resp.Header)
+	if resp.StatusCode != http.StatusOK {
+		res, err := readResponseBody(resp)
+		_ = resp.Body.Close()
+		release()
+		sc.recordRequest(node, r.URL.Path, start, resp.StatusCode,
+			err)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read response body: %w",
+				err)
+		}
+
+		sc.LogWarnf("error returned from IRONdb: [%d] %s (request ID: %s)",
+			resp.StatusCode, string(res), reqID)
+		return nil, resp.Header, newSnowthError(r, resp.StatusCode, res)
+	}
+
+	sc.recordRequest(node, r.URL.Path, start, resp.StatusCode, nil)
+	sc.LogDebugf("gosnowth response %s: %+v", reqID, resp)
+	sb := &streamBody{Reader: resp.Body}
+	if strings.EqualFold(resp.Header.Get(snowthapi.HeaderContentEncoding),
+		snowthapi.EncodingGzip) {
+		gr, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			_ = resp.Body.Close()
+			release()
+			return nil, nil, fmt.Errorf("unable to read response body: %w",
+				err)
+		}
+
+		sb.Reader = gr
+		sb.closers = append(sb.closers, gr.Close)
+	}
+
+	sb.closers = append(sb.closers, resp.Body.Close, func() error {
+		release()
+		return nil
+	})
+
+	return sb, resp.Header, nil
+}
@@ -0,0 +1,158 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestDoRequestStream(t *testing.T) {
+	t.Parallel()
+	data := strings.Repeat("0123456789", 100000)
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/find/1/tags?query=test" {
+			_, _ = w.Write([]byte(data))
+			return
+		}
+
+		if r.RequestURI == "/find/1/tags?query=gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			gw := gzip.NewWriter(w)
+			_, _ = gw.Write([]byte(data))
+			_ = gw.Close()
+			return
+		}
+
+		if r.RequestURI == "/find/1/tags?query=error" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid query"}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	node := sc.GetActiveNode()
+	for _, q := range []string{"test", "gzip"} {
+		rc, _, err := sc.DoRequestStream(node, "GET",
+			"/find/1/tags?query="+q, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b, err := ioutil.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := rc.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		if string(b) != data {
+			t.Errorf("Expected body length: %v, got: %v", len(data), len(b))
+		}
+	}
+
+	rc, _, err := sc.DoRequestStreamContext(context.Background(), node, "GET",
+		"/find/1/tags?query=error", nil, nil)
+	if rc != nil {
+		t.Error("Expected no body for unsuccessful response")
+	}
+
+	var se *SnowthError
+	if !errors.As(err, &se) {
+		t.Fatalf("Expected IRONdb error, got: %v", err)
+	}
+
+	if se.Status != http.StatusBadRequest || se.Message != "invalid query" {
+		t.Errorf("Expected error status: 400, invalid query, got: %v, %v",
+			se.Status, se.Message)
+	}
+
+	if err := sc.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := sc.DoRequestStream(node, "GET", "/find/1/tags?query=test",
+		nil, nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+}
+
+func TestDoRequestStreamFailover(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/read/stream" {
+			_, _ = w.Write([]byte("ok"))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse("http://127.0.0.1:1")
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	sc.SetConnectRetries(1)
+	down := &SnowthNode{url: u, identifier: "down"}
+	rc, _, err := sc.DoRequestStream(down, "GET", "/read/stream", nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "ok" {
+		t.Errorf("Expected body: ok, got: %v", string(b))
+	}
+}
@@ -0,0 +1,175 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// StreamWriteOptions values contain optional parameters used to control the
+// behavior of a streaming write.
+type StreamWriteOptions struct {
+	// BatchSize is the number of datapoints which causes the encoded data to
+	// be written in a single request. The default is 1000.
+	BatchSize int
+
+	// FlushInterval is the maximum duration datapoints are held before they
+	// are written. The default is one second.
+	FlushInterval time.Duration
+
+	// OnError, if set, is called with the error and the number of datapoints
+	// dropped when a datapoint cannot be encoded or a write request fails.
+	OnError func(err error, records int)
+}
+
+// streamWriter values hold the state of a streaming write.
+type streamWriter struct {
+	sc    *SnowthClient
+	opts  StreamWriteOptions
+	nodes []*SnowthNode
+	ws    WriteSummary
+	first int
+	recs  [][]byte
+	names []string
+	size  int64
+}
+
+// fail records a failed chunk of the stream.
+func (w *streamWriter) fail(first, records int, err error) {
+	w.ws.add(WriteChunk{First: first, Records: records, Err: err}, nil)
+	if w.opts.OnError != nil {
+		w.opts.OnError(err, records)
+	}
+}
+
+// flush writes the encoded datapoints held by the writer, splitting them into
+// multiple requests if they exceed the maximum write payload size.
+func (w *streamWriter) flush(ctx context.Context) {
+	if len(w.recs) == 0 {
+		return
+	}
+
+	first, n := w.first, len(w.recs)
+	recs, names, size := w.recs, w.names, w.size
+	w.first += n
+	w.recs, w.names, w.size = nil, nil, 0
+	if ctx.Err() != nil {
+		w.fail(first, n, fmt.Errorf("context terminated: %w", ctx.Err()))
+		return
+	}
+
+	if err := w.sc.checkCardinality(names, nil); err != nil {
+		w.fail(first, n, err)
+		return
+	}
+
+	max := w.sc.getMaxWritePayload()
+	if max <= 0 {
+		max = size + int64(n) + 3
+	}
+
+	chunks, err := splitJSONRecords(recs, max)
+	if err != nil {
+		w.fail(first, n, err)
+		return
+	}
+
+	for _, c := range chunks {
+		wc := WriteChunk{First: first + c.first, Records: c.records,
+			Bytes: c.buf.Len()}
+		var r *IRONdbPutResponse
+		if r, wc.Err = w.sc.writeRaw(ctx, c.buf, snowthapi.ContentTypeJSON,
+			uint64(c.records), w.nodes...); wc.Err != nil {
+			w.fail(wc.First, wc.Records, wc.Err)
+			continue
+		}
+
+		w.ws.add(wc, r)
+	}
+}
+
+// StreamWrite writes datapoints received from a channel to the IRONdb raw
+// write endpoint in JSON format, until the channel is closed. Datapoints are
+// encoded as they are received, and written in batches when the batch size is
+// reached or the flush interval elapses, so long running collectors can write
+// steady streams of data without holding them in memory. Failed batches are
+// dropped, and reported to the OnError function of the options. The NaN and
+// infinite value policy and write precision of the client are applied to
+// floating point values, and datapoints dropped by the policy are skipped
+// without being reported. The returned response contains the combined
+// results of all batches, and if any batch failed, the returned error is a
+// WriteSummary describing the failures.
+func (sc *SnowthClient) StreamWrite(data <-chan RawMetric,
+	options *StreamWriteOptions,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	return sc.StreamWriteContext(context.Background(), data, options,
+		nodes...)
+}
+
+// StreamWriteContext is the context aware version of StreamWrite. If the
+// context is cancelled, the datapoints held by the writer are dropped, and
+// the write returns without waiting for the channel to be closed.
+func (sc *SnowthClient) StreamWriteContext(ctx context.Context,
+	data <-chan RawMetric, options *StreamWriteOptions,
+	nodes ...*SnowthNode) (*IRONdbPutResponse, error) {
+	opts := StreamWriteOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultBatchSize
+	}
+
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = defaultBatchFlushInterval
+	}
+
+	w := &streamWriter{sc: sc, opts: opts, nodes: nodes}
+	wp := sc.getWritePolicy()
+	clk := sc.getClock()
+	tick := clk.NewTicker(opts.FlushInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			w.flush(ctx)
+			return &w.ws.Response, w.ws.err()
+		case <-tick.C():
+			w.flush(ctx)
+		case m, ok := <-data:
+			if !ok {
+				w.flush(ctx)
+				return &w.ws.Response, w.ws.err()
+			}
+
+			b, name, err := encodeRawMetric(m, clk.Now(), wp)
+			if err != nil {
+				w.flush(ctx)
+				w.fail(w.first, 1, fmt.Errorf("raw metric %d: %w", w.first,
+					err))
+				w.first++
+				continue
+			}
+
+			if b == nil {
+				continue
+			}
+
+			max := sc.getMaxWritePayload()
+			if max > 0 && w.size+int64(len(b)+len(w.recs)+3) > max {
+				w.flush(ctx)
+			}
+
+			w.recs = append(w.recs, b)
+			w.names = append(w.names, name)
+			w.size += int64(len(b))
+			if len(w.recs) >= opts.BatchSize {
+				w.flush(ctx)
+			}
+		}
+	}
+}
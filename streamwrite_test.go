@@ -0,0 +1,187 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStreamWrite(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	batches := []int{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			data := []map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				t.Error(err)
+			}
+
+			mu.Lock()
+			batches = append(batches, len(data))
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{"records":` + strconv.Itoa(len(data)) +
+				`,"updated":0,"misdirected":0,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	ch := make(chan RawMetric)
+	go func() {
+		defer close(ch)
+		for i := 0; i < 6; i++ {
+			m := RawMetric{
+				AccountID: 1,
+				CheckUUID: "fc85e0ab-f568-45e6-86ee-d7443be8277d",
+				CheckName: "test",
+				Metric:    "test" + strconv.Itoa(i),
+				Timestamp: time.Unix(1529509020, 0),
+				Value:     i,
+			}
+
+			if i == 2 {
+				m.Value = struct{}{}
+			}
+
+			ch <- m
+		}
+	}()
+
+	dropped := 0
+	res, err := sc.StreamWrite(ch, &StreamWriteOptions{
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		OnError: func(err error, records int) {
+			dropped += records
+		},
+	}, &SnowthNode{url: u})
+	ws := &WriteSummary{}
+	if !errors.As(err, &ws) {
+		t.Fatalf("Expected error type: %T, got: %v", ws, err)
+	}
+
+	if ws.Failed != 1 || ws.Records != 5 {
+		t.Errorf("Expected failed: 1, records: 5, got: %v, %v", ws.Failed,
+			ws.Records)
+	}
+
+	if dropped != 1 {
+		t.Errorf("Expected dropped: 1, got: %v", dropped)
+	}
+
+	if res.Records != 5 {
+		t.Errorf("Expected records: 5, got: %v", res.Records)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(batches) != 3 || batches[0] != 2 || batches[1] != 2 ||
+		batches[2] != 1 {
+		t.Errorf("Expected batches: [2 2 1], got: %v", batches)
+	}
+}
+
+func TestStreamWriteInterval(t *testing.T) {
+	t.Parallel()
+	written := make(chan int, 1)
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/raw") {
+			data := []map[string]interface{}{}
+			if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+				t.Error(err)
+			}
+
+			written <- len(data)
+			_, _ = w.Write([]byte(`{"records":` + strconv.Itoa(len(data)) +
+				`,"updated":0,"misdirected":0,"errors":0}`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	ch := make(chan RawMetric, 1)
+	done := make(chan error, 1)
+	go func() {
+		_, err := sc.StreamWrite(ch, &StreamWriteOptions{
+			FlushInterval: 10 * time.Millisecond,
+		}, &SnowthNode{url: u})
+		done <- err
+	}()
+
+	ch <- RawMetric{Metric: "test", Value: 1.5}
+	select {
+	case n := <-written:
+		if n != 1 {
+			t.Errorf("Expected records: 1, got: %v", n)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected write after flush interval")
+	}
+
+	close(ch)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected stream write to return after channel closed")
+	}
+}
@@ -0,0 +1,95 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// SurrogateEntry values represent an entry in the IRONdb surrogate database,
+// which maps a metric, identified by account ID, check UUID, and canonical
+// metric name, to the internal ID used to store its data.
+type SurrogateEntry struct {
+	SurrogateID uint64 `json:"surrogate_id"`
+	AccountID   int64  `json:"account_id"`
+	UUID        string `json:"check_uuid"`
+	MetricName  string `json:"metric_name"`
+}
+
+// LookupSurrogateID finds the surrogate database entry, including the
+// internal surrogate ID, of a metric. Surrogate IDs are assigned separately by
+// each node, so the ID returned is only valid on the node which was queried.
+// If no node is specified, the query is sent to a node which owns the metric.
+func (sc *SnowthClient) LookupSurrogateID(accountID int64, uuid, metric string,
+	nodes ...*SnowthNode) (*SurrogateEntry, error) {
+	return sc.LookupSurrogateIDContext(context.Background(), accountID, uuid,
+		metric, nodes...)
+}
+
+// LookupSurrogateIDContext is the context aware version of LookupSurrogateID.
+func (sc *SnowthClient) LookupSurrogateIDContext(ctx context.Context,
+	accountID int64, uuid, metric string,
+	nodes ...*SnowthNode) (*SurrogateEntry, error) {
+	if uuid == "" || metric == "" {
+		return nil, fmt.Errorf("check uuid and metric name required")
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.readNode(ctx, uuid, metric)
+	}
+
+	q := url.Values{}
+	q.Set("account_id", strconv.FormatInt(accountID, 10))
+	q.Set("check_uuid", uuid)
+	q.Set("metric_name", metric)
+	return sc.lookupSurrogate(ctx, node,
+		snowthapi.PathSurrogateLookup+"?"+q.Encode())
+}
+
+// LookupSurrogate finds the surrogate database entry with the specified
+// internal surrogate ID, returning the account ID, check UUID, and canonical
+// metric name of the metric it identifies. Since surrogate IDs are assigned
+// separately by each node, the lookup should be sent to the node from which
+// the ID was obtained. An error matching ErrNotFound is returned if the node
+// has no entry with the ID.
+func (sc *SnowthClient) LookupSurrogate(id uint64,
+	nodes ...*SnowthNode) (*SurrogateEntry, error) {
+	return sc.LookupSurrogateContext(context.Background(), id, nodes...)
+}
+
+// LookupSurrogateContext is the context aware version of LookupSurrogate.
+func (sc *SnowthClient) LookupSurrogateContext(ctx context.Context, id uint64,
+	nodes ...*SnowthNode) (*SurrogateEntry, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	return sc.lookupSurrogate(ctx, node, snowthapi.PathSurrogateLookup+
+		"?surrogate_id="+strconv.FormatUint(id, 10))
+}
+
+// lookupSurrogate sends a surrogate database lookup request to a node.
+func (sc *SnowthClient) lookupSurrogate(ctx context.Context, node *SnowthNode,
+	u string) (*SurrogateEntry, error) {
+	body, _, err := sc.DoRequestContext(ctx, node, "GET", u, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &SurrogateEntry{}
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	return r, nil
+}
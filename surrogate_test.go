@@ -0,0 +1,92 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+const surrogateTestData = `{
+	"surrogate_id": 4821,
+	"account_id": 1,
+	"check_uuid": "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+	"metric_name": "test|ST[a:b]"
+}`
+
+func TestLookupSurrogate(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/surrogate/lookup?account_id=1&check_uuid="+
+			"1f846f26-0cfd-4df5-b4f1-e0930604e577&metric_name=test%7CST%5Ba"+
+			"%3Ab%5D" || r.RequestURI == "/surrogate/lookup?surrogate_id=4821" {
+			_, _ = w.Write([]byte(surrogateTestData))
+			return
+		}
+
+		if r.RequestURI == "/surrogate/lookup?surrogate_id=1" {
+			w.WriteHeader(404)
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	exp := SurrogateEntry{
+		SurrogateID: 4821,
+		AccountID:   1,
+		UUID:        "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		MetricName:  "test|ST[a:b]",
+	}
+
+	res, err := sc.LookupSurrogateID(1, "1f846f26-0cfd-4df5-b4f1-e0930604e577",
+		"test|ST[a:b]", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *res != exp {
+		t.Errorf("Expected entry: %+v, got: %+v", exp, *res)
+	}
+
+	if res, err = sc.LookupSurrogate(4821, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if *res != exp {
+		t.Errorf("Expected entry: %+v, got: %+v", exp, *res)
+	}
+
+	if _, err := sc.LookupSurrogate(1, node); !IsNotFound(err) {
+		t.Errorf("Expected not found error, got: %v", err)
+	}
+
+	if _, err := sc.LookupSurrogateID(1, "", "test", node); err == nil {
+		t.Error("Expected error for missing uuid")
+	}
+}
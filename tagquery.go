@@ -0,0 +1,138 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tagQueryCategoryRE matches reserved tag categories, such as __name, which
+// are written literally in tag queries.
+var tagQueryCategoryRE = regexp.MustCompile(`^__[a-z_]+$`)
+
+// TagQuery values are composable parts of an IRONdb tag search query, built
+// using Tag, TagWildcard, TagRegex, And, Or, and Not. Categories and values
+// are encoded when the query is built, so they can contain any characters.
+// Errors in any part of a query are returned when the query is built.
+type TagQuery struct {
+	q   string
+	err error
+}
+
+// Build returns the rendered tag query, or an error if any part of the query
+// was invalid.
+func (tq TagQuery) Build() (string, error) {
+	if tq.err != nil {
+		return "", tq.err
+	}
+
+	if tq.q == "" {
+		return "", fmt.Errorf("tag query cannot be empty")
+	}
+
+	return tq.q, nil
+}
+
+// String returns the rendered tag query, ignoring any errors.
+func (tq TagQuery) String() string {
+	return tq.q
+}
+
+// tagQueryCategory encodes a tag category for use in a tag query.
+func tagQueryCategory(cat string) (string, error) {
+	if cat == "" {
+		return "", fmt.Errorf("tag category cannot be empty")
+	}
+
+	if tagQueryCategoryRE.MatchString(cat) {
+		return cat, nil
+	}
+
+	return encodeTagLiteral(cat), nil
+}
+
+// Tag returns a tag query matching metrics with a tag of the specified
+// category and exact value. The reserved __name category matches the metric
+// name.
+func Tag(cat, val string) TagQuery {
+	c, err := tagQueryCategory(cat)
+	if err != nil {
+		return TagQuery{err: err}
+	}
+
+	return TagQuery{q: c + ":" + encodeTagLiteral(val)}
+}
+
+// TagRegex returns a tag query matching metrics with a tag of the specified
+// category and a value matching a regular expression. The expression is not
+// anchored, so it can match any part of the value.
+func TagRegex(cat, re string) TagQuery {
+	c, err := tagQueryCategory(cat)
+	if err != nil {
+		return TagQuery{err: err}
+	}
+
+	if _, err := regexp.Compile(re); err != nil {
+		return TagQuery{err: fmt.Errorf("invalid tag query regular "+
+			"expression: %s: %w", re, err)}
+	}
+
+	return TagQuery{q: c + ":" + encodeTagRegex(re)}
+}
+
+// TagWildcard returns a tag query matching metrics with a tag of the
+// specified category and a value matching a wildcard pattern, in which *
+// matches any sequence of characters and ? matches any single character.
+func TagWildcard(cat, pattern string) TagQuery {
+	sb := strings.Builder{}
+	sb.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteString(".")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	sb.WriteString("$")
+	return TagRegex(cat, sb.String())
+}
+
+// tagQueryOp returns a tag query applying an operator to a list of queries.
+func tagQueryOp(op string, qs []TagQuery) TagQuery {
+	if len(qs) == 0 {
+		return TagQuery{err: fmt.Errorf("%s() requires at least one query",
+			op)}
+	}
+
+	parts := make([]string, len(qs))
+	for i, q := range qs {
+		s, err := q.Build()
+		if err != nil {
+			return TagQuery{err: err}
+		}
+
+		parts[i] = s
+	}
+
+	return TagQuery{q: op + "(" + strings.Join(parts, ",") + ")"}
+}
+
+// And returns a tag query matching metrics which match all of the queries.
+func And(qs ...TagQuery) TagQuery {
+	return tagQueryOp("and", qs)
+}
+
+// Or returns a tag query matching metrics which match any of the queries.
+func Or(qs ...TagQuery) TagQuery {
+	return tagQueryOp("or", qs)
+}
+
+// Not returns a tag query matching metrics which do not match a query.
+func Not(q TagQuery) TagQuery {
+	return tagQueryOp("not", []TagQuery{q})
+}
@@ -0,0 +1,47 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestTagQuery(t *testing.T) {
+	t.Parallel()
+	b64 := func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	}
+
+	q, err := And(Tag("__name", "cpu"), Tag("service", "api"),
+		Not(Tag("env", "dev")),
+		Or(TagWildcard("host", "web-*.example"), TagRegex("dc", "east|west")),
+	).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := `and(__name:b"` + b64("cpu") + `",b"` + b64("service") + `":b"` +
+		b64("api") + `",not(b"` + b64("env") + `":b"` + b64("dev") +
+		`"),or(b"` + b64("host") + `":b/` + b64(`^web-.*\.example$`) +
+		`/,b"` + b64("dc") + `":b/` + b64("east|west") + `/))`
+	if q != exp {
+		t.Errorf("Expected query: %v, got: %v", exp, q)
+	}
+
+	if s := Tag("a:b", "c,d").String(); s != `b"`+b64("a:b")+`":b"`+
+		b64("c,d")+`"` {
+		t.Errorf("Unexpected encoded tag: %v", s)
+	}
+
+	for _, tq := range []TagQuery{
+		{},
+		And(),
+		Tag("", "a"),
+		TagRegex("a", "("),
+		And(Tag("a", "b"), Not(TagRegex("c", "["))),
+	} {
+		if _, err := tq.Build(); err == nil {
+			t.Errorf("Expected error for query: %v", tq)
+		}
+	}
+}
@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strconv"
 	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // FindTagsItem values represent results returned from IRONdb tag queries.
@@ -27,6 +30,15 @@ type FindTagsResult struct {
 	Items     []FindTagsItem
 	FindCount *FindTagsCount
 	Count     int64
+
+	// Truncated is set when IRONdb reports more matching metrics than were
+	// returned, because the advisory limit of the request, or the default
+	// limit of the node, was applied to the results.
+	Truncated bool
+
+	// Info describes the completeness of the results. It is not set for
+	// count only requests.
+	Info *SearchResultInfo
 }
 
 // FindTagsCount values represent results from count only requests.
@@ -36,14 +48,45 @@ type FindTagsCount struct {
 }
 
 // FindTagsOptions values contain optional parameters to be passed to the
-// IRONdb find tags call by a FindTags operation.
+// IRONdb find tags call by a FindTags operation. Options can be nil, in which
+// case the options returned by DefaultFindTagsOptions are used.
 type FindTagsOptions struct {
+	// Start and End limit the activity data returned to a time window, if
+	// both are set.
 	Start     time.Time `json:"activity_start_secs"`
 	End       time.Time `json:"activity_end_secs"`
 	Activity  int64     `json:"activity"`
 	Latest    int64     `json:"latest"`
 	CountOnly int64     `json:"count_only"`
 	Limit     int64     `json:"limit"`
+
+	// Offset skips a number of results, so that large result sets can be
+	// retrieved in pages of Limit results.
+	Offset int64 `json:"offset"`
+
+	// Sort orders the results by a field, such as "metric_name".
+	Sort string `json:"sort"`
+
+	// Extra contains any additional query parameters to send, so that server
+	// options not otherwise supported by this struct can be used.
+	Extra url.Values `json:"-"`
+}
+
+// DefaultFindTagsOptions returns the default options of find tags requests,
+// which request activity data, but not latest values.
+func DefaultFindTagsOptions() *FindTagsOptions {
+	return &FindTagsOptions{Activity: 1}
+}
+
+// findTagsOptions returns a copy of find tags options, or the default options
+// if they are nil.
+func findTagsOptions(options *FindTagsOptions) *FindTagsOptions {
+	if options == nil {
+		return DefaultFindTagsOptions()
+	}
+
+	opts := *options
+	return &opts
 }
 
 // FindTagsLatest values contain the most recent data values for a metric.
@@ -190,6 +233,40 @@ func (ftl *FindTagsLatestHistogram) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// findTagsRequest returns the URL and headers of a find tags request.
+func (sc *SnowthClient) findTagsRequest(node *SnowthNode, accountID int64,
+	query string, options *FindTagsOptions) (string, http.Header) {
+	u := fmt.Sprintf("%s?query=%s",
+		sc.getURL(node, fmt.Sprintf("%s/%d/tags", snowthapi.PathFind,
+			accountID)),
+		url.QueryEscape(query))
+	if !options.Start.IsZero() && !options.End.IsZero() &&
+		options.Start.Unix() != 0 && options.End.Unix() != 0 {
+		u += fmt.Sprintf("&activity_start_secs=%s&activity_end_secs=%s",
+			formatTimestamp(options.Start), formatTimestamp(options.End))
+	}
+
+	u += fmt.Sprintf("&activity=%d", options.Activity)
+	u += fmt.Sprintf("&latest=%d", options.Latest)
+	if options.CountOnly != 0 {
+		u += fmt.Sprintf("&count_only=%d", options.CountOnly)
+	}
+
+	if options.Offset != 0 {
+		u += fmt.Sprintf("&offset=%d", options.Offset)
+	}
+
+	if options.Sort != "" {
+		u += "&sort=" + url.QueryEscape(options.Sort)
+	}
+
+	if len(options.Extra) > 0 {
+		u += "&" + options.Extra.Encode()
+	}
+
+	return u, searchHeaders(options.Limit)
+}
+
 // FindTags retrieves metrics that are associated with the provided tag query.
 func (sc *SnowthClient) FindTags(accountID int64, query string,
 	options *FindTagsOptions, nodes ...*SnowthNode) (*FindTagsResult, error) {
@@ -201,6 +278,7 @@ func (sc *SnowthClient) FindTags(accountID int64, query string,
 func (sc *SnowthClient) FindTagsContext(ctx context.Context, accountID int64,
 	query string, options *FindTagsOptions,
 	nodes ...*SnowthNode) (*FindTagsResult, error) {
+	options = findTagsOptions(options)
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
@@ -208,52 +286,332 @@ func (sc *SnowthClient) FindTagsContext(ctx context.Context, accountID int64,
 		node = sc.GetActiveNode()
 	}
 
-	u := fmt.Sprintf("%s?query=%s",
-		sc.getURL(node, fmt.Sprintf("/find/%d/tags", accountID)),
-		url.QueryEscape(query))
-	if !options.Start.IsZero() && !options.End.IsZero() &&
-		options.Start.Unix() != 0 && options.End.Unix() != 0 {
-		u += fmt.Sprintf("&activity_start_secs=%s&activity_end_secs=%s",
-			formatTimestamp(options.Start), formatTimestamp(options.End))
+	u, hdrs := sc.findTagsRequest(node, accountID, query, options)
+	r := &FindTagsResult{}
+	body, header, err := sc.doRequest(ctx, node, sc.activeNodeIDs(), "GET",
+		u, nil, hdrs)
+	if err != nil {
+		return nil, err
 	}
 
-	u += fmt.Sprintf("&activity=%d", options.Activity)
-	u += fmt.Sprintf("&latest=%d", options.Latest)
 	if options.CountOnly != 0 {
-		u += fmt.Sprintf("&count_only=%d", options.CountOnly)
+		if err := sc.decodeResponse(body, &r.FindCount); err != nil {
+			return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+		}
+
+		r.Count = findTagsCount(header, 0)
+		return r, nil
 	}
 
-	hdrs := http.Header{}
-	if options.Limit != 0 {
-		hdrs.Set("X-Snowth-Advisory-Limit", strconv.FormatInt(options.Limit, 10))
+	// Items are decoded one at a time from the response, to avoid holding
+	// the decoded JSON of the whole response in memory.
+	r.Items = []FindTagsItem{}
+	n, err := sc.decodeFindTagsItems(body, func(item FindTagsItem) error {
+		r.Items = append(r.Items, item)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
-	r := &FindTagsResult{}
-	body, header, err := sc.DoRequestContext(ctx, node, "GET", u, nil, hdrs)
+	r.Info = newSearchResultInfo(header, options.Limit, n)
+	r.Count = r.Info.Count
+	r.Truncated = r.Info.Truncated
+	return r, nil
+}
+
+// CountTags retrieves the number of metrics that are associated with the
+// provided tag query, without retrieving the metrics themselves.
+func (sc *SnowthClient) CountTags(accountID int64, query string,
+	options *FindTagsOptions, nodes ...*SnowthNode) (*FindTagsCount, error) {
+	return sc.CountTagsContext(context.Background(), accountID, query,
+		options, nodes...)
+}
+
+// CountTagsContext is the context aware version of CountTags.
+func (sc *SnowthClient) CountTagsContext(ctx context.Context,
+	accountID int64, query string, options *FindTagsOptions,
+	nodes ...*SnowthNode) (*FindTagsCount, error) {
+	opts := findTagsOptions(options)
+	opts.CountOnly = 1
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	u, hdrs := sc.findTagsRequest(node, accountID, query, opts)
+	body, header, err := sc.doRequest(ctx, node, sc.activeNodeIDs(), "GET",
+		u, nil, hdrs)
 	if err != nil {
 		return nil, err
 	}
 
-	if options.CountOnly != 0 {
-		if err := decodeJSON(body, &r.FindCount); err != nil {
-			return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	r := &FindTagsCount{}
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	// Some nodes report the count only in the search result count header.
+	if r.Count == 0 {
+		r.Count = findTagsCount(header, 0)
+	}
+
+	return r, nil
+}
+
+// decodeFindTagsItems decodes the items of a find tags response one at a time,
+// calling fn with each, and returns the number of items decoded. Items are
+// decoded using the schema mode of the client.
+func (sc *SnowthClient) decodeFindTagsItems(r io.Reader,
+	fn func(item FindTagsItem) error) (int64, error) {
+	if r == nil {
+		return 0, fmt.Errorf("unable to decode from nil reader")
+	}
+
+	dec := json.NewDecoder(r)
+	strict := sc.strictSchema()
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := expectDelim(dec, '['); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	for dec.More() {
+		item := FindTagsItem{}
+		if err := dec.Decode(&item); err != nil {
+			if strict {
+				return n, schemaError(err)
+			}
+
+			return n, err
 		}
-	} else {
-		if err := decodeJSON(body, &r.Items); err != nil {
-			return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+
+		n++
+		if err := fn(item); err != nil {
+			return n, err
 		}
 	}
 
-	// Return a results count and capture it from the header , if provided.
-	r.Count = int64(len(r.Items))
+	return n, expectDelim(dec, ']')
+}
+
+// findTagsCount returns the total number of results of a find tags request,
+// from the search result count header if provided, or n otherwise.
+func findTagsCount(header http.Header, n int64) int64 {
 	if header != nil {
-		c := header.Get("X-Snowth-Search-Result-Count")
-		if c != "" {
+		if c := header.Get(snowthapi.HeaderSearchResultCount); c != "" {
 			if cv, err := strconv.ParseInt(c, 10, 64); err == nil {
-				r.Count = cv
+				return cv
 			}
 		}
 	}
 
+	return n
+}
+
+// FindTagsStream retrieves metrics that are associated with the provided tag
+// query, decoding the response as it is received and calling fn with each
+// metric found, so that large result sets need not be held in memory. The
+// returned result contains the count of metrics, but no items. Decoding stops
+// if fn returns an error, and the error is returned. Count only requests are
+// not supported.
+func (sc *SnowthClient) FindTagsStream(accountID int64, query string,
+	options *FindTagsOptions, fn func(item FindTagsItem) error,
+	nodes ...*SnowthNode) (*FindTagsResult, error) {
+	return sc.FindTagsStreamContext(context.Background(), accountID, query,
+		options, fn, nodes...)
+}
+
+// FindTagsStreamContext is the context aware version of FindTagsStream.
+func (sc *SnowthClient) FindTagsStreamContext(ctx context.Context,
+	accountID int64, query string, options *FindTagsOptions,
+	fn func(item FindTagsItem) error,
+	nodes ...*SnowthNode) (*FindTagsResult, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("stream function required")
+	}
+
+	opts := findTagsOptions(options)
+	if opts.CountOnly != 0 {
+		return nil, fmt.Errorf("count only requests cannot be streamed")
+	}
+
+	return sc.findTagsStream(ctx, accountID, query, opts, fn, nodes...)
+}
+
+// findTagsStream sends a find tags request, decoding the items of the
+// response as they are received and calling fn with each.
+func (sc *SnowthClient) findTagsStream(ctx context.Context, accountID int64,
+	query string, options *FindTagsOptions, fn func(item FindTagsItem) error,
+	nodes ...*SnowthNode) (*FindTagsResult, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	u, hdrs := sc.findTagsRequest(node, accountID, query, options)
+	body, header, err := sc.DoRequestStreamContext(ctx, node, "GET", u, nil,
+		hdrs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer body.Close()
+	var fnErr error
+	n, err := sc.decodeFindTagsItems(body, func(item FindTagsItem) error {
+		fnErr = fn(item)
+		return fnErr
+	})
+	if fnErr != nil {
+		return nil, fnErr
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	info := newSearchResultInfo(header, options.Limit, n)
+	return &FindTagsResult{
+		Count:     info.Count,
+		Truncated: info.Truncated,
+		Info:      info,
+	}, nil
+}
+
+// FindTagCats retrieves the tag categories of the metrics which match a tag
+// query. Encoded categories are decoded, so the returned categories can be
+// used directly in tag queries built with Tag or other builder functions.
+func (sc *SnowthClient) FindTagCats(accountID int64, query string,
+	nodes ...*SnowthNode) ([]string, error) {
+	return sc.FindTagCatsContext(context.Background(), accountID, query,
+		nodes...)
+}
+
+// FindTagCatsContext is the context aware version of FindTagCats.
+func (sc *SnowthClient) FindTagCatsContext(ctx context.Context,
+	accountID int64, query string, nodes ...*SnowthNode) ([]string, error) {
+	r, _, err := sc.FindTagCatsInfoContext(ctx, accountID, query, nil,
+		nodes...)
 	return r, err
 }
+
+// FindTagCatsInfo retrieves the tag categories of the metrics which match a
+// tag query, as FindTagCats does, applying the search options, and also
+// returns information about the completeness of the results.
+func (sc *SnowthClient) FindTagCatsInfo(accountID int64, query string,
+	options *SearchOptions,
+	nodes ...*SnowthNode) ([]string, *SearchResultInfo, error) {
+	return sc.FindTagCatsInfoContext(context.Background(), accountID, query,
+		options, nodes...)
+}
+
+// FindTagCatsInfoContext is the context aware version of FindTagCatsInfo.
+func (sc *SnowthClient) FindTagCatsInfoContext(ctx context.Context,
+	accountID int64, query string, options *SearchOptions,
+	nodes ...*SnowthNode) ([]string, *SearchResultInfo, error) {
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	u := fmt.Sprintf("%s?query=%s",
+		sc.getURL(node, fmt.Sprintf("%s/%d/tag_cats", snowthapi.PathFind,
+			accountID)),
+		url.QueryEscape(query))
+	return sc.findTagParts(ctx, node, u, options)
+}
+
+// findTagParts sends a request for tag categories or values, and returns the
+// decoded results.
+func (sc *SnowthClient) findTagParts(ctx context.Context, node *SnowthNode,
+	u string, options *SearchOptions) ([]string, *SearchResultInfo, error) {
+	opts := SearchOptions{}
+	if options != nil {
+		opts = *options
+	}
+
+	body, header, err := sc.doRequest(ctx, node, sc.activeNodeIDs(), "GET", u,
+		nil, searchHeaders(opts.Limit))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r := []string{}
+	if err := sc.decodeResponse(body, &r); err != nil {
+		return nil, nil, fmt.Errorf("unable to decode IRONdb response: %w",
+			err)
+	}
+
+	for i, s := range r {
+		r[i] = decodeTagPart(s)
+	}
+
+	return r, newSearchResultInfo(header, opts.Limit, int64(len(r))), nil
+}
+
+// FindTagVals retrieves the distinct values of a tag category among the
+// metrics which match a tag query. Categories containing characters which
+// IRONdb requires to be encoded are encoded, and encoded values are decoded.
+func (sc *SnowthClient) FindTagVals(accountID int64, category, query string,
+	nodes ...*SnowthNode) ([]string, error) {
+	return sc.FindTagValsContext(context.Background(), accountID, category,
+		query, nodes...)
+}
+
+// FindTagValsContext is the context aware version of FindTagVals.
+func (sc *SnowthClient) FindTagValsContext(ctx context.Context,
+	accountID int64, category, query string,
+	nodes ...*SnowthNode) ([]string, error) {
+	r, _, err := sc.FindTagValsInfoContext(ctx, accountID, category, query,
+		nil, nodes...)
+	return r, err
+}
+
+// FindTagValsInfo retrieves the distinct values of a tag category among the
+// metrics which match a tag query, as FindTagVals does, applying the search
+// options, and also returns information about the completeness of the results.
+func (sc *SnowthClient) FindTagValsInfo(accountID int64, category,
+	query string, options *SearchOptions,
+	nodes ...*SnowthNode) ([]string, *SearchResultInfo, error) {
+	return sc.FindTagValsInfoContext(context.Background(), accountID,
+		category, query, options, nodes...)
+}
+
+// FindTagValsInfoContext is the context aware version of FindTagValsInfo.
+func (sc *SnowthClient) FindTagValsInfoContext(ctx context.Context,
+	accountID int64, category, query string, options *SearchOptions,
+	nodes ...*SnowthNode) ([]string, *SearchResultInfo, error) {
+	if category == "" {
+		return nil, nil, fmt.Errorf("tag category required")
+	}
+
+	var node *SnowthNode
+	if len(nodes) > 0 && nodes[0] != nil {
+		node = nodes[0]
+	} else {
+		node = sc.GetActiveNode()
+	}
+
+	return sc.findTagParts(ctx, node,
+		sc.findTagValsURL(node, accountID, category, query), options)
+}
+
+// findTagValsURL returns the URL of a request for the values of a tag
+// category.
+func (sc *SnowthClient) findTagValsURL(node *SnowthNode, accountID int64,
+	category, query string) string {
+	return fmt.Sprintf("%s?category=%s&query=%s",
+		sc.getURL(node, fmt.Sprintf("%s/%d/tag_vals", snowthapi.PathFind,
+			accountID)),
+		url.QueryEscape(canonicalTagPart(category, false)),
+		url.QueryEscape(query))
+}
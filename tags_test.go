@@ -4,9 +4,11 @@ package gosnowth
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -108,6 +110,12 @@ func TestFindTags(t *testing.T) {
 			return
 		}
 
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=truncated") {
+			w.Header().Set("X-Snowth-Search-Result-Count", "3")
+			_, _ = w.Write([]byte(tagsTestData))
+			return
+		}
+
 		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=test") {
 			w.Header().Set("X-Snowth-Search-Result-Count", "1")
 			_, _ = w.Write([]byte(tagsTestData))
@@ -143,6 +151,15 @@ func TestFindTags(t *testing.T) {
 		t.Fatalf("Expected result count: 1, got: %v", res.Count)
 	}
 
+	cnt, err := sc.CountTags(1, "test", nil, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cnt.Count != 22 {
+		t.Errorf("Expected count: 22, got: %v", cnt.Count)
+	}
+
 	res, err = sc.FindTags(1, "test", &FindTagsOptions{
 		Start:     time.Unix(1, 0),
 		End:       time.Unix(2, 0),
@@ -237,4 +254,283 @@ func TestFindTags(t *testing.T) {
 		t.Fatalf("Expected activity timestamp: 1561848300, got %v",
 			res.Items[0].Activity[1][1])
 	}
+
+	if res.Truncated {
+		t.Error("Expected untruncated result")
+	}
+
+	res, err = sc.FindTags(1, "truncated", &FindTagsOptions{Limit: 1}, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.Count != 3 {
+		t.Errorf("Expected result count: 3, got: %v", res.Count)
+	}
+
+	if !res.Truncated {
+		t.Error("Expected truncated result")
+	}
+}
+
+func TestFindTagsStream(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=test") {
+			w.Header().Set("X-Snowth-Search-Result-Count", "2")
+			_, _ = w.Write([]byte(tagsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	items := []FindTagsItem{}
+	res, err := sc.FindTagsStream(1, "test", nil,
+		func(item FindTagsItem) error {
+			items = append(items, item)
+			return nil
+		}, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(items) != 1 ||
+		items[0].UUID != "3aa57ac2-28de-4ec4-aa3d-ed0ddd48fa4d" {
+		t.Errorf("Unexpected items: %+v", items)
+	}
+
+	if res.Count != 2 || !res.Truncated || res.Items != nil {
+		t.Errorf("Unexpected result: %+v", res)
+	}
+
+	stop := errors.New("stop")
+	if _, err := sc.FindTagsStream(1, "test", nil,
+		func(item FindTagsItem) error {
+			return stop
+		}, node); err != stop {
+		t.Errorf("Expected error: %v, got: %v", stop, err)
+	}
+
+	if _, err := sc.FindTagsStream(1, "test", &FindTagsOptions{CountOnly: 1},
+		func(item FindTagsItem) error {
+			return nil
+		}, node); err == nil {
+		t.Error("Expected error for count only request")
+	}
+}
+
+func TestFindTagCats(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/find/1/tag_cats?query=and%28__name%3Acpu%29" {
+			if l := r.Header.Get("X-Snowth-Advisory-Limit"); l != "" {
+				w.Header().Set("X-Snowth-Search-Result-Count", "5")
+			}
+
+			_, _ = w.Write([]byte(`["__name","host","b\"YSBi\""]`))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	res, err := sc.FindTagCats(1, "and(__name:cpu)", &SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"__name", "host", "a b"}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("Expected categories: %v, got: %v", exp, res)
+	}
+
+	res, info, err := sc.FindTagCatsInfo(1, "and(__name:cpu)",
+		&SearchOptions{Limit: 3}, &SnowthNode{url: u})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("Expected categories: %v, got: %v", exp, res)
+	}
+
+	expInfo := &SearchResultInfo{Limit: 3, Returned: 3, Count: 5,
+		Truncated: true}
+	if !reflect.DeepEqual(info, expInfo) {
+		t.Errorf("Expected info: %+v, got: %+v", expInfo, info)
+	}
+}
+
+func TestFindTagVals(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.URL.Path == "/find/1/tag_vals" {
+			q := r.URL.Query()
+			if q.Get("query") != "and(__name:cpu)" {
+				t.Errorf("Expected query: and(__name:cpu), got: %v",
+					q.Get("query"))
+			}
+
+			switch q.Get("category") {
+			case "host":
+				_, _ = w.Write([]byte(`["web1","b\"d2ViIDI=\""]`))
+			case `b"YSBi"`:
+				_, _ = w.Write([]byte(`[]`))
+			default:
+				t.Errorf("Unexpected category: %v", q.Get("category"))
+				w.WriteHeader(500)
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.FindTagVals(1, "host", "and(__name:cpu)", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []string{"web1", "web 2"}
+	if !reflect.DeepEqual(res, exp) {
+		t.Errorf("Expected values: %v, got: %v", exp, res)
+	}
+
+	if res, err = sc.FindTagVals(1, "a b", "and(__name:cpu)",
+		node); err != nil || len(res) != 0 {
+		t.Errorf("Expected no values, got: %v, %v", res, err)
+	}
+
+	if _, err := sc.FindTagVals(1, "", "and(__name:cpu)", node); err == nil {
+		t.Error("Expected error for empty category")
+	}
+}
+
+func TestFindTagsOptions(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/find/1/tags?query=test&activity=1&latest=0" ||
+			r.RequestURI == "/find/1/tags?query=test&activity=0&latest=0"+
+				"&offset=20&sort=metric_name&explain=1" {
+			_, _ = w.Write([]byte(tagsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	if _, err := sc.FindTags(1, "test", nil, node); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &FindTagsOptions{
+		Offset: 20,
+		Sort:   "metric_name",
+		Extra:  url.Values{"explain": {"1"}},
+	}
+
+	if _, err := sc.FindTags(1, "test", opts, node); err != nil {
+		t.Fatal(err)
+	}
+
+	if d := DefaultFindTagsOptions(); d.Activity != 1 || d.Latest != 0 {
+		t.Errorf("Unexpected default options: %+v", d)
+	}
 }
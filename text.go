@@ -9,6 +9,8 @@ import (
 	"path"
 	"strconv"
 	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 // TextValueResponse values represent text data responses.
@@ -60,18 +62,19 @@ func (sc *SnowthClient) ReadTextValuesContext(ctx context.Context,
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(uuid, metric))
+		node = sc.readNode(ctx, uuid, metric)
 	}
 
 	r := TextValueResponse{}
-	body, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/read",
-		strconv.FormatInt(start.Unix(), 10),
-		strconv.FormatInt(end.Unix(), 10), uuid, metric), nil, nil)
+	body, _, err := sc.DoRequestContext(ctx, node, "GET",
+		path.Join(snowthapi.PathRead,
+			strconv.FormatInt(start.Unix(), 10),
+			strconv.FormatInt(end.Unix(), 10), uuid, metric), nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := decodeJSON(body, &r); err != nil {
+	if err := sc.decodeResponse(body, &r); err != nil {
 		return nil, fmt.Errorf("unable to decode IRONdb response: %w", err)
 	}
 
@@ -86,7 +89,8 @@ type TextData struct {
 	Value  string `json:"value"`
 }
 
-// WriteText writes text data to an IRONdb node.
+// WriteText writes text data to an IRONdb node. If the encoded data exceeds
+// the maximum write payload size, it is written in multiple requests.
 func (sc *SnowthClient) WriteText(data []TextData, nodes ...*SnowthNode) error {
 	return sc.WriteTextContext(context.Background(), data, nodes...)
 }
@@ -94,11 +98,29 @@ func (sc *SnowthClient) WriteText(data []TextData, nodes ...*SnowthNode) error {
 // WriteTextContext is the context aware version of WriteText.
 func (sc *SnowthClient) WriteTextContext(ctx context.Context,
 	data []TextData, nodes ...*SnowthNode) error {
+	data = append([]TextData(nil), data...)
+	names := make([]string, len(data))
+	for i := range data {
+		data[i].Metric = CanonicalMetricName(data[i].Metric)
+		names[i] = data[i].Metric
+	}
+
+	if err := sc.checkCardinality(names, nil); err != nil {
+		return err
+	}
+
+	if level := sc.getWriteConsistency(ctx); level != WriteConsistencyOne &&
+		(len(nodes) == 0 || nodes[0] == nil) {
+		return sc.writeConsistent(ctx, level, snowthapi.PathWriteText, len(data),
+			func(i int) interface{} { return data[i] },
+			func(i int) (string, string) { return data[i].ID, data[i].Metric })
+	}
+
 	var node *SnowthNode
 	if len(nodes) > 0 && nodes[0] != nil {
 		node = nodes[0]
 	} else if len(data) > 0 {
-		node = sc.GetActiveNode(sc.FindMetricNodeIDs(data[0].ID,
+		node = sc.GetActiveNode(sc.FindMetricNodeIDsContext(ctx, data[0].ID,
 			data[0].Metric))
 	}
 
@@ -107,6 +129,54 @@ func (sc *SnowthClient) WriteTextContext(ctx context.Context,
 		return fmt.Errorf("failed to encode TextData for write: %w", err)
 	}
 
-	_, _, err := sc.DoRequestContext(ctx, node, "POST", "/write/text", buf, nil)
-	return err
+	return sc.writeJSON(ctx, node, snowthapi.PathWriteText, buf, len(data),
+		func(i int) interface{} { return data[i] })
+}
+
+// LatestText values contain the most recent text value of a metric. The value
+// is never nil, and is of the same type as the Text value of a LatestValue.
+type LatestText struct {
+	UUID       string
+	MetricName string
+	CheckTags  []string
+	Time       time.Time
+	Value      *string
+}
+
+// GetLatestText retrieves the most recent text value, and its timestamp, of
+// every text metric matching a tag query. Metrics with no recent non-null text
+// value are not included in the results. Null values are skipped, as they are
+// by GetLatestValues.
+func (sc *SnowthClient) GetLatestText(accountID int64, query string,
+	nodes ...*SnowthNode) ([]LatestText, error) {
+	return sc.GetLatestTextContext(context.Background(), accountID, query,
+		nodes...)
+}
+
+// GetLatestTextContext is the context aware version of GetLatestText.
+func (sc *SnowthClient) GetLatestTextContext(ctx context.Context,
+	accountID int64, query string,
+	nodes ...*SnowthNode) ([]LatestText, error) {
+	items, err := sc.findLatest(ctx, accountID, query, nodes...)
+	if err != nil {
+		return nil, err
+	}
+
+	r := []LatestText{}
+	for _, item := range items {
+		lv := latestValue(item.Latest, "text")
+		if lv == nil {
+			continue
+		}
+
+		r = append(r, LatestText{
+			UUID:       item.UUID,
+			MetricName: item.MetricName,
+			CheckTags:  item.CheckTags,
+			Time:       lv.Time,
+			Value:      lv.Text,
+		})
+	}
+
+	return r, nil
 }
@@ -164,3 +164,93 @@ func TestWriteText(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+const latestTextTestData = `[
+	{
+		"uuid": "11223344-5566-7788-9900-aabbccddeeff",
+		"check_tags": ["test:test"],
+		"metric_name": "status",
+		"type": "text",
+		"latest": {
+			"text": [
+				[1561848300000, "old"],
+				[1561848360000, "new"],
+				[1561848420000, null]
+			]
+		},
+		"account_id": 1
+	},
+	{
+		"uuid": "11223344-5566-7788-9900-aabbccddeeff",
+		"metric_name": "count",
+		"type": "numeric",
+		"latest": {
+			"numeric": [
+				[1561848300000, 1]
+			]
+		},
+		"account_id": 1
+	}
+]`
+
+func TestGetLatestText(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/find/1/tags?query=test") {
+			if r.URL.Query().Get("latest") != "1" {
+				t.Errorf("Expected latest: 1, got: %v",
+					r.URL.Query().Get("latest"))
+			}
+
+			_, _ = w.Write([]byte(latestTextTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	res, err := sc.GetLatestText(1, "test", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 {
+		t.Fatalf("Expected length: 1, got: %v", len(res))
+	}
+
+	if res[0].MetricName != "status" {
+		t.Errorf("Expected metric name: status, got: %v", res[0].MetricName)
+	}
+
+	if res[0].Value == nil || *res[0].Value != "new" {
+		t.Errorf("Expected value: new, got: %v", res[0].Value)
+	}
+
+	if res[0].Time.Unix() != 1561848360 {
+		t.Errorf("Expected time: 1561848360, got: %v", res[0].Time.Unix())
+	}
+}
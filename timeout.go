@@ -0,0 +1,133 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// SetReadTimeout sets the timeout duration applied to data read and search
+// operations. A value of zero means no additional timeout is applied.
+func (sc *SnowthClient) SetReadTimeout(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.readTimeout = d
+	sc.raiseHTTPTimeout(d)
+}
+
+// SetWriteTimeout sets the timeout duration applied to data write and delete
+// operations. A value of zero means no additional timeout is applied.
+func (sc *SnowthClient) SetWriteTimeout(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.writeTimeout = d
+	sc.raiseHTTPTimeout(d)
+}
+
+// SetAdminTimeout sets the timeout duration applied to administrative
+// operations. A value of zero means no additional timeout is applied.
+func (sc *SnowthClient) SetAdminTimeout(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.adminTimeout = d
+	sc.raiseHTTPTimeout(d)
+}
+
+// raiseHTTPTimeout replaces the HTTP client of the client with a copy using a
+// timeout of at least d, so that operation timeouts longer than the HTTP
+// request timeout take effect. An HTTP client without a timeout is not
+// changed. The client must be locked by the caller.
+func (sc *SnowthClient) raiseHTTPTimeout(d time.Duration) {
+	hc, ok := sc.c.(*http.Client)
+	if !ok || hc.Timeout == 0 || hc.Timeout >= d {
+		return
+	}
+
+	c := *hc
+	c.Timeout = d
+	sc.c = &c
+}
+
+// operationKind values identify the kind of operation performed by a request.
+type operationKind int
+
+// Kinds of operations performed by requests.
+const (
+	opRead operationKind = iota
+	opWrite
+	opAdmin
+)
+
+// classifyOperation returns the kind of operation performed by a request with
+// the specified method and URL.
+func classifyOperation(method, ref string) operationKind {
+	p := ref
+	if u, err := url.Parse(ref); err == nil {
+		p = u.Path
+	}
+
+	switch {
+	case isWriteOperation(method, p):
+		return opWrite
+	case isAdminOperation(p):
+		return opAdmin
+	default:
+		return opRead
+	}
+}
+
+// operationTimeout returns the configured timeout duration for the kind of
+// operation performed by a request with the specified method and URL.
+func (sc *SnowthClient) operationTimeout(method, ref string) time.Duration {
+	kind := classifyOperation(method, ref)
+	sc.RLock()
+	defer sc.RUnlock()
+	switch kind {
+	case opWrite:
+		return sc.writeTimeout
+	case opAdmin:
+		return sc.adminTimeout
+	default:
+		return sc.readTimeout
+	}
+}
+
+// isWriteOperation returns whether a request with the specified method and
+// path writes or deletes data.
+func isWriteOperation(method, p string) bool {
+	switch {
+	case method == "DELETE":
+		return true
+	case method != "POST" && method != "PUT":
+		return false
+	case strings.HasPrefix(p, snowthapi.PathWrite),
+		strings.HasPrefix(p, snowthapi.PathRaw),
+		strings.HasPrefix(p, snowthapi.PathHistogramWrite),
+		strings.HasPrefix(p, snowthapi.PathSurrogate),
+		strings.HasPrefix(p, snowthapi.PathCheckTags):
+		return true
+	}
+
+	return false
+}
+
+// isAdminOperation returns whether a request with the specified path is an
+// administrative operation.
+func isAdminOperation(p string) bool {
+	for _, prefix := range []string{
+		snowthapi.PathState, snowthapi.PathStats, snowthapi.PathGossip,
+		snowthapi.PathTopology, snowthapi.PathActivate, snowthapi.PathLocate,
+		snowthapi.PathToporing, snowthapi.PathEventer,
+		snowthapi.PathJournalReplay,
+	} {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+
+	return p == snowthapi.PathLua
+}
@@ -0,0 +1,182 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOperationTimeout(t *testing.T) {
+	sc := &SnowthClient{
+		readTimeout:  time.Minute,
+		writeTimeout: time.Second,
+		adminTimeout: 2 * time.Second,
+	}
+
+	tests := []struct {
+		method string
+		url    string
+		exp    time.Duration
+	}{
+		{"GET", "/read/1/2/3/uuid/metric/average", time.Minute},
+		{"GET", "/find/1/tags?query=test", time.Minute},
+		{"POST", "/fetch", time.Minute},
+		{"POST", "/extension/lua/public/caql_v1", time.Minute},
+		{"POST", "/write/numeric", time.Second},
+		{"POST", "/raw", time.Second},
+		{"DELETE", "/raw/uuid/metric?start_ts=1", time.Second},
+		{"POST", "/histogram/write", time.Second},
+		{"GET", "/state", 2 * time.Second},
+		{"GET", "/stats.json", 2 * time.Second},
+		{"GET", "/topology/xml/hash", 2 * time.Second},
+		{"GET", "/extension/lua", 2 * time.Second},
+		{"GET", "/journal/replay", 2 * time.Second},
+		{"POST", "/journal/replay/pause", 2 * time.Second},
+		{"POST", "/journal/replay/resume/peer", 2 * time.Second},
+		{"PUT", "/check_tags/uuid", time.Second},
+		{"DELETE", "/check_tags/uuid", time.Second},
+		{"GET", "/check_tags/uuid", time.Minute},
+	}
+
+	for _, test := range tests {
+		d := sc.operationTimeout(test.method, test.url)
+		if d != test.exp {
+			t.Errorf("Expected timeout for %s %s: %v, got: %v",
+				test.method, test.url, test.exp, d)
+		}
+	}
+}
+
+func TestWriteTimeout(t *testing.T) {
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/write/numeric") {
+			select {
+			case <-r.Context().Done():
+			case <-time.After(200 * time.Millisecond):
+			}
+
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetRetries(0)
+	sc.SetConnectRetries(0)
+	sc.SetWriteTimeout(20 * time.Millisecond)
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	node := &SnowthNode{url: u}
+	err = sc.WriteNumericContext(context.Background(),
+		[]NumericWrite{{}}, node)
+	if err == nil {
+		t.Fatal("Expected write timeout error")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) &&
+		!strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("Expected deadline exceeded error, got: %v", err)
+	}
+
+	if _, err := sc.GetNodeState(node); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+func TestOperationTimeoutHTTPTimeout(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetWriteTimeout(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	hc, ok := sc.c.(*http.Client)
+	if !ok {
+		t.Fatal("Expected an HTTP client")
+	}
+
+	if hc.Timeout != time.Minute {
+		t.Errorf("Expected HTTP timeout: %v, got: %v", time.Minute,
+			hc.Timeout)
+	}
+
+	sc.SetAdminTimeout(2 * time.Minute)
+	hc, ok = sc.c.(*http.Client)
+	if !ok {
+		t.Fatal("Expected an HTTP client")
+	}
+
+	if hc.Timeout != 2*time.Minute {
+		t.Errorf("Expected HTTP timeout: %v, got: %v", 2*time.Minute,
+			hc.Timeout)
+	}
+
+	sc.SetReadTimeout(time.Second)
+	if hc := sc.c.(*http.Client); hc.Timeout != 2*time.Minute {
+		t.Errorf("Expected HTTP timeout: %v, got: %v", 2*time.Minute,
+			hc.Timeout)
+	}
+
+	cl, err := sc.WithConfig(&ClientOverrides{ReadTimeout: 3 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if hc := cl.c.(*http.Client); hc.Timeout != 3*time.Minute {
+		t.Errorf("Expected HTTP timeout: %v, got: %v", 3*time.Minute,
+			hc.Timeout)
+	}
+}
@@ -0,0 +1,179 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TLSAuditEvent values describe the use of a connection to a node for which
+// TLS certificate verification is disabled.
+type TLSAuditEvent struct {
+	Host string
+	Time time.Time
+}
+
+// TLSConfig gets the TLS configuration used to connect to IRONdb nodes.
+func (c *Config) TLSConfig() *tls.Config {
+	c.RLock()
+	defer c.RUnlock()
+	return c.tlsConfig
+}
+
+// SetTLSConfig sets the TLS configuration used to connect to IRONdb nodes
+// using HTTPS. If this is nil, the default, the default TLS configuration of
+// the HTTP client is used.
+func (c *Config) SetTLSConfig(tc *tls.Config) {
+	c.Lock()
+	c.tlsConfig = tc
+	c.Unlock()
+}
+
+// InsecureNodes gets the hosts of the nodes whose TLS certificates are not
+// verified.
+func (c *Config) InsecureNodes() []string {
+	c.RLock()
+	defer c.RUnlock()
+	return append([]string{}, c.insecureNodes...)
+}
+
+// SetInsecureNodes sets the hosts of the nodes whose TLS certificates are not
+// verified, such as lab nodes addressed by IP address with self-signed
+// certificates. Each entry is a host name or IP address, which matches any
+// port, or a host and port. Certificate verification of all other nodes is
+// unaffected. The first use of each node with verification disabled is logged
+// as a warning and reported to any OnTLSAudit callbacks.
+func (c *Config) SetInsecureNodes(hosts ...string) error {
+	for _, h := range hosts {
+		if h == "" || strings.ContainsAny(h, "/?#@") {
+			return fmt.Errorf("invalid insecure node host: %q", h)
+		}
+	}
+
+	c.Lock()
+	c.insecureNodes = append([]string{}, hosts...)
+	c.Unlock()
+	return nil
+}
+
+// OnTLSAudit registers a callback function which will be invoked the first
+// time a request is sent to each node whose TLS certificate is not verified.
+// The function is invoked immediately for any such nodes already used, such
+// as while the client was created.
+func (sc *SnowthClient) OnTLSAudit(f func(e *TLSAuditEvent)) {
+	if f == nil {
+		return
+	}
+
+	if sc.parent != nil {
+		sc.parent.OnTLSAudit(f)
+		return
+	}
+
+	sc.Lock()
+	sc.tlsAuditFuncs = append(sc.tlsAuditFuncs, f)
+	hosts := make([]string, 0, len(sc.tlsAudited))
+	for h := range sc.tlsAudited {
+		hosts = append(hosts, h)
+	}
+
+	sc.Unlock()
+	sort.Strings(hosts)
+	now := sc.getClock().Now()
+	for _, h := range hosts {
+		f(&TLSAuditEvent{Host: h, Time: now})
+	}
+}
+
+// auditInsecure logs a warning and notifies the TLS audit callbacks the first
+// time a node whose TLS certificate is not verified is used.
+func (sc *SnowthClient) auditInsecure(host string) {
+	sc.Lock()
+	if sc.tlsAudited[host] {
+		sc.Unlock()
+		return
+	}
+
+	if sc.tlsAudited == nil {
+		sc.tlsAudited = map[string]bool{}
+	}
+
+	sc.tlsAudited[host] = true
+	fns := make([]func(e *TLSAuditEvent), len(sc.tlsAuditFuncs))
+	copy(fns, sc.tlsAuditFuncs)
+	sc.Unlock()
+	sc.LogWarnf("TLS AUDIT: certificate verification is disabled for "+
+		"node: %s", host)
+	e := &TLSAuditEvent{Host: host, Time: sc.getClock().Now()}
+	for _, f := range fns {
+		f(e)
+	}
+}
+
+// nodeTLSTransport values are HTTP transports which send requests to nodes
+// whose TLS certificates are not verified using a separate transport, so that
+// certificate verification is only disabled for those nodes.
+type nodeTLSTransport struct {
+	secure   *http.Transport
+	insecure *http.Transport
+	hosts    []string
+	audit    func(host string)
+}
+
+// newNodeTLSTransport creates a transport which disables TLS certificate
+// verification for the specified hosts only.
+func newNodeTLSTransport(t *http.Transport,
+	hosts []string) *nodeTLSTransport {
+	it := t.Clone()
+	if it.TLSClientConfig == nil {
+		it.TLSClientConfig = &tls.Config{}
+	}
+
+	it.TLSClientConfig.InsecureSkipVerify = true
+	return &nodeTLSTransport{
+		secure:   t,
+		insecure: it,
+		hosts:    append([]string{}, hosts...),
+	}
+}
+
+// isInsecure returns whether certificate verification is disabled for the
+// host of a request.
+func (nt *nodeTLSTransport) isInsecure(r *http.Request) bool {
+	if r.URL == nil || r.URL.Scheme != "https" {
+		return false
+	}
+
+	for _, h := range nt.hosts {
+		if strings.EqualFold(h, r.URL.Host) ||
+			strings.EqualFold(h, r.URL.Hostname()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoundTrip sends a request using the transport for its host.
+func (nt *nodeTLSTransport) RoundTrip(r *http.Request) (*http.Response,
+	error) {
+	if nt.isInsecure(r) {
+		if nt.audit != nil {
+			nt.audit(r.URL.Host)
+		}
+
+		return nt.insecure.RoundTrip(r)
+	}
+
+	return nt.secure.RoundTrip(r)
+}
+
+// CloseIdleConnections closes the idle connections of both transports.
+func (nt *nodeTLSTransport) CloseIdleConnections() {
+	nt.secure.CloseIdleConnections()
+	nt.insecure.CloseIdleConnections()
+}
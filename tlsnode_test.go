@@ -0,0 +1,107 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+func TestSetInsecureNodes(t *testing.T) {
+	t.Parallel()
+	cfg, err := NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, h := range []string{"", "https://10.0.0.1", "10.0.0.1/api"} {
+		if err := cfg.SetInsecureNodes(h); err == nil {
+			t.Errorf("Expected invalid host error: %q", h)
+		}
+	}
+
+	if err := cfg.SetInsecureNodes("10.0.0.1", "lab:8443"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cfg.InsecureNodes()) != 2 {
+		t.Errorf("Expected insecure nodes: 2, got: %v", cfg.InsecureNodes())
+	}
+}
+
+func TestInsecureNodeTLS(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewClient(cfg); err == nil {
+		t.Fatal("Expected certificate verification error")
+	}
+
+	if err := cfg.SetInsecureNodes("other", u.Hostname()); err != nil {
+		t.Fatal(err)
+	}
+
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	events := []*TLSAuditEvent{}
+	sc.OnTLSAudit(func(e *TLSAuditEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	})
+
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	if len(events) != 1 || events[0].Host != u.Host {
+		t.Errorf("Expected audit event: %v, got: %v", u.Host, events)
+	}
+
+	mu.Unlock()
+	pool := x509.NewCertPool()
+	pool.AddCert(ms.Certificate())
+	cfg2, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg2.SetTLSConfig(&tls.Config{RootCAs: pool})
+	if _, err := NewClient(cfg2); err != nil {
+		t.Errorf("Expected verified connection, got: %v", err)
+	}
+}
@@ -12,6 +12,8 @@ import (
 	"path"
 	"sort"
 	"strings"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
 )
 
 type topologyNodeSlot struct {
@@ -325,11 +327,14 @@ func (sc *SnowthClient) GetTopologyInfoContext(ctx context.Context,
 	if topologyID == "" {
 		return nil, fmt.Errorf("no active topology")
 	}
-	if topologyID == sc.currentTopology && sc.currentTopologyCompiled != nil {
-		return sc.currentTopologyCompiled, nil
+	root := sc.root()
+	if topologyID == root.currentTopology &&
+		root.currentTopologyCompiled != nil {
+		return root.currentTopologyCompiled, nil
 	}
 	body, _, err := sc.DoRequestContext(ctx, node, "GET",
-		path.Join("/topology/xml", node.GetCurrentTopology()), nil, nil)
+		path.Join(snowthapi.PathTopologyXML, node.GetCurrentTopology()),
+		nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -340,8 +345,8 @@ func (sc *SnowthClient) GetTopologyInfoContext(ctx context.Context,
 	if err = r.compile(); err != nil {
 		return nil, err
 	}
-	sc.currentTopology = topologyID
-	sc.currentTopologyCompiled = r
+	root.currentTopology = topologyID
+	root.currentTopologyCompiled = r
 
 	return r, nil
 }
@@ -367,7 +372,8 @@ func (sc *SnowthClient) LoadTopologyContext(ctx context.Context, hash string,
 		return fmt.Errorf("failed to encode request data: %w", err)
 	}
 
-	_, _, err = sc.DoRequestContext(ctx, node, "POST", path.Join("/topology", hash), b, nil)
+	_, _, err = sc.DoRequestContext(ctx, node, "POST",
+		path.Join(snowthapi.PathTopology, hash), b, nil)
 	return err
 }
 
@@ -381,6 +387,7 @@ func (sc *SnowthClient) ActivateTopology(hash string, node *SnowthNode) error {
 // WARNING THIS IS DANGEROUS.
 func (sc *SnowthClient) ActivateTopologyContext(ctx context.Context,
 	hash string, node *SnowthNode) error {
-	_, _, err := sc.DoRequestContext(ctx, node, "GET", path.Join("/activate", hash), nil, nil)
+	_, _, err := sc.DoRequestContext(ctx, node, "GET",
+		path.Join(snowthapi.PathActivate, hash), nil, nil)
 	return err
 }
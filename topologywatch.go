@@ -0,0 +1,242 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// topologyEventBuffer is the number of topology events buffered for each
+// watcher. Events are dropped for watchers which do not receive them quickly
+// enough to keep the buffer from filling.
+const topologyEventBuffer = 64
+
+// TopologyEventType values identify the kind of change described by a
+// TopologyEvent.
+type TopologyEventType int
+
+// Topology event types.
+const (
+	// TopologyNodeAdded events describe nodes which joined the cluster.
+	TopologyNodeAdded TopologyEventType = iota
+
+	// TopologyNodeRemoved events describe nodes which left the cluster.
+	TopologyNodeRemoved
+
+	// TopologyNodeWeightChanged events describe nodes whose weight in the
+	// topology changed.
+	TopologyNodeWeightChanged
+)
+
+// String returns a string representation of the topology event type.
+func (tt TopologyEventType) String() string {
+	switch tt {
+	case TopologyNodeAdded:
+		return "added"
+	case TopologyNodeRemoved:
+		return "removed"
+	case TopologyNodeWeightChanged:
+		return "weight_changed"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(tt))
+	}
+}
+
+// TopologyEvent values describe a change in the cluster membership detected
+// by node discovery.
+type TopologyEvent struct {
+	Type TopologyEventType
+
+	// Node is the topology entry of the node. For removed nodes, this is the
+	// entry from the previous topology.
+	Node TopologyNode
+
+	// PrevWeight is the previous weight of nodes whose weight changed.
+	PrevWeight uint16
+
+	// Topology is the topology hash in which the change was detected.
+	Topology string
+	Time     time.Time
+}
+
+// topologyWatcher values deliver topology events to a WatchTopology caller.
+type topologyWatcher struct {
+	mu     sync.Mutex
+	ch     chan TopologyEvent
+	closed bool
+}
+
+// send delivers an event without blocking, returning false if the event was
+// dropped because the watcher buffer is full.
+func (tw *topologyWatcher) send(e TopologyEvent) bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.closed {
+		return true
+	}
+
+	select {
+	case tw.ch <- e:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the watcher channel.
+func (tw *topologyWatcher) close() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if !tw.closed {
+		tw.closed = true
+		close(tw.ch)
+	}
+}
+
+// WatchTopology returns a channel which receives an event whenever node
+// discovery detects that a node was added to or removed from the cluster, or
+// that the weight of a node changed. The channel is closed when the context
+// is cancelled. An error is returned if node discovery is not running.
+func (sc *SnowthClient) WatchTopology(
+	ctx context.Context) (<-chan TopologyEvent, error) {
+	if sc.parent != nil {
+		return sc.parent.WatchTopology(ctx)
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tw := &topologyWatcher{ch: make(chan TopologyEvent, topologyEventBuffer)}
+	sc.Lock()
+	if sc.discoverStop == nil {
+		sc.Unlock()
+		return nil, fmt.Errorf("node discovery is not running")
+	}
+
+	sc.topologyWatchers = append(sc.topologyWatchers, tw)
+	done := sc.done
+	sc.Unlock()
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+		}
+
+		sc.Lock()
+		for i, w := range sc.topologyWatchers {
+			if w == tw {
+				sc.topologyWatchers = append(sc.topologyWatchers[:i],
+					sc.topologyWatchers[i+1:]...)
+				break
+			}
+		}
+
+		sc.Unlock()
+		tw.close()
+	}()
+
+	return tw.ch, nil
+}
+
+// topologyEvents compares a topology with the previously retrieved topology
+// and returns events describing the differences. If no topology has been
+// retrieved, the topology is compared with the identified known nodes, and no
+// weight changes are reported.
+func (sc *SnowthClient) topologyEvents(hash string,
+	topology *Topology) []TopologyEvent {
+	if sc.parent != nil {
+		return sc.parent.topologyEvents(hash, topology)
+	}
+
+	now := sc.getClock().Now()
+	sc.Lock()
+	defer sc.Unlock()
+	prev, seeded := sc.topologyNodes, false
+	if prev == nil {
+		seeded = true
+		prev = map[string]TopologyNode{}
+		for _, n := range append(sc.activeNodes, sc.inactiveNodes...) {
+			if n.identifier != "" {
+				prev[strings.ToLower(n.identifier)] = TopologyNode{
+					ID:      n.identifier,
+					Address: n.GetURL().Hostname(),
+				}
+			}
+		}
+	}
+
+	events := []TopologyEvent{}
+	cur := make(map[string]TopologyNode, len(topology.Nodes))
+	for _, tn := range topology.Nodes {
+		id := strings.ToLower(tn.ID)
+		cur[id] = tn
+		p, ok := prev[id]
+		switch {
+		case !ok:
+			events = append(events, TopologyEvent{
+				Type:     TopologyNodeAdded,
+				Node:     tn,
+				Topology: hash,
+				Time:     now,
+			})
+		case !seeded && p.Weight != tn.Weight:
+			events = append(events, TopologyEvent{
+				Type:       TopologyNodeWeightChanged,
+				Node:       tn,
+				PrevWeight: p.Weight,
+				Topology:   hash,
+				Time:       now,
+			})
+		}
+	}
+
+	removed := []TopologyEvent{}
+	for id, p := range prev {
+		if _, ok := cur[id]; !ok {
+			removed = append(removed, TopologyEvent{
+				Type:     TopologyNodeRemoved,
+				Node:     p,
+				Topology: hash,
+				Time:     now,
+			})
+		}
+	}
+
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Node.ID < removed[j].Node.ID
+	})
+
+	sc.topologyNodes = cur
+	return append(events, removed...)
+}
+
+// notifyTopology delivers topology events to all topology watchers.
+func (sc *SnowthClient) notifyTopology(events []TopologyEvent) {
+	if sc.parent != nil {
+		sc.parent.notifyTopology(events)
+		return
+	}
+
+	if len(events) == 0 {
+		return
+	}
+
+	sc.RLock()
+	tws := make([]*topologyWatcher, len(sc.topologyWatchers))
+	copy(tws, sc.topologyWatchers)
+	sc.RUnlock()
+	for _, e := range events {
+		sc.LogInfof("topology node %s: %s", e.Type, e.Node.ID)
+		for _, tw := range tws {
+			if !tw.send(e) {
+				sc.LogWarnf("topology event dropped: %s: %s", e.Type,
+					e.Node.ID)
+			}
+		}
+	}
+}
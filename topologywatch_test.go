@@ -0,0 +1,132 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTopologyEventTypeString(t *testing.T) {
+	t.Parallel()
+	for tt, exp := range map[TopologyEventType]string{
+		TopologyNodeAdded:         "added",
+		TopologyNodeRemoved:       "removed",
+		TopologyNodeWeightChanged: "weight_changed",
+		TopologyEventType(9):      "unknown(9)",
+	} {
+		if tt.String() != exp {
+			t.Errorf("Expected string: %v, got: %v", exp, tt.String())
+		}
+	}
+}
+
+func TestWatchTopology(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	topo, hash := "", ""
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = w.Write([]byte(strings.Replace(statsTestData,
+				"294cbd39999c2270964029691e8bc5e231a867d525ccba62181dc8988ff218dc",
+				hash, 1)))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/topology/xml/") {
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = w.Write([]byte(topo))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	setTopo := func(h string, nodes ...string) {
+		mu.Lock()
+		defer mu.Unlock()
+		hash = h
+		topo = fmt.Sprintf(`<nodes n="2"><node `+
+			`id="bb6f7162-4828-11df-bab8-6bac200dcc2a" address="%s" `+
+			`port="%s" apiport="%s" weight="10"/>%s</nodes>`,
+			u.Hostname(), u.Port(), u.Port(), strings.Join(nodes, ""))
+	}
+
+	setTopo("1", `<node id="other" address="127.0.0.1" port="1" `+
+		`apiport="1" weight="10"/>`)
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	if _, err := sc.WatchTopology(context.Background()); err == nil {
+		t.Error("Expected error when discovery is not running")
+	}
+
+	sc.SetDiscoverInterval(time.Hour)
+	sc.StartDiscovery()
+	defer sc.StopDiscovery()
+	ctx, cancel := context.WithCancel(context.Background())
+	ch, err := sc.WatchTopology(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sc.RefreshTopology(); err != nil {
+		t.Fatal(err)
+	}
+
+	e := <-ch
+	if e.Type != TopologyNodeAdded || e.Node.ID != "other" {
+		t.Errorf("Expected added node: other, got: %+v", e)
+	}
+
+	setTopo("2", `<node id="other" address="127.0.0.1" port="1" `+
+		`apiport="1" weight="20"/>`)
+	if err := sc.RefreshTopology(); err != nil {
+		t.Fatal(err)
+	}
+
+	e = <-ch
+	if e.Type != TopologyNodeWeightChanged || e.Node.Weight != 20 ||
+		e.PrevWeight != 10 {
+		t.Errorf("Expected weight changed: 10 -> 20, got: %+v", e)
+	}
+
+	setTopo("3")
+	if err := sc.RefreshTopology(); err != nil {
+		t.Fatal(err)
+	}
+
+	e = <-ch
+	if e.Type != TopologyNodeRemoved || e.Node.ID != "other" {
+		t.Errorf("Expected removed node: other, got: %+v", e)
+	}
+
+	cancel()
+	for range ch {
+		t.Error("Expected no more events")
+	}
+}
@@ -0,0 +1,201 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TracerName is the name of the instrumentation library passed to tracer
+// providers when creating the tracer used to instrument requests.
+const TracerName = "github.com/circonus-labs/gosnowth"
+
+// Span attribute keys set on the spans of requests.
+const (
+	SpanAttrMethod     = "http.method"
+	SpanAttrEndpoint   = "http.target"
+	SpanAttrStatusCode = "http.status_code"
+	SpanAttrNode       = "net.peer.name"
+	SpanAttrAccountID  = "irondb.account_id"
+	SpanAttrRequestID  = "irondb.request_id"
+)
+
+// TracerProvider values create the tracers used to instrument requests. The
+// client does not depend on any tracing library, and this interface is
+// implemented by small adapters, such as one wrapping an OpenTelemetry
+// TracerProvider and TextMapPropagator.
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+// Tracer values start the spans of requests and propagate trace contexts to
+// IRONdb nodes.
+type Tracer interface {
+	// Start starts a new client span, which is a child of any span in the
+	// context, and returns a context containing the new span.
+	Start(ctx context.Context, name string) (context.Context, Span)
+
+	// Inject adds the trace context of the span in the context to the
+	// headers of a request.
+	Inject(ctx context.Context, h http.Header)
+}
+
+// Span values are the spans of requests started by a Tracer.
+type Span interface {
+	SetAttribute(key string, value interface{})
+	RecordError(err error)
+	End()
+}
+
+// spanKey is the context key used to store the span of a request.
+type spanKey struct{}
+
+// tracerProviderKey is the context key used to set the tracer provider of
+// individual requests.
+type tracerProviderKey struct{}
+
+// WithTracerProvider returns a copy of a context which will cause all requests
+// made using it to be traced using the specified tracer provider, regardless
+// of client settings.
+func WithTracerProvider(ctx context.Context,
+	tp TracerProvider) context.Context {
+	return context.WithValue(ctx, tracerProviderKey{}, tp)
+}
+
+// TracerProvider gets the tracer provider used to trace requests.
+func (c *Config) TracerProvider() TracerProvider {
+	c.RLock()
+	defer c.RUnlock()
+	return c.tracerProvider
+}
+
+// SetTracerProvider sets the tracer provider used to trace requests. Every
+// request, including all of its retries, is recorded as a client span with
+// the method, endpoint, node address, account ID and status code of the
+// request, and the trace context of the caller is propagated to IRONdb. By
+// default, requests are not traced.
+func (c *Config) SetTracerProvider(tp TracerProvider) {
+	c.Lock()
+	c.tracerProvider = tp
+	c.Unlock()
+}
+
+// SetTracerProvider sets the tracer provider used to trace requests. A nil
+// value disables tracing.
+func (sc *SnowthClient) SetTracerProvider(tp TracerProvider) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.tracerProvider = tp
+}
+
+// tracer returns the tracer to use for requests made with a context, or nil
+// if requests are not traced.
+func (sc *SnowthClient) tracer(ctx context.Context) Tracer {
+	tp, ok := ctx.Value(tracerProviderKey{}).(TracerProvider)
+	if !ok || tp == nil {
+		sc.RLock()
+		tp = sc.tracerProvider
+		sc.RUnlock()
+	}
+
+	if tp == nil {
+		return nil
+	}
+
+	return tp.Tracer(TracerName)
+}
+
+// startSpan starts the span of a request, returning a context containing the
+// span, or nil if requests are not traced.
+func (sc *SnowthClient) startSpan(ctx context.Context, method,
+	ref string) (context.Context, Span) {
+	t := sc.tracer(ctx)
+	if t == nil {
+		return ctx, nil
+	}
+
+	p := ref
+	if u, err := url.Parse(ref); err == nil {
+		p = u.Path
+	}
+
+	ctx, span := t.Start(ctx, "IRONdb "+method+" "+endpointName(p))
+	if span == nil {
+		return ctx, nil
+	}
+
+	ctx = context.WithValue(ctx, spanKey{}, span)
+	span.SetAttribute(SpanAttrMethod, method)
+	span.SetAttribute(SpanAttrEndpoint, p)
+	if id, ok := accountIDFromRef(ref); ok {
+		span.SetAttribute(SpanAttrAccountID, id)
+	}
+
+	return ctx, span
+}
+
+// endSpan records the result of a request in its span, and ends the span.
+func endSpan(span Span, err error) {
+	if span == nil {
+		return
+	}
+
+	var se *SnowthError
+	switch {
+	case err == nil:
+		span.SetAttribute(SpanAttrStatusCode, http.StatusOK)
+	case errors.As(err, &se) && se.Status != 0:
+		span.SetAttribute(SpanAttrStatusCode, se.Status)
+	}
+
+	if err != nil {
+		span.RecordError(err)
+	}
+
+	span.End()
+}
+
+// traceRequest records the node address and request ID of an attempt of a
+// request in the span of the request, and adds the trace context to the
+// request headers, if the request is traced. When a request is retried, the
+// span contains the values of the last attempt.
+func (sc *SnowthClient) traceRequest(r *http.Request, reqID string) {
+	span, ok := r.Context().Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+
+	span.SetAttribute(SpanAttrNode, r.URL.Host)
+	span.SetAttribute(SpanAttrRequestID, reqID)
+	if t := sc.tracer(r.Context()); t != nil {
+		t.Inject(r.Context(), r.Header)
+	}
+}
+
+// accountIDFromRef returns the account ID of a request, taken from the
+// account_id query parameter or the path of find requests.
+func accountIDFromRef(ref string) (int64, bool) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return 0, false
+	}
+
+	if v := u.Query().Get("account_id"); v != "" {
+		if id, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return id, true
+		}
+	}
+
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) > 2 && parts[0] == "find" {
+		if id, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+			return id, true
+		}
+	}
+
+	return 0, false
+}
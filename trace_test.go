@@ -0,0 +1,156 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+type testSpan struct {
+	sync.Mutex
+	name  string
+	attrs map[string]interface{}
+	err   error
+	ended bool
+}
+
+func (s *testSpan) SetAttribute(key string, value interface{}) {
+	s.Lock()
+	defer s.Unlock()
+	s.attrs[key] = value
+}
+
+func (s *testSpan) RecordError(err error) {
+	s.Lock()
+	defer s.Unlock()
+	s.err = err
+}
+
+func (s *testSpan) End() {
+	s.Lock()
+	defer s.Unlock()
+	s.ended = true
+}
+
+type testSpanKey struct{}
+
+type testTracer struct {
+	sync.Mutex
+	spans []*testSpan
+}
+
+func (tt *testTracer) Tracer(name string) Tracer {
+	return tt
+}
+
+func (tt *testTracer) Start(ctx context.Context,
+	name string) (context.Context, Span) {
+	tt.Lock()
+	defer tt.Unlock()
+	s := &testSpan{name: name, attrs: map[string]interface{}{}}
+	tt.spans = append(tt.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, name), s
+}
+
+func (tt *testTracer) Inject(ctx context.Context, h http.Header) {
+	if v, ok := ctx.Value(testSpanKey{}).(string); ok {
+		h.Set("Traceparent", v)
+	}
+}
+
+func TestAccountIDFromRef(t *testing.T) {
+	t.Parallel()
+	for ref, exp := range map[string]int64{
+		"/find/12/tags?query=and(a:b)":                  12,
+		"http://localhost:8112/find/3/tag_cats":         3,
+		"/extension/lua/public/caql_v1?account_id=42&q": 42,
+		"/find/x/tags": 0,
+		"/stats.json":  0,
+	} {
+		id, ok := accountIDFromRef(ref)
+		if id != exp || ok != (exp != 0) {
+			t.Errorf("Expected account ID for %s: %v, got: %v, %v", ref,
+				exp, id, ok)
+		}
+	}
+}
+
+func TestTracing(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	parents := []string{}
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if r.RequestURI == "/find/1/tags?query=test&activity=0&latest=0" {
+			mu.Lock()
+			parents = append(parents, r.Header.Get("Traceparent"))
+			mu.Unlock()
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := &testTracer{}
+	ctx := WithTracerProvider(context.Background(), tt)
+	if _, err := sc.FindTagsContext(ctx, 1, "test",
+		&FindTagsOptions{}); err == nil {
+		t.Fatal("Expected error response")
+	}
+
+	sc.SetTracerProvider(tt)
+	if _, err := sc.GetStats(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tt.spans) != 2 {
+		t.Fatalf("Expected spans: 2, got: %v", len(tt.spans))
+	}
+
+	s := tt.spans[0]
+	if s.name != "IRONdb GET /find/tags" || !s.ended || s.err == nil {
+		t.Errorf("Expected ended find span with error, got: %+v", s)
+	}
+
+	if s.attrs[SpanAttrAccountID] != int64(1) ||
+		s.attrs[SpanAttrStatusCode] != http.StatusBadRequest ||
+		s.attrs[SpanAttrNode] != sc.GetActiveNode().GetURL().Host ||
+		s.attrs[SpanAttrRequestID] == nil {
+		t.Errorf("Expected span attributes, got: %v", s.attrs)
+	}
+
+	if len(parents) != 1 || parents[0] != "IRONdb GET /find/tags" {
+		t.Errorf("Expected propagated trace context, got: %v", parents)
+	}
+
+	s = tt.spans[1]
+	if s.name != "IRONdb GET /stats.json" ||
+		s.attrs[SpanAttrStatusCode] != http.StatusOK || s.err != nil {
+		t.Errorf("Expected successful stats span, got: %+v", s)
+	}
+}
@@ -0,0 +1,224 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ParseCheckUUID parses and validates a check UUID string, returning an error
+// if it is malformed or the nil UUID. Validating check UUIDs before use avoids
+// requests which IRONdb would reject.
+func ParseCheckUUID(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid check uuid: %s: %w", s, err)
+	}
+
+	if id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("invalid check uuid: nil uuid")
+	}
+
+	return id, nil
+}
+
+// checkUUIDString returns the string form of a check UUID, or an error if it
+// is the nil UUID.
+func checkUUIDString(id uuid.UUID) (string, error) {
+	if id == uuid.Nil {
+		return "", fmt.Errorf("invalid check uuid: nil uuid")
+	}
+
+	return id.String(), nil
+}
+
+// ID returns the identifier of the node as a UUID, or an error if the node has
+// no valid identifier.
+func (sn *SnowthNode) ID() (uuid.UUID, error) {
+	id, err := uuid.Parse(sn.identifier)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid node identifier: %s: %w",
+			sn.identifier, err)
+	}
+
+	return id, nil
+}
+
+// UUIDClient values provide versions of the metric APIs of a SnowthClient
+// which accept check UUIDs as uuid.UUID values, and node identifiers as UUIDs,
+// rejecting nil UUIDs before any request is sent. All methods take a context
+// and otherwise behave the same as the SnowthClient methods of the same name.
+type UUIDClient struct {
+	sc *SnowthClient
+}
+
+// UUIDs returns a UUIDClient which uses this client.
+func (sc *SnowthClient) UUIDs() *UUIDClient {
+	return &UUIDClient{sc: sc}
+}
+
+// Node returns the active or inactive node with the specified identifier, or
+// nil if the client has no such node.
+func (uc *UUIDClient) Node(id uuid.UUID) *SnowthNode {
+	s := id.String()
+	for _, nodes := range [][]*SnowthNode{uc.sc.ListActiveNodes(),
+		uc.sc.ListInactiveNodes()} {
+		for _, n := range nodes {
+			if n.identifier == s {
+				return n
+			}
+		}
+	}
+
+	return nil
+}
+
+// FindMetricNodeIDs returns the identifiers of the nodes which own a metric.
+func (uc *UUIDClient) FindMetricNodeIDs(ctx context.Context, id uuid.UUID,
+	metric string) ([]uuid.UUID, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := uc.sc.FindMetricNodeIDsContext(ctx, s, metric)
+	r := make([]uuid.UUID, 0, len(ids))
+	for _, nid := range ids {
+		u, err := uuid.Parse(nid)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node identifier: %s: %w", nid,
+				err)
+		}
+
+		r = append(r, u)
+	}
+
+	return r, nil
+}
+
+// LocateMetric locates which nodes contain the specified metric.
+func (uc *UUIDClient) LocateMetric(ctx context.Context, id uuid.UUID,
+	metric string, nodes ...*SnowthNode) ([]TopologyNode, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.LocateMetricContext(ctx, s, metric, nodes...)
+}
+
+// ReadNumericValues reads numeric data from a node.
+func (uc *UUIDClient) ReadNumericValues(ctx context.Context,
+	start, end time.Time, period int64, t string, id uuid.UUID,
+	metric string, nodes ...*SnowthNode) ([]NumericValue, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.ReadNumericValuesContext(ctx, start, end, period, t, s,
+		metric, nodes...)
+}
+
+// ReadRawNumericValues reads raw numeric data from a node.
+func (uc *UUIDClient) ReadRawNumericValues(ctx context.Context,
+	start, end time.Time, id uuid.UUID, metric string,
+	nodes ...*SnowthNode) ([]RawNumericValue, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.ReadRawNumericValuesContext(ctx, start, end, s, metric,
+		nodes...)
+}
+
+// ReadRollupValues reads rollup data from a node.
+func (uc *UUIDClient) ReadRollupValues(ctx context.Context, id uuid.UUID,
+	metric string, period time.Duration, start, end time.Time,
+	dataType string, nodes ...*SnowthNode) ([]RollupValue, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.ReadRollupValuesContext(ctx, s, metric, period, start, end,
+		dataType, nodes...)
+}
+
+// ReadTextValues reads text data from a node.
+func (uc *UUIDClient) ReadTextValues(ctx context.Context, id uuid.UUID,
+	metric string, start, end time.Time,
+	nodes ...*SnowthNode) ([]TextValue, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.ReadTextValuesContext(ctx, s, metric, start, end, nodes...)
+}
+
+// ReadHistogramValues reads histogram data from a node.
+func (uc *UUIDClient) ReadHistogramValues(ctx context.Context, id uuid.UUID,
+	metric string, period time.Duration, start, end time.Time,
+	nodes ...*SnowthNode) ([]HistogramValue, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.ReadHistogramValuesContext(ctx, s, metric, period, start,
+		end, nodes...)
+}
+
+// DeleteMetricFull deletes all data and metadata of a metric.
+func (uc *UUIDClient) DeleteMetricFull(ctx context.Context, accountID int64,
+	id uuid.UUID, metric string, nodes ...*SnowthNode) (*DeleteReport, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.DeleteMetricFullContext(ctx, accountID, s, metric, nodes...)
+}
+
+// DeleteNumericBefore deletes the numeric data of a metric before a cutoff
+// time.
+func (uc *UUIDClient) DeleteNumericBefore(ctx context.Context, id uuid.UUID,
+	metric string, cutoff time.Time,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.DeleteNumericBeforeContext(ctx, s, metric, cutoff, nodes...)
+}
+
+// DeleteNumericRange deletes the numeric data of a metric in a time range.
+func (uc *UUIDClient) DeleteNumericRange(ctx context.Context, id uuid.UUID,
+	metric string, start, end time.Time,
+	nodes ...*SnowthNode) (*DeleteReport, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.DeleteNumericRangeContext(ctx, s, metric, start, end,
+		nodes...)
+}
+
+// LookupSurrogateID finds the surrogate database entry of a metric.
+func (uc *UUIDClient) LookupSurrogateID(ctx context.Context, accountID int64,
+	id uuid.UUID, metric string,
+	nodes ...*SnowthNode) (*SurrogateEntry, error) {
+	s, err := checkUUIDString(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.sc.LookupSurrogateIDContext(ctx, accountID, s, metric, nodes...)
+}
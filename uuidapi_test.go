@@ -0,0 +1,113 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseCheckUUID(t *testing.T) {
+	t.Parallel()
+	id, err := ParseCheckUUID("fc85e0ab-f568-45e6-86ee-d7443be8277d")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id.String() != "fc85e0ab-f568-45e6-86ee-d7443be8277d" {
+		t.Errorf("Expected uuid: fc85e0ab-f568-45e6-86ee-d7443be8277d, "+
+			"got: %v", id)
+	}
+
+	for _, s := range []string{"", "fc85e0ab", uuid.Nil.String()} {
+		if _, err := ParseCheckUUID(s); err == nil {
+			t.Errorf("Expected error for uuid: %v", s)
+		}
+	}
+}
+
+func TestUUIDClient(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		if strings.HasPrefix(r.RequestURI, "/rollup/"+
+			"fc85e0ab-f568-45e6-86ee-d7443be8277d/online?") {
+			_, _ = w.Write([]byte(rollupTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	u, err := url.Parse(ms.URL)
+	if err != nil {
+		t.Fatal("Invalid test URL")
+	}
+
+	uc := sc.UUIDs()
+	node := &SnowthNode{url: u}
+	id := uuid.MustParse("fc85e0ab-f568-45e6-86ee-d7443be8277d")
+	res, err := uc.ReadRollupValues(context.Background(), id, "online",
+		time.Second, time.Unix(1529509020, 0), time.Unix(1529509200, 0),
+		"average", node)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res) != 1 {
+		t.Errorf("Expected length: 1, got: %v", len(res))
+	}
+
+	if _, err := uc.ReadTextValues(context.Background(), uuid.Nil, "online",
+		time.Unix(1529509020, 0), time.Unix(1529509200, 0),
+		node); err == nil {
+		t.Error("Expected error for nil uuid")
+	}
+
+	if _, err := uc.DeleteMetricFull(context.Background(), 1, uuid.Nil,
+		"online", node); err == nil {
+		t.Error("Expected error for nil uuid")
+	}
+
+	nid := uuid.MustParse("9d1a34cd-b150-4c19-a894-e20280b42b62")
+	n := &SnowthNode{url: u, identifier: nid.String()}
+	sc.AddNodes(n)
+	if uc.Node(nid) != n {
+		t.Errorf("Expected node: %v, got: %v", n, uc.Node(nid))
+	}
+
+	if uc.Node(uuid.New()) != nil {
+		t.Error("Expected nil node for unknown identifier")
+	}
+
+	if got, err := n.ID(); err != nil || got != nid {
+		t.Errorf("Expected node ID: %v, got: %v, %v", nid, got, err)
+	}
+
+	if _, err := node.ID(); err == nil {
+		t.Error("Expected error for missing node identifier")
+	}
+}
@@ -0,0 +1,114 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// parseVersion parses a semantic version string, such as "0.23.1", into its
+// numeric components. A leading "v" and any pre-release or build metadata
+// suffix are ignored, and missing minor or patch components are zero.
+func parseVersion(s string) ([3]int64, error) {
+	r := [3]int64{}
+	v := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+
+	parts := strings.Split(v, ".")
+	if v == "" || len(parts) > 3 {
+		return r, fmt.Errorf("invalid version: %q", s)
+	}
+
+	for i, p := range parts {
+		n, err := strconv.ParseInt(p, 10, 64)
+		if err != nil || n < 0 {
+			return r, fmt.Errorf("invalid version: %q", s)
+		}
+
+		r[i] = n
+	}
+
+	return r, nil
+}
+
+// compareVersions compares two semantic version strings, returning -1, 0 or 1
+// if the first version is less than, equal to, or greater than the second.
+func compareVersions(a, b string) (int, error) {
+	va, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+
+	vb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range va {
+		switch {
+		case va[i] < vb[i]:
+			return -1, nil
+		case va[i] > vb[i]:
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// VersionAtLeast returns whether the node is known to be running the specified
+// semantic version of IRONdb, or a later version. False is returned if the
+// version of the node is unknown or either version is invalid.
+func (sn *SnowthNode) VersionAtLeast(v string) bool {
+	c, err := compareVersions(sn.semVer, v)
+	return err == nil && c >= 0
+}
+
+// NodeFilter values are functions which select the nodes eligible to be
+// returned by GetActiveNodeWith.
+type NodeFilter func(node *SnowthNode) bool
+
+// WithMinVersion returns a node filter which selects only nodes known to be
+// running the specified semantic version of IRONdb, or a later version. It
+// is used to route requests using features only available in newer versions
+// of IRONdb to nodes which support them.
+func WithMinVersion(v string) NodeFilter {
+	return func(node *SnowthNode) bool {
+		return node.VersionAtLeast(v)
+	}
+}
+
+// GetActiveNodeWith returns a random active node selected by all of the
+// specified filters, or nil if no active node is selected by the filters.
+func (sc *SnowthClient) GetActiveNodeWith(filters ...NodeFilter) *SnowthNode {
+	if sc.parent != nil {
+		return sc.parent.GetActiveNodeWith(filters...)
+	}
+
+	sc.RLock()
+	defer sc.RUnlock()
+	nodes := []*SnowthNode{}
+	for _, node := range sc.activeNodes {
+		match := true
+		for _, f := range filters {
+			if f != nil && !f(node) {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			nodes = append(nodes, node)
+		}
+	}
+
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	return nodes[rand.Intn(len(nodes))]
+}
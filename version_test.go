@@ -0,0 +1,71 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b string
+		exp  int
+		err  bool
+	}{
+		{"0.23.0", "0.23.0", 0, false},
+		{"v0.23.1", "0.23.0", 1, false},
+		{"0.22.9", "0.23", -1, false},
+		{"1.0.0-beta.1", "1", 0, false},
+		{"0.1.1570000000", "0.23.0", -1, false},
+		{"", "0.23.0", 0, true},
+		{"0.x.0", "0.23.0", 0, true},
+		{"0.23.0", "1.2.3.4", 0, true},
+	}
+
+	for _, tt := range tests {
+		c, err := compareVersions(tt.a, tt.b)
+		if (err != nil) != tt.err {
+			t.Errorf("Expected error for %q, %q: %v, got: %v", tt.a, tt.b,
+				tt.err, err)
+			continue
+		}
+
+		if c != tt.exp {
+			t.Errorf("Expected comparison of %q, %q: %v, got: %v", tt.a,
+				tt.b, tt.exp, c)
+		}
+	}
+}
+
+func TestGetActiveNodeWith(t *testing.T) {
+	t.Parallel()
+	sc := &SnowthClient{}
+	u1, _ := url.Parse("http://node1:8112")
+	u2, _ := url.Parse("http://node2:8112")
+	u3, _ := url.Parse("http://node3:8112")
+	sc.activeNodes = []*SnowthNode{
+		{url: u1, semVer: "0.22.4"},
+		{url: u2, semVer: "0.23.1"},
+		{url: u3},
+	}
+
+	for i := 0; i < 10; i++ {
+		node := sc.GetActiveNodeWith(WithMinVersion("0.23.0"))
+		if node == nil || node.GetURL() != u2 {
+			t.Fatalf("Expected node: %v, got: %v", u2, node)
+		}
+	}
+
+	if node := sc.GetActiveNodeWith(WithMinVersion("1.0.0")); node != nil {
+		t.Errorf("Expected node: nil, got: %v", node.GetURL())
+	}
+
+	if node := sc.GetActiveNodeWith(); node == nil {
+		t.Error("Expected an active node")
+	}
+
+	if sc.activeNodes[2].VersionAtLeast("0.0.1") {
+		t.Error("Expected unknown version not to satisfy minimum version")
+	}
+}
@@ -0,0 +1,247 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/snowthapi"
+)
+
+// NodeHealthEvent values describe a change in the active state of a node
+// detected by the node health watchdog.
+type NodeHealthEvent struct {
+	Node   *SnowthNode
+	Active bool
+	Err    error
+	Time   time.Time
+}
+
+// OnNodeHealthChange registers a callback function which will be invoked by
+// the node health watchdog whenever a node is activated or deactivated.
+func (sc *SnowthClient) OnNodeHealthChange(f func(e *NodeHealthEvent)) {
+	if f == nil {
+		return
+	}
+
+	if sc.parent != nil {
+		sc.parent.OnNodeHealthChange(f)
+		return
+	}
+
+	sc.Lock()
+	defer sc.Unlock()
+	sc.healthFuncs = append(sc.healthFuncs, f)
+}
+
+// SetWatchdogInterval sets the interval at which the node health watchdog
+// checks the state of every node.
+func (sc *SnowthClient) SetWatchdogInterval(d time.Duration) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.watchdogInterval = d
+}
+
+// SetWatchdogFailures sets the number of consecutive failed health checks
+// after which the watchdog will deactivate a node.
+func (sc *SnowthClient) SetWatchdogFailures(num int64) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.watchdogFailures = num
+}
+
+// StartWatchdog starts a background process which periodically checks the
+// /state and /stats.json endpoints of every known node, deactivating nodes
+// which fail consecutive checks and reactivating nodes which recover. The
+// watchdog runs until the context is cancelled or StopWatchdog() is called.
+// If a watchdog is already running, this function does nothing.
+func (sc *SnowthClient) StartWatchdog(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sc.Lock()
+	wi := sc.watchdogInterval
+	if wi <= time.Duration(0) || sc.watchdogStop != nil || sc.closing ||
+		sc.parent != nil {
+		sc.Unlock()
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	sc.watchdogStop = stop
+	sc.watchdogDone = done
+	sc.Unlock()
+	go func() {
+		defer close(done)
+		tick := sc.getClock().NewTicker(wi)
+		defer tick.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			case <-tick.C():
+				sc.LogDebugf("firing node health watchdog")
+				sc.checkNodes(ctx, wi)
+			}
+		}
+	}()
+}
+
+// StopWatchdog stops a running node health watchdog and waits for it to
+// exit.
+func (sc *SnowthClient) StopWatchdog() {
+	sc.Lock()
+	stop, done := sc.watchdogStop, sc.watchdogDone
+	sc.watchdogStop, sc.watchdogDone = nil, nil
+	sc.Unlock()
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// healthFailed records a failed health check of a node, and returns the number
+// of consecutive failed health checks of the node.
+func (sc *SnowthClient) healthFailed(node *SnowthNode) int64 {
+	sc.Lock()
+	defer sc.Unlock()
+	if sc.healthFailures == nil {
+		sc.healthFailures = map[string]int64{}
+	}
+
+	sc.healthFailures[node.GetURL().String()]++
+	return sc.healthFailures[node.GetURL().String()]
+}
+
+// healthPassed records a successful health check of a node, and returns
+// whether the node had previously failed health checks.
+func (sc *SnowthClient) healthPassed(node *SnowthNode) bool {
+	sc.Lock()
+	defer sc.Unlock()
+	if _, ok := sc.healthFailures[node.GetURL().String()]; !ok {
+		return false
+	}
+
+	delete(sc.healthFailures, node.GetURL().String())
+	return true
+}
+
+// checkNodes performs a health check of every known node and moves the nodes
+// to the active or inactive pools as required.
+func (sc *SnowthClient) checkNodes(ctx context.Context,
+	timeout time.Duration) {
+	sc.RLock()
+	threshold := sc.watchdogFailures
+	sc.RUnlock()
+	if threshold < 1 {
+		threshold = 1
+	}
+
+	changed := false
+	defer func() {
+		if changed {
+			sc.persistState()
+		}
+	}()
+
+	for _, node := range sc.ListInactiveNodes() {
+		if sc.isQuarantined(node) {
+			continue
+		}
+
+		if err := sc.checkNodeHealth(ctx, timeout, node); err != nil {
+			sc.healthFailed(node)
+			continue
+		}
+
+		sc.healthPassed(node)
+		sc.LogInfof("watchdog activating node: %s", node.GetURL().Host)
+		sc.ActivateNodes(node)
+		changed = true
+		sc.notifyHealth(&NodeHealthEvent{
+			Node:   node,
+			Active: true,
+			Time:   sc.getClock().Now(),
+		})
+	}
+
+	for _, node := range sc.ListActiveNodes() {
+		err := sc.checkNodeHealth(ctx, timeout, node)
+		if err == nil {
+			if sc.healthPassed(node) {
+				changed = true
+			}
+
+			continue
+		}
+
+		changed = true
+		sc.LogWarnf("watchdog health check failed: %s: %v",
+			node.GetURL().Host, err)
+		if sc.healthFailed(node) < threshold {
+			continue
+		}
+
+		sc.LogWarnf("watchdog deactivating node: %s", node.GetURL().Host)
+		sc.DeactivateNodes(node)
+		changed = true
+		sc.notifyHealth(&NodeHealthEvent{
+			Node:   node,
+			Active: false,
+			Err:    err,
+			Time:   sc.getClock().Now(),
+		})
+	}
+}
+
+// checkNodeHealth requests the state and stats of a single node, without
+// retrying on other nodes, and returns an error if either request fails.
+func (sc *SnowthClient) checkNodeHealth(ctx context.Context,
+	timeout time.Duration, node *SnowthNode) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if _, _, err := sc.do(ctx, node, "GET", snowthapi.PathState,
+		nil, nil); err != nil {
+		return fmt.Errorf("unable to get node state: %w", err)
+	}
+
+	body, _, err := sc.do(ctx, node, "GET", snowthapi.PathStats, nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get node stats: %w", err)
+	}
+
+	stats := &Stats{}
+	if err := decodeJSON(body, &stats); err != nil {
+		return fmt.Errorf("unable to decode IRONdb response: %w", err)
+	}
+
+	sc.Lock()
+	if id := stats.Identity(); id != "" {
+		node.identifier = id
+	}
+
+	if ver := stats.SemVer(); ver != "" {
+		node.semVer = ver
+	}
+
+	sc.Unlock()
+	return nil
+}
+
+// notifyHealth invokes all registered node health callbacks with an event.
+func (sc *SnowthClient) notifyHealth(e *NodeHealthEvent) {
+	sc.RLock()
+	fns := make([]func(e *NodeHealthEvent), len(sc.healthFuncs))
+	copy(fns, sc.healthFuncs)
+	sc.RUnlock()
+	for _, f := range fns {
+		f(e)
+	}
+}
@@ -0,0 +1,96 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchdog(t *testing.T) {
+	var failing int32
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(500)
+			_, _ = w.Write([]byte("node unavailable"))
+			return
+		}
+
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	cfg, err := NewConfig(ms.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cfg.SetWatchdogInterval(10 * time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.SetWatchdogFailures(2)
+	sc, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	events := make(chan *NodeHealthEvent, 10)
+	sc.OnNodeHealthChange(func(e *NodeHealthEvent) {
+		events <- e
+	})
+
+	sc.StartWatchdog(context.Background())
+	defer sc.StopWatchdog()
+	atomic.StoreInt32(&failing, 1)
+	select {
+	case e := <-events:
+		if e.Active {
+			t.Error("Expected node deactivation event")
+		}
+
+		if e.Err == nil {
+			t.Error("Expected deactivation error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected node deactivation event")
+	}
+
+	if len(sc.ListActiveNodes()) != 0 {
+		t.Errorf("Expected active nodes: 0, got: %v",
+			len(sc.ListActiveNodes()))
+	}
+
+	atomic.StoreInt32(&failing, 0)
+	select {
+	case e := <-events:
+		if !e.Active {
+			t.Error("Expected node activation event")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected node activation event")
+	}
+
+	if len(sc.ListActiveNodes()) != 1 {
+		t.Errorf("Expected active nodes: 1, got: %v",
+			len(sc.ListActiveNodes()))
+	}
+
+	sc.StopWatchdog()
+	sc.StopWatchdog()
+}
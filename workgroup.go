@@ -0,0 +1,144 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// defaultWorkGroupConcurrency is the number of tasks run at the same time by
+// a work group, if neither the work group nor the client sets a limit.
+const defaultWorkGroupConcurrency = 4
+
+// WorkGroup values run tasks against IRONdb concurrently, bounded by the
+// concurrency limits of the client which created them. Work groups are used
+// by the client for fan-out operations, and can be used to build custom
+// parallel workflows against many nodes. Requests made by tasks using the
+// client are subject to its request queue, priorities, rate limits and retry
+// budget, and tasks are counted as in-flight requests, so that Shutdown waits
+// for them to complete.
+type WorkGroup struct {
+	sc   *SnowthClient
+	ctx  context.Context
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	errs *multiError
+}
+
+// NewWorkGroup creates a new work group which runs at most concurrency tasks
+// at the same time, using the context as the parent of the context passed to
+// each task. A concurrency value of zero or less uses the maximum number of
+// concurrent requests of the client, or four if the client has no limit. The
+// concurrency is never greater than the maximum number of concurrent requests
+// of the client, since additional tasks would only wait in its request queue.
+func (sc *SnowthClient) NewWorkGroup(ctx context.Context,
+	concurrency int) *WorkGroup {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	sc.RLock()
+	q := sc.requestQueue
+	sc.RUnlock()
+	if q != nil && (concurrency <= 0 || concurrency > q.max) {
+		concurrency = q.max
+	}
+
+	if concurrency <= 0 {
+		concurrency = defaultWorkGroupConcurrency
+	}
+
+	return &WorkGroup{
+		sc:   sc,
+		ctx:  ctx,
+		sem:  make(chan struct{}, concurrency),
+		errs: newMultiError(),
+	}
+}
+
+// Go runs a task in the work group, waiting until fewer than the maximum
+// number of tasks are running. If the context of the work group is terminated
+// while waiting, or the client has been closed, the task is not run and an
+// error is returned, which is also returned by Wait.
+func (g *WorkGroup) Go(f func(ctx context.Context) error) error {
+	acquired := false
+	select {
+	case <-g.ctx.Done():
+	case g.sem <- struct{}{}:
+		acquired = true
+	}
+
+	if err := g.ctx.Err(); err != nil {
+		if acquired {
+			<-g.sem
+		}
+
+		err = fmt.Errorf("context terminated: %w", err)
+		g.addError(err)
+		return err
+	}
+
+	done, err := g.sc.startRequest()
+	if err != nil {
+		<-g.sem
+		g.addError(err)
+		return err
+	}
+
+	g.wg.Add(1)
+	go func() {
+		defer func() {
+			done()
+			<-g.sem
+			g.wg.Done()
+		}()
+
+		if err := f(g.ctx); err != nil {
+			g.addError(err)
+		}
+	}()
+
+	return nil
+}
+
+// GoNodes runs a task for each of the specified nodes in the work group, or
+// for each active node if no nodes are specified.
+func (g *WorkGroup) GoNodes(f func(ctx context.Context,
+	node *SnowthNode) error, nodes ...*SnowthNode) {
+	if len(nodes) == 0 {
+		nodes = g.sc.ListActiveNodes()
+	}
+
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+
+		n := node
+		_ = g.Go(func(ctx context.Context) error {
+			return f(ctx, n)
+		})
+	}
+}
+
+// Wait waits for all tasks in the work group to complete, and returns the
+// errors returned by any of them.
+func (g *WorkGroup) Wait() error {
+	g.wg.Wait()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.errs.HasError() {
+		return g.errs
+	}
+
+	return nil
+}
+
+// addError records an error returned by a task.
+func (g *WorkGroup) addError(err error) {
+	g.mu.Lock()
+	g.errs.Add(err)
+	g.mu.Unlock()
+}
@@ -0,0 +1,123 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestWorkGroup(t *testing.T) {
+	t.Parallel()
+	ms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter,
+		r *http.Request) {
+		if r.RequestURI == "/state" {
+			_, _ = w.Write([]byte(stateTestData))
+			return
+		}
+
+		if r.RequestURI == "/stats.json" {
+			_, _ = w.Write([]byte(statsTestData))
+			return
+		}
+
+		t.Errorf("Unexpected request: %v", r)
+		w.WriteHeader(500)
+	}))
+
+	defer ms.Close()
+	sc, err := NewSnowthClient(false, ms.URL)
+	if err != nil {
+		t.Fatal("Unable to create snowth client", err)
+	}
+
+	sc.SetMaxConcurrentRequests(2)
+	g := sc.NewWorkGroup(context.Background(), 10)
+	if cap(g.sem) != 2 {
+		t.Errorf("Expected concurrency: 2, got: %v", cap(g.sem))
+	}
+
+	var mu sync.Mutex
+	running, max := 0, 0
+	errTest := errors.New("test error")
+	for i := 0; i < 10; i++ {
+		i := i
+		if err := g.Go(func(ctx context.Context) error {
+			mu.Lock()
+			running++
+			if running > max {
+				max = running
+			}
+
+			mu.Unlock()
+			_, err := sc.GetStatsContext(ctx)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			if err != nil {
+				return err
+			}
+
+			if i == 3 {
+				return errTest
+			}
+
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = g.Wait()
+	if !errors.Is(err, errTest) {
+		t.Errorf("Expected error: %v, got: %v", errTest, err)
+	}
+
+	if max > 2 {
+		t.Errorf("Expected maximum running tasks: 2, got: %v", max)
+	}
+
+	nodes := 0
+	g = sc.NewWorkGroup(context.Background(), 0)
+	g.GoNodes(func(ctx context.Context, node *SnowthNode) error {
+		mu.Lock()
+		nodes++
+		mu.Unlock()
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := len(sc.ListActiveNodes()); nodes != exp {
+		t.Errorf("Expected tasks: %v, got: %v", exp, nodes)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g = sc.NewWorkGroup(ctx, 1)
+	if err := g.Go(func(ctx context.Context) error {
+		return nil
+	}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected error: %v, got: %v", context.Canceled, err)
+	}
+
+	if err := sc.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	g = sc.NewWorkGroup(context.Background(), 1)
+	if err := g.Go(func(ctx context.Context) error {
+		return nil
+	}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+
+	if err := g.Wait(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected error: %v, got: %v", ErrClosed, err)
+	}
+}
@@ -0,0 +1,174 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+// NonFinitePolicy values control how NaN and infinite floating point values
+// are handled when they are written.
+type NonFinitePolicy int
+
+// Policies for writing NaN and infinite values.
+const (
+	// NonFiniteAllow sends NaN and infinite values to IRONdb unchanged. This
+	// is the default policy.
+	NonFiniteAllow NonFinitePolicy = iota
+
+	// NonFiniteDrop removes data points with NaN or infinite values from the
+	// write.
+	NonFiniteDrop
+
+	// NonFiniteError fails the entire write, without sending any data, if any
+	// data point has a NaN or infinite value.
+	NonFiniteError
+
+	// NonFiniteNull replaces NaN and infinite values with absent numeric
+	// values, which are stored as null.
+	NonFiniteNull
+)
+
+// String returns a string representation of the policy.
+func (p NonFinitePolicy) String() string {
+	switch p {
+	case NonFiniteAllow:
+		return "allow"
+	case NonFiniteDrop:
+		return "drop"
+	case NonFiniteError:
+		return "error"
+	case NonFiniteNull:
+		return "null"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(p))
+	}
+}
+
+// SetNonFinitePolicy sets the policy used to handle NaN and infinite values
+// in raw writes of FlatBuffers metric lists and RawMetric values, including
+// streaming and Prometheus remote writes. The other write operations only
+// accept integer values, and are not affected.
+func (sc *SnowthClient) SetNonFinitePolicy(p NonFinitePolicy) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.nonFinitePolicy = p
+}
+
+// SetWritePrecision sets the number of decimal places to which floating point
+// values are rounded in the raw writes affected by the NaN and infinite value
+// policy. A negative value disables rounding.
+func (sc *SnowthClient) SetWritePrecision(digits int) {
+	sc.Lock()
+	defer sc.Unlock()
+	sc.writePrecision = digits
+}
+
+// writePolicy values contain the NaN and infinite value policy and precision
+// limit applied to written floating point values.
+type writePolicy struct {
+	nonFinite NonFinitePolicy
+	precision int
+}
+
+// getWritePolicy returns the write policy of the client.
+func (sc *SnowthClient) getWritePolicy() writePolicy {
+	sc.RLock()
+	defer sc.RUnlock()
+	return writePolicy{nonFinite: sc.nonFinitePolicy,
+		precision: sc.writePrecision}
+}
+
+// noop returns true if the policy does not change any value.
+func (wp writePolicy) noop() bool {
+	return wp.nonFinite == NonFiniteAllow && wp.precision < 0
+}
+
+// value applies the policy to a floating point value. It returns the value to
+// write, which is nil if the value is written as null, and false if the value
+// is dropped.
+func (wp writePolicy) value(v float64) (interface{}, bool, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		switch wp.nonFinite {
+		case NonFiniteDrop:
+			return nil, false, nil
+		case NonFiniteError:
+			return nil, false, fmt.Errorf("invalid value: %v", v)
+		case NonFiniteNull:
+			return nil, true, nil
+		default:
+			return v, true, nil
+		}
+	}
+
+	if wp.precision >= 0 {
+		scale := math.Pow10(wp.precision)
+		v = math.Round(v*scale) / scale
+	}
+
+	return v, true, nil
+}
+
+// applyWritePolicy returns a metric list with the NaN and infinite value
+// policy and precision limit of the client applied to all floating point
+// values. The provided metric list is not modified.
+func (sc *SnowthClient) applyWritePolicy(
+	ml *noit.MetricListT) (*noit.MetricListT, error) {
+	wp := sc.getWritePolicy()
+	if wp.noop() {
+		return ml, nil
+	}
+
+	res := &noit.MetricListT{Metrics: make([]*noit.MetricT, 0,
+		len(ml.Metrics))}
+	for i, m := range ml.Metrics {
+		if m == nil || m.Value == nil || m.Value.Value == nil ||
+			m.Value.Value.Type != noit.MetricValueUnionDoubleValue {
+			res.Metrics = append(res.Metrics, m)
+			continue
+		}
+
+		dv, ok := m.Value.Value.Value.(*noit.DoubleValueT)
+		if !ok || dv == nil {
+			res.Metrics = append(res.Metrics, m)
+			continue
+		}
+
+		v, ok, err := wp.value(dv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for metric %d: %s: %w",
+				i, m.Value.Name, err)
+		}
+
+		if !ok {
+			continue
+		}
+
+		uv := &noit.MetricValueUnionT{
+			Type:  noit.MetricValueUnionAbsentNumericValue,
+			Value: &noit.AbsentNumericValueT{},
+		}
+
+		if fv, ok := v.(float64); ok {
+			if fv == dv.Value || math.IsNaN(fv) {
+				res.Metrics = append(res.Metrics, m)
+				continue
+			}
+
+			uv = &noit.MetricValueUnionT{
+				Type:  noit.MetricValueUnionDoubleValue,
+				Value: &noit.DoubleValueT{Value: fv},
+			}
+		}
+
+		mv := *m.Value
+		mv.Value = uv
+		nm := *m
+		nm.Value = &mv
+		res.Metrics = append(res.Metrics, &nm)
+	}
+
+	return res, nil
+}
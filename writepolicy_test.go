@@ -0,0 +1,138 @@
+// Package gosnowth contains an IRONdb client library written in Go.
+package gosnowth
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/circonus-labs/gosnowth/fb/noit"
+)
+
+func testDoubleMetricList(values ...float64) *noit.MetricListT {
+	ml := &noit.MetricListT{}
+	for _, v := range values {
+		ml.Metrics = append(ml.Metrics, &noit.MetricT{
+			Timestamp: 1,
+			CheckName: "test",
+			CheckUuid: "11223344-5566-7788-9900-aabbccddeeff",
+			AccountId: 1,
+			Value: &noit.MetricValueT{
+				Name:      "test",
+				Timestamp: 1,
+				Value: &noit.MetricValueUnionT{
+					Type:  noit.MetricValueUnionDoubleValue,
+					Value: &noit.DoubleValueT{Value: v},
+				},
+			},
+		})
+	}
+
+	return ml
+}
+
+func TestApplyWritePolicy(t *testing.T) {
+	sc := &SnowthClient{writePrecision: -1}
+	ml := testDoubleMetricList(1.23456, math.NaN(), math.Inf(1))
+	res, err := sc.applyWritePolicy(ml)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res != ml {
+		t.Error("Expected unchanged metric list")
+	}
+
+	sc.SetNonFinitePolicy(NonFiniteDrop)
+	if res, err = sc.applyWritePolicy(ml); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Metrics) != 1 {
+		t.Errorf("Expected metrics: 1, got: %v", len(res.Metrics))
+	}
+
+	sc.SetNonFinitePolicy(NonFiniteError)
+	if _, err = sc.applyWritePolicy(ml); err == nil {
+		t.Error("Expected invalid value error")
+	}
+
+	sc.SetNonFinitePolicy(NonFiniteNull)
+	sc.SetWritePrecision(2)
+	if res, err = sc.applyWritePolicy(ml); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(res.Metrics) != 3 {
+		t.Fatalf("Expected metrics: 3, got: %v", len(res.Metrics))
+	}
+
+	v := res.Metrics[0].Value.Value.Value.(*noit.DoubleValueT).Value
+	if v != 1.23 {
+		t.Errorf("Expected value: 1.23, got: %v", v)
+	}
+
+	if res.Metrics[1].Value.Value.Type !=
+		noit.MetricValueUnionAbsentNumericValue {
+		t.Errorf("Expected absent numeric value, got: %v",
+			res.Metrics[1].Value.Value.Type)
+	}
+
+	if !math.IsNaN(ml.Metrics[1].Value.Value.Value.(*noit.DoubleValueT).Value) {
+		t.Error("Expected original metric list to be unmodified")
+	}
+
+	if NonFiniteNull.String() != "null" {
+		t.Errorf("Expected string: null, got: %v", NonFiniteNull.String())
+	}
+}
+
+func TestEncodeRawMetricWritePolicy(t *testing.T) {
+	now := time.Unix(1, 0)
+	m := RawMetric{CheckUUID: "11223344-5566-7788-9900-aabbccddeeff",
+		Metric: "test", Value: 1.23456}
+	wp := writePolicy{nonFinite: NonFiniteNull, precision: 2}
+	b, _, err := encodeRawMetric(m, now, wp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), `"value":1.23`) ||
+		strings.Contains(string(b), "1.234") {
+		t.Errorf("Expected rounded value, got: %s", b)
+	}
+
+	m.Value = math.NaN()
+	if b, _, err = encodeRawMetric(m, now, wp); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), `"value":null`) {
+		t.Errorf("Expected null value, got: %s", b)
+	}
+
+	wp.nonFinite = NonFiniteDrop
+	if b, _, err = encodeRawMetric(m, now, wp); err != nil {
+		t.Fatal(err)
+	}
+
+	if b != nil {
+		t.Errorf("Expected dropped metric, got: %s", b)
+	}
+
+	wp.nonFinite = NonFiniteError
+	m.Value = float32(math.Inf(1))
+	if _, _, err = encodeRawMetric(m, now, wp); err == nil {
+		t.Error("Expected invalid value error")
+	}
+
+	m.Value = int64(5)
+	if b, _, err = encodeRawMetric(m, now, wp); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(string(b), `"value":5`) {
+		t.Errorf("Expected integer value, got: %s", b)
+	}
+}